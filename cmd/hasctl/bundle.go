@@ -0,0 +1,123 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/redhat-appstudio/application-service/pkg/appbundle"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// renames is a repeatable flag of old=new renames
+type renames map[string]string
+
+func (r renames) String() string {
+	var pairs []string
+	for oldName, newName := range r {
+		pairs = append(pairs, oldName+"="+newName)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (r renames) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid rename %q, expected old=new", value)
+	}
+	r[parts[0]] = parts[1]
+	return nil
+}
+
+// runExport writes the bundle of the Application to the output file, or to out if no file is given
+func runExport(cl client.Client, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	namespace := flags.String("namespace", "default", "namespace of the Application")
+	application := flags.String("application", "", "name of the Application to export (required)")
+	output := flags.String("o", "", "file the bundle is written to, the standard output if empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *application == "" {
+		return fmt.Errorf("the -application flag is required")
+	}
+
+	bundle, err := appbundle.Export(context.Background(), cl, *namespace, *application)
+	if err != nil {
+		return err
+	}
+	data, err := appbundle.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		return os.WriteFile(*output, data, 0600)
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// runImport creates the resources of the bundle file in the namespace, and prints the created resources to out
+func runImport(cl client.Client, args []string, out io.Writer) error {
+	options := appbundle.ImportOptions{
+		Components:   renames{},
+		Environments: renames{},
+	}
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("f", "", "file of the bundle to import, - for the standard input (required)")
+	flags.StringVar(&options.Namespace, "namespace", "default", "namespace the resources are created in")
+	flags.StringVar(&options.Application, "application", "", "name of the imported Application, the name in the bundle if empty")
+	flags.Var(renames(options.Components), "rename-component", "rename a Component, as old=new (repeatable)")
+	flags.Var(renames(options.Environments), "rename-environment", "rename an Environment, as old=new (repeatable)")
+	gitOpsURL := flags.String("gitops-url", "", "URL of the GitOps repository the Application is bound to, a new repository is generated if empty")
+	gitOpsBranch := flags.String("gitops-branch", "", "branch of the GitOps repository")
+	gitOpsContext := flags.String("gitops-context", "", "context directory of the GitOps repository")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("the -f flag is required")
+	}
+	if *gitOpsURL != "" {
+		options.GitOpsRepository = &appbundle.GitOpsRepository{URL: *gitOpsURL, Branch: *gitOpsBranch, Context: *gitOpsContext}
+	}
+
+	var data []byte
+	var err error
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		return err
+	}
+	bundle, err := appbundle.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	created, err := appbundle.Import(context.Background(), cl, bundle, options)
+	for _, obj := range created {
+		fmt.Fprintf(out, "%s/%s created\n", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName())
+	}
+	return err
+}
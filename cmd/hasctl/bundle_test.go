@@ -0,0 +1,94 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestExportImport(t *testing.T) {
+	require.NoError(t, appstudiov1alpha1.AddToScheme(scheme.Scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		&appstudiov1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "petclinic", Namespace: "source"},
+		},
+		&appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "source"},
+			Spec:       appstudiov1alpha1.ComponentSpec{ComponentName: "backend", Application: "petclinic", ContainerImage: "quay.io/test/backend:latest"},
+		},
+	).Build()
+	bundleFile := filepath.Join(t.TempDir(), "bundle.yaml")
+
+	var out bytes.Buffer
+	err := runExport(cl, []string{"-namespace", "source", "-application", "petclinic", "-o", bundleFile}, &out)
+	require.NoError(t, err)
+	data, err := os.ReadFile(bundleFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "kind: ApplicationBundle")
+
+	err = runImport(cl, []string{"-f", bundleFile, "-namespace", "target", "-application", "petclinic-copy",
+		"-rename-component", "backend=api", "-gitops-url", "https://github.com/testorg/petclinic-gitops"}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "Application/petclinic-copy created\nComponent/api created\n", out.String())
+
+	var application appstudiov1alpha1.Application
+	require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Namespace: "target", Name: "petclinic-copy"}, &application))
+	assert.Equal(t, "https://github.com/testorg/petclinic-gitops", application.Spec.GitOpsRepository.URL)
+}
+
+func TestBundleFlags(t *testing.T) {
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr string
+	}{
+		{
+			name:    "Export without an Application",
+			run:     func() error { return runExport(cl, nil, &bytes.Buffer{}) },
+			wantErr: "the -application flag is required",
+		},
+		{
+			name:    "Import without a file",
+			run:     func() error { return runImport(cl, nil, &bytes.Buffer{}) },
+			wantErr: "the -f flag is required",
+		},
+		{
+			name:    "Import with an invalid rename",
+			run:     func() error { return runImport(cl, []string{"-rename-component", "backend"}, &bytes.Buffer{}) },
+			wantErr: "invalid rename \"backend\", expected old=new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.run(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,274 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+)
+
+// detectedComponent is a component detected in a context of the source, as a ComponentDetectionQuery would report it
+type detectedComponent struct {
+	Context       string `json:"context"`
+	DevfileURL    string `json:"devfileURL,omitempty"`
+	DockerfileURL string `json:"dockerfileURL,omitempty"`
+	Ports         []int  `json:"ports,omitempty"`
+	Language      string `json:"language,omitempty"`
+	ProjectType   string `json:"projectType,omitempty"`
+}
+
+// detectOptions are the options of the detection of the components of a local directory
+type detectOptions struct {
+	// Dir is the local directory of the source, the clone of the repository of the source if it has a URL
+	Dir string
+	// Source is the source of the components, as in the spec of a ComponentDetectionQuery
+	Source appstudiov1alpha1.GitSource
+	// RegistryURL is the devfile registry the components without a devfile are matched against
+	RegistryURL string
+	// Depth is 0 to detect a single component at the context, 1 to detect the components of the sub-folders of the context,
+	// and -1 to decide with Alizer, as the ComponentDetectionQuery controller does
+	Depth int
+}
+
+// runDetect detects the components of a local directory or a git repository, and prints them to out
+func runDetect(args []string, out io.Writer) error {
+	var options detectOptions
+	flags := flag.NewFlagSet("detect", flag.ContinueOnError)
+	flags.StringVar(&options.Dir, "path", "", "local directory to detect the components of")
+	flags.StringVar(&options.Source.URL, "url", "", "URL of the git repository to clone and detect the components of")
+	flags.StringVar(&options.Source.Revision, "revision", "", "revision of the git repository, its default branch if empty")
+	flags.StringVar(&options.Source.Context, "context", "", "context directory of the components in the source")
+	flags.StringVar(&options.Source.DevfileURL, "devfile-url", "", "URL of a devfile to validate instead of detecting the components")
+	flags.StringVar(&options.RegistryURL, "registry-url", getEnv("DEVFILE_REGISTRY_URL", devfile.DevfileRegistryEndpoint), "URL of the devfile registry")
+	flags.IntVar(&options.Depth, "depth", -1, "0 to detect a single component at the context, 1 to detect the components of its sub-folders, -1 to decide with Alizer")
+	output := flags.String("o", "json", "output format, json or yaml")
+	verbose := flags.Bool("v", false, "print the logs of the detection to the standard error")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (options.Dir == "") == (options.Source.URL == "") {
+		return fmt.Errorf("exactly one of the -path and -url flags is required")
+	}
+	if options.Depth < -1 || options.Depth > 1 {
+		return fmt.Errorf("the -depth flag must be -1, 0 or 1")
+	}
+	if *output != "json" && *output != "yaml" {
+		return fmt.Errorf("unsupported output format %q, expected json or yaml", *output)
+	}
+
+	log := logr.Discard()
+	if *verbose {
+		log = zap.New(zap.WriteTo(os.Stderr), zap.UseDevMode(true))
+	}
+
+	if options.Source.URL != "" && options.Source.DevfileURL == "" {
+		dir, err := os.MkdirTemp("", "hasctl-detect-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		if err := util.CloneRepo(dir, options.Source.URL, options.Source.Revision, os.Getenv("GITHUB_TOKEN")); err != nil {
+			return err
+		}
+		if options.Source.Revision == "" {
+			if options.Source.Revision, err = getCurrentBranch(dir); err != nil {
+				return err
+			}
+		}
+		options.Dir = dir
+	}
+
+	components, err := detect(log, devfile.AlizerClient{}, options)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if *output == "yaml" {
+		data, err = yaml.Marshal(components)
+	} else {
+		data, err = json.MarshalIndent(components, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// detect runs the detection of the ComponentDetectionQuery controller against the local directory of the options, and returns the components
+// sorted by context
+func detect(log logr.Logger, a devfile.Alizer, options detectOptions) ([]detectedComponent, error) {
+	source := options.Source
+	context := source.Context
+	if context == "" {
+		context = "./"
+	}
+	devfilesMap := make(map[string][]byte)
+	devfilesURLMap := make(map[string]string)
+	dockerfileContextMap := make(map[string]string)
+	componentPortsMap := make(map[string][]int)
+
+	if source.DevfileURL != "" {
+		shouldIgnoreDevfile, devfileBytes, err := devfile.ValidateDevfile(log, source.DevfileURL)
+		if err != nil {
+			return nil, err
+		}
+		if shouldIgnoreDevfile {
+			return nil, fmt.Errorf("the devfile %s does not contain a valid outerloop definition", source.DevfileURL)
+		}
+		devfilesMap[context] = devfileBytes
+		devfilesURLMap[context] = source.DevfileURL
+		return getDetectedComponents(devfilesMap, devfilesURLMap, dockerfileContextMap, componentPortsMap)
+	}
+
+	componentPath := path.Join(options.Dir, context)
+	isDevfilePresent := false
+	devfilePath := findFile(componentPath, []string{devfile.Devfile, devfile.HiddenDevfile, devfile.HiddenDirDevfile, devfile.HiddenDirHiddenDevfile})
+	if devfilePath != "" {
+		shouldIgnoreDevfile, devfileBytes, err := devfile.ValidateDevfile(log, path.Join(componentPath, devfilePath))
+		if err != nil {
+			return nil, err
+		}
+		if !shouldIgnoreDevfile {
+			devfilesMap[context] = devfileBytes
+			devfilesURLMap[context] = getDevfileURL(source, componentPath, context, devfilePath)
+			isDevfilePresent = true
+		}
+	}
+	dockerfilePath := findFile(componentPath, []string{devfile.Dockerfile, devfile.DockerDirDockerfile, devfile.HiddenDirDockerfile, devfile.BuildDirDockerfile,
+		devfile.Containerfile, devfile.DockerDirContainerfile, devfile.HiddenDirContainerfile, devfile.BuildDirContainerfile})
+	isDockerfilePresent := dockerfilePath != ""
+	if !isDevfilePresent && isDockerfilePresent {
+		dockerfileContextMap[context] = dockerfilePath
+	}
+
+	isMultiComponent := options.Depth == 1
+	if options.Depth == -1 {
+		var err error
+		if isMultiComponent, err = devfile.IsMultiComponent(log, a, componentPath, isDevfilePresent, isDockerfilePresent); err != nil {
+			return nil, err
+		}
+	}
+
+	devfilesMap, devfilesURLMap, dockerfileContextMap, componentPortsMap, err := devfile.DetectLocalComponents(log, a, componentPath, context, options.RegistryURL, source,
+		devfilesMap, devfilesURLMap, dockerfileContextMap, componentPortsMap, isMultiComponent, isDevfilePresent, isDockerfilePresent)
+	if err != nil {
+		return nil, err
+	}
+	if isMultiComponent {
+		// Without a git URL, the devfiles found in the sub-folders have no URL, so their local path is reported instead
+		for subContext := range devfilesMap {
+			if devfilesURLMap[subContext] == "" {
+				subPath := path.Join(componentPath, subContext)
+				devfilesURLMap[subContext] = getDevfileURL(source, subPath, subContext, findFile(subPath, []string{devfile.Devfile, devfile.HiddenDevfile,
+					devfile.HiddenDirDevfile, devfile.HiddenDirHiddenDevfile}))
+			}
+		}
+	}
+	return getDetectedComponents(devfilesMap, devfilesURLMap, dockerfileContextMap, componentPortsMap)
+}
+
+// getDetectedComponents returns the components of the contexts of the maps returned by the detection, sorted by context
+func getDetectedComponents(devfilesMap map[string][]byte, devfilesURLMap map[string]string, dockerfileContextMap map[string]string, componentPortsMap map[string][]int) ([]detectedComponent, error) {
+	contexts := make(map[string]bool)
+	for context := range devfilesMap {
+		contexts[context] = true
+	}
+	for context := range devfilesURLMap {
+		contexts[context] = true
+	}
+	for context := range dockerfileContextMap {
+		contexts[context] = true
+	}
+
+	components := []detectedComponent{}
+	for context := range contexts {
+		component := detectedComponent{
+			Context:       context,
+			DevfileURL:    devfilesURLMap[context],
+			DockerfileURL: dockerfileContextMap[context],
+			Ports:         componentPortsMap[context],
+		}
+		if devfileBytes, ok := devfilesMap[context]; ok {
+			devfileData, err := devfile.ParseDevfile(devfile.DevfileSrc{Data: string(devfileBytes)})
+			if err != nil {
+				return nil, err
+			}
+			component.Language = devfileData.GetMetadata().Language
+			component.ProjectType = devfileData.GetMetadata().ProjectType
+		}
+		components = append(components, component)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].Context < components[j].Context })
+	return components, nil
+}
+
+// getDevfileURL returns the URL of the devfile of the context in the git repository of the source, or its local path if the source has no URL
+func getDevfileURL(source appstudiov1alpha1.GitSource, dir string, context string, devfilePath string) string {
+	if source.URL == "" {
+		return path.Join(dir, devfilePath)
+	}
+	link, err := devfile.UpdateGitLink(source.URL, source.Revision, path.Join(source.Context, context, devfilePath))
+	if err != nil {
+		return path.Join(dir, devfilePath)
+	}
+	return link
+}
+
+// findFile returns the first of the locations that is a file in the directory, or an empty string if none is
+func findFile(dir string, locations []string) string {
+	for _, location := range locations {
+		if info, err := os.Stat(path.Join(dir, location)); err == nil && !info.IsDir() {
+			return location
+		}
+	}
+	return ""
+}
+
+// getCurrentBranch returns the branch checked out in the git repository of the directory
+func getCurrentBranch(dir string) (string, error) {
+	c := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	c.Dir = dir
+	branch, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the branch of the repo: %v", err)
+	}
+	return strings.TrimSpace(string(branch)), nil
+}
+
+// getEnv returns the value of the environment variable, or the default value if it is not set
+func getEnv(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
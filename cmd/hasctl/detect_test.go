@@ -0,0 +1,209 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDevfile = `
+schemaVersion: 2.2.0
+metadata:
+  name: backend
+  language: Java
+  projectType: springboot
+components:
+- name: image-build
+  image:
+    imageName: backend:latest
+    dockerfile:
+      uri: docker/Dockerfile
+      buildContext: .
+- name: kubernetes-deploy
+  attributes:
+    deployment/container-port: 8080
+  kubernetes:
+    deployByDefault: false
+    inlined: |-
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: backend
+      spec:
+        template:
+          spec:
+            containers:
+            - image: backend:latest
+              name: backend
+commands:
+- id: build-image
+  apply:
+    component: image-build
+- id: deployk8s
+  apply:
+    component: kubernetes-deploy
+- id: deploy
+  composite:
+    commands:
+    - build-image
+    - deployk8s
+    group:
+      kind: deploy
+      isDefault: true
+`
+
+// writeFiles writes the files, keyed by their path relative to the directory
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		filePath := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0750))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0600))
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		options detectOptions
+		want    []detectedComponent
+		wantErr string
+	}{
+		{
+			name:    "Devfile at the root",
+			files:   map[string]string{"devfile.yaml": testDevfile, "docker/Dockerfile": "FROM scratch"},
+			options: detectOptions{Depth: -1},
+			want: []detectedComponent{
+				{Context: "./", DevfileURL: "devfile.yaml", Language: "Java", ProjectType: "springboot"},
+			},
+		},
+		{
+			name:    "Dockerfile at the root",
+			files:   map[string]string{"Containerfile": "FROM scratch"},
+			options: detectOptions{Depth: -1},
+			want: []detectedComponent{
+				{Context: "./", DockerfileURL: "Containerfile"},
+			},
+		},
+		{
+			name: "Components in sub-folders",
+			files: map[string]string{
+				"backend/.devfile/devfile.yaml": testDevfile,
+				"backend/docker/Dockerfile":     "FROM scratch",
+				"frontend/build/Dockerfile":     "FROM scratch",
+			},
+			options: detectOptions{Depth: 1},
+			want: []detectedComponent{
+				{Context: "backend", DevfileURL: "backend/.devfile/devfile.yaml", Language: "Java", ProjectType: "springboot"},
+				{Context: "frontend", DockerfileURL: "build/Dockerfile"},
+			},
+		},
+		{
+			name:    "Components in the sub-folders of the context of a git repository",
+			files:   map[string]string{"services/backend/devfile.yaml": testDevfile},
+			options: detectOptions{Depth: 1, Source: appstudiov1alpha1.GitSource{URL: "https://github.com/testorg/petclinic", Revision: "main", Context: "services"}},
+			want: []detectedComponent{
+				{Context: "backend", DevfileURL: "https://raw.githubusercontent.com/testorg/petclinic/main/services/backend/devfile.yaml", Language: "Java", ProjectType: "springboot"},
+			},
+		},
+		{
+			name:    "No component found by Alizer",
+			files:   map[string]string{"empty/README.md": "empty"},
+			options: detectOptions{Depth: -1, Source: appstudiov1alpha1.GitSource{Context: "empty"}},
+			want:    []detectedComponent{},
+		},
+		{
+			name:    "Invalid devfile",
+			files:   map[string]string{"devfile.yaml": "schemaVersion: 2.2.0\ncomponents: invalid"},
+			options: detectOptions{Depth: 0},
+			wantErr: "failed to parse the devfile content",
+		},
+		{
+			name:    "Alizer error",
+			files:   map[string]string{"errorAnalyze/README.md": "error"},
+			options: detectOptions{Depth: -1, Source: appstudiov1alpha1.GitSource{Context: "errorAnalyze"}},
+			wantErr: "dummy DetectComponents err",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			tt.options.Dir = dir
+			tt.options.RegistryURL = devfile.DevfileStageRegistryEndpoint
+
+			components, err := detect(logr.Discard(), devfile.MockAlizerClient{}, tt.options)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			// The local paths of the devfiles are relative to the temporary directory
+			for i := range components {
+				if rel, err := filepath.Rel(dir, components[i].DevfileURL); err == nil && filepath.IsAbs(components[i].DevfileURL) {
+					components[i].DevfileURL = rel
+				}
+			}
+			assert.Equal(t, tt.want, components)
+		})
+	}
+}
+
+func TestDetectFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "No source",
+			wantErr: "exactly one of the -path and -url flags is required",
+		},
+		{
+			name:    "Both sources",
+			args:    []string{"-path", ".", "-url", "https://github.com/testorg/petclinic"},
+			wantErr: "exactly one of the -path and -url flags is required",
+		},
+		{
+			name:    "Invalid depth",
+			args:    []string{"-path", ".", "-depth", "2"},
+			wantErr: "the -depth flag must be -1, 0 or 1",
+		},
+		{
+			name:    "Invalid output",
+			args:    []string{"-path", ".", "-o", "xml"},
+			wantErr: "unsupported output format \"xml\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, runDetect(tt.args, &bytes.Buffer{}), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/controllers"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+)
+
+// runRender writes the GitOps resources of a Component, and of its overlay for an Environment, to an output directory, and prints the written files to out
+func runRender(args []string, out io.Writer) error {
+	var options controllers.RenderOptions
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	componentFile := flags.String("component", "", "file of the Component, its devfile is the one of its status unless -devfile is set")
+	devfileFile := flags.String("devfile", "", "file of the devfile of the Component")
+	environmentFile := flags.String("environment", "", "file of the Environment to render the overlay of the Component for")
+	snapshotFile := flags.String("snapshot", "", "file of the Snapshot the Component is bound at, the image of the Component if empty")
+	application := flags.String("application", "application", "Application of the Component, if -component is not set")
+	format := flags.String("format", string(gitops.KustomizeFormat), "format of the GitOps resources, kustomize or helm")
+	flags.StringVar(&options.Context, "context", "", "context directory of the GitOps repository")
+	output := flags.String("o", "", "directory the GitOps repository is rendered in (required)")
+	verbose := flags.Bool("v", false, "print the logs of the render to the standard error")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		return fmt.Errorf("the -o flag is required")
+	}
+	if *componentFile == "" && *devfileFile == "" {
+		return fmt.Errorf("at least one of the -component and -devfile flags is required")
+	}
+	options.Format = gitops.Format(*format)
+	if options.Format != gitops.KustomizeFormat && options.Format != gitops.HelmFormat {
+		return fmt.Errorf("unsupported format %q, expected %s or %s", *format, gitops.KustomizeFormat, gitops.HelmFormat)
+	}
+
+	if *componentFile != "" {
+		if err := readYAMLFile(*componentFile, &options.Component); err != nil {
+			return err
+		}
+	}
+	if *devfileFile != "" {
+		devfileBytes, err := os.ReadFile(*devfileFile)
+		if err != nil {
+			return err
+		}
+		options.Component.Status.Devfile = string(devfileBytes)
+	}
+	if *componentFile == "" {
+		// Without a Component, the Component is named after the devfile
+		devfileData, err := devfile.ParseDevfile(devfile.DevfileSrc{Data: options.Component.Status.Devfile})
+		if err != nil {
+			return err
+		}
+		options.Component.Name = devfileData.GetMetadata().Name
+		options.Component.Spec.ComponentName = options.Component.Name
+		options.Component.Spec.Application = *application
+	}
+	if options.Component.Namespace == "" {
+		options.Component.Namespace = "default"
+	}
+	if *environmentFile != "" {
+		options.Environment = &appstudiov1alpha1.Environment{}
+		if err := readYAMLFile(*environmentFile, options.Environment); err != nil {
+			return err
+		}
+	}
+	if *snapshotFile != "" {
+		options.Snapshot = &appstudiov1alpha1.Snapshot{}
+		if err := readYAMLFile(*snapshotFile, options.Snapshot); err != nil {
+			return err
+		}
+	}
+
+	log := logr.Discard()
+	if *verbose {
+		log = zap.New(zap.WriteTo(os.Stderr), zap.UseDevMode(true))
+	}
+	fs := ioutils.NewFilesystem()
+	if err := controllers.RenderGitOps(log, fs, *output, options); err != nil {
+		return err
+	}
+	return fs.Walk(*output, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			fmt.Fprintln(out, path)
+		}
+		return err
+	})
+}
+
+// readYAMLFile unmarshals the YAML of the file into obj
+func readYAMLFile(file string, obj interface{}) error {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, obj); err != nil {
+		return fmt.Errorf("unable to parse %s: %v", file, err)
+	}
+	return nil
+}
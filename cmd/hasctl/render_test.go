@@ -0,0 +1,193 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	component := `
+apiVersion: appstudio.redhat.com/v1alpha1
+kind: Component
+metadata:
+  name: backend
+  namespace: test
+spec:
+  componentName: backend
+  application: petclinic
+  containerImage: quay.io/test/backend:latest
+  targetPort: 8080
+`
+	environment := `
+apiVersion: appstudio.redhat.com/v1alpha1
+kind: Environment
+metadata:
+  name: staging
+spec:
+  configuration:
+    env:
+    - name: LOG_LEVEL
+      value: debug
+`
+	snapshot := `
+apiVersion: appstudio.redhat.com/v1alpha1
+kind: Snapshot
+metadata:
+  name: petclinic-snapshot
+spec:
+  application: petclinic
+  components:
+  - name: backend
+    containerImage: quay.io/test/backend:v2
+`
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		// args are the arguments of the command, other than -o
+		args      []string
+		wantFiles []string
+		// wantFile is a file expected to contain wantContent
+		wantFile    string
+		wantContent string
+		wantErr     string
+	}{
+		{
+			name:  "Devfile alone",
+			files: map[string]string{"devfile.yaml": testDevfile},
+			args:  []string{"-devfile", "devfile.yaml"},
+			wantFiles: []string{
+				"components/backend/base/deployment.yaml",
+				"components/backend/base/kustomization.yaml",
+			},
+			wantFile:    "components/backend/base/deployment.yaml",
+			wantContent: "app.kubernetes.io/part-of: application",
+		},
+		{
+			name:  "Component with an environment and a snapshot",
+			files: map[string]string{"component.yaml": component, "devfile.yaml": testDevfile, "environment.yaml": environment, "snapshot.yaml": snapshot},
+			args:  []string{"-component", "component.yaml", "-devfile", "devfile.yaml", "-environment", "environment.yaml", "-snapshot", "snapshot.yaml", "-context", "gitops"},
+			wantFiles: []string{
+				"gitops/components/backend/base/deployment.yaml",
+				"gitops/components/backend/base/kustomization.yaml",
+				"gitops/components/backend/base/service.yaml",
+				"gitops/components/backend/overlays/staging/deployment-patch.yaml",
+				"gitops/components/backend/overlays/staging/kustomization.yaml",
+				"gitops/components/backend/overlays/staging/route.yaml",
+			},
+			wantFile:    "gitops/components/backend/overlays/staging/deployment-patch.yaml",
+			wantContent: "quay.io/test/backend:v2",
+		},
+		{
+			name:  "Helm chart",
+			files: map[string]string{"component.yaml": component, "devfile.yaml": testDevfile, "environment.yaml": environment},
+			args:  []string{"-component", "component.yaml", "-devfile", "devfile.yaml", "-environment", "environment.yaml", "-format", "helm"},
+			wantFiles: []string{
+				"components/backend/chart/Chart.yaml",
+				"components/backend/chart/templates/deployment-backend.yaml",
+				"components/backend/chart/values-staging.yaml",
+				"components/backend/chart/values.yaml",
+			},
+		},
+		{
+			name:    "Invalid component file",
+			files:   map[string]string{"component.yaml": "metadata: invalid"},
+			args:    []string{"-component", "component.yaml"},
+			wantErr: "cannot unmarshal string into Go struct field .metadata",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			outputDir := filepath.Join(dir, "output")
+			var args []string
+			for _, arg := range tt.args {
+				if _, ok := tt.files[arg]; ok {
+					arg = filepath.Join(dir, arg)
+				}
+				args = append(args, arg)
+			}
+
+			var out bytes.Buffer
+			err := runRender(append(args, "-o", outputDir), &out)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			var files []string
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				rel, err := filepath.Rel(outputDir, line)
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				files = append(files, filepath.ToSlash(rel))
+			}
+			sort.Strings(files)
+			assert.Equal(t, tt.wantFiles, files)
+			if tt.wantFile != "" {
+				content, err := os.ReadFile(filepath.Join(outputDir, tt.wantFile))
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Contains(t, string(content), tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestRenderFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "No output",
+			args:    []string{"-devfile", "devfile.yaml"},
+			wantErr: "the -o flag is required",
+		},
+		{
+			name:    "No component",
+			args:    []string{"-o", "output"},
+			wantErr: "at least one of the -component and -devfile flags is required",
+		},
+		{
+			name:    "Invalid format",
+			args:    []string{"-devfile", "devfile.yaml", "-o", "output", "-format", "jsonnet"},
+			wantErr: "unsupported format \"jsonnet\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runRender(tt.args, &bytes.Buffer{})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
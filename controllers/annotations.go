@@ -0,0 +1,221 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// AutoscalingAnnotation is set on a Component to declare its autoscaling bounds and targets, as a JSON object
+	AutoscalingAnnotation = "appstudio.openshift.io/autoscaling"
+
+	// ProbesAnnotation is set on a Component to configure its liveness, readiness and startup probes, as a JSON object
+	ProbesAnnotation = "appstudio.openshift.io/probes"
+
+	// ContainersAnnotation is set on a Component to declare its main container, sidecars and init containers, as a JSON object
+	ContainersAnnotation = "appstudio.openshift.io/containers"
+
+	// WorkloadAnnotation is set on a Component to run it as a Deployment, StatefulSet, Job or CronJob, as a JSON object
+	WorkloadAnnotation = "appstudio.openshift.io/workload"
+
+	// AvailabilityAnnotation is set on a Component to declare its pod disruption budget, topology spread and anti-affinity, as a JSON object
+	AvailabilityAnnotation = "appstudio.openshift.io/availability"
+
+	// NetworkPolicyAnnotation is set on a Component to generate its NetworkPolicy and declare the sibling Components it sends traffic to, as a JSON object
+	NetworkPolicyAnnotation = "appstudio.openshift.io/network-policy"
+
+	// RouterNamespaceAnnotation is set on an Environment to name the namespace of the router or ingress controller
+	// that the NetworkPolicies admit the public traffic from
+	RouterNamespaceAnnotation = "appstudio.openshift.io/router-namespace"
+
+	// GitOpsFormatAnnotation is set on an Application to select the format of its GitOps resources, kustomize or helm
+	GitOpsFormatAnnotation = "appstudio.openshift.io/gitops-format"
+
+	// AutoscalingOverridesAnnotation is set on a SnapshotEnvironmentBinding to override the autoscaling configuration
+	// of its Components for the environment, as a JSON object keyed by Component name
+	AutoscalingOverridesAnnotation = "appstudio.openshift.io/autoscaling-overrides"
+
+	// AvailabilityOverridesAnnotation is set on a SnapshotEnvironmentBinding to override the availability configuration
+	// of its Components for the environment, as a JSON object keyed by Component name
+	AvailabilityOverridesAnnotation = "appstudio.openshift.io/availability-overrides"
+
+	// DeployedStateAnnotation is written on a SnapshotEnvironmentBinding by the controller to record the Snapshot, image, commit ID
+	// and time of the last sync of each of its Components, as a JSON object keyed by Component name
+	DeployedStateAnnotation = "appstudio.openshift.io/deployed-state"
+
+	// DryRunAnnotation is set to "true" on a SnapshotEnvironmentBinding to generate its overlays and report the diff
+	// against the GitOps repository branch in a ConfigMap, without pushing
+	DryRunAnnotation = "appstudio.openshift.io/dry-run"
+
+	// DeploymentHistoryAnnotation is written on a SnapshotEnvironmentBinding by the controller to record its last syncs, with the Snapshot,
+	// commit ID and time of each sync, as a JSON array with the most recent sync first
+	DeploymentHistoryAnnotation = "appstudio.openshift.io/deployment-history"
+
+	// RollbackAnnotation is set on a SnapshotEnvironmentBinding to pin it to an earlier Snapshot, or to a commit of its deployment history,
+	// as a JSON object, e.g. {"snapshot": "my-snapshot"} or {"commitID": "ca82a6d"}
+	RollbackAnnotation = "appstudio.openshift.io/rollback"
+
+	// CompletedRollbackAnnotation is written on a SnapshotEnvironmentBinding by the controller to record the last rollback to a commit that it
+	// pushed, with the commit rolled back to and the resulting commit ID of each component, as a JSON object, so that the rollback is only pushed once
+	CompletedRollbackAnnotation = "appstudio.openshift.io/completed-rollback"
+
+	// SecretsAnnotation is set on an Environment to reference the Secrets of all of its Components, as a JSON object with secretKeyRef
+	// env vars, SealedSecrets and ExternalSecrets. Secret values are never written in the GitOps repository
+	SecretsAnnotation = "appstudio.openshift.io/secrets"
+
+	// SecretOverridesAnnotation is set on a SnapshotEnvironmentBinding to reference additional Secrets per Component,
+	// as a JSON object keyed by Component name with the format of the secrets annotation of the Environment
+	SecretOverridesAnnotation = "appstudio.openshift.io/secret-overrides"
+
+	// GitOpsRepositoryAccessAnnotation is set on an Application to configure the visibility of its generated GitOps repository and grant
+	// permissions on it, as a JSON object, e.g. {"visibility": "private", "collaborators": {"user": "write"}, "teams": {"team-slug": "maintain"}}
+	GitOpsRepositoryAccessAnnotation = "appstudio.openshift.io/gitops-repository-access"
+
+	// GitOpsRepositoryGrantsAnnotation is written on an Application by the controller to record the collaborators and teams it granted
+	// access to the GitOps repository, so that their access is revoked once they are removed from the access annotation, with the digest
+	// of the access settings it applied and the time they were applied, so that they are only applied again once they change or are due a resync
+	GitOpsRepositoryGrantsAnnotation = "appstudio.openshift.io/gitops-repository-grants"
+
+	// GitOpsRepositoryRetentionAnnotation is set on an Application to archive its generated GitOps repository when it is deleted, instead of
+	// deleting it. It is either "archive", to retain the archived repository indefinitely, the duration after which it is deleted, e.g. "720h",
+	// or "delete", to delete the repository with the Application. It is set to "archive" when the Application is created, and an Application
+	// without it archives its repository indefinitely
+	GitOpsRepositoryRetentionAnnotation = "appstudio.openshift.io/gitops-repository-retention"
+
+	// AppModelPublicationAnnotation is written on an Application by the controller to record the last publication of its devfile
+	// to the app-model repository, with the commit ID, the digest of the published devfile and the time of the publication, as a JSON object
+	AppModelPublicationAnnotation = "appstudio.openshift.io/app-model-publication"
+
+	// GitOpsRepositoryMigrationAnnotation is set on an Application to rename its generated GitOps repository, or move it to another
+	// GitHub org, as a JSON object, e.g. {"org": "other-org", "name": "new-name"}. The org defaults to the org of the controller and must be
+	// one of its migration orgs, and the name defaults to the name given by the naming policy of the controller. Moving the repository to
+	// another Git provider is not supported
+	GitOpsRepositoryMigrationAnnotation = "appstudio.openshift.io/gitops-repository-migration"
+
+	// GitOpsRepositoryMigrationStatusAnnotation is written on an Application by the controller to record the progress of the migration
+	// of its GitOps repository, with the previous and new repository URLs, the phase and the updated Components, as a JSON object
+	GitOpsRepositoryMigrationStatusAnnotation = "appstudio.openshift.io/gitops-repository-migration-status"
+
+	// ApplicationHealthAnnotation is written on an Application by the controller to record the health aggregated from its Components
+	// and Bindings, with the counts of Components by health and the environments it is bound to with their last commit, as a JSON object
+	ApplicationHealthAnnotation = "appstudio.openshift.io/health"
+)
+
+// ClusterStateAnnotations are the annotations written by the controllers to record the state of the resources in their cluster,
+// and the annotations requesting one-off operations on them. They don't apply to copies of the resources in another namespace or cluster
+var ClusterStateAnnotations = []string{
+	AppModelPublicationAnnotation,
+	CompletedRollbackAnnotation,
+	DeployedStateAnnotation,
+	DeploymentHistoryAnnotation,
+	DryRunAnnotation,
+	GitOpsRepositoryGrantsAnnotation,
+	GitOpsRepositoryMigrationAnnotation,
+	GitOpsRepositoryMigrationStatusAnnotation,
+	ApplicationHealthAnnotation,
+	RollbackAnnotation,
+	finalizeCount,
+}
+
+// getJSONAnnotation unmarshals the JSON value of the given annotation into out.
+// false is returned if the annotation is not set on the object
+func getJSONAnnotation(obj client.Object, annotation string, out interface{}) (bool, error) {
+	value, ok := obj.GetAnnotations()[annotation]
+	if !ok || value == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(value), out); err != nil {
+		return true, fmt.Errorf("unable to parse the %s annotation on %s: %v", annotation, obj.GetName(), err)
+	}
+	return true, nil
+}
+
+// setJSONAnnotation sets the annotation on the object to the JSON value of in
+func setJSONAnnotation(obj client.Object, annotation string, in interface{}) error {
+	value, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("unable to marshal the %s annotation of %s: %v", annotation, obj.GetName(), err)
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[annotation] = string(value)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// annotationsChangedPredicate returns a predicate that triggers on updates where the value of any of the given annotations changed.
+// It is meant to be combined with predicate.GenerationChangedPredicate, so that changes to the annotations that the controllers read
+// are reconciled without reacting to the annotations that the controllers write themselves
+func annotationsChangedPredicate(annotations ...string) predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			oldAnnotations := e.ObjectOld.GetAnnotations()
+			newAnnotations := e.ObjectNew.GetAnnotations()
+			for _, annotation := range annotations {
+				if oldAnnotations[annotation] != newAnnotations[annotation] {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// metadataChangedPredicate returns a predicate that triggers on updates that change the generation of the object, or its metadata other
+// than the given annotations. Updates that only change the status of the object, or the annotations that the controllers write themselves,
+// are ignored, so that recording the outcome of a reconcile does not trigger another one
+func metadataChangedPredicate(ignoredAnnotations ...string) predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			if e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+				!e.ObjectOld.GetDeletionTimestamp().Equal(e.ObjectNew.GetDeletionTimestamp()) ||
+				!reflect.DeepEqual(e.ObjectOld.GetFinalizers(), e.ObjectNew.GetFinalizers()) ||
+				!reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) {
+				return true
+			}
+			oldAnnotations := withoutAnnotations(e.ObjectOld.GetAnnotations(), ignoredAnnotations)
+			newAnnotations := withoutAnnotations(e.ObjectNew.GetAnnotations(), ignoredAnnotations)
+			return !reflect.DeepEqual(oldAnnotations, newAnnotations)
+		},
+	}
+}
+
+// withoutAnnotations returns a copy of the annotations without the given ones
+func withoutAnnotations(annotations map[string]string, removed []string) map[string]string {
+	filtered := make(map[string]string, len(annotations))
+	for annotation, value := range annotations {
+		filtered[annotation] = value
+	}
+	for _, annotation := range removed {
+		delete(filtered, annotation)
+	}
+	return filtered
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestMetadataChangedPredicate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(application *appstudiov1alpha1.Application)
+		want   bool
+	}{
+		{
+			name: "Spec changed",
+			update: func(application *appstudiov1alpha1.Application) {
+				application.Spec.DisplayName = "New name"
+				application.Generation++
+			},
+			want: true,
+		},
+		{
+			name: "Annotation read by the controller changed",
+			update: func(application *appstudiov1alpha1.Application) {
+				application.Annotations[GitOpsRepositoryAccessAnnotation] = `{"visibility": "private"}`
+			},
+			want: true,
+		},
+		{
+			name: "Finalize counter incremented",
+			update: func(application *appstudiov1alpha1.Application) {
+				application.Annotations[finalizeCount] = "1"
+			},
+			want: true,
+		},
+		{
+			name: "Finalizer removed",
+			update: func(application *appstudiov1alpha1.Application) {
+				application.Finalizers = nil
+			},
+			want: true,
+		},
+		{
+			name: "Deletion requested",
+			update: func(application *appstudiov1alpha1.Application) {
+				now := metav1.Now()
+				application.DeletionTimestamp = &now
+			},
+			want: true,
+		},
+		{
+			name: "Health annotation changed",
+			update: func(application *appstudiov1alpha1.Application) {
+				application.Annotations[ApplicationHealthAnnotation] = `{"components": {"total": 2, "ready": 2}}`
+			},
+		},
+		{
+			name: "Grants annotation added",
+			update: func(application *appstudiov1alpha1.Application) {
+				application.Annotations[GitOpsRepositoryGrantsAnnotation] = `{"collaborators": ["user1"]}`
+			},
+		},
+		{
+			name: "Status changed",
+			update: func(application *appstudiov1alpha1.Application) {
+				meta.SetStatusCondition(&application.Status.Conditions, metav1.Condition{Type: applicationReadyConditionType, Status: metav1.ConditionTrue, Reason: "Ready"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-app",
+					Namespace:  "default",
+					Generation: 1,
+					Finalizers: []string{appFinalizerName},
+					Annotations: map[string]string{
+						finalizeCount:               "0",
+						ApplicationHealthAnnotation: `{"components": {"total": 2}}`,
+					},
+				},
+			}
+			updatedApplication := application.DeepCopy()
+			tt.update(updatedApplication)
+
+			p := metadataChangedPredicate(ApplicationHealthAnnotation, GitOpsRepositoryGrantsAnnotation)
+			assert.Equal(t, tt.want, p.Update(event.UpdateEvent{ObjectOld: application, ObjectNew: updatedApplication}))
+		})
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -43,7 +44,6 @@ import (
 	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
 	github "github.com/redhat-appstudio/application-service/pkg/github"
 	logutil "github.com/redhat-appstudio/application-service/pkg/log"
-	util "github.com/redhat-appstudio/application-service/pkg/util"
 )
 
 // ApplicationReconciler reconciles a Application object
@@ -53,6 +53,15 @@ type ApplicationReconciler struct {
 	Log               logr.Logger
 	GitHubTokenClient github.GitHubToken
 	GitHubOrg         string
+
+	// GitOpsRepoVisibility is the visibility policy of the generated GitOps repositories, private if unset
+	GitOpsRepoVisibility github.RepositoryVisibility
+
+	// GitOpsRepoNaming is the naming policy of the generated GitOps repositories
+	GitOpsRepoNaming github.RepositoryNamingPolicy
+
+	// GitOpsRepoMigrationOrgs are the GitHub orgs that the generated GitOps repositories can be migrated to, the org of the controller if unset
+	GitOpsRepoMigrationOrgs []string
 }
 
 const applicationName = "Application"
@@ -60,6 +69,7 @@ const applicationName = "Application"
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -131,6 +141,9 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				return ctrl.Result{}, err
 			}
 		}
+
+		// The GitOps repository of a deleted Application is archived or deleted, and must not be reconciled any further
+		return ctrl.Result{}, nil
 	}
 
 	log.Info(fmt.Sprintf("Starting reconcile loop for %v", req.NamespacedName))
@@ -140,16 +153,21 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		// See if a gitops/appModel repo(s) were passed in. If not, generate them.
 		gitOpsRepo := application.Spec.GitOpsRepository.URL
 		appModelRepo := application.Spec.AppModelRepository.URL
+		var gitOpsBranch string
 		if gitOpsRepo == "" {
 			// If both repositories are blank, just generate a single shared repository
-			uniqueHash := util.GenerateUniqueHashForWorkloadImageTag(application.Namespace)
-			repoName := github.GenerateNewRepositoryName(application.Name, uniqueHash)
+			access, err := r.getRepositoryAccess(&application)
+			if err != nil {
+				log.Error(err, "")
+				r.SetCreateConditionAndUpdateCR(ctx, req, &application, err)
+				return reconcile.Result{}, err
+			}
 
-			// Generate the git repo in the redhat-appstudio-appdata org
+			// Generate the git repo in the redhat-appstudio-appdata org, named by the naming policy
 			// Not an SLI metric.  Used for determining the number of git operation requests
 			metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "GenerateNewRepository"}
 			metrics.ControllerGitRequest.With(metricsLabel).Inc()
-			repoUrl, err := ghClient.GenerateNewRepository(ctx, r.GitHubOrg, repoName, "GitOps Repository")
+			repoUrl, err := ghClient.GenerateNewRepositoryWithPolicy(ctx, r.GitHubOrg, r.GitOpsRepoNaming, getRepositoryNameValues(&application), "GitOps Repository", access.Visibility)
 			if err != nil {
 				metrics.HandleRateLimitMetrics(err, metricsLabel)
 				log.Error(err, fmt.Sprintf("Unable to create repository %v", repoUrl))
@@ -158,6 +176,17 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			}
 
 			gitOpsRepo = repoUrl
+		} else {
+			// Validate the user-supplied GitOps repository before writing the devfile, as Components only use the repository once it is set
+			var validationMessage string
+			gitOpsBranch, validationMessage, err = r.validateGitOpsRepository(ctx, &application, ghClient)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to validate the GitOps repository %v", req.NamespacedName))
+				r.SetGitOpsRepositoryConditionAndUpdateCR(ctx, req, &application, "", err)
+				r.SetCreateConditionAndUpdateCR(ctx, req, &application, err)
+				return reconcile.Result{}, err
+			}
+			r.SetGitOpsRepositoryConditionAndUpdateCR(ctx, req, &application, validationMessage, nil)
 		}
 		if appModelRepo == "" {
 			// If the appModelRepo is unset, just set it to the gitops repo
@@ -171,6 +200,18 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			r.SetCreateConditionAndUpdateCR(ctx, req, &application, err)
 			return reconcile.Result{}, err
 		}
+		if application.Spec.GitOpsRepository.URL == "" {
+			// Record that the GitOps repository was generated, so that only the controller's own repositories are managed by it
+			devfileMeta := devfileData.GetMetadata()
+			devfileMeta.Attributes = devfileMeta.Attributes.PutBoolean(generatedGitOpsRepositoryAttribute, true)
+			devfileData.SetMetadata(devfileMeta)
+		}
+		if application.Spec.GitOpsRepository.Branch == "" && gitOpsBranch != "" {
+			// Record the default branch of the user-supplied GitOps repository, so that Components push to it
+			devfileMeta := devfileData.GetMetadata()
+			devfileMeta.Attributes = devfileMeta.Attributes.PutString("gitOpsRepository.branch", gitOpsBranch)
+			devfileData.SetMetadata(devfileMeta)
+		}
 		yamlData, err := yaml.Marshal(devfileData)
 		if err != nil {
 			log.Error(err, fmt.Sprintf("Unable to marshall Application devfile, exiting reconcile loop %v", req.NamespacedName))
@@ -225,8 +266,24 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// Rename or move the generated GitOps repository if a migration was requested
+	if err := r.migrateGitOpsRepository(ctx, req, &application, ghClient); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Keep the visibility and the permissions of the generated GitOps repository in sync with the Application
+	result, err := r.reconcileRepositoryAccess(ctx, req, &application, ghClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Publish the devfile of the Application to its app-model repository whenever it changes
+	if err := r.publishAppModel(ctx, req, &application, ghClient); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	log.Info(fmt.Sprintf("Finished reconcile loop for %v", req.NamespacedName))
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -234,8 +291,14 @@ func (r *ApplicationReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 	gofakeit.New(0)
 	log := ctrl.LoggerFrom(ctx).WithName("controllers").WithName("Application")
 
+	if err := r.setupHealthWithManager(mgr); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&appstudiov1alpha1.Application{}).
+		// The annotations written by the controllers and the status of the Application record the outcome of the reconciles,
+		// and must not trigger another reconcile and its GitHub calls
+		For(&appstudiov1alpha1.Application{}, builder.WithPredicates(metadataChangedPredicate(ApplicationHealthAnnotation, GitOpsRepositoryGrantsAnnotation, AppModelPublicationAnnotation, GitOpsRepositoryMigrationStatusAnnotation))).
 		WithOptions(controller.Options{
 			RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Duration(1*time.Second), time.Duration(1000*time.Second)),
 		}).
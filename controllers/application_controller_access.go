@@ -0,0 +1,270 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/metrics"
+	util "github.com/redhat-appstudio/application-service/pkg/util"
+	"golang.org/x/exp/maps"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// gitOpsRepositoryAccessConditionType is the condition of an Application reporting the reconciliation of the access to its GitOps repository
+const gitOpsRepositoryAccessConditionType = "GitOpsRepositoryAccess"
+
+// generatedGitOpsRepositoryAttribute is the devfile attribute of an Application recording that its GitOps repository was generated by the controller
+const generatedGitOpsRepositoryAttribute = "gitOpsRepository.generated"
+
+// repositoryAccessResyncPeriod is the period at which the access settings of the generated GitOps repositories are reconciled,
+// to revert the changes made to them out-of-band
+const repositoryAccessResyncPeriod = time.Hour
+
+// repositoryGrants are the collaborators and teams that the controller granted access to the GitOps repository of an Application,
+// with the digest of the access settings it last applied and the time they were applied
+type repositoryGrants struct {
+	Collaborators []string     `json:"collaborators,omitempty"`
+	Teams         []string     `json:"teams,omitempty"`
+	AccessDigest  string       `json:"accessDigest,omitempty"`
+	SyncedAt      *metav1.Time `json:"syncedAt,omitempty"`
+}
+
+// getRepositoryAccessDigest returns the digest of the access settings of the repository
+func getRepositoryAccessDigest(repoName string, access github.RepositoryAccess) (string, error) {
+	// maps are marshalled with sorted keys, so equal settings have the same digest
+	accessJSON, err := json.Marshal(access)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(append([]byte(repoName+"\n"), accessJSON...))
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// getRepositoryAccessResyncDelay returns the time left until the access settings with the digest must be applied again to the repository.
+// The settings are applied right away if they differ from the settings that were last applied, or if they were applied a resync period ago
+func getRepositoryAccessResyncDelay(grants repositoryGrants, accessDigest string, now time.Time) time.Duration {
+	if grants.AccessDigest != accessDigest || grants.SyncedAt == nil {
+		return 0
+	}
+	delay := grants.SyncedAt.Add(repositoryAccessResyncPeriod).Sub(now)
+	if delay < 0 || delay > repositoryAccessResyncPeriod {
+		return 0
+	}
+	return delay
+}
+
+// getGitOpsRepositoryURL returns the URL of the GitOps repository recorded in the devfile of the Application
+func getGitOpsRepositoryURL(application *appstudiov1alpha1.Application) (string, error) {
+	devfileSrc := devfile.DevfileSrc{
+		Data: application.Status.Devfile,
+	}
+	devfileObj, err := devfile.ParseDevfile(devfileSrc)
+	if err != nil {
+		return "", err
+	}
+	devfileGitOps := devfileObj.GetMetadata().Attributes.Get("gitOpsRepository.url", &err)
+	if err != nil {
+		return "", err
+	}
+	gitOpsURL, ok := devfileGitOps.(string)
+	if !ok {
+		return "", fmt.Errorf("the gitOpsRepository.url attribute of the devfile of Application %s is not a string", application.Name)
+	}
+	return gitOpsURL, nil
+}
+
+// getControllerOrg returns the GitHub org of the controller holding the repository, either the org the GitOps repositories are generated in
+// or one of the orgs they can be migrated to, or an empty string if the repository is in none of them
+func (r *ApplicationReconciler) getControllerOrg(repoURL string) string {
+	if !github.IsGitHubRepository(repoURL) {
+		return ""
+	}
+	_, orgName, err := github.GetRepoAndOrgFromURL(repoURL)
+	if err != nil {
+		return ""
+	}
+	for _, org := range getGitHubOrgs(r.GitHubOrg, r.GitOpsRepoMigrationOrgs) {
+		if strings.EqualFold(orgName, org) {
+			return org
+		}
+	}
+	return ""
+}
+
+// isGeneratedRepository returns true if the GitOps repository of the Application was generated by the controller, in its GitHub org or
+// in the org it was migrated to.
+// The generation is recorded in the devfile of the Application when the repository is generated, and the Applications created
+// before it was recorded are considered generated if they have no user-supplied repository
+func (r *ApplicationReconciler) isGeneratedRepository(application *appstudiov1alpha1.Application, gitOpsURL string) bool {
+	if r.getControllerOrg(gitOpsURL) == "" {
+		return false
+	}
+	devfileData, err := devfile.ParseDevfile(devfile.DevfileSrc{Data: application.Status.Devfile})
+	if err != nil {
+		return false
+	}
+	devfileAttributes := devfileData.GetMetadata().Attributes
+	if _, ok := devfileAttributes[generatedGitOpsRepositoryAttribute]; ok {
+		generated := devfileAttributes.GetBoolean(generatedGitOpsRepositoryAttribute, &err)
+		return err == nil && generated
+	}
+	return application.Spec.GitOpsRepository.URL == ""
+}
+
+// getRepositoryNameValues returns the values of the placeholders of the naming template of the GitOps repository generated for the Application
+func getRepositoryNameValues(application *appstudiov1alpha1.Application) github.RepositoryNameValues {
+	return github.RepositoryNameValues{
+		Namespace:   application.Namespace,
+		Application: application.Name,
+		DisplayName: application.Spec.DisplayName,
+		Hash:        util.GenerateUniqueHashForWorkloadImageTag(application.Namespace),
+	}
+}
+
+// getRepositoryAccess returns the access settings of the GitOps repository of the Application. The visibility defaults to the visibility policy
+// of the controller, and an Application can only request a visibility that exposes the repository to fewer users than the policy
+func (r *ApplicationReconciler) getRepositoryAccess(application *appstudiov1alpha1.Application) (github.RepositoryAccess, error) {
+	policy, err := github.ParseRepositoryVisibility(string(r.GitOpsRepoVisibility))
+	if err != nil {
+		return github.RepositoryAccess{}, err
+	}
+
+	var access github.RepositoryAccess
+	if _, err := getJSONAnnotation(application, GitOpsRepositoryAccessAnnotation, &access); err != nil {
+		return access, err
+	}
+	if err := access.Validate(); err != nil {
+		return access, fmt.Errorf("invalid %s annotation on Application %s: %v", GitOpsRepositoryAccessAnnotation, application.Name, err)
+	}
+	if access.Visibility == "" {
+		access.Visibility = policy
+	} else if access.Visibility.IsMoreExposedThan(policy) {
+		return access, fmt.Errorf("the %s visibility requested by Application %s is not allowed by the %s visibility policy of GitOps repositories", access.Visibility, application.Name, policy)
+	}
+	return access, nil
+}
+
+// getRevokedGrants returns the collaborators and teams that were granted access to the repository and that the access no longer lists
+func getRevokedGrants(grants repositoryGrants, access github.RepositoryAccess) ([]string, []string) {
+	var revokedCollaborators, revokedTeams []string
+	for _, user := range grants.Collaborators {
+		if _, ok := access.Collaborators[user]; !ok {
+			revokedCollaborators = append(revokedCollaborators, user)
+		}
+	}
+	for _, team := range grants.Teams {
+		if _, ok := access.Teams[team]; !ok {
+			revokedTeams = append(revokedTeams, team)
+		}
+	}
+	return revokedCollaborators, revokedTeams
+}
+
+// reconcileRepositoryAccess sets the visibility and the collaborator and team permissions of the GitOps repository generated for the Application
+// to the ones of its access annotation, reverting any change made out-of-band, and revokes the grants that were removed from the annotation.
+// The repositories that were not generated by the controller are left untouched. The GitHub API is only called when the access annotation changed,
+// or once per resync period to correct drift, and the Application is requeued for its next resync
+func (r *ApplicationReconciler) reconcileRepositoryAccess(ctx context.Context, req ctrl.Request, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	gitOpsURL, err := getGitOpsRepositoryURL(application)
+	if err != nil || !r.isGeneratedRepository(application, gitOpsURL) {
+		return ctrl.Result{}, err
+	}
+
+	changes, resyncDelay, err := r.updateRepositoryAccess(ctx, application, ghClient, gitOpsURL)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to reconcile the access to the GitOps repository %s %v", gitOpsURL, req.NamespacedName))
+		r.SetRepositoryAccessConditionAndUpdateCR(ctx, req, application, "", err)
+		return ctrl.Result{}, err
+	}
+	if resyncDelay > 0 {
+		// The access settings were applied recently and did not change since
+		return ctrl.Result{RequeueAfter: resyncDelay}, nil
+	}
+
+	message := "The GitOps repository access is in sync with the Application"
+	if len(changes) > 0 {
+		message = fmt.Sprintf("Reconciled the GitOps repository access: %s", strings.Join(changes, ", "))
+		log.Info(fmt.Sprintf("%s %v", message, req.NamespacedName))
+	}
+	r.SetRepositoryAccessConditionAndUpdateCR(ctx, req, application, message, nil)
+	return ctrl.Result{RequeueAfter: repositoryAccessResyncPeriod}, nil
+}
+
+// updateRepositoryAccess reconciles the access settings of the GitOps repository and records the collaborators and teams that were granted
+// access on the Application. The settings that were changed are returned. If the settings were applied recently and did not change since,
+// nothing is done and the time left until they must be applied again is returned instead
+func (r *ApplicationReconciler) updateRepositoryAccess(ctx context.Context, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient, gitOpsURL string) ([]string, time.Duration, error) {
+	repoName, orgName, err := github.GetRepoAndOrgFromURL(gitOpsURL)
+	if err != nil {
+		return nil, 0, err
+	}
+	access, err := r.getRepositoryAccess(application)
+	if err != nil {
+		return nil, 0, err
+	}
+	accessDigest, err := getRepositoryAccessDigest(repoName, access)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The grants annotation is only written by the controller, an invalid annotation is replaced
+	var grants repositoryGrants
+	if _, err := getJSONAnnotation(application, GitOpsRepositoryGrantsAnnotation, &grants); err != nil {
+		grants = repositoryGrants{}
+	}
+	if resyncDelay := getRepositoryAccessResyncDelay(grants, accessDigest, time.Now()); resyncDelay > 0 {
+		return nil, resyncDelay, nil
+	}
+	revokedCollaborators, revokedTeams := getRevokedGrants(grants, access)
+
+	metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "ReconcileRepositoryAccess"}
+	metrics.ControllerGitRequest.With(metricsLabel).Inc()
+	changes, err := ghClient.ReconcileRepositoryAccess(ctx, orgName, repoName, access, revokedCollaborators, revokedTeams)
+	metrics.HandleRateLimitMetrics(err, metricsLabel)
+	if err != nil {
+		return changes, 0, err
+	}
+
+	syncedAt := metav1.Now()
+	appliedGrants := repositoryGrants{
+		Collaborators: maps.Keys(access.Collaborators),
+		Teams:         maps.Keys(access.Teams),
+		AccessDigest:  accessDigest,
+		SyncedAt:      &syncedAt,
+	}
+	sort.Strings(appliedGrants.Collaborators)
+	sort.Strings(appliedGrants.Teams)
+	patch := client.MergeFrom(application.DeepCopy())
+	if err := setJSONAnnotation(application, GitOpsRepositoryGrantsAnnotation, appliedGrants); err != nil {
+		return changes, 0, err
+	}
+	return changes, 0, r.Patch(ctx, application, patch)
+}
@@ -0,0 +1,407 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGetRepositoryAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     github.RepositoryVisibility
+		annotation string
+		want       github.RepositoryAccess
+		wantErr    string
+	}{
+		{
+			name: "No policy and no annotation, the repository is private",
+			want: github.RepositoryAccess{Visibility: github.PrivateVisibility},
+		},
+		{
+			name:   "The visibility defaults to the policy",
+			policy: github.InternalVisibility,
+			want:   github.RepositoryAccess{Visibility: github.InternalVisibility},
+		},
+		{
+			name:       "Application requests a more restricted visibility and grants",
+			policy:     github.PublicVisibility,
+			annotation: `{"visibility": "private", "collaborators": {"alice": "write"}, "teams": {"sre": "maintain"}}`,
+			want: github.RepositoryAccess{
+				Visibility:    github.PrivateVisibility,
+				Collaborators: map[string]string{"alice": "write"},
+				Teams:         map[string]string{"sre": "maintain"},
+			},
+		},
+		{
+			name:       "Application requests a visibility that the policy does not allow",
+			annotation: `{"visibility": "public"}`,
+			wantErr:    "the public visibility requested by Application test-app is not allowed by the private visibility policy of GitOps repositories",
+		},
+		{
+			name:       "Invalid permission",
+			annotation: `{"collaborators": {"alice": "owner"}}`,
+			wantErr:    "invalid permission \"owner\" for alice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ApplicationReconciler{GitOpsRepoVisibility: tt.policy}
+			application := &appstudiov1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "test-app"}}
+			if tt.annotation != "" {
+				application.Annotations = map[string]string{GitOpsRepositoryAccessAnnotation: tt.annotation}
+			}
+
+			access, err := r.getRepositoryAccess(application)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.want, access)
+		})
+	}
+}
+
+func TestReconcileRepositoryAccess(t *testing.T) {
+	appLookupKey := types.NamespacedName{Name: "test-app", Namespace: "default"}
+
+	tests := []struct {
+		name        string
+		gitOpsURL   string
+		annotations map[string]string
+		// syncedAgo, if set, records the access annotation as applied that long ago
+		syncedAgo    time.Duration
+		wantRequeue  bool
+		wantGrants   *repositoryGrants
+		wantErr      bool
+		wantMessage  string
+		wantNoAccess bool
+		// wantSkipped is true if the access was applied recently, and nothing is done until the next resync
+		wantSkipped bool
+	}{
+		{
+			name:      "Generated repository with grants",
+			gitOpsURL: "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			annotations: map[string]string{
+				GitOpsRepositoryAccessAnnotation: `{"collaborators": {"bob": "read"}, "teams": {"sre": "maintain"}}`,
+				GitOpsRepositoryGrantsAnnotation: `{"collaborators": ["alice"]}`,
+			},
+			wantRequeue: true,
+			wantGrants:  &repositoryGrants{Collaborators: []string{"bob"}, Teams: []string{"sre"}},
+			wantMessage: "Reconciled the GitOps repository access: collaborator alice revoked, collaborator bob read",
+		},
+		{
+			name:      "Generated repository in sync",
+			gitOpsURL: "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			annotations: map[string]string{
+				GitOpsRepositoryAccessAnnotation: `{"collaborators": {"bob": "write"}}`,
+				GitOpsRepositoryGrantsAnnotation: `{"collaborators":["bob"]}`,
+			},
+			wantRequeue: true,
+			wantGrants:  &repositoryGrants{Collaborators: []string{"bob"}},
+			wantMessage: "The GitOps repository access is in sync with the Application",
+		},
+		{
+			name:      "Generated repository with access applied recently",
+			gitOpsURL: "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			annotations: map[string]string{
+				GitOpsRepositoryAccessAnnotation: `{"collaborators": {"bob": "write"}}`,
+			},
+			syncedAgo:   10 * time.Minute,
+			wantSkipped: true,
+		},
+		{
+			name:      "Generated repository with access applied a resync period ago",
+			gitOpsURL: "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			annotations: map[string]string{
+				GitOpsRepositoryAccessAnnotation: `{"collaborators": {"bob": "write"}}`,
+			},
+			syncedAgo:   2 * repositoryAccessResyncPeriod,
+			wantRequeue: true,
+			wantGrants:  &repositoryGrants{Collaborators: []string{"bob"}},
+			wantMessage: "The GitOps repository access is in sync with the Application",
+		},
+		{
+			name:      "Generated repository with an invalid access annotation",
+			gitOpsURL: "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			annotations: map[string]string{
+				GitOpsRepositoryAccessAnnotation: `{"visibility": "public"}`,
+			},
+			wantErr:     true,
+			wantMessage: "GitOps repository access reconcile failed: the public visibility requested by Application test-app is not allowed by the private visibility policy of GitOps repositories",
+		},
+		{
+			name:         "User supplied repository",
+			gitOpsURL:    "https://github.com/testorg/gitops-repo",
+			annotations:  map[string]string{GitOpsRepositoryAccessAnnotation: `{"teams": {"sre": "maintain"}}`},
+			wantNoAccess: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        appLookupKey.Name,
+					Namespace:   appLookupKey.Namespace,
+					Annotations: tt.annotations,
+				},
+			}
+			devfileData, err := devfile.ConvertApplicationToDevfile(*application, tt.gitOpsURL, tt.gitOpsURL)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			devfileYaml, err := yaml.Marshal(devfileData)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			application.Status.Devfile = string(devfileYaml)
+
+			r := &ApplicationReconciler{
+				GitHubOrg: github.AppStudioAppDataOrg,
+			}
+			if tt.syncedAgo != 0 {
+				access, err := r.getRepositoryAccess(application)
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				accessDigest, err := getRepositoryAccessDigest("test-repo-1", access)
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				syncedAt := metav1.NewTime(time.Now().Add(-tt.syncedAgo))
+				if err := setJSONAnnotation(application, GitOpsRepositoryGrantsAnnotation, repositoryGrants{Collaborators: []string{"bob"}, AccessDigest: accessDigest, SyncedAt: &syncedAt}); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+			}
+			annotations := application.DeepCopy().Annotations
+
+			fakeClient := NewFakeClient(t, application)
+			r.Client = fakeClient
+			ghClient := &github.GitHubClient{TokenName: "mock", Client: github.GetMockedClient()}
+
+			result, err := r.reconcileRepositoryAccess(context.Background(), ctrl.Request{NamespacedName: appLookupKey}, application, ghClient)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error value %v", err)
+			}
+			assert.Equal(t, tt.wantRequeue, result.RequeueAfter == repositoryAccessResyncPeriod)
+			if tt.wantSkipped {
+				assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter < repositoryAccessResyncPeriod, "expected a requeue before the next resync")
+			}
+
+			updatedApplication := appstudiov1alpha1.Application{}
+			if err := fakeClient.Get(context.Background(), appLookupKey, &updatedApplication); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			condition := meta.FindStatusCondition(updatedApplication.Status.Conditions, gitOpsRepositoryAccessConditionType)
+			if tt.wantSkipped {
+				assert.Nil(t, condition)
+				assert.Equal(t, annotations, updatedApplication.Annotations)
+				return
+			}
+			if tt.wantNoAccess {
+				assert.Nil(t, condition)
+				assert.NotContains(t, updatedApplication.Annotations, GitOpsRepositoryGrantsAnnotation)
+				return
+			}
+			if assert.NotNil(t, condition) {
+				assert.Equal(t, tt.wantMessage, condition.Message)
+				assert.Equal(t, !tt.wantErr, condition.Status == metav1.ConditionTrue)
+			}
+			if tt.wantGrants != nil {
+				var grants repositoryGrants
+				if _, err := getJSONAnnotation(&updatedApplication, GitOpsRepositoryGrantsAnnotation, &grants); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Equal(t, tt.wantGrants.Collaborators, grants.Collaborators)
+				assert.Equal(t, tt.wantGrants.Teams, grants.Teams)
+				assert.NotEmpty(t, grants.AccessDigest)
+				if assert.NotNil(t, grants.SyncedAt) {
+					assert.WithinDuration(t, time.Now(), grants.SyncedAt.Time, time.Minute)
+				}
+			}
+		})
+	}
+}
+
+func TestGetRepositoryAccessResyncDelay(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	syncedAt := func(ago time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(-ago))
+		return &t
+	}
+
+	tests := []struct {
+		name   string
+		grants repositoryGrants
+		want   time.Duration
+	}{
+		{
+			name:   "Access applied recently",
+			grants: repositoryGrants{AccessDigest: "digest", SyncedAt: syncedAt(10 * time.Minute)},
+			want:   50 * time.Minute,
+		},
+		{
+			name:   "Access changed since it was applied",
+			grants: repositoryGrants{AccessDigest: "previous-digest", SyncedAt: syncedAt(10 * time.Minute)},
+		},
+		{
+			name:   "Access applied a resync period ago",
+			grants: repositoryGrants{AccessDigest: "digest", SyncedAt: syncedAt(repositoryAccessResyncPeriod)},
+		},
+		{
+			name:   "Access applied in the future",
+			grants: repositoryGrants{AccessDigest: "digest", SyncedAt: syncedAt(-2 * repositoryAccessResyncPeriod)},
+		},
+		{
+			name:   "Access never applied",
+			grants: repositoryGrants{Collaborators: []string{"bob"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getRepositoryAccessResyncDelay(tt.grants, "digest", now))
+		})
+	}
+}
+
+func TestIsGeneratedRepository(t *testing.T) {
+	generatedURL := "https://github.com/redhat-appstudio-appdata/test-repo-1"
+
+	tests := []struct {
+		name          string
+		gitOpsURL     string
+		specURL       string
+		recorded      bool
+		wantGenerated bool
+	}{
+		{
+			name:          "Generation recorded in the devfile",
+			gitOpsURL:     generatedURL,
+			recorded:      true,
+			wantGenerated: true,
+		},
+		{
+			name:          "Application created before the generation was recorded",
+			gitOpsURL:     generatedURL,
+			wantGenerated: true,
+		},
+		{
+			name:      "User-supplied repository of the controller org",
+			gitOpsURL: generatedURL,
+			specURL:   generatedURL,
+		},
+		{
+			name:          "Repository migrated to another org of the controller",
+			gitOpsURL:     "https://github.com/other-org/petclinic",
+			recorded:      true,
+			wantGenerated: true,
+		},
+		{
+			name:      "Repository of an org whose name contains the controller org",
+			gitOpsURL: "https://github.com/redhat-appstudio-appdata-fork/test-repo-1",
+			recorded:  true,
+		},
+		{
+			name:      "Repository named after the controller org",
+			gitOpsURL: "https://github.com/testorg/redhat-appstudio-appdata",
+			recorded:  true,
+		},
+		{
+			name:      "Repository with the controller org path on another host",
+			gitOpsURL: "https://gitlab.com/redhat-appstudio-appdata/test-repo-1",
+			recorded:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-app", Namespace: "default"},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{URL: tt.specURL},
+				},
+			}
+			devfileData, err := devfile.ConvertApplicationToDevfile(*application, tt.gitOpsURL, tt.gitOpsURL)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			if tt.recorded {
+				devfileMeta := devfileData.GetMetadata()
+				devfileMeta.Attributes = devfileMeta.Attributes.PutBoolean(generatedGitOpsRepositoryAttribute, true)
+				devfileData.SetMetadata(devfileMeta)
+			}
+			devfileYaml, err := yaml.Marshal(devfileData)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			application.Status.Devfile = string(devfileYaml)
+
+			r := &ApplicationReconciler{GitHubOrg: github.AppStudioAppDataOrg, GitOpsRepoMigrationOrgs: []string{github.AppStudioAppDataOrg, "other-org"}}
+			assert.Equal(t, tt.wantGenerated, r.isGeneratedRepository(application, tt.gitOpsURL))
+		})
+	}
+}
+
+func TestReconcileRecordsGeneratedRepository(t *testing.T) {
+	appLookupKey := types.NamespacedName{Name: "test-app", Namespace: "default"}
+	application := &appstudiov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       appLookupKey.Name,
+			Namespace:  appLookupKey.Namespace,
+			Finalizers: []string{appFinalizerName},
+		},
+		Spec: appstudiov1alpha1.ApplicationSpec{DisplayName: "Test Application"},
+	}
+	fakeClient := NewFakeClient(t, application)
+	r := &ApplicationReconciler{
+		Client:            fakeClient,
+		GitHubTokenClient: github.MockGitHubTokenClient{},
+		GitHubOrg:         github.AppStudioAppDataOrg,
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: appLookupKey}); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	updatedApplication := appstudiov1alpha1.Application{}
+	if err := fakeClient.Get(context.Background(), appLookupKey, &updatedApplication); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	gitOpsURL, err := getGitOpsRepositoryURL(&updatedApplication)
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Contains(t, updatedApplication.Status.Devfile, generatedGitOpsRepositoryAttribute)
+	assert.True(t, r.isGeneratedRepository(&updatedApplication, gitOpsURL))
+}
@@ -0,0 +1,186 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser/data"
+	"github.com/prometheus/client_golang/prometheus"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// appModelPublishedConditionType is the condition of an Application reporting the publication of its devfile to the app-model repository
+	appModelPublishedConditionType = "AppModelPublished"
+
+	// appModelFileName is the name of the file holding the devfile of the Application in the context of its app-model repository
+	appModelFileName = "devfile.yaml"
+)
+
+// appModelPublication records the last publication of the devfile of an Application to its app-model repository
+type appModelPublication struct {
+	CommitID    string      `json:"commitID,omitempty"`
+	Digest      string      `json:"digest"`
+	PublishedAt metav1.Time `json:"publishedAt"`
+}
+
+// appModelRepository is the location of the app-model repository recorded in the devfile of an Application
+type appModelRepository struct {
+	url     string
+	branch  string
+	context string
+}
+
+// getAppModelRepository returns the app-model repository recorded in the attributes of the Application devfile
+func getAppModelRepository(devfileData data.DevfileData) (appModelRepository, error) {
+	var err error
+	devfileAttributes := devfileData.GetMetadata().Attributes
+	repository := appModelRepository{
+		url: devfileAttributes.GetString("appModelRepository.url", &err),
+	}
+	if err != nil {
+		return repository, err
+	}
+	// The branch and context are optional
+	repository.branch = devfileAttributes.GetString("appModelRepository.branch", &err)
+	repository.context = devfileAttributes.GetString("appModelRepository.context", &err)
+	return repository, nil
+}
+
+// getDevfileDigest returns the digest of the devfile, to tell whether it changed since it was last published
+func getDevfileDigest(devfileYaml string) string {
+	digest := sha256.Sum256([]byte(devfileYaml))
+	return hex.EncodeToString(digest[:])
+}
+
+// publishAppModel commits the devfile of the Application, with its projects and container image attributes, to the app-model repository
+// whenever it changes, and records the ID of the commit on the Application. The devfile records the location of the app-model repository, so
+// nothing is validated or published, and no GitHub call is made, unless the devfile or the location changed since the last publication.
+// Only the app-model repositories hosted on github.com are published to
+func (r *ApplicationReconciler) publishAppModel(ctx context.Context, req ctrl.Request, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient) error {
+	log := ctrl.LoggerFrom(ctx)
+	if application.Status.Devfile == "" {
+		return nil
+	}
+
+	var publication appModelPublication
+	if _, err := getJSONAnnotation(application, AppModelPublicationAnnotation, &publication); err != nil {
+		// The publication annotation is only written by the controller, an invalid annotation is replaced
+		publication = appModelPublication{}
+	}
+	digest := getDevfileDigest(application.Status.Devfile)
+	if publication.Digest == digest {
+		return nil
+	}
+
+	devfileData, err := devfile.ParseDevfile(devfile.DevfileSrc{Data: application.Status.Devfile})
+	if err != nil {
+		return err
+	}
+	repository, err := getAppModelRepository(devfileData)
+	if err != nil {
+		return err
+	}
+	if !github.IsGitHubRepository(repository.url) {
+		// The devfile is published with the GitHub API, the repositories hosted elsewhere are left untouched
+		return nil
+	}
+
+	commitID, err := r.commitAppModel(ctx, application, ghClient, repository)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to publish the devfile to the app-model repository %v", req.NamespacedName))
+		r.SetAppModelConditionAndUpdateCR(ctx, req, application, "", err)
+		return err
+	}
+	if commitID != "" {
+		publication.CommitID = commitID
+	}
+	publication.Digest = digest
+	publication.PublishedAt = metav1.Now()
+
+	patch := client.MergeFrom(application.DeepCopy())
+	if err := setJSONAnnotation(application, AppModelPublicationAnnotation, publication); err != nil {
+		return err
+	}
+	if err := r.Patch(ctx, application, patch); err != nil {
+		return err
+	}
+	message := "The app-model repository holds the devfile of the Application"
+	if publication.CommitID != "" {
+		message = fmt.Sprintf("The devfile of the Application was published to the app-model repository at commit %s", publication.CommitID)
+	}
+	r.SetAppModelConditionAndUpdateCR(ctx, req, application, message, nil)
+	return nil
+}
+
+// isSameRepository returns true if both URLs are the URL of the same repository
+func isSameRepository(repoURL string, otherRepoURL string) bool {
+	normalize := func(repoURL string) string {
+		return strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+	}
+	return strings.EqualFold(normalize(repoURL), normalize(otherRepoURL))
+}
+
+// validateAppModelRepository checks that the Application can publish to its app-model repository with the token of the controller, and returns
+// the branch to publish to. The token can push to every repository of the GitHub orgs of the controller, so the only repository of the orgs that
+// an Application can publish to is its own GitOps repository. Any other repository is validated like a user-supplied GitOps repository
+func (r *ApplicationReconciler) validateAppModelRepository(ctx context.Context, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient, repository appModelRepository) (string, error) {
+	if orgName := r.getControllerOrg(repository.url); orgName != "" {
+		gitOpsURL, err := getGitOpsRepositoryURL(application)
+		if err != nil {
+			return "", err
+		}
+		if !isSameRepository(repository.url, gitOpsURL) {
+			return "", fmt.Errorf("the app-model repository %s is in the %s GitHub org and is not the GitOps repository of Application %s", repository.url, orgName, application.Name)
+		}
+		return repository.branch, nil
+	}
+
+	metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "ValidateRepository"}
+	metrics.ControllerGitRequest.With(metricsLabel).Inc()
+	branch, _, err := ghClient.ValidateRepository(ctx, repository.url, repository.branch, repository.context)
+	metrics.HandleRateLimitMetrics(err, metricsLabel)
+	return branch, err
+}
+
+// commitAppModel commits the devfile of the Application to the app-model repository, once it is validated, and returns the ID of the commit,
+// empty if the repository already holds the devfile
+func (r *ApplicationReconciler) commitAppModel(ctx context.Context, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient, repository appModelRepository) (string, error) {
+	var err error
+	repository.branch, err = r.validateAppModelRepository(ctx, application, ghClient, repository)
+	if err != nil {
+		return "", err
+	}
+
+	metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "PublishFile"}
+	metrics.ControllerGitRequest.With(metricsLabel).Inc()
+	filePath := strings.TrimPrefix(path.Join("/", repository.context, appModelFileName), "/")
+	commitID, err := ghClient.PublishFile(ctx, repository.url, repository.branch, filePath, []byte(application.Status.Devfile), fmt.Sprintf("Update the devfile of Application %s", application.Name))
+	metrics.HandleRateLimitMetrics(err, metricsLabel)
+	return commitID, err
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPublishAppModel(t *testing.T) {
+	appLookupKey := types.NamespacedName{Name: "test-app", Namespace: "default"}
+
+	tests := []struct {
+		name         string
+		appModelURL  string
+		noDevfile    bool
+		published    bool
+		wantCommitID string
+		wantMessage  string
+		wantErr      bool
+	}{
+		{
+			name:         "Devfile published to the app-model repository",
+			appModelURL:  "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			wantCommitID: "ca82a6dff817ec66f44342007202690a93763949",
+			wantMessage:  "The devfile of the Application was published to the app-model repository at commit ca82a6dff817ec66f44342007202690a93763949",
+		},
+		{
+			name:        "Devfile already published",
+			appModelURL: "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			published:   true,
+		},
+		{
+			name:        "Devfile already published to a repository that is no longer reachable",
+			appModelURL: "https://github.com/testorg/test-error-response",
+			published:   true,
+		},
+		{
+			name:         "Devfile published to a user-supplied app-model repository",
+			appModelURL:  "https://github.com/testorg/test-repo-1",
+			wantCommitID: "ca82a6dff817ec66f44342007202690a93763949",
+			wantMessage:  "The devfile of the Application was published to the app-model repository at commit ca82a6dff817ec66f44342007202690a93763949",
+		},
+		{
+			name:        "App-model repository of another Application in the org of the controller",
+			appModelURL: "https://github.com/redhat-appstudio-appdata/test-repo-2",
+			wantErr:     true,
+			wantMessage: "App-model repository publication failed: the app-model repository https://github.com/redhat-appstudio-appdata/test-repo-2 is in the redhat-appstudio-appdata GitHub org and is not the GitOps repository of Application test-app",
+		},
+		{
+			name:        "App-model repository without write access",
+			appModelURL: "https://github.com/testorg/test-repo-2",
+			wantErr:     true,
+			wantMessage: "App-model repository publication failed: no write access to the repository https://github.com/testorg/test-repo-2",
+		},
+		{
+			name:        "App-model repository not hosted on GitHub is not published to",
+			appModelURL: "https://gitlab.com/testorg/test-repo-1",
+		},
+		{
+			name:      "No devfile yet",
+			noDevfile: true,
+		},
+		{
+			name:        "Unreachable app-model repository",
+			appModelURL: "https://github.com/testorg/test-error-response",
+			wantErr:     true,
+			wantMessage: "App-model repository publication failed: failed to get repo test-error-response under testorg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      appLookupKey.Name,
+					Namespace: appLookupKey.Namespace,
+				},
+			}
+			if !tt.noDevfile {
+				devfileData, err := devfile.ConvertApplicationToDevfile(*application, "https://github.com/redhat-appstudio-appdata/test-repo-1", tt.appModelURL)
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				devfileYaml, err := yaml.Marshal(devfileData)
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				application.Status.Devfile = string(devfileYaml)
+			}
+			if tt.published {
+				application.Annotations = map[string]string{
+					AppModelPublicationAnnotation: `{"commitID": "1234", "digest": "` + getDevfileDigest(application.Status.Devfile) + `"}`,
+				}
+			}
+
+			fakeClient := NewFakeClient(t, application)
+			r := &ApplicationReconciler{
+				Client:    fakeClient,
+				GitHubOrg: github.AppStudioAppDataOrg,
+			}
+			ghClient := &github.GitHubClient{TokenName: "mock", Client: github.GetMockedClient()}
+
+			err := r.publishAppModel(context.Background(), ctrl.Request{NamespacedName: appLookupKey}, application, ghClient)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error value %v", err)
+			}
+
+			updatedApplication := appstudiov1alpha1.Application{}
+			if err := fakeClient.Get(context.Background(), appLookupKey, &updatedApplication); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			condition := meta.FindStatusCondition(updatedApplication.Status.Conditions, appModelPublishedConditionType)
+			if tt.wantMessage == "" {
+				// Nothing is published when the devfile didn't change
+				assert.Nil(t, condition)
+				return
+			}
+			if assert.NotNil(t, condition) {
+				assert.Contains(t, condition.Message, tt.wantMessage)
+				assert.Equal(t, !tt.wantErr, condition.Status == metav1.ConditionTrue)
+			}
+			if tt.wantErr {
+				assert.NotContains(t, updatedApplication.Annotations, AppModelPublicationAnnotation)
+				return
+			}
+
+			var publication appModelPublication
+			if _, err := getJSONAnnotation(&updatedApplication, AppModelPublicationAnnotation, &publication); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantCommitID, publication.CommitID)
+			assert.Equal(t, getDevfileDigest(application.Status.Devfile), publication.Digest)
+		})
+	}
+}
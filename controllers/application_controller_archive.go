@@ -0,0 +1,207 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// archiveRetention is the value of the retention annotation that archives the GitOps repository and retains it indefinitely
+	archiveRetention = "archive"
+
+	// deleteRetention is the value of the retention annotation that deletes the GitOps repository along with the Application
+	deleteRetention = "delete"
+
+	// gitOpsRepositoryArchivesConfigMapName is the ConfigMap of a namespace that records where the GitOps repositories
+	// of its deleted Applications were archived, keyed by Application name
+	gitOpsRepositoryArchivesConfigMapName = "gitops-repository-archives"
+
+	// defaultArchiveSweepPeriod is the period at which the archived GitOps repositories are checked for deletion
+	defaultArchiveSweepPeriod = time.Hour
+)
+
+// repositoryArchive records where and when the GitOps repository of a deleted Application was archived,
+// and the time after which it is deleted, if any
+type repositoryArchive struct {
+	Repository  string       `json:"repository"`
+	ArchivedAt  metav1.Time  `json:"archivedAt"`
+	DeleteAfter *metav1.Time `json:"deleteAfter,omitempty"`
+}
+
+// getRetentionPeriod returns the period after which the archived GitOps repository of the Application is deleted,
+// 0 if the repository is retained indefinitely. The retention annotation is either "archive" or a duration, e.g. "720h",
+// and an Application without the annotation retains the repository indefinitely
+func getRetentionPeriod(application *appstudiov1alpha1.Application) (time.Duration, error) {
+	retention := application.Annotations[GitOpsRepositoryRetentionAnnotation]
+	if retention == "" || retention == archiveRetention {
+		return 0, nil
+	}
+	period, err := time.ParseDuration(retention)
+	if err != nil || period <= 0 {
+		return 0, fmt.Errorf("invalid %s annotation %q on Application %s, it must be %q, %q or a positive duration", GitOpsRepositoryRetentionAnnotation, retention, application.Name, archiveRetention, deleteRetention)
+	}
+	return period, nil
+}
+
+// archiveRepository archives the GitOps repository of the Application being deleted, with the retention window of its retention
+// annotation, and records the archive in the archives ConfigMap of the namespace. An invalid retention annotation retains the repository indefinitely
+func (r *ApplicationReconciler) archiveRepository(ctx context.Context, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient, gitOpsURL string, orgName string, repoName string) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	now := metav1.Now()
+	archive := repositoryArchive{
+		Repository: gitOpsURL,
+		ArchivedAt: now,
+	}
+	period, err := getRetentionPeriod(application)
+	if err != nil {
+		log.Error(err, "Retaining the archived GitOps repository indefinitely")
+	} else if period > 0 {
+		deleteAfter := metav1.NewTime(now.Add(period))
+		archive.DeleteAfter = &deleteAfter
+	}
+	var deleteAfter time.Time
+	if archive.DeleteAfter != nil {
+		deleteAfter = archive.DeleteAfter.Time
+	}
+
+	metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "ArchiveRepository"}
+	metrics.ControllerGitRequest.With(metricsLabel).Inc()
+	err = ghClient.ArchiveRepository(ctx, orgName, repoName, deleteAfter)
+	metrics.HandleRateLimitMetrics(err, metricsLabel)
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Archived the GitOps repository %s of Application %s/%s", gitOpsURL, application.Namespace, application.Name))
+	return r.recordRepositoryArchive(ctx, application, archive)
+}
+
+// recordRepositoryArchive records the archive of the GitOps repository of the Application in the archives ConfigMap of its namespace.
+// The ConfigMap has no owner, so that it outlives the Application
+func (r *ApplicationReconciler) recordRepositoryArchive(ctx context.Context, application *appstudiov1alpha1.Application, archive repositoryArchive) error {
+	value, err := json.Marshal(archive)
+	if err != nil {
+		return err
+	}
+
+	configMap := corev1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Name: gitOpsRepositoryArchivesConfigMapName, Namespace: application.Namespace}, &configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err != nil {
+		configMap = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      gitOpsRepositoryArchivesConfigMapName,
+				Namespace: application.Namespace,
+			},
+			Data: map[string]string{application.Name: string(value)},
+		}
+		return r.Create(ctx, &configMap)
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[application.Name] = string(value)
+	return r.Update(ctx, &configMap)
+}
+
+// GitOpsRepositorySweeper periodically deletes the GitOps repositories that were archived when their Application was deleted
+// and whose retention window ended, in the org of the controller and in the orgs the repositories can be migrated to. It only runs on the leader
+type GitOpsRepositorySweeper struct {
+	Log                     logr.Logger
+	GitHubTokenClient       github.GitHubToken
+	GitHubOrg               string
+	GitOpsRepoMigrationOrgs []string
+
+	// Period is the period at which the archived repositories are checked, hourly if unset
+	Period time.Duration
+}
+
+// Start runs the sweeper until the context is done
+func (s *GitOpsRepositorySweeper) Start(ctx context.Context) error {
+	period := s.Period
+	if period == 0 {
+		period = defaultArchiveSweepPeriod
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.Sweep(ctx, time.Now()); err != nil {
+			s.Log.Error(err, "Unable to delete the expired archived GitOps repositories")
+		} else if len(deleted) > 0 {
+			s.Log.Info(fmt.Sprintf("Deleted the expired archived GitOps repositories %v", deleted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection makes the sweeper run on the leader only
+func (s *GitOpsRepositorySweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Sweep deletes the archived GitOps repositories whose retention window ended before now, and returns their full names
+func (s *GitOpsRepositorySweeper) Sweep(ctx context.Context, now time.Time) ([]string, error) {
+	ghClient, err := s.GitHubTokenClient.GetNewGitHubClient("")
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	for _, orgName := range getGitHubOrgs(s.GitHubOrg, s.GitOpsRepoMigrationOrgs) {
+		metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "ListExpiredArchivedRepositories"}
+		metrics.ControllerGitRequest.With(metricsLabel).Inc()
+		expired, err := ghClient.ListExpiredArchivedRepositories(ctx, orgName, now)
+		metrics.HandleRateLimitMetrics(err, metricsLabel)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, repoName := range expired {
+			metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "DeleteRepository"}
+			metrics.ControllerGitRequest.With(metricsLabel).Inc()
+			err := ghClient.DeleteRepository(ctx, orgName, repoName)
+			metrics.HandleRateLimitMetrics(err, metricsLabel)
+			if err != nil {
+				return deleted, fmt.Errorf("failed to delete the archived repo %s under %s, error: %v", repoName, orgName, err)
+			}
+			deleted = append(deleted, orgName+"/"+repoName)
+		}
+	}
+	return deleted, nil
+}
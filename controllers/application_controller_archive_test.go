@@ -0,0 +1,293 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGetRetentionPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention string
+		want      time.Duration
+		wantErr   bool
+	}{
+		{
+			name:      "Archive indefinitely",
+			retention: "archive",
+		},
+		{
+			name: "No retention annotation, archive indefinitely",
+		},
+		{
+			name:      "Retention window",
+			retention: "720h",
+			want:      720 * time.Hour,
+		},
+		{
+			name:      "Invalid retention",
+			retention: "a month",
+			wantErr:   true,
+		},
+		{
+			name:      "Negative retention",
+			retention: "-1h",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-app",
+					Annotations: map[string]string{GitOpsRepositoryRetentionAnnotation: tt.retention},
+				},
+			}
+			period, err := getRetentionPeriod(application)
+			if tt.wantErr != (err != nil) {
+				t.Errorf("TestGetRetentionPeriod() unexpected error value: %v", err)
+			}
+			assert.Equal(t, tt.want, period)
+		})
+	}
+}
+
+func TestFinalizeArchive(t *testing.T) {
+	gitOpsURL := "https://github.com/redhat-appstudio-appdata/test-repo-1"
+
+	tests := []struct {
+		name            string
+		applicationName string
+		gitOpsURL       string
+		retention       *string
+		finalizers      []string
+		existingArchive bool
+		wantArchive     bool
+		wantDeleteAfter time.Duration
+	}{
+		{
+			name:            "Archived indefinitely",
+			applicationName: "test-app-1",
+			retention:       &[]string{"archive"}[0],
+			wantArchive:     true,
+		},
+		{
+			name:            "Archived with a retention window, next to the archive of another Application",
+			applicationName: "test-app-2",
+			retention:       &[]string{"168h"}[0],
+			existingArchive: true,
+			wantArchive:     true,
+			wantDeleteAfter: 168 * time.Hour,
+		},
+		{
+			name:            "Invalid retention annotation archives indefinitely",
+			applicationName: "test-app-3",
+			retention:       &[]string{"forever"}[0],
+			wantArchive:     true,
+		},
+		{
+			name:            "Deletion opted in, the repository is deleted",
+			applicationName: "test-app-4",
+			retention:       &[]string{"delete"}[0],
+		},
+		{
+			name:            "Application with the finalizer and without the retention annotation is archived indefinitely",
+			applicationName: "test-app-5",
+			finalizers:      []string{appFinalizerName},
+			wantArchive:     true,
+		},
+		{
+			name:            "Repository migrated to another org of the controller is archived",
+			applicationName: "test-app-6",
+			gitOpsURL:       "https://github.com/other-org/petclinic",
+			retention:       &[]string{"archive"}[0],
+			wantArchive:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitOpsURL := gitOpsURL
+			if tt.gitOpsURL != "" {
+				gitOpsURL = tt.gitOpsURL
+			}
+			application := &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       tt.applicationName,
+					Namespace:  "default",
+					Finalizers: tt.finalizers,
+				},
+			}
+			if tt.retention != nil {
+				application.Annotations = map[string]string{GitOpsRepositoryRetentionAnnotation: *tt.retention}
+			}
+			devfileData, err := devfile.ConvertApplicationToDevfile(*application, gitOpsURL, gitOpsURL)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			devfileYaml, err := yaml.Marshal(devfileData)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			application.Status.Devfile = string(devfileYaml)
+
+			var objs []runtime.Object
+			objs = append(objs, application)
+			if tt.existingArchive {
+				objs = append(objs, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: gitOpsRepositoryArchivesConfigMapName, Namespace: "default"},
+					Data:       map[string]string{"other-app": `{"repository": "https://github.com/redhat-appstudio-appdata/other-repo"}`},
+				})
+			}
+			fakeClient := NewFakeClient(t, objs...)
+			r := &ApplicationReconciler{
+				Client:                  fakeClient,
+				GitHubOrg:               github.AppStudioAppDataOrg,
+				GitOpsRepoMigrationOrgs: []string{"other-org"},
+			}
+			ghClient := &github.GitHubClient{TokenName: "mock", Client: github.GetMockedClient()}
+
+			before := time.Now().Truncate(time.Second)
+			if err := r.Finalize(context.Background(), application, ghClient); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			configMap := corev1.ConfigMap{}
+			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: gitOpsRepositoryArchivesConfigMapName, Namespace: "default"}, &configMap)
+			if !tt.wantArchive {
+				assert.Error(t, err, "no archive is recorded when the repository is deleted")
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			if tt.existingArchive {
+				assert.Contains(t, configMap.Data, "other-app")
+			}
+			var archive repositoryArchive
+			if err := json.Unmarshal([]byte(configMap.Data[tt.applicationName]), &archive); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, gitOpsURL, archive.Repository)
+			assert.False(t, archive.ArchivedAt.Time.Before(before))
+			if tt.wantDeleteAfter == 0 {
+				assert.Nil(t, archive.DeleteAfter)
+			} else if assert.NotNil(t, archive.DeleteAfter) {
+				assert.Equal(t, tt.wantDeleteAfter, archive.DeleteAfter.Sub(archive.ArchivedAt.Time))
+			}
+		})
+	}
+}
+
+func TestReconcileDeletedApplication(t *testing.T) {
+	appLookupKey := types.NamespacedName{Name: "test-app", Namespace: "default"}
+	gitOpsURL := "https://github.com/redhat-appstudio-appdata/test-repo-1"
+	deletionTimestamp := metav1.Now()
+	application := &appstudiov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              appLookupKey.Name,
+			Namespace:         appLookupKey.Namespace,
+			Finalizers:        []string{appFinalizerName},
+			DeletionTimestamp: &deletionTimestamp,
+			Annotations: map[string]string{
+				GitOpsRepositoryRetentionAnnotation: "archive",
+				GitOpsRepositoryAccessAnnotation:    `{"collaborators": {"user1": "write"}}`,
+			},
+		},
+	}
+	devfileData, err := devfile.ConvertApplicationToDevfile(*application, gitOpsURL, gitOpsURL)
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	devfileYaml, err := yaml.Marshal(devfileData)
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	application.Status.Devfile = string(devfileYaml)
+
+	fakeClient := NewFakeClient(t, application)
+	r := &ApplicationReconciler{
+		Client:            fakeClient,
+		GitHubTokenClient: github.MockGitHubTokenClient{},
+		GitHubOrg:         github.AppStudioAppDataOrg,
+	}
+
+	// The repository is archived, and the deleted Application is not reconciled any further
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: appLookupKey})
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, ctrl.Result{}, result)
+
+	configMap := corev1.ConfigMap{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: gitOpsRepositoryArchivesConfigMapName, Namespace: "default"}, &configMap); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Contains(t, configMap.Data, appLookupKey.Name)
+}
+
+func TestGitOpsRepositorySweeperSweep(t *testing.T) {
+	tests := []struct {
+		name          string
+		migrationOrgs []string
+		want          []string
+	}{
+		{
+			name: "Org of the controller",
+			want: []string{"redhat-appstudio-appdata/test-archived-repo-1"},
+		},
+		{
+			name:          "Org of the controller and migration orgs",
+			migrationOrgs: []string{github.AppStudioAppDataOrg, "other-org"},
+			want:          []string{"redhat-appstudio-appdata/test-archived-repo-1", "other-org/test-archived-repo-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &GitOpsRepositorySweeper{
+				GitHubTokenClient:       github.MockGitHubTokenClient{},
+				GitHubOrg:               github.AppStudioAppDataOrg,
+				GitOpsRepoMigrationOrgs: tt.migrationOrgs,
+			}
+
+			deleted, err := s.Sweep(context.Background(), time.Now())
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			// Each mocked org holds a repository that isn't archived, an archived repository with an expired retention window
+			// and an archived repository retained indefinitely
+			assert.Equal(t, tt.want, deleted)
+		})
+	}
+}
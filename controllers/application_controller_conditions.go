@@ -99,3 +99,75 @@ func (r *ApplicationReconciler) SetUpdateConditionAndUpdateCR(ctx context.Contex
 		log.Error(err, "Unable to update Application status")
 	}
 }
+
+// SetRepositoryAccessConditionAndUpdateCR sets the condition that reports the reconciliation of the GitOps repository access settings
+func (r *ApplicationReconciler) SetRepositoryAccessConditionAndUpdateCR(ctx context.Context, req ctrl.Request, application *appstudiov1alpha1.Application, message string, accessError error) {
+	if accessError != nil {
+		logutil.LogAPIResourceChangeEvent(ctrl.LoggerFrom(ctx), application.Name, "Application", logutil.ResourceUpdate, accessError)
+	}
+	r.setConditionAndUpdateCR(ctx, req, application, getCondition(gitOpsRepositoryAccessConditionType, message, "GitOps repository access reconcile failed", accessError))
+}
+
+// SetGitOpsRepositoryConditionAndUpdateCR sets the condition reporting the validation of the user-supplied GitOps repository of the Application
+func (r *ApplicationReconciler) SetGitOpsRepositoryConditionAndUpdateCR(ctx context.Context, req ctrl.Request, application *appstudiov1alpha1.Application, message string, validationError error) {
+	if validationError != nil {
+		logutil.LogAPIResourceChangeEvent(ctrl.LoggerFrom(ctx), application.Name, "Application", logutil.ResourceCreate, validationError)
+	}
+	r.setConditionAndUpdateCR(ctx, req, application, getCondition(gitOpsRepositoryValidatedConditionType, message, "GitOps repository validation failed", validationError))
+}
+
+// SetAppModelConditionAndUpdateCR sets the condition reporting the publication of the devfile of the Application to its app-model repository
+func (r *ApplicationReconciler) SetAppModelConditionAndUpdateCR(ctx context.Context, req ctrl.Request, application *appstudiov1alpha1.Application, message string, publishError error) {
+	if publishError != nil {
+		logutil.LogAPIResourceChangeEvent(ctrl.LoggerFrom(ctx), application.Name, "Application", logutil.ResourceUpdate, publishError)
+	}
+	r.setConditionAndUpdateCR(ctx, req, application, getCondition(appModelPublishedConditionType, message, "App-model repository publication failed", publishError))
+}
+
+// SetMigrationConditionAndUpdateCR sets the condition reporting the progress of the migration of the GitOps repository of the Application
+func (r *ApplicationReconciler) SetMigrationConditionAndUpdateCR(ctx context.Context, req ctrl.Request, application *appstudiov1alpha1.Application, message string, migrationError error) {
+	if migrationError != nil {
+		logutil.LogAPIResourceChangeEvent(ctrl.LoggerFrom(ctx), application.Name, "Application", logutil.ResourceUpdate, migrationError)
+	}
+	r.setConditionAndUpdateCR(ctx, req, application, getCondition(gitOpsRepositoryMigratedConditionType, message, "GitOps repository migration failed", migrationError))
+}
+
+// getCondition returns the condition of the given type, true with the message if err is nil, and false with the failure message and err otherwise
+func getCondition(conditionType string, message string, failure string, err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Error",
+			Message: fmt.Sprintf("%s: %v", failure, err),
+		}
+	}
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "OK",
+		Message: message,
+	}
+}
+
+// setConditionAndUpdateCR sets the condition on the status of the Application, unless it is unchanged
+func (r *ApplicationReconciler) setConditionAndUpdateCR(ctx context.Context, req ctrl.Request, application *appstudiov1alpha1.Application, condition metav1.Condition) {
+	log := ctrl.LoggerFrom(ctx)
+	var currentApplication appstudiov1alpha1.Application
+	err := r.Get(ctx, req.NamespacedName, &currentApplication)
+	if err != nil {
+		log.Error(err, "Unable to get current Application status")
+		return
+	}
+	patch := client.MergeFrom(currentApplication.DeepCopy())
+
+	current := meta.FindStatusCondition(currentApplication.Status.Conditions, condition.Type)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+		return
+	}
+	meta.SetStatusCondition(&currentApplication.Status.Conditions, condition)
+	err = r.Client.Status().Patch(ctx, &currentApplication, patch)
+	if err != nil {
+		log.Error(err, "Unable to update Application status")
+	}
+}
@@ -19,11 +19,9 @@ package controllers
 import (
 	"context"
 	"strconv"
-	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
-	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
 	github "github.com/redhat-appstudio/application-service/pkg/github"
 	"github.com/redhat-appstudio/application-service/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -43,36 +41,43 @@ func (r *ApplicationReconciler) AddFinalizer(ctx context.Context, application *a
 		appAnnotations = make(map[string]string)
 	}
 	appAnnotations[finalizeCount] = "0"
+	// Record that the GitOps repository is archived on deletion, unless the retention annotation was set by the user
+	if _, ok := appAnnotations[GitOpsRepositoryRetentionAnnotation]; !ok {
+		appAnnotations[GitOpsRepositoryRetentionAnnotation] = archiveRetention
+	}
 	application.SetAnnotations(appAnnotations)
 	return r.Update(ctx, application)
 }
 
-// Finalize deletes the corresponding GitOps repo for the given Application CR.
+// Finalize archives the corresponding GitOps repo for the given Application CR, or deletes it if the retention annotation of the Application opts in to it.
+// Applications without the retention annotation, e.g. the ones that had the finalizer before the annotation was introduced, archive their repo
 func (r *ApplicationReconciler) Finalize(ctx context.Context, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient) error {
-	// Get the GitOps repository URL
-	devfileSrc := devfile.DevfileSrc{
-		Data: application.Status.Devfile,
-	}
-	devfileObj, err := devfile.ParseDevfile(devfileSrc)
-	if err != nil {
-		return err
+	// Nothing was generated for an Application whose devfile was never set, e.g. if its GitOps repository failed validation
+	if application.Status.Devfile == "" {
+		return nil
 	}
-	devfileGitOps := devfileObj.GetMetadata().Attributes.Get("gitOpsRepository.url", &err)
+
+	// Get the GitOps repository URL
+	gitOpsURL, err := getGitOpsRepositoryURL(application)
 	if err != nil {
 		return err
 	}
-	gitOpsURL := devfileGitOps.(string)
 
-	// Only delete the GitOps repo if we created it.
-	if strings.Contains(gitOpsURL, r.GitHubOrg) {
-		repoName, err := github.GetRepoNameFromURL(gitOpsURL, r.GitHubOrg)
+	// Only archive or delete the GitOps repo if we created it.
+	if r.isGeneratedRepository(application, gitOpsURL) {
+		// The repository may have been migrated out of the org of the controller
+		repoName, orgName, err := github.GetRepoAndOrgFromURL(gitOpsURL)
 		if err != nil {
 			return err
 		}
 
+		if application.Annotations[GitOpsRepositoryRetentionAnnotation] != deleteRetention {
+			return r.archiveRepository(ctx, application, ghClient, gitOpsURL, orgName, repoName)
+		}
+
 		metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "DeleteRepository"}
 		metrics.ControllerGitRequest.With(metricsLabel).Inc()
-		err = ghClient.DeleteRepository(ctx, r.GitHubOrg, repoName)
+		err = ghClient.DeleteRepository(ctx, orgName, repoName)
 		metrics.HandleRateLimitMetrics(err, metricsLabel)
 		return err
 
@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/gomega"
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/yaml"
@@ -135,6 +136,40 @@ var _ = Describe("Application controller finalizer counter tests", func() {
 		})
 	})
 
+	Context("Delete Application CR with the default retention", func() {
+		It("Should archive the GitOps repository and record its location", func() {
+			// Create a simple Application CR, the retention annotation defaults to archive
+			fetchedApp := createAndFetchSimpleApp(AppName, AppNamespace, DisplayName, Description)
+			hasAppLookupKey := types.NamespacedName{Name: AppName, Namespace: AppNamespace}
+			Eventually(func() string {
+				k8sClient.Get(context.Background(), hasAppLookupKey, fetchedApp)
+				return fetchedApp.Annotations[GitOpsRepositoryRetentionAnnotation]
+			}, timeout, interval).Should(Equal(archiveRetention))
+
+			// Delete the specified resource
+			Eventually(func() error {
+				return k8sClient.Delete(context.Background(), fetchedApp)
+			}, timeout, interval).Should(Succeed())
+
+			// Wait for delete to finish
+			Eventually(func() error {
+				f := &appstudiov1alpha1.Application{}
+				return k8sClient.Get(context.Background(), hasAppLookupKey, f)
+			}, timeout, interval).ShouldNot(Succeed())
+
+			// The archive of the GitOps repository is recorded in the namespace
+			configMap := &corev1.ConfigMap{}
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), types.NamespacedName{Name: gitOpsRepositoryArchivesConfigMapName, Namespace: AppNamespace}, configMap)
+				return configMap.Data[AppName] != ""
+			}, timeout, interval).Should(BeTrue())
+			Expect(configMap.Data[AppName]).Should(ContainSubstring("redhat-appstudio-appdata"))
+			Expect(configMap.Data[AppName]).ShouldNot(ContainSubstring("deleteAfter"))
+
+			Expect(k8sClient.Delete(context.Background(), configMap)).Should(Succeed())
+		})
+	})
+
 })
 
 // Simple function to create, retrieve from k8s, and return a simple Application CR
@@ -170,36 +205,41 @@ func createAndFetchSimpleApp(name string, namespace string, display string, desc
 	return fetchedHasApp
 }
 
+// createAndFetchSimpleAppWithRepo creates an Application whose devfile references the given GitOps repository. The repository is
+// set in the devfile after the Application is created, bypassing the validation of user-supplied GitOps repositories, so that
+// tests can check how the other controllers handle unusable repositories
 func createAndFetchSimpleAppWithRepo(name string, namespace string, display string, description string, gitopsRepo string) *appstudiov1alpha1.Application {
-	ctx := context.Background()
-
-	hasApp := &appstudiov1alpha1.Application{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: "appstudio.redhat.com/v1alpha1",
-			Kind:       "Application",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-		Spec: appstudiov1alpha1.ApplicationSpec{
-			DisplayName: display,
-			Description: description,
-			GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
-				URL: gitopsRepo,
-			},
-		},
-	}
-
-	Expect(k8sClient.Create(ctx, hasApp)).Should(Succeed())
+	createAndFetchSimpleApp(name, namespace, display, description)
+	return setAppGitOpsRepositoryURL(types.NamespacedName{Name: name, Namespace: namespace}, gitopsRepo)
+}
 
-	// Look up the has app resource that was created.
-	// num(conditions) may still be < 1 on the first try, so retry until at least _some_ condition is set
-	hasAppLookupKey := types.NamespacedName{Name: name, Namespace: namespace}
+// setAppGitOpsRepositoryURL sets the GitOps and app model repository URLs in the devfile of the Application, bypassing the validation
+// of user-supplied GitOps repositories
+func setAppGitOpsRepositoryURL(hasAppLookupKey types.NamespacedName, gitopsRepo string) *appstudiov1alpha1.Application {
 	fetchedHasApp := &appstudiov1alpha1.Application{}
+	var devfileYaml []byte
+	Eventually(func() error {
+		if err := k8sClient.Get(context.Background(), hasAppLookupKey, fetchedHasApp); err != nil {
+			return err
+		}
+		curDevfile, err := devfile.ParseDevfile(devfile.DevfileSrc{Data: fetchedHasApp.Status.Devfile})
+		if err != nil {
+			return err
+		}
+		devfileMeta := curDevfile.GetMetadata()
+		devfileMeta.Attributes = devfileMeta.Attributes.PutString("gitOpsRepository.url", gitopsRepo).PutString("appModelRepository.url", gitopsRepo)
+		curDevfile.SetMetadata(devfileMeta)
+		devfileYaml, err = yaml.Marshal(curDevfile)
+		if err != nil {
+			return err
+		}
+		fetchedHasApp.Status.Devfile = string(devfileYaml)
+		return k8sClient.Status().Update(context.Background(), fetchedHasApp)
+	}, timeout, interval).Should(Succeed())
+
 	Eventually(func() bool {
 		k8sClient.Get(context.Background(), hasAppLookupKey, fetchedHasApp)
-		return len(fetchedHasApp.Status.Conditions) > 0
+		return fetchedHasApp.Status.Devfile == string(devfileYaml)
 	}, timeout, interval).Should(BeTrue())
 
 	return fetchedHasApp
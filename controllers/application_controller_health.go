@@ -0,0 +1,265 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// applicationReadyConditionType is the condition of an Application reporting the aggregated health of its Components and Bindings
+const applicationReadyConditionType = "Ready"
+
+// componentCounts are the numbers of Components of an Application by health. A Component whose GitOps generation failed
+// is counted in GitOpsGenerationFailed, and in Failed only if its creation or update failed too
+type componentCounts struct {
+	Total                  int `json:"total"`
+	Ready                  int `json:"ready"`
+	Failed                 int `json:"failed"`
+	GitOpsGenerationFailed int `json:"gitOpsGenerationFailed"`
+}
+
+// environmentHealth is the health of the Binding of an Application to an environment, with the Snapshot and the last commit it pushed
+type environmentHealth struct {
+	Environment string `json:"environment"`
+	Binding     string `json:"binding"`
+	Snapshot    string `json:"snapshot,omitempty"`
+	CommitID    string `json:"commitID,omitempty"`
+	Ready       bool   `json:"ready"`
+}
+
+// applicationHealth is the health of an Application aggregated from its Components and Bindings
+type applicationHealth struct {
+	Components   componentCounts     `json:"components"`
+	Environments []environmentHealth `json:"environments,omitempty"`
+}
+
+// ReconcileHealth aggregates the health of the Components and the Bindings of the Application into its health annotation and Ready condition.
+// It runs in its own controller, triggered by the changes to the Components and the Bindings, so that they don't trigger the GitHub
+// operations of the Application reconcile loop. The Application controller ignores the updates of the health annotation and of the status
+func (r *ApplicationReconciler) ReconcileHealth(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var application appstudiov1alpha1.Application
+	if err := r.Get(ctx, req.NamespacedName, &application); err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !application.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	health, err := r.getApplicationHealth(ctx, &application)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to aggregate the health of the Application %v", req.NamespacedName))
+		return ctrl.Result{}, err
+	}
+
+	var currentHealth applicationHealth
+	if _, err := getJSONAnnotation(&application, ApplicationHealthAnnotation, &currentHealth); err != nil || !reflect.DeepEqual(currentHealth, health) {
+		patch := client.MergeFrom(application.DeepCopy())
+		if err := setJSONAnnotation(&application, ApplicationHealthAnnotation, health); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Patch(ctx, &application, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	r.setConditionAndUpdateCR(ctx, req, &application, getReadyCondition(health))
+	return ctrl.Result{}, nil
+}
+
+// getApplicationHealth returns the health of the Components and the Bindings of the Application
+func (r *ApplicationReconciler) getApplicationHealth(ctx context.Context, application *appstudiov1alpha1.Application) (applicationHealth, error) {
+	var health applicationHealth
+
+	var componentList appstudiov1alpha1.ComponentList
+	if err := r.List(ctx, &componentList, client.InNamespace(application.Namespace)); err != nil {
+		return health, err
+	}
+	for i := range componentList.Items {
+		component := &componentList.Items[i]
+		if component.Spec.Application != application.Name || !component.DeletionTimestamp.IsZero() {
+			continue
+		}
+		health.Components.Total++
+		failed := isConditionFalse(component.Status.Conditions, "Created") || isConditionFalse(component.Status.Conditions, "Updated")
+		gitOpsGenerationFailed := isConditionFalse(component.Status.Conditions, "GitOpsResourcesGenerated")
+		switch {
+		case failed:
+			health.Components.Failed++
+		case !gitOpsGenerationFailed && meta.IsStatusConditionTrue(component.Status.Conditions, "Created"):
+			health.Components.Ready++
+		}
+		if gitOpsGenerationFailed {
+			health.Components.GitOpsGenerationFailed++
+		}
+	}
+
+	var bindingList appstudiov1alpha1.SnapshotEnvironmentBindingList
+	if err := r.List(ctx, &bindingList, client.InNamespace(application.Namespace)); err != nil {
+		return health, err
+	}
+	for i := range bindingList.Items {
+		binding := &bindingList.Items[i]
+		if binding.Spec.Application != application.Name || !binding.DeletionTimestamp.IsZero() {
+			continue
+		}
+		health.Environments = append(health.Environments, getEnvironmentHealth(binding))
+	}
+	sort.Slice(health.Environments, func(i, j int) bool {
+		if health.Environments[i].Environment != health.Environments[j].Environment {
+			return health.Environments[i].Environment < health.Environments[j].Environment
+		}
+		return health.Environments[i].Binding < health.Environments[j].Binding
+	})
+	return health, nil
+}
+
+// getEnvironmentHealth returns the health of the Binding. A Binding is ready once its GitOps resources were generated and none of its
+// Components failed to deploy. The last commit is the one of its deployment history, or of the status of its Components
+func getEnvironmentHealth(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) environmentHealth {
+	environment := environmentHealth{
+		Environment: binding.Spec.Environment,
+		Binding:     binding.Name,
+		Snapshot:    binding.Spec.Snapshot,
+		Ready:       meta.IsStatusConditionTrue(binding.Status.GitOpsRepoConditions, "GitOpsResourcesGenerated"),
+	}
+	for _, condition := range binding.Status.ComponentDeploymentConditions {
+		if condition.Status == metav1.ConditionFalse {
+			environment.Ready = false
+		}
+	}
+
+	if deploymentHistory, err := getDeploymentHistory(binding); err == nil && len(deploymentHistory) > 0 {
+		environment.CommitID = deploymentHistory[0].CommitID
+	}
+	for _, component := range binding.Status.Components {
+		if environment.CommitID != "" {
+			break
+		}
+		environment.CommitID = component.GitOpsRepository.CommitID
+	}
+	return environment
+}
+
+// getReadyCondition returns the Ready condition of the Application for its health
+func getReadyCondition(health applicationHealth) metav1.Condition {
+	var notReadyEnvironments []string
+	for _, environment := range health.Environments {
+		if !environment.Ready {
+			notReadyEnvironments = append(notReadyEnvironments, environment.Environment)
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    applicationReadyConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "OK",
+		Message: fmt.Sprintf("%d of %d Component(s) ready, bound to %d environment(s)", health.Components.Ready, health.Components.Total, len(health.Environments)),
+	}
+	switch {
+	case health.Components.Failed > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ComponentsFailed"
+		condition.Message = fmt.Sprintf("%d of %d Component(s) failed", health.Components.Failed, health.Components.Total)
+	case health.Components.GitOpsGenerationFailed > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "GitOpsGenerationFailed"
+		condition.Message = fmt.Sprintf("The GitOps generation of %d of %d Component(s) failed", health.Components.GitOpsGenerationFailed, health.Components.Total)
+	case health.Components.Ready < health.Components.Total:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ComponentsNotReady"
+		condition.Message = fmt.Sprintf("%d of %d Component(s) ready", health.Components.Ready, health.Components.Total)
+	case len(notReadyEnvironments) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "EnvironmentsNotReady"
+		condition.Message = fmt.Sprintf("The Bindings to the environment(s) %s are not ready", strings.Join(notReadyEnvironments, ", "))
+	}
+	return condition
+}
+
+// isConditionFalse returns true if the condition of the given type is set and false
+func isConditionFalse(conditions []metav1.Condition, conditionType string) bool {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	return condition != nil && condition.Status == metav1.ConditionFalse
+}
+
+// setupHealthWithManager sets up the controller aggregating the health of the Applications with the Manager
+func (r *ApplicationReconciler) setupHealthWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("applicationhealth").
+		// The health is aggregated when the Application is created, and then when its Components and Bindings change
+		For(&appstudiov1alpha1.Application{}, builder.WithPredicates(predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return false
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return false
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return false
+			},
+		})).
+		Watches(&source.Kind{Type: &appstudiov1alpha1.Component{}},
+			handler.EnqueueRequestsFromMapFunc(MapToApplication), builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					oldComponent, ok := e.ObjectOld.(*appstudiov1alpha1.Component)
+					if !ok {
+						return false
+					}
+					newComponent, ok := e.ObjectNew.(*appstudiov1alpha1.Component)
+					if !ok {
+						return false
+					}
+					return oldComponent.Spec.Application != newComponent.Spec.Application || !reflect.DeepEqual(oldComponent.Status.Conditions, newComponent.Status.Conditions)
+				},
+			})).
+		Watches(&source.Kind{Type: &appstudiov1alpha1.SnapshotEnvironmentBinding{}},
+			handler.EnqueueRequestsFromMapFunc(MapToApplication), builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					oldBinding, ok := e.ObjectOld.(*appstudiov1alpha1.SnapshotEnvironmentBinding)
+					if !ok {
+						return false
+					}
+					newBinding, ok := e.ObjectNew.(*appstudiov1alpha1.SnapshotEnvironmentBinding)
+					if !ok {
+						return false
+					}
+					return !reflect.DeepEqual(oldBinding.Spec, newBinding.Spec) || !reflect.DeepEqual(oldBinding.Status, newBinding.Status) ||
+						oldBinding.Annotations[DeploymentHistoryAnnotation] != newBinding.Annotations[DeploymentHistoryAnnotation]
+				},
+			})).
+		Complete(reconcile.Func(r.ReconcileHealth))
+}
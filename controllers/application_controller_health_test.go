@@ -0,0 +1,178 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestReconcileHealth(t *testing.T) {
+	appLookupKey := types.NamespacedName{Name: "test-app", Namespace: "default"}
+
+	condition := func(conditionType string, status metav1.ConditionStatus) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: status, Reason: "Test"}
+	}
+	component := func(name string, application string, conditions ...metav1.Condition) *appstudiov1alpha1.Component {
+		return &appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: appLookupKey.Namespace},
+			Spec:       appstudiov1alpha1.ComponentSpec{ComponentName: name, Application: application},
+			Status:     appstudiov1alpha1.ComponentStatus{Conditions: conditions},
+		}
+	}
+	binding := func(name string, environment string, status appstudiov1alpha1.SnapshotEnvironmentBindingStatus, annotations map[string]string) *appstudiov1alpha1.SnapshotEnvironmentBinding {
+		return &appstudiov1alpha1.SnapshotEnvironmentBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: appLookupKey.Namespace, Annotations: annotations},
+			Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+				Application: appLookupKey.Name,
+				Environment: environment,
+				Snapshot:    "test-snapshot",
+			},
+			Status: status,
+		}
+	}
+	generated := []metav1.Condition{condition("GitOpsResourcesGenerated", metav1.ConditionTrue)}
+
+	tests := []struct {
+		name       string
+		objs       []runtime.Object
+		want       applicationHealth
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "No Components",
+			want:       applicationHealth{},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "OK",
+		},
+		{
+			name: "Components ready and bound to environments",
+			objs: []runtime.Object{
+				component("backend", appLookupKey.Name, condition("Created", metav1.ConditionTrue), condition("GitOpsResourcesGenerated", metav1.ConditionTrue)),
+				component("frontend", appLookupKey.Name, condition("Created", metav1.ConditionTrue), condition("Updated", metav1.ConditionTrue)),
+				component("other", "other-app", condition("Created", metav1.ConditionFalse)),
+				binding("prod-binding", "prod", appstudiov1alpha1.SnapshotEnvironmentBindingStatus{
+					GitOpsRepoConditions: generated,
+					Components: []appstudiov1alpha1.BindingComponentStatus{
+						{Name: "backend", GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{CommitID: "1234"}},
+					},
+				}, nil),
+				binding("dev-binding", "dev", appstudiov1alpha1.SnapshotEnvironmentBindingStatus{
+					GitOpsRepoConditions: generated,
+					Components: []appstudiov1alpha1.BindingComponentStatus{
+						{Name: "backend", GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{CommitID: "1234"}},
+					},
+				}, map[string]string{DeploymentHistoryAnnotation: `[{"snapshot": "test-snapshot", "commitID": "5678"}]`}),
+			},
+			want: applicationHealth{
+				Components: componentCounts{Total: 2, Ready: 2},
+				Environments: []environmentHealth{
+					{Environment: "dev", Binding: "dev-binding", Snapshot: "test-snapshot", CommitID: "5678", Ready: true},
+					{Environment: "prod", Binding: "prod-binding", Snapshot: "test-snapshot", CommitID: "1234", Ready: true},
+				},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "OK",
+		},
+		{
+			name: "Component failed",
+			objs: []runtime.Object{
+				component("backend", appLookupKey.Name, condition("Created", metav1.ConditionTrue), condition("Updated", metav1.ConditionFalse)),
+				component("frontend", appLookupKey.Name, condition("Created", metav1.ConditionTrue), condition("GitOpsResourcesGenerated", metav1.ConditionFalse)),
+			},
+			want:       applicationHealth{Components: componentCounts{Total: 2, Failed: 1, GitOpsGenerationFailed: 1}},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "ComponentsFailed",
+		},
+		{
+			name: "GitOps generation failed",
+			objs: []runtime.Object{
+				component("backend", appLookupKey.Name, condition("Created", metav1.ConditionTrue), condition("GitOpsResourcesGenerated", metav1.ConditionFalse)),
+			},
+			want:       applicationHealth{Components: componentCounts{Total: 1, GitOpsGenerationFailed: 1}},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "GitOpsGenerationFailed",
+		},
+		{
+			name: "Component being created",
+			objs: []runtime.Object{
+				component("backend", appLookupKey.Name),
+			},
+			want:       applicationHealth{Components: componentCounts{Total: 1}},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "ComponentsNotReady",
+		},
+		{
+			name: "Binding not ready",
+			objs: []runtime.Object{
+				binding("staging-binding", "staging", appstudiov1alpha1.SnapshotEnvironmentBindingStatus{
+					GitOpsRepoConditions:          generated,
+					ComponentDeploymentConditions: []metav1.Condition{condition("AllComponentsDeployed", metav1.ConditionFalse)},
+				}, nil),
+			},
+			want: applicationHealth{
+				Environments: []environmentHealth{
+					{Environment: "staging", Binding: "staging-binding", Snapshot: "test-snapshot"},
+				},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "EnvironmentsNotReady",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      appLookupKey.Name,
+					Namespace: appLookupKey.Namespace,
+				},
+			}
+			fakeClient := NewFakeClient(t, append(tt.objs, application)...)
+			r := &ApplicationReconciler{Client: fakeClient}
+
+			if _, err := r.ReconcileHealth(context.Background(), ctrl.Request{NamespacedName: appLookupKey}); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			updatedApplication := appstudiov1alpha1.Application{}
+			if err := fakeClient.Get(context.Background(), appLookupKey, &updatedApplication); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			var health applicationHealth
+			if _, err := getJSONAnnotation(&updatedApplication, ApplicationHealthAnnotation, &health); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.want, health)
+
+			readyCondition := meta.FindStatusCondition(updatedApplication.Status.Conditions, applicationReadyConditionType)
+			if assert.NotNil(t, readyCondition) {
+				assert.Equal(t, tt.wantStatus, readyCondition.Status)
+				assert.Equal(t, tt.wantReason, readyCondition.Reason)
+			}
+		})
+	}
+}
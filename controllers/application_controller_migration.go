@@ -0,0 +1,307 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/metrics"
+	"golang.org/x/exp/slices"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// gitOpsRepositoryMigratedConditionType is the condition of an Application reporting the progress of the migration of its GitOps repository
+const gitOpsRepositoryMigratedConditionType = "GitOpsRepositoryMigrated"
+
+// The phases of the migration of a GitOps repository, in order
+const (
+	migrationPhaseMoving              = "MovingRepository"
+	migrationPhaseUpdatingApplication = "UpdatingApplication"
+	migrationPhaseUpdatingComponents  = "UpdatingComponents"
+	migrationPhaseCompleted           = "Completed"
+)
+
+// gitHubProvider is the only Git provider that GitOps repositories can be migrated to
+const gitHubProvider = "github"
+
+// gitOpsRepositoryMigration is the migration of the generated GitOps repository of an Application requested in its migration annotation.
+// The repository is renamed to Name, the name given by the naming policy of the controller if unset,
+// and transferred to the GitHub org Org, the org of the controller if unset. Moving the repository to a Git provider other than GitHub
+// is not supported, Provider is only set to reject such migrations explicitly
+type gitOpsRepositoryMigration struct {
+	Provider string `json:"provider,omitempty"`
+	Org      string `json:"org,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// gitOpsRepositoryMigrationStatus records the progress of the migration of the GitOps repository of an Application
+type gitOpsRepositoryMigrationStatus struct {
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	Phase       string       `json:"phase"`
+	Components  []string     `json:"components,omitempty"`
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// getMigrationOrgs returns the GitHub orgs that the GitOps repositories can be migrated to
+func (r *ApplicationReconciler) getMigrationOrgs() []string {
+	if len(r.GitOpsRepoMigrationOrgs) == 0 {
+		return []string{r.GitHubOrg}
+	}
+	return r.GitOpsRepoMigrationOrgs
+}
+
+// getGitHubOrgs returns the GitHub orgs holding the generated GitOps repositories, the org of the controller that they are generated in
+// and the orgs that they can be migrated to
+func getGitHubOrgs(gitHubOrg string, migrationOrgs []string) []string {
+	orgs := []string{gitHubOrg}
+	for _, org := range migrationOrgs {
+		if !slices.Contains(orgs, org) {
+			orgs = append(orgs, org)
+		}
+	}
+	return orgs
+}
+
+// getMigrationTarget returns the org and name that the GitOps repository of the Application is migrated to. The org must be one of the
+// migration orgs of the controller, as the annotation is set by the tenant and the repository is moved with the token of the controller
+func (r *ApplicationReconciler) getMigrationTarget(application *appstudiov1alpha1.Application, migration gitOpsRepositoryMigration) (string, string, error) {
+	if migration.Provider != "" && !strings.EqualFold(migration.Provider, gitHubProvider) {
+		return "", "", fmt.Errorf("invalid %s annotation on Application %s, moving the repository to the %s Git provider is not supported, it can only be moved within GitHub", GitOpsRepositoryMigrationAnnotation, application.Name, migration.Provider)
+	}
+	if strings.Contains(migration.Org, "/") || strings.Contains(migration.Name, "/") {
+		return "", "", fmt.Errorf("invalid %s annotation on Application %s, the repository can only be moved to another GitHub org", GitOpsRepositoryMigrationAnnotation, application.Name)
+	}
+	orgName := migration.Org
+	if orgName == "" {
+		orgName = r.GitHubOrg
+	}
+	migrationOrgs := r.getMigrationOrgs()
+	if !slices.Contains(migrationOrgs, orgName) {
+		return "", "", fmt.Errorf("invalid %s annotation on Application %s, the repository can't be moved to the %s GitHub org, it can only be moved to %s", GitOpsRepositoryMigrationAnnotation, application.Name, orgName, strings.Join(migrationOrgs, ", "))
+	}
+	repoName := migration.Name
+	if repoName == "" {
+		// The target must not change between reconciles, so that the migration completes
+		if !r.GitOpsRepoNaming.IsDeterministic() {
+			return "", "", fmt.Errorf("invalid %s annotation on Application %s, the name is required as the naming template %q gives random names", GitOpsRepositoryMigrationAnnotation, application.Name, r.GitOpsRepoNaming.Template)
+		}
+		repoName = r.GitOpsRepoNaming.Name(getRepositoryNameValues(application))
+	}
+	return orgName, repoName, nil
+}
+
+// migrateGitOpsRepository renames the generated GitOps repository of the Application, or moves it to another GitHub org, as requested
+// by its migration annotation. The devfile of the Application and the GitOps status of its Components are then updated to the new
+// repository, which makes the Bindings of the Components sync to it. The migration is resumed from its last completed phase
+// if it fails, and its progress is recorded in the migration status annotation and condition of the Application
+func (r *ApplicationReconciler) migrateGitOpsRepository(ctx context.Context, req ctrl.Request, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	var migration gitOpsRepositoryMigration
+	found, err := getJSONAnnotation(application, GitOpsRepositoryMigrationAnnotation, &migration)
+	if !found || application.Status.Devfile == "" {
+		return nil
+	}
+	var migrationStatus gitOpsRepositoryMigrationStatus
+	if _, statusErr := getJSONAnnotation(application, GitOpsRepositoryMigrationStatusAnnotation, &migrationStatus); statusErr != nil {
+		// The migration status annotation is only written by the controller, an invalid annotation restarts the migration
+		migrationStatus = gitOpsRepositoryMigrationStatus{}
+	}
+	var orgName, repoName string
+	if err == nil {
+		orgName, repoName, err = r.getMigrationTarget(application, migration)
+	}
+	if err != nil {
+		// An invalid annotation is reported and not retried until it is changed
+		log.Error(err, fmt.Sprintf("Invalid GitOps repository migration %v", req.NamespacedName))
+		r.SetMigrationConditionAndUpdateCR(ctx, req, application, "", err)
+		return nil
+	}
+	targetURL := "https://github.com/" + orgName + "/" + repoName
+	if migrationStatus.To == targetURL && migrationStatus.Phase == migrationPhaseCompleted {
+		return nil
+	}
+
+	if migrationStatus.To != targetURL {
+		gitOpsURL, err := getGitOpsRepositoryURL(application)
+		if err != nil {
+			return err
+		}
+		if !r.isGeneratedRepository(application, gitOpsURL) {
+			err := fmt.Errorf("the GitOps repository %s was not generated for Application %s and can't be migrated", gitOpsURL, application.Name)
+			r.SetMigrationConditionAndUpdateCR(ctx, req, application, "", err)
+			return nil
+		}
+		migrationStatus = gitOpsRepositoryMigrationStatus{From: gitOpsURL, To: targetURL, Phase: migrationPhaseMoving}
+		if gitOpsURL == targetURL {
+			migrationStatus.Phase = migrationPhaseCompleted
+		}
+	}
+
+	for migrationStatus.Phase != migrationPhaseCompleted {
+		if err := r.runMigrationPhase(ctx, application, ghClient, &migrationStatus); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to migrate the GitOps repository from %s to %s %v", migrationStatus.From, migrationStatus.To, req.NamespacedName))
+			r.SetMigrationConditionAndUpdateCR(ctx, req, application, "", fmt.Errorf("%s phase failed: %v", migrationStatus.Phase, err))
+			return err
+		}
+		if err := r.updateMigrationStatus(ctx, application, migrationStatus); err != nil {
+			return err
+		}
+		r.SetMigrationConditionAndUpdateCR(ctx, req, application, getMigrationMessage(migrationStatus), nil)
+	}
+	log.Info(fmt.Sprintf("Migrated the GitOps repository from %s to %s %v", migrationStatus.From, migrationStatus.To, req.NamespacedName))
+	return nil
+}
+
+// runMigrationPhase runs the current phase of the migration and moves the migration status to the next phase
+func (r *ApplicationReconciler) runMigrationPhase(ctx context.Context, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient, migrationStatus *gitOpsRepositoryMigrationStatus) error {
+	switch migrationStatus.Phase {
+	case migrationPhaseMoving:
+		if err := r.moveRepository(ctx, ghClient, migrationStatus.From, migrationStatus.To); err != nil {
+			return err
+		}
+		migrationStatus.Phase = migrationPhaseUpdatingApplication
+	case migrationPhaseUpdatingApplication:
+		if err := r.updateApplicationRepository(ctx, application, migrationStatus.From, migrationStatus.To); err != nil {
+			return err
+		}
+		migrationStatus.Phase = migrationPhaseUpdatingComponents
+	case migrationPhaseUpdatingComponents:
+		components, err := r.updateComponentsRepository(ctx, application, migrationStatus.From, migrationStatus.To)
+		if err != nil {
+			return err
+		}
+		completedAt := metav1.Now()
+		migrationStatus.Components = components
+		migrationStatus.CompletedAt = &completedAt
+		migrationStatus.Phase = migrationPhaseCompleted
+	default:
+		return fmt.Errorf("unknown migration phase %q", migrationStatus.Phase)
+	}
+	return nil
+}
+
+// getMigrationMessage returns the message of the migration condition for the phase of the migration
+func getMigrationMessage(migrationStatus gitOpsRepositoryMigrationStatus) string {
+	switch migrationStatus.Phase {
+	case migrationPhaseUpdatingApplication:
+		return fmt.Sprintf("Moved the GitOps repository %s to %s, updating the Application", migrationStatus.From, migrationStatus.To)
+	case migrationPhaseUpdatingComponents:
+		return fmt.Sprintf("Updated the Application to the GitOps repository %s, updating its Components", migrationStatus.To)
+	default:
+		return fmt.Sprintf("Migrated the GitOps repository %s to %s, %d Component(s) updated", migrationStatus.From, migrationStatus.To, len(migrationStatus.Components))
+	}
+}
+
+// moveRepository renames the repository, or transfers it to another org, from the given URL to the target URL
+func (r *ApplicationReconciler) moveRepository(ctx context.Context, ghClient *github.GitHubClient, fromURL string, toURL string) error {
+	repoName, orgName, err := github.GetRepoAndOrgFromURL(fromURL)
+	if err != nil {
+		return err
+	}
+	parsedURL, err := url.Parse(toURL)
+	if err != nil {
+		return err
+	}
+	parts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("unable to parse the GitOps repository URL %s", toURL)
+	}
+
+	metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "MoveRepository"}
+	metrics.ControllerGitRequest.With(metricsLabel).Inc()
+	_, err = ghClient.MoveRepository(ctx, orgName, repoName, parts[0], parts[1])
+	metrics.HandleRateLimitMetrics(err, metricsLabel)
+	return err
+}
+
+// updateApplicationRepository sets the GitOps repository URL of the devfile of the Application to the migrated repository,
+// and its app-model repository URL too if it was the GitOps repository
+func (r *ApplicationReconciler) updateApplicationRepository(ctx context.Context, application *appstudiov1alpha1.Application, fromURL string, toURL string) error {
+	devfileData, err := devfile.ParseDevfile(devfile.DevfileSrc{Data: application.Status.Devfile})
+	if err != nil {
+		return err
+	}
+	devfileMeta := devfileData.GetMetadata()
+	devfileMeta.Attributes = devfileMeta.Attributes.PutString("gitOpsRepository.url", toURL)
+	if devfileMeta.Attributes.GetString("appModelRepository.url", &err) == fromURL {
+		devfileMeta.Attributes = devfileMeta.Attributes.PutString("appModelRepository.url", toURL)
+	}
+	devfileData.SetMetadata(devfileMeta)
+	yamlData, err := yaml.Marshal(devfileData)
+	if err != nil {
+		return err
+	}
+
+	var currentApplication appstudiov1alpha1.Application
+	if err := r.Get(ctx, client.ObjectKeyFromObject(application), &currentApplication); err != nil {
+		return err
+	}
+	patch := client.MergeFrom(currentApplication.DeepCopy())
+	currentApplication.Status.Devfile = string(yamlData)
+	if err := r.Client.Status().Patch(ctx, &currentApplication, patch); err != nil {
+		return err
+	}
+	application.Status.Devfile = string(yamlData)
+	return nil
+}
+
+// updateComponentsRepository sets the GitOps repository URL in the status of the Components of the Application that use the repository
+// being migrated, and returns their names. The Bindings of the Components are synced to the migrated repository on the status update
+func (r *ApplicationReconciler) updateComponentsRepository(ctx context.Context, application *appstudiov1alpha1.Application, fromURL string, toURL string) ([]string, error) {
+	var componentList appstudiov1alpha1.ComponentList
+	if err := r.List(ctx, &componentList, client.InNamespace(application.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var components []string
+	for i := range componentList.Items {
+		component := &componentList.Items[i]
+		if component.Spec.Application != application.Name || (component.Status.GitOps.RepositoryURL != fromURL && component.Status.GitOps.RepositoryURL != toURL) {
+			continue
+		}
+		components = append(components, component.Name)
+		if component.Status.GitOps.RepositoryURL == toURL {
+			continue
+		}
+		patch := client.MergeFrom(component.DeepCopy())
+		component.Status.GitOps.RepositoryURL = toURL
+		if err := r.Client.Status().Patch(ctx, component, patch); err != nil {
+			return components, fmt.Errorf("unable to update the GitOps repository of Component %s: %v", component.Name, err)
+		}
+	}
+	return components, nil
+}
+
+// updateMigrationStatus records the progress of the migration in the migration status annotation of the Application
+func (r *ApplicationReconciler) updateMigrationStatus(ctx context.Context, application *appstudiov1alpha1.Application, migrationStatus gitOpsRepositoryMigrationStatus) error {
+	patch := client.MergeFrom(application.DeepCopy())
+	if err := setJSONAnnotation(application, GitOpsRepositoryMigrationStatusAnnotation, migrationStatus); err != nil {
+		return err
+	}
+	return r.Patch(ctx, application, patch)
+}
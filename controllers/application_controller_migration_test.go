@@ -0,0 +1,268 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+func TestMigrateGitOpsRepository(t *testing.T) {
+	appLookupKey := types.NamespacedName{Name: "test-app", Namespace: "default"}
+	generatedURL := "https://github.com/redhat-appstudio-appdata/petclinic-gitops"
+	renamedURL := "https://github.com/redhat-appstudio-appdata/missing-repo-petclinic"
+	defaultURL := "https://github.com/redhat-appstudio-appdata/default-test-app"
+
+	tests := []struct {
+		name            string
+		gitOpsURL       string
+		naming          github.RepositoryNamingPolicy
+		migrationOrgs   []string
+		annotations     map[string]string
+		wantErr         bool
+		wantGitOpsURL   string
+		wantPhase       string
+		wantComponents  []string
+		wantMessage     string
+		wantNoCondition bool
+	}{
+		{
+			name:      "Repository renamed",
+			gitOpsURL: generatedURL,
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation: `{"name": "missing-repo-petclinic"}`,
+			},
+			wantGitOpsURL:  renamedURL,
+			wantPhase:      migrationPhaseCompleted,
+			wantComponents: []string{"backend", "frontend"},
+			wantMessage:    "Migrated the GitOps repository " + generatedURL + " to " + renamedURL + ", 2 Component(s) updated",
+		},
+		{
+			name:      "Repository renamed by the naming policy by default",
+			gitOpsURL: generatedURL,
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation: `{}`,
+			},
+			wantGitOpsURL:  defaultURL,
+			wantPhase:      migrationPhaseCompleted,
+			wantComponents: []string{"backend", "frontend"},
+			wantMessage:    "Migrated the GitOps repository " + generatedURL + " to " + defaultURL,
+		},
+		{
+			name:      "Migration resumed at the update of the Components",
+			gitOpsURL: renamedURL,
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation:       `{"name": "missing-repo-petclinic"}`,
+				GitOpsRepositoryMigrationStatusAnnotation: `{"from": "` + generatedURL + `", "to": "` + renamedURL + `", "phase": "UpdatingComponents"}`,
+			},
+			wantGitOpsURL:  renamedURL,
+			wantPhase:      migrationPhaseCompleted,
+			wantComponents: []string{"backend", "frontend"},
+			wantMessage:    "Migrated the GitOps repository " + generatedURL + " to " + renamedURL,
+		},
+		{
+			name:      "Migration already completed",
+			gitOpsURL: renamedURL,
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation:       `{"name": "missing-repo-petclinic"}`,
+				GitOpsRepositoryMigrationStatusAnnotation: `{"from": "` + generatedURL + `", "to": "` + renamedURL + `", "phase": "Completed"}`,
+			},
+			wantGitOpsURL:   renamedURL,
+			wantPhase:       migrationPhaseCompleted,
+			wantNoCondition: true,
+		},
+		{
+			name:            "No migration requested",
+			gitOpsURL:       generatedURL,
+			wantGitOpsURL:   generatedURL,
+			wantNoCondition: true,
+		},
+		{
+			name:      "User-supplied repository",
+			gitOpsURL: "https://github.com/testorg/petclinic-gitops",
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation: `{"name": "missing-repo-petclinic"}`,
+			},
+			wantGitOpsURL: "https://github.com/testorg/petclinic-gitops",
+			wantMessage:   "GitOps repository migration failed: the GitOps repository https://github.com/testorg/petclinic-gitops was not generated for Application test-app and can't be migrated",
+		},
+		{
+			name:      "Move to another provider",
+			gitOpsURL: generatedURL,
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation: `{"org": "gitlab.com/testorg"}`,
+			},
+			wantGitOpsURL: generatedURL,
+			wantMessage:   "GitOps repository migration failed: invalid appstudio.openshift.io/gitops-repository-migration annotation on Application test-app",
+		},
+		{
+			name:      "Move to another Git provider",
+			gitOpsURL: generatedURL,
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation: `{"provider": "gitlab", "org": "testorg"}`,
+			},
+			wantGitOpsURL: generatedURL,
+			wantMessage:   "moving the repository to the gitlab Git provider is not supported, it can only be moved within GitHub",
+		},
+		{
+			name:      "Move to an org outside of the migration orgs",
+			gitOpsURL: generatedURL,
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation: `{"org": "other-org", "name": "missing-repo-petclinic"}`,
+			},
+			wantGitOpsURL: generatedURL,
+			wantMessage:   "the repository can't be moved to the other-org GitHub org, it can only be moved to redhat-appstudio-appdata",
+		},
+		{
+			name:          "Move to one of the migration orgs",
+			gitOpsURL:     generatedURL,
+			migrationOrgs: []string{github.AppStudioAppDataOrg, "other-org"},
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation: `{"provider": "github", "org": "other-org", "name": "missing-repo-petclinic"}`,
+			},
+			wantGitOpsURL:  "https://github.com/other-org/missing-repo-petclinic",
+			wantPhase:      migrationPhaseCompleted,
+			wantComponents: []string{"backend", "frontend"},
+			wantMessage:    "Migrated the GitOps repository " + generatedURL + " to https://github.com/other-org/missing-repo-petclinic",
+		},
+		{
+			name:      "Naming policy gives random names",
+			gitOpsURL: generatedURL,
+			naming:    github.RepositoryNamingPolicy{Template: "{application}-{random}"},
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation: `{}`,
+			},
+			wantGitOpsURL: generatedURL,
+			wantMessage:   "the name is required as the naming template \"{application}-{random}\" gives random names",
+		},
+		{
+			name:      "Repository can't be moved",
+			gitOpsURL: generatedURL,
+			annotations: map[string]string{
+				GitOpsRepositoryMigrationAnnotation: `{"name": "test-error-response"}`,
+			},
+			wantErr:       true,
+			wantGitOpsURL: generatedURL,
+			wantMessage:   "GitOps repository migration failed: MovingRepository phase failed: failed to get repo test-error-response under redhat-appstudio-appdata",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        appLookupKey.Name,
+					Namespace:   appLookupKey.Namespace,
+					Annotations: tt.annotations,
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "Pet Clinic",
+				},
+			}
+			devfileData, err := devfile.ConvertApplicationToDevfile(*application, tt.gitOpsURL, "")
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			devfileYaml, err := yaml.Marshal(devfileData)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			application.Status.Devfile = string(devfileYaml)
+
+			objs := []runtime.Object{application}
+			for _, component := range []struct{ name, application, gitOpsURL string }{
+				{"backend", appLookupKey.Name, generatedURL},
+				{"frontend", appLookupKey.Name, generatedURL},
+				{"other-app", "other-app", generatedURL},
+			} {
+				objs = append(objs, &appstudiov1alpha1.Component{
+					ObjectMeta: metav1.ObjectMeta{Name: component.name, Namespace: appLookupKey.Namespace},
+					Spec:       appstudiov1alpha1.ComponentSpec{ComponentName: component.name, Application: component.application},
+					Status:     appstudiov1alpha1.ComponentStatus{GitOps: appstudiov1alpha1.GitOpsStatus{RepositoryURL: component.gitOpsURL}},
+				})
+			}
+
+			fakeClient := NewFakeClient(t, objs...)
+			r := &ApplicationReconciler{
+				Client:                  fakeClient,
+				GitHubOrg:               github.AppStudioAppDataOrg,
+				GitOpsRepoNaming:        tt.naming,
+				GitOpsRepoMigrationOrgs: tt.migrationOrgs,
+			}
+			ghClient := &github.GitHubClient{TokenName: "mock", Client: github.GetMockedClient()}
+
+			err = r.migrateGitOpsRepository(context.Background(), ctrl.Request{NamespacedName: appLookupKey}, application, ghClient)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error value %v", err)
+			}
+
+			updatedApplication := appstudiov1alpha1.Application{}
+			if err := fakeClient.Get(context.Background(), appLookupKey, &updatedApplication); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			gitOpsURL, err := getGitOpsRepositoryURL(&updatedApplication)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantGitOpsURL, gitOpsURL)
+			assert.Equal(t, updatedApplication.Status.Devfile, application.Status.Devfile)
+
+			condition := meta.FindStatusCondition(updatedApplication.Status.Conditions, gitOpsRepositoryMigratedConditionType)
+			if tt.wantNoCondition {
+				assert.Nil(t, condition)
+			} else if assert.NotNil(t, condition) {
+				assert.Contains(t, condition.Message, tt.wantMessage)
+				assert.Equal(t, tt.wantPhase == migrationPhaseCompleted, condition.Status == metav1.ConditionTrue)
+			}
+
+			var migrationStatus gitOpsRepositoryMigrationStatus
+			if _, err := getJSONAnnotation(&updatedApplication, GitOpsRepositoryMigrationStatusAnnotation, &migrationStatus); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantPhase, migrationStatus.Phase)
+			if tt.wantComponents == nil {
+				return
+			}
+			assert.Equal(t, tt.wantComponents, migrationStatus.Components)
+			assert.NotNil(t, migrationStatus.CompletedAt)
+
+			// Only the Components of the Application are moved to the migrated repository
+			for _, name := range []string{"backend", "frontend", "other-app"} {
+				var component appstudiov1alpha1.Component
+				if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: name, Namespace: appLookupKey.Namespace}, &component); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				wantURL := tt.wantGitOpsURL
+				if name == "other-app" {
+					wantURL = generatedURL
+				}
+				assert.Equal(t, wantURL, component.Status.GitOps.RepositoryURL)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/gomega"
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	//+kubebuilder:scaffold:imports
@@ -92,6 +93,23 @@ var _ = Describe("Application controller", func() {
 			Expect(string(devfile.GetMetadata().Attributes["gitOpsRepository.url"].Raw)).Should(Not(Equal("")))
 			Expect(string(devfile.GetMetadata().Attributes["appModelRepository.url"].Raw)).Should(Not(Equal("")))
 
+			// The devfile should be published to the app-model repository
+			Eventually(func() string {
+				k8sClient.Get(context.Background(), hasAppLookupKey, createdHasApp)
+				return createdHasApp.Annotations[AppModelPublicationAnnotation]
+			}, timeout, interval).Should(ContainSubstring("ca82a6dff817ec66f44342007202690a93763949"))
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), hasAppLookupKey, createdHasApp)
+				return meta.IsStatusConditionTrue(createdHasApp.Status.Conditions, appModelPublishedConditionType)
+			}, timeout, interval).Should(BeTrue())
+
+			// An Application without Components is ready
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), hasAppLookupKey, createdHasApp)
+				return meta.IsStatusConditionTrue(createdHasApp.Status.Conditions, applicationReadyConditionType)
+			}, timeout, interval).Should(BeTrue())
+			Expect(createdHasApp.Annotations[ApplicationHealthAnnotation]).Should(Equal(`{"components":{"total":0,"ready":0,"failed":0,"gitOpsGenerationFailed":0}}`))
+
 			// Delete the specified resource
 			deleteHASAppCR(hasAppLookupKey)
 		})
@@ -194,6 +212,57 @@ var _ = Describe("Application controller", func() {
 			Expect(string(devfile.GetMetadata().Attributes["appModelRepository.url"].Raw)).Should(Not(Equal("")))
 			Expect(string(devfile.GetMetadata().Attributes["appModelRepository.url"].Raw)).Should(ContainSubstring(hasApp.Spec.GitOpsRepository.URL))
 
+			// The gitops repository was validated and its default branch recorded
+			Expect(string(devfile.GetMetadata().Attributes["gitOpsRepository.branch"].Raw)).Should(ContainSubstring("main"))
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), hasAppLookupKey, createdHasApp)
+				return meta.IsStatusConditionTrue(createdHasApp.Status.Conditions, gitOpsRepositoryValidatedConditionType)
+			}, timeout, interval).Should(BeTrue())
+
+			// Delete the specified resource
+			deleteHASAppCR(hasAppLookupKey)
+		})
+	})
+
+	Context("Create Application with a gitops repository that can't be pushed to", func() {
+		It("Should not set the devfile and should set the validation condition", func() {
+			ctx := context.Background()
+
+			applicationName := HASAppName + "5"
+
+			hasApp := &appstudiov1alpha1.Application{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Application",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      applicationName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: DisplayName,
+					Description: Description,
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "https://github.com/testorg/test-repo-2",
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hasApp)).Should(Succeed())
+
+			hasAppLookupKey := types.NamespacedName{Name: applicationName, Namespace: HASAppNamespace}
+			createdHasApp := &appstudiov1alpha1.Application{}
+			var condition *metav1.Condition
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), hasAppLookupKey, createdHasApp)
+				condition = meta.FindStatusCondition(createdHasApp.Status.Conditions, gitOpsRepositoryValidatedConditionType)
+				return condition != nil
+			}, timeout, interval).Should(BeTrue())
+
+			Expect(condition.Status).Should(Equal(metav1.ConditionFalse))
+			Expect(condition.Message).Should(ContainSubstring("no write access to the repository https://github.com/testorg/test-repo-2"))
+			Expect(createdHasApp.Status.Devfile).Should(Equal(""))
+
 			// Delete the specified resource
 			deleteHASAppCR(hasAppLookupKey)
 		})
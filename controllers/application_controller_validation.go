@@ -0,0 +1,60 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/metrics"
+)
+
+// gitOpsRepositoryValidatedConditionType is the condition of an Application reporting the validation of its user-supplied GitOps repository
+const gitOpsRepositoryValidatedConditionType = "GitOpsRepositoryValidated"
+
+// validateGitOpsRepository checks that the user-supplied GitOps repository of the Application is reachable and can be pushed to,
+// and creates its branch and context directory if they are missing. The branch of the repository, the default branch if the
+// Application does not set one, and the message of the validation condition are returned. The token of the controller only has
+// access to GitHub, so the repositories hosted elsewhere are used as they are, without validation. The token of the controller can push
+// to every repository of the GitHub orgs of the controller, which only hold the repositories generated for other Applications, so they
+// are rejected
+func (r *ApplicationReconciler) validateGitOpsRepository(ctx context.Context, application *appstudiov1alpha1.Application, ghClient *github.GitHubClient) (string, string, error) {
+	gitOpsRepository := application.Spec.GitOpsRepository
+	if !github.IsGitHubRepository(gitOpsRepository.URL) {
+		return gitOpsRepository.Branch, fmt.Sprintf("The GitOps repository %s is not hosted on github.com, its access was not validated", gitOpsRepository.URL), nil
+	}
+	if orgName := r.getControllerOrg(gitOpsRepository.URL); orgName != "" {
+		return "", "", fmt.Errorf("the GitOps repository %s is in the %s GitHub org, which only holds generated repositories, and can't be supplied by Application %s", gitOpsRepository.URL, orgName, application.Name)
+	}
+
+	metricsLabel := prometheus.Labels{"controller": applicationName, "tokenName": ghClient.TokenName, "operation": "ValidateRepository"}
+	metrics.ControllerGitRequest.With(metricsLabel).Inc()
+	branch, changes, err := ghClient.ValidateRepository(ctx, gitOpsRepository.URL, gitOpsRepository.Branch, gitOpsRepository.Context)
+	metrics.HandleRateLimitMetrics(err, metricsLabel)
+	if err != nil {
+		return branch, "", err
+	}
+
+	message := fmt.Sprintf("The GitOps repository %s is reachable and its branch %s can be pushed to", gitOpsRepository.URL, branch)
+	if len(changes) > 0 {
+		message = fmt.Sprintf("%s, %s", message, strings.Join(changes, ", "))
+	}
+	return branch, message, nil
+}
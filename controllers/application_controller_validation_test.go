@@ -0,0 +1,143 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestReconcileUserSuppliedGitOpsRepository(t *testing.T) {
+	appLookupKey := types.NamespacedName{Name: "test-app", Namespace: "default"}
+
+	tests := []struct {
+		name             string
+		gitOpsRepository appstudiov1alpha1.ApplicationGitRepository
+		wantBranch       string
+		wantMessage      string
+		wantErr          bool
+	}{
+		{
+			name:             "Valid repository, the default branch is recorded in the devfile",
+			gitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{URL: "https://github.com/testorg/test-repo-1"},
+			wantBranch:       "main",
+			wantMessage:      "The GitOps repository https://github.com/testorg/test-repo-1 is reachable and its branch main can be pushed to",
+		},
+		{
+			name: "Missing branch and context directory are created",
+			gitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+				URL:     "https://github.com/testorg/test-repo-1",
+				Branch:  "missing-branch",
+				Context: "gitops/missing-context",
+			},
+			wantBranch:  "missing-branch",
+			wantMessage: "The GitOps repository https://github.com/testorg/test-repo-1 is reachable and its branch missing-branch can be pushed to, created branch missing-branch, created context directory gitops/missing-context",
+		},
+		{
+			name: "Repository not hosted on GitHub is used without validation",
+			gitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+				URL:    "https://gitlab.com/testorg/test-repo-1",
+				Branch: "main",
+			},
+			wantBranch:  "main",
+			wantMessage: "The GitOps repository https://gitlab.com/testorg/test-repo-1 is not hosted on github.com, its access was not validated",
+		},
+		{
+			name:             "No write access to the repository",
+			gitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{URL: "https://github.com/testorg/test-repo-2"},
+			wantErr:          true,
+			wantMessage:      "GitOps repository validation failed: no write access to the repository https://github.com/testorg/test-repo-2",
+		},
+		{
+			name:             "Repository of the controller org",
+			gitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{URL: "https://github.com/redhat-appstudio-appdata/test-repo-1"},
+			wantErr:          true,
+			wantMessage:      "GitOps repository validation failed: the GitOps repository https://github.com/redhat-appstudio-appdata/test-repo-1 is in the redhat-appstudio-appdata GitHub org",
+		},
+		{
+			name:             "Repository of the controller org with a differently cased owner",
+			gitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{URL: "https://github.com/Redhat-AppStudio-AppData/test-repo-1"},
+			wantErr:          true,
+			wantMessage:      "which only holds generated repositories, and can't be supplied by Application test-app",
+		},
+		{
+			name:             "Unreachable repository",
+			gitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{URL: "https://github.com/testorg/test-error-response"},
+			wantErr:          true,
+			wantMessage:      "GitOps repository validation failed: failed to get repo test-error-response under testorg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       appLookupKey.Name,
+					Namespace:  appLookupKey.Namespace,
+					Finalizers: []string{appFinalizerName},
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName:      "Test Application",
+					GitOpsRepository: tt.gitOpsRepository,
+				},
+			}
+			fakeClient := NewFakeClient(t, application)
+			r := &ApplicationReconciler{
+				Client:            fakeClient,
+				GitHubTokenClient: github.MockGitHubTokenClient{},
+				GitHubOrg:         github.AppStudioAppDataOrg,
+			}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: appLookupKey})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error value %v", err)
+			}
+
+			updatedApplication := appstudiov1alpha1.Application{}
+			if err := fakeClient.Get(context.Background(), appLookupKey, &updatedApplication); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			condition := meta.FindStatusCondition(updatedApplication.Status.Conditions, gitOpsRepositoryValidatedConditionType)
+			if assert.NotNil(t, condition) {
+				assert.Contains(t, condition.Message, tt.wantMessage)
+				assert.Equal(t, !tt.wantErr, condition.Status == metav1.ConditionTrue)
+			}
+			if tt.wantErr {
+				// Components don't use the repository until it is validated
+				assert.Empty(t, updatedApplication.Status.Devfile)
+				return
+			}
+
+			devfileData, err := devfile.ParseDevfile(devfile.DevfileSrc{Data: updatedApplication.Status.Devfile})
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			branch := devfileData.GetMetadata().Attributes.GetString("gitOpsRepository.branch", &err)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBranch, branch)
+		})
+	}
+}
@@ -24,14 +24,12 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redhat-appstudio/application-service/pkg/metrics"
-	gitopsgenv1alpha1 "github.com/redhat-developer/gitops-generator/api/v1alpha1"
 	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
 	"golang.org/x/exp/maps"
-	corev1 "k8s.io/api/core/v1"
 
-	devfileParser "github.com/devfile/library/v2/pkg/devfile/parser"
 	"github.com/go-logr/logr"
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
 	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
 	github "github.com/redhat-appstudio/application-service/pkg/github"
 	logutil "github.com/redhat-appstudio/application-service/pkg/log"
@@ -39,11 +37,14 @@ import (
 	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
 	"github.com/spf13/afero"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -54,11 +55,13 @@ import (
 // SnapshotEnvironmentBindingReconciler reconciles a SnapshotEnvironmentBinding object
 type SnapshotEnvironmentBindingReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	Log               logr.Logger
-	AppFS             afero.Afero
-	Generator         gitopsgen.Generator
-	GitHubTokenClient github.GitHubToken
+	Scheme             *runtime.Scheme
+	Log                logr.Logger
+	AppFS              afero.Afero
+	Generator          gitopsgen.Generator
+	GitHubTokenClient  github.GitHubToken
+	RepositoryDiffer   gitops.RepositoryDiffer
+	RepositoryReverter gitops.RepositoryReverter
 }
 
 const asebName = "SnapshotEnvironmentBinding"
@@ -68,6 +71,8 @@ const asebName = "SnapshotEnvironmentBinding"
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotenvironmentbindings/finalizers,verbs=update
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshots,verbs=get;list;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -106,18 +111,59 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 	// Add the Go-GitHub client name to the context
 	ctx = context.WithValue(ctx, github.GHClientKey, ghClient.TokenName)
 
+	// Check if the SnapshotEnvironmentBinding CR is under deletion
+	// If so: Remove the environment overlays from the GitOps repository and remove the finalizer.
+	if appSnapshotEnvBinding.ObjectMeta.DeletionTimestamp.IsZero() {
+		if !containsString(appSnapshotEnvBinding.GetFinalizers(), bindingFinalizerName) {
+			// Attach the finalizer and carry on, the update does not change the generation that the reconciler is triggered on
+			if err := r.AddFinalizer(ctx, &appSnapshotEnvBinding); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	} else {
+		if containsString(appSnapshotEnvBinding.GetFinalizers(), bindingFinalizerName) {
+			// A finalizer is present for the SnapshotEnvironmentBinding CR, so make sure we do the necessary cleanup steps
+			if err := r.Finalize(ctx, &appSnapshotEnvBinding, ghClient); err != nil {
+				finalizeCounter, err := getCounterAnnotation(finalizeCount, &appSnapshotEnvBinding)
+				if err == nil && finalizeCounter < 5 {
+					// The Finalize function failed, so increment the finalize count and requeue, since the count annotation does not trigger the reconciler
+					setCounterAnnotation(finalizeCount, &appSnapshotEnvBinding, finalizeCounter+1)
+					err := r.Update(ctx, &appSnapshotEnvBinding)
+					if err != nil {
+						log.Error(err, "Error incrementing finalizer count on resource")
+					}
+					return ctrl.Result{Requeue: true}, nil
+				} else {
+					// if fail to remove the overlays here, log the error, but don't return error
+					// Don't want to get stuck in a cycle of repeatedly trying to update the repository and failing
+					log.Error(err, fmt.Sprintf("Unable to remove the environment overlays of binding %v in namespace %v", appSnapshotEnvBinding.GetName(), appSnapshotEnvBinding.GetNamespace()))
+				}
+			}
+
+			// remove the finalizer from the list and update it.
+			controllerutil.RemoveFinalizer(&appSnapshotEnvBinding, bindingFinalizerName)
+			if err := r.Update(ctx, &appSnapshotEnvBinding); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
 	applicationName := appSnapshotEnvBinding.Spec.Application
 	environmentName := appSnapshotEnvBinding.Spec.Environment
 	snapshotName := appSnapshotEnvBinding.Spec.Snapshot
 	components := appSnapshotEnvBinding.Spec.Components
 
 	// Check if the labels have been applied to the binding
+	// The labels are used to map the bound Environment, Snapshot and the Components of the Application to the binding, the snapshot
+	// label is updated when the binding is moved to another Snapshot
 	requiredLabels := map[string]string{
 		"appstudio.application": applicationName,
 		"appstudio.environment": environmentName,
+		"appstudio.snapshot":    snapshotName,
 	}
 	bindingLabels := appSnapshotEnvBinding.GetLabels()
-	if bindingLabels["appstudio.application"] == "" || bindingLabels["appstudio.environment"] == "" {
+	if bindingLabels["appstudio.application"] == "" || bindingLabels["appstudio.environment"] == "" || bindingLabels["appstudio.snapshot"] != snapshotName {
 		if bindingLabels != nil {
 			maps.Copy(bindingLabels, requiredLabels)
 		} else {
@@ -129,6 +175,20 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 		}
 	}
 
+	// A rollback pins the binding to an earlier Snapshot, which is rendered instead of the Snapshot of the spec, or to a commit of its
+	// deployment history, which the environment overlays of the components are reverted to
+	rollback, err := getRollback(&appSnapshotEnvBinding)
+	if err != nil {
+		log.Error(err, "")
+		r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+		return ctrl.Result{}, err
+	}
+	if rollback != nil && rollback.CommitID != "" {
+		return r.rollbackToCommit(ctx, req, &appSnapshotEnvBinding, ghClient, rollback.CommitID)
+	} else if rollback != nil {
+		snapshotName = rollback.Snapshot
+	}
+
 	// Get the Environment CR
 	environment := appstudiov1alpha1.Environment{}
 	err = r.Get(ctx, types.NamespacedName{Name: environmentName, Namespace: appSnapshotEnvBinding.Namespace}, &environment)
@@ -154,7 +214,85 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 		return ctrl.Result{}, err
 	}
 
+	autoscalingOverrides, err := getAutoscalingOverrides(&appSnapshotEnvBinding)
+	if err != nil {
+		log.Error(err, "")
+		r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+		return ctrl.Result{}, err
+	}
+
+	availabilityOverrides, err := getAvailabilityOverrides(&appSnapshotEnvBinding)
+	if err != nil {
+		log.Error(err, "")
+		r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+		return ctrl.Result{}, err
+	}
+
+	environmentWorkload, err := getEnvironmentWorkload(&environment)
+	if err != nil {
+		log.Error(err, "")
+		r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+		return ctrl.Result{}, err
+	}
+
+	environmentSecrets, err := getEnvironmentSecrets(&environment)
+	if err != nil {
+		log.Error(err, "")
+		r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+		return ctrl.Result{}, err
+	}
+
+	secretOverrides, err := getSecretOverrides(&appSnapshotEnvBinding)
+	if err != nil {
+		log.Error(err, "")
+		r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+		return ctrl.Result{}, err
+	}
+
+	// Get the Application CR to find the format of the GitOps resources, bindings of an Application that does not exist yet use kustomize
+	gitOpsFormat := gitops.KustomizeFormat
+	application := appstudiov1alpha1.Application{}
+	err = r.Get(ctx, types.NamespacedName{Name: applicationName, Namespace: appSnapshotEnvBinding.Namespace}, &application)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, fmt.Sprintf("unable to get the Application %s %v", applicationName, req.NamespacedName))
+		r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+		return ctrl.Result{}, err
+	} else if err == nil {
+		gitOpsFormat, err = getGitOpsFormat(&application)
+		if err != nil {
+			log.Error(err, "")
+			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The NetworkPolicies of the Components admit the traffic of the siblings that declare them as a dependency,
+	// and select the pods of the Application with their part-of label
+	var networkPolicyDependents map[string][]string
+	var podTemplateLabels map[string]string
+	if gitOpsFormat == gitops.KustomizeFormat {
+		var hasNetworkPolicy bool
+		networkPolicyDependents, hasNetworkPolicy, err = r.getNetworkPolicyDependents(ctx, appSnapshotEnvBinding.Namespace, components)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("unable to get the network policy dependencies of the components %v", req.NamespacedName))
+			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+			return ctrl.Result{}, err
+		}
+		if hasNetworkPolicy {
+			podTemplateLabels = map[string]string{devfile.PartOfLabel: applicationName}
+		}
+	}
+
+	dryRun := isDryRun(&appSnapshotEnvBinding)
+
+	// The overlays of the components removed from the binding are removed in the commit of the first component that is still bound
+	removedComponents := getRemovedComponents(&appSnapshotEnvBinding)
+	prunedComponents := make(map[string]bool)
+
 	componentGeneratedResources := make(map[string][]string)
+	deployedComponents := make(map[string]DeployedComponent)
+	var lastCommitID string
+	syncTime := metav1.Now()
 	var tempDir string
 	clone := true
 
@@ -182,71 +320,21 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 			return ctrl.Result{}, err
 		}
 
-		var clusterIngressDomain string
-		isKubernetesCluster := isKubernetesCluster(environment)
-		unsupportedConfig := environment.Spec.UnstableConfigurationFields
-		if unsupportedConfig != nil {
-			clusterIngressDomain = unsupportedConfig.IngressDomain
-		}
-
-		// Safeguard if Ingress Domain is empty on Kubernetes
-		if isKubernetesCluster && clusterIngressDomain == "" {
-			err = fmt.Errorf("ingress domain cannot be empty on a Kubernetes cluster")
-			log.Error(err, "unable to create an ingress resource on a Kubernetes cluster")
-			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
-			return ctrl.Result{}, err
-		}
-
-		devfileSrc := devfile.DevfileSrc{
-			Data: hasComponent.Status.Devfile,
-		}
-		compDevfileData, err := devfile.ParseDevfile(devfileSrc)
-		if err != nil {
-			errMsg := fmt.Sprintf("Unable to parse the devfile from Component status, exiting reconcile loop %v", req.NamespacedName)
-			log.Error(err, errMsg)
-			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, fmt.Errorf("%v: %v", errMsg, err))
-			return ctrl.Result{}, err
-		}
-
-		deployAssociatedComponents, err := devfileParser.GetDeployComponents(compDevfileData)
-		if err != nil {
-			log.Error(err, "unable to get deploy components")
-			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
-			return ctrl.Result{}, err
-		}
-
-		var hostname string
-		if isKubernetesCluster {
-			hostname, err = devfile.GetIngressHostName(hasComponent.Name, appSnapshotEnvBinding.Namespace, clusterIngressDomain)
-			if err != nil {
-				log.Error(err, fmt.Sprintf("unable to get generate a host name from an ingress domain for %s %v", hasComponent.Name, req.NamespacedName))
-				r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
-				return ctrl.Result{}, err
-			}
-		}
-
-		kubernetesResources, err := devfile.GetResourceFromDevfile(log, compDevfileData, deployAssociatedComponents, hasComponent.Name, hasComponent.Spec.Application, hasComponent.Spec.ContainerImage, hostname)
+		overlay, err := newEnvironmentOverlay(log, &hasComponent, component, environment, environmentWorkload, appSnapshot)
 		if err != nil {
-			log.Error(err, "unable to get kubernetes resources from the devfile outerloop components")
 			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
 			return ctrl.Result{}, err
 		}
-
-		var imageName string
-
-		for _, snapshotComponent := range appSnapshot.Spec.Components {
-			if snapshotComponent.Name == componentName {
-				imageName = snapshotComponent.ContainerImage
-				break
-			}
+		// The secret references of the binding component are merged on top of the ones of the environment
+		overlay.secrets = environmentSecrets.Merge(secretOverrides[componentName])
+		if override, ok := autoscalingOverrides[componentName]; ok {
+			overlay.autoscalingOverride = &override
 		}
-
-		if imageName == "" {
-			err := fmt.Errorf("application snapshot %s did not reference component %s", snapshotName, componentName)
-			log.Error(err, "")
-			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
-			return ctrl.Result{}, err
+		if override, ok := availabilityOverrides[componentName]; ok {
+			overlay.availabilityOverride = &override
 		}
+		overlay.networkPolicyDependents = networkPolicyDependents[componentName]
+		overlay.podTemplateLabels = podTemplateLabels
 
 		gitOpsRemoteURL, gitOpsBranch, gitOpsContext, err := util.ProcessGitOpsStatus(hasComponent.Status.GitOps, ghClient.Token)
 		if err != nil {
@@ -254,14 +342,6 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 			return ctrl.Result{}, err
 		}
 
-		isStatusUpdated := false
-		for _, bindingStatusComponent := range appSnapshotEnvBinding.Status.Components {
-			if bindingStatusComponent.Name == componentName {
-				isStatusUpdated = true
-				break
-			}
-		}
-
 		if clone {
 			// Create a temp folder to create the gitops resources in
 			tempDir, err = ioutils.CreateTempPath(appSnapshotEnvBinding.Name, r.AppFS)
@@ -272,61 +352,71 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 			}
 		}
 
-		envVars := make([]corev1.EnvVar, 0)
-		for _, env := range component.Configuration.Env {
-			envVars = append(envVars, corev1.EnvVar{
-				Name:  env.Name,
-				Value: env.Value,
-			})
-		}
+		if gitOpsFormat == gitops.HelmFormat {
+			// The component Helm chart is generated by the component controller, the environment only needs a values file
+			if clone {
+				metrics.ControllerGitRequest.With(prometheus.Labels{"controller": asebName, "tokenName": ghClient.TokenName, "operation": "CloneRepo"}).Inc()
+				err = r.Generator.CloneRepo(tempDir, gitOpsRemoteURL, applicationName, gitOpsBranch)
+				if err != nil {
+					log.Error(err, fmt.Sprintf("unable to clone the gitops repository for %s %v", componentName, req.NamespacedName))
+					_ = r.AppFS.RemoveAll(tempDir)
+					r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+					return ctrl.Result{}, err
+				}
+			}
 
-		environmentConfigEnvVars := make([]corev1.EnvVar, 0)
-		for _, env := range environment.Spec.Configuration.Env {
-			environmentConfigEnvVars = append(environmentConfigEnvVars, corev1.EnvVar{
-				Name:  env.Name,
-				Value: env.Value,
-			})
-		}
-		componentResources := corev1.ResourceRequirements{}
-		if component.Configuration.Resources != nil {
-			componentResources = *component.Configuration.Resources
-		}
+			helmValues, err := getHelmEnvironmentValues(log, overlay)
+			if err != nil {
+				_ = r.AppFS.RemoveAll(tempDir)
+				r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+				return ctrl.Result{}, err
+			}
 
-		kubeLabels := map[string]string{
-			"app.kubernetes.io/name":       componentName,
-			"app.kubernetes.io/instance":   component.Name,
-			"app.kubernetes.io/part-of":    applicationName,
-			"app.kubernetes.io/managed-by": "kustomize",
-			"app.kubernetes.io/created-by": "application-service",
-		}
-		genOptions := gitopsgenv1alpha1.GeneratorOptions{
-			Name:                component.Name,
-			Replicas:            component.Configuration.Replicas,
-			Resources:           componentResources,
-			BaseEnvVar:          envVars,
-			OverlayEnvVar:       environmentConfigEnvVars,
-			K8sLabels:           kubeLabels,
-			IsKubernetesCluster: isKubernetesCluster,
-			TargetPort:          hasComponent.Spec.TargetPort, // pass the target port to the gitops gen library as they may generate a route/ingress based on the target port if the devfile does not have an ingress/route or an endpoint
+			chartPath := gitops.GetHelmChartPath(filepath.Join(tempDir, applicationName, gitOpsContext), componentName)
+			valuesFile, err := gitops.GenerateHelmEnvironmentValues(r.AppFS, chartPath, environmentName, helmValues)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("unable to generate the Helm values for %s %v", componentName, req.NamespacedName))
+				_ = r.AppFS.RemoveAll(tempDir)
+				r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+				return ctrl.Result{}, err
+			}
+			componentGeneratedResources[componentName] = []string{valuesFile}
+		} else {
+			metrics.ControllerGitRequest.With(prometheus.Labels{"controller": asebName, "tokenName": ghClient.TokenName, "operation": "GenerateOverlaysAndPush"}).Inc()
+			err = generateKustomizeOverlay(log, r.AppFS, r.Generator, filepath.Join(tempDir, applicationName), clone, gitOpsRemoteURL, gitOpsBranch, gitOpsContext, environmentName, overlay, componentGeneratedResources)
+			if err != nil {
+				_ = r.AppFS.RemoveAll(tempDir) // not worried with an err, its a best case attempt to delete the temp clone dir
+				r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+				return ctrl.Result{}, err
+			}
 		}
 
-		if !reflect.DeepEqual(kubernetesResources, devfileParser.KubernetesResources{}) {
-			genOptions.KubernetesResources.Routes = append(genOptions.KubernetesResources.Routes, kubernetesResources.Routes...)
-			genOptions.KubernetesResources.Ingresses = append(genOptions.KubernetesResources.Ingresses, kubernetesResources.Ingresses...)
+		if clone {
+			for _, removedComponent := range removedComponents {
+				if removedComponent.GitOpsRepository.URL != hasComponent.Status.GitOps.RepositoryURL || removedComponent.GitOpsRepository.Branch != gitOpsBranch {
+					continue
+				}
+				if err := removeComponentOverlay(r.AppFS, filepath.Join(tempDir, applicationName), removedComponent, environmentName); err != nil {
+					log.Error(err, fmt.Sprintf("unable to remove the overlay of the removed component %s %v", removedComponent.Name, req.NamespacedName))
+					_ = r.AppFS.RemoveAll(tempDir)
+					r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+					return ctrl.Result{}, err
+				}
+				prunedComponents[removedComponent.Name] = true
+			}
 		}
 
-		if isKubernetesCluster && len(genOptions.KubernetesResources.Ingresses) == 0 {
-			// provide the hostname for the component if there are no ingresses
-			// Gitops Generator Library will create the Ingress with the hostname
-			genOptions.Route = hostname
+		// In dry-run mode the overlays of every component are generated in the clone, and compared to the branch head once they are all generated
+		if dryRun {
+			clone = false
+			continue
 		}
 
-		//Gitops functions return sanitized error messages
-		metrics.ControllerGitRequest.With(prometheus.Labels{"controller": asebName, "tokenName": ghClient.TokenName, "operation": "GenerateOverlaysAndPush"}).Inc()
-		err = r.Generator.GenerateOverlaysAndPush(tempDir, clone, gitOpsRemoteURL, genOptions, applicationName, environmentName, imageName, "", r.AppFS, gitOpsBranch, gitOpsContext, true, componentGeneratedResources)
+		metrics.ControllerGitRequest.With(prometheus.Labels{"controller": asebName, "tokenName": ghClient.TokenName, "operation": "CommitAndPush"}).Inc()
+		err = r.Generator.CommitAndPush(tempDir, applicationName, gitOpsRemoteURL, componentName, gitOpsBranch, fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName))
 		if err != nil {
-			log.Error(err, fmt.Sprintf("unable to get generate gitops resources for %s %v", componentName, req.NamespacedName))
-			_ = r.AppFS.RemoveAll(tempDir) // not worried with an err, its a best case attempt to delete the temp clone dir
+			log.Error(err, fmt.Sprintf("unable to commit and push gitops resources for %s %v", componentName, req.NamespacedName))
+			_ = r.AppFS.RemoveAll(tempDir)
 			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
 			return ctrl.Result{}, err
 		}
@@ -343,34 +433,68 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 			return ctrl.Result{}, err
 		}
 
-		if !isStatusUpdated {
-			componentStatus := appstudiov1alpha1.BindingComponentStatus{
-				Name: componentName,
-				GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{
-					URL:      hasComponent.Status.GitOps.RepositoryURL,
-					Branch:   gitOpsBranch,
-					Path:     filepath.Join(gitOpsContext, "components", componentName, "overlays", environmentName),
-					CommitID: commitID,
-				},
-			}
-
-			if _, ok := componentGeneratedResources[componentName]; ok {
-				componentStatus.GitOpsRepository.GeneratedResources = componentGeneratedResources[componentName]
-			}
-
-			appSnapshotEnvBinding.Status.Components = append(appSnapshotEnvBinding.Status.Components, componentStatus)
+		// The status entry of the component is updated on every sync, to point to the latest commit pushed for the component.
+		// A Helm chart is deployed with the values file of the environment, which is recorded in the generated resources
+		bindingPath := filepath.Join(gitOpsContext, "components", componentName, "overlays", environmentName)
+		if gitOpsFormat == gitops.HelmFormat {
+			bindingPath = gitops.GetHelmChartPath(gitOpsContext, componentName)
+		}
+		componentStatus := appstudiov1alpha1.BindingComponentStatus{
+			Name: componentName,
+			GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{
+				URL:                hasComponent.Status.GitOps.RepositoryURL,
+				Branch:             gitOpsBranch,
+				Path:               bindingPath,
+				GeneratedResources: componentGeneratedResources[componentName],
+				CommitID:           commitID,
+			},
+		}
+		appSnapshotEnvBinding.Status.Components = setComponentStatus(appSnapshotEnvBinding.Status.Components, componentStatus)
+		lastCommitID = commitID
+		deployedComponents[componentName] = DeployedComponent{
+			Snapshot:  snapshotName,
+			Image:     overlay.imageName,
+			CommitID:  commitID,
+			Timestamp: syncTime,
 		}
 
 		// Set the clone to false, since we dont want to clone the repo again for the other components
 		clone = false
 	}
 
+	if dryRun {
+		return r.reportDryRun(ctx, req, &appSnapshotEnvBinding, tempDir, applicationName)
+	}
+
 	// Remove the cloned path
 	err = r.AppFS.RemoveAll(tempDir)
 	if err != nil {
 		log.Error(err, "Unable to remove the clone dir")
 	}
 
+	// Remove the overlays of the removed components that are in another repository than the bound components, or that are not
+	// bound to any component anymore, and drop their status entries
+	if len(removedComponents) > 0 {
+		var unprunedComponents []appstudiov1alpha1.BindingComponentStatus
+		for _, removedComponent := range removedComponents {
+			if !prunedComponents[removedComponent.Name] {
+				unprunedComponents = append(unprunedComponents, removedComponent)
+			}
+		}
+		if err := r.pruneRemovedComponents(ghClient, &appSnapshotEnvBinding, unprunedComponents); err != nil {
+			log.Error(err, fmt.Sprintf("unable to remove the overlays of the removed components %v", req.NamespacedName))
+			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+			return ctrl.Result{}, err
+		}
+		appSnapshotEnvBinding.Status.Components = pruneComponentStatus(appSnapshotEnvBinding.Status.Components, removedComponents)
+	}
+
+	if rollback != nil {
+		setRollbackCondition(&appSnapshotEnvBinding, fmt.Sprintf("Rolled back to the Snapshot %s", snapshotName))
+	} else {
+		meta.RemoveStatusCondition(&appSnapshotEnvBinding.Status.GitOpsRepoConditions, rolledBackConditionType)
+	}
+
 	// Update the binding status to reflect the GitOps data
 	err = r.Client.Status().Update(ctx, &appSnapshotEnvBinding)
 	if err != nil {
@@ -379,6 +503,18 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 		return ctrl.Result{}, err
 	}
 
+	// Record the Snapshot and the images that were rendered for the synced components, and the last commit in the deployment history
+	var historyEntry *DeploymentHistoryEntry
+	if lastCommitID != "" {
+		historyEntry = &DeploymentHistoryEntry{Snapshot: snapshotName, CommitID: lastCommitID, Timestamp: syncTime}
+	}
+	err = r.updateDeployedState(ctx, &appSnapshotEnvBinding, deployedComponents, removedComponents, historyEntry)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to record the deployed state of %v", req.NamespacedName))
+		r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+		return ctrl.Result{}, err
+	}
+
 	r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, nil)
 
 	log.Info(fmt.Sprintf("Finished reconcile loop for %v", req.NamespacedName))
@@ -403,7 +539,7 @@ func isKubernetesCluster(environment appstudiov1alpha1.Environment) bool {
 func (r *SnapshotEnvironmentBindingReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	log := ctrl.LoggerFrom(ctx).WithName("controllers").WithName("Environment")
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&appstudiov1alpha1.SnapshotEnvironmentBinding{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&appstudiov1alpha1.SnapshotEnvironmentBinding{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, annotationsChangedPredicate(AutoscalingOverridesAnnotation, AvailabilityOverridesAnnotation, DryRunAnnotation, RollbackAnnotation, SecretOverridesAnnotation)))).
 		// Watch for Environment CR updates and reconcile all the Bindings that reference the Environment
 		Watches(&source.Kind{Type: &appstudiov1alpha1.Environment{}},
 			handler.EnqueueRequestsFromMapFunc(MapToBindingByBoundObjectName(r.Client, "Environment", "appstudio.environment")), builder.WithPredicates(predicate.Funcs{
@@ -425,6 +561,57 @@ func (r *SnapshotEnvironmentBindingReconciler) SetupWithManager(ctx context.Cont
 				GenericFunc: func(e event.GenericEvent) bool {
 					return false
 				},
+			})).
+		// Watch for Snapshot CR updates and reconcile all the Bindings that reference the Snapshot
+		Watches(&source.Kind{Type: &appstudiov1alpha1.Snapshot{}},
+			handler.EnqueueRequestsFromMapFunc(MapToBindingByBoundObjectName(r.Client, "Snapshot", "appstudio.snapshot")), builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(e event.CreateEvent) bool {
+					return false
+				},
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					if e.ObjectOld.GetGeneration() == e.ObjectNew.GetGeneration() {
+						return false
+					}
+					log := log.WithValues("namespace", e.ObjectNew.GetNamespace())
+					logutil.LogAPIResourceChangeEvent(log, e.ObjectNew.GetName(), "Snapshot", logutil.ResourceUpdate, nil)
+					return true
+				},
+				DeleteFunc: func(e event.DeleteEvent) bool {
+					return false
+				},
+				GenericFunc: func(e event.GenericEvent) bool {
+					return false
+				},
+			})).
+		// Watch for Component CR updates and reconcile all the Bindings that bind the Component. The devfile and the GitOps repository
+		// of the Component are part of its status, which the Component controller updates without changing the generation
+		Watches(&source.Kind{Type: &appstudiov1alpha1.Component{}},
+			handler.EnqueueRequestsFromMapFunc(MapComponentToBindings(r.Client)), builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(e event.CreateEvent) bool {
+					return false
+				},
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					oldComponent, ok := e.ObjectOld.(*appstudiov1alpha1.Component)
+					if !ok {
+						return false
+					}
+					newComponent, ok := e.ObjectNew.(*appstudiov1alpha1.Component)
+					if !ok {
+						return false
+					}
+					if oldComponent.Generation == newComponent.Generation && oldComponent.Status.Devfile == newComponent.Status.Devfile && reflect.DeepEqual(oldComponent.Status.GitOps, newComponent.Status.GitOps) {
+						return false
+					}
+					log := log.WithValues("namespace", e.ObjectNew.GetNamespace())
+					logutil.LogAPIResourceChangeEvent(log, e.ObjectNew.GetName(), "Component", logutil.ResourceUpdate, nil)
+					return true
+				},
+				DeleteFunc: func(e event.DeleteEvent) bool {
+					return false
+				},
+				GenericFunc: func(e event.GenericEvent) bool {
+					return false
+				},
 			})).WithEventFilter(predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
 			log := log.WithValues("namespace", e.Object.GetNamespace())
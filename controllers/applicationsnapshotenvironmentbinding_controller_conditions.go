@@ -64,3 +64,27 @@ func (r *SnapshotEnvironmentBindingReconciler) SetConditionAndUpdateCR(ctx conte
 
 	}
 }
+
+// SetDryRunConditionAndUpdateCR sets the dry-run condition of the binding with the summary of the diff that a sync would push
+func (r *SnapshotEnvironmentBindingReconciler) SetDryRunConditionAndUpdateCR(ctx context.Context, req ctrl.Request, appSnapshotEnvBinding *appstudiov1alpha1.SnapshotEnvironmentBinding, message string) {
+	log := r.Log.WithValues("namespace", req.NamespacedName.Namespace)
+
+	var currentSEB appstudiov1alpha1.SnapshotEnvironmentBinding
+	err := r.Get(ctx, req.NamespacedName, &currentSEB)
+	if err != nil {
+		return
+	}
+
+	patch := client.MergeFrom(currentSEB.DeepCopy())
+	meta.SetStatusCondition(&currentSEB.Status.GitOpsRepoConditions, metav1.Condition{
+		Type:    "GitOpsResourcesDryRun",
+		Status:  metav1.ConditionTrue,
+		Reason:  "DryRun",
+		Message: message,
+	})
+
+	err = r.Client.Status().Patch(ctx, &currentSEB, patch)
+	if err != nil {
+		log.Error(err, "Unable to update application snapshot environment binding")
+	}
+}
@@ -0,0 +1,132 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// maxDryRunDiffSize bounds the size of the diff written in the dry-run ConfigMap, well below the 1MiB limit of a ConfigMap
+	maxDryRunDiffSize = 256 * 1024
+
+	// maxDryRunFiles bounds the number of changed files listed in the dry-run ConfigMap
+	maxDryRunFiles = 500
+)
+
+// isDryRun returns true if the dry-run annotation is set on the binding
+func isDryRun(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) bool {
+	dryRun, _ := strconv.ParseBool(binding.GetAnnotations()[DryRunAnnotation])
+	return dryRun
+}
+
+// getDryRunConfigMapName returns the name of the ConfigMap that holds the dry-run diff of the binding
+func getDryRunConfigMapName(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) string {
+	return binding.Name + "-dry-run"
+}
+
+// reportDryRun computes the diff of the GitOps repository cloned in tempDir against the head of its branch, writes it in the dry-run
+// ConfigMap of the binding and sets the dry-run condition with its summary. The clone is removed and nothing is pushed
+func (r *SnapshotEnvironmentBindingReconciler) reportDryRun(ctx context.Context, req ctrl.Request, binding *appstudiov1alpha1.SnapshotEnvironmentBinding, tempDir string, applicationName string) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// No repository is cloned if every component skips the GitOps resource generation
+	var repositoryDiff gitops.RepositoryDiff
+	if tempDir != "" {
+		repositoryDiffer := r.RepositoryDiffer
+		if repositoryDiffer == nil {
+			repositoryDiffer = gitops.GitRepositoryDiffer{}
+		}
+		var err error
+		repositoryDiff, err = repositoryDiffer.GetRepositoryDiff(r.AppFS, filepath.Join(tempDir, applicationName), maxDryRunDiffSize)
+		_ = r.AppFS.RemoveAll(tempDir) // not worried with an err, its a best case attempt to delete the temp clone dir
+		if err != nil {
+			log.Error(err, fmt.Sprintf("unable to compute the dry-run diff of %v", req.NamespacedName))
+			r.SetConditionAndUpdateCR(ctx, req, binding, err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	configMapName, err := r.writeDryRunConfigMap(ctx, binding, repositoryDiff)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to write the dry-run diff of %v", req.NamespacedName))
+		r.SetConditionAndUpdateCR(ctx, req, binding, err)
+		return ctrl.Result{}, err
+	}
+
+	r.SetDryRunConditionAndUpdateCR(ctx, req, binding, fmt.Sprintf("GitOps repository dry-run: %s, the diff is in the ConfigMap %s", repositoryDiff.Summary(), configMapName))
+
+	log.Info(fmt.Sprintf("Finished dry-run reconcile loop for %v", req.NamespacedName))
+	return ctrl.Result{}, nil
+}
+
+// writeDryRunConfigMap creates or updates the dry-run ConfigMap of the binding with the diff, and returns its name.
+// The ConfigMap is owned by the binding, so that it is deleted with the binding
+func (r *SnapshotEnvironmentBindingReconciler) writeDryRunConfigMap(ctx context.Context, binding *appstudiov1alpha1.SnapshotEnvironmentBinding, repositoryDiff gitops.RepositoryDiff) (string, error) {
+	files := repositoryDiff.Files
+	if len(files) > maxDryRunFiles {
+		files = append(files[:maxDryRunFiles:maxDryRunFiles], fmt.Sprintf("... and %d more file(s)", len(repositoryDiff.Files)-maxDryRunFiles))
+	}
+	data := map[string]string{
+		"summary":   repositoryDiff.Summary(),
+		"files":     strings.Join(files, "\n"),
+		"diff":      repositoryDiff.Diff,
+		"truncated": strconv.FormatBool(repositoryDiff.Truncated),
+	}
+
+	configMapName := getDryRunConfigMapName(binding)
+	configMap := corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: binding.Namespace}, &configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", err
+	} else if err != nil {
+		configMap = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      configMapName,
+				Namespace: binding.Namespace,
+				Labels: map[string]string{
+					"appstudio.application": binding.Spec.Application,
+					"appstudio.environment": binding.Spec.Environment,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: appstudiov1alpha1.GroupVersion.String(),
+						Kind:       "SnapshotEnvironmentBinding",
+						Name:       binding.Name,
+						UID:        binding.UID,
+					},
+				},
+			},
+			Data: data,
+		}
+		return configMapName, r.Create(ctx, &configMap)
+	}
+
+	configMap.Data = data
+	return configMapName, r.Update(ctx, &configMap)
+}
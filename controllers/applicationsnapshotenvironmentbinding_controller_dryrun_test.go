@@ -0,0 +1,188 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestIsDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{
+			name: "No dry-run annotation",
+		},
+		{
+			name:        "Dry-run enabled",
+			annotations: map[string]string{DryRunAnnotation: "true"},
+			want:        true,
+		},
+		{
+			name:        "Dry-run disabled",
+			annotations: map[string]string{DryRunAnnotation: "false"},
+		},
+		{
+			name:        "Invalid dry-run annotation",
+			annotations: map[string]string{DryRunAnnotation: "yes please"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &appstudiov1alpha1.SnapshotEnvironmentBinding{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			assert.Equal(t, tt.want, isDryRun(binding))
+		})
+	}
+}
+
+func TestReportDryRun(t *testing.T) {
+	bindingLookupKey := types.NamespacedName{Name: "test-binding", Namespace: "default"}
+	configMapLookupKey := types.NamespacedName{Name: "test-binding-dry-run", Namespace: "default"}
+
+	tests := []struct {
+		name           string
+		files          map[string]string
+		noClone        bool
+		existingDiff   bool
+		wantFiles      string
+		wantSummary    string
+		wantTruncated  string
+		wantDiffPrefix string
+	}{
+		{
+			name:           "Generated overlays are reported",
+			files:          map[string]string{"components/comp/overlays/staging/deployment-patch.yaml": "spec:\n  replicas: 2\n"},
+			wantFiles:      "A components/comp/overlays/staging/deployment-patch.yaml",
+			wantSummary:    "1 file(s) changed, 2 insertion(s)(+), 0 deletion(s)(-)",
+			wantTruncated:  "false",
+			wantDiffPrefix: "diff --git a/components/comp/overlays/staging/deployment-patch.yaml",
+		},
+		{
+			name:          "Diff larger than the maximum size is truncated",
+			files:         map[string]string{"components/comp/overlays/staging/deployment-patch.yaml": strings.Repeat("# padding\n", maxDryRunDiffSize/10+1)},
+			wantFiles:     "A components/comp/overlays/staging/deployment-patch.yaml",
+			wantSummary:   fmt.Sprintf("1 file(s) changed, %d insertion(s)(+), 0 deletion(s)(-)", maxDryRunDiffSize/10+1),
+			wantTruncated: "true",
+		},
+		{
+			name:          "Existing dry-run ConfigMap is updated",
+			files:         map[string]string{"components/comp/overlays/staging/deployment-patch.yaml": "spec:\n  replicas: 2\n"},
+			existingDiff:  true,
+			wantFiles:     "A components/comp/overlays/staging/deployment-patch.yaml",
+			wantSummary:   "1 file(s) changed, 2 insertion(s)(+), 0 deletion(s)(-)",
+			wantTruncated: "false",
+		},
+		{
+			name:          "No repository cloned",
+			noClone:       true,
+			wantSummary:   "no changes",
+			wantTruncated: "false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &appstudiov1alpha1.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        bindingLookupKey.Name,
+					Namespace:   bindingLookupKey.Namespace,
+					Annotations: map[string]string{DryRunAnnotation: "true"},
+				},
+				Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+					Application: "test-app",
+					Environment: "staging",
+				},
+			}
+			objs := []runtime.Object{binding}
+			if tt.existingDiff {
+				objs = append(objs, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: configMapLookupKey.Name, Namespace: configMapLookupKey.Namespace},
+					Data:       map[string]string{"summary": "no changes"},
+				})
+			}
+
+			fs := ioutils.NewMemoryFilesystem()
+			fakeClient := NewFakeClient(t, objs...)
+			r := &SnapshotEnvironmentBindingReconciler{
+				Client:           fakeClient,
+				Log:              ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
+				AppFS:            fs,
+				RepositoryDiffer: gitops.MockRepositoryDiffer{},
+			}
+
+			var tempDir string
+			if !tt.noClone {
+				var err error
+				tempDir, err = ioutils.CreateTempPath(binding.Name, fs)
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				for path, content := range tt.files {
+					assert.NoError(t, fs.WriteFile(filepath.Join(tempDir, "test-app", path), []byte(content), 0600))
+				}
+			}
+
+			_, err := r.reportDryRun(context.Background(), ctrl.Request{NamespacedName: bindingLookupKey}, binding, tempDir, "test-app")
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			configMap := &corev1.ConfigMap{}
+			if err := fakeClient.Get(context.Background(), configMapLookupKey, configMap); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantSummary, configMap.Data["summary"])
+			assert.Equal(t, tt.wantFiles, configMap.Data["files"])
+			assert.Equal(t, tt.wantTruncated, configMap.Data["truncated"])
+			assert.LessOrEqual(t, len(configMap.Data["diff"]), maxDryRunDiffSize)
+			assert.True(t, strings.HasPrefix(configMap.Data["diff"], tt.wantDiffPrefix))
+
+			updatedBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{}
+			if err := fakeClient.Get(context.Background(), bindingLookupKey, updatedBinding); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			condition := meta.FindStatusCondition(updatedBinding.Status.GitOpsRepoConditions, "GitOpsResourcesDryRun")
+			if assert.NotNil(t, condition) {
+				assert.Equal(t, fmt.Sprintf("GitOps repository dry-run: %s, the diff is in the ConfigMap %s", tt.wantSummary, configMapLookupKey.Name), condition.Message)
+			}
+
+			// the clone is removed
+			if tempDir != "" {
+				exists, _ := fs.Exists(tempDir)
+				assert.False(t, exists, "the temporary clone should be removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const bindingFinalizerName = "snapshotenvironmentbinding.appstudio.redhat.com/finalizer"
+
+// AddFinalizer adds the finalizer to the SnapshotEnvironmentBinding CR and initiates the finalize count on the annotation
+func (r *SnapshotEnvironmentBindingReconciler) AddFinalizer(ctx context.Context, binding *appstudiov1alpha1.SnapshotEnvironmentBinding) error {
+	controllerutil.AddFinalizer(binding, bindingFinalizerName)
+
+	// Initialize the finalizer counter
+	bindingAnnotations := binding.ObjectMeta.GetAnnotations()
+	if bindingAnnotations == nil {
+		bindingAnnotations = make(map[string]string)
+	}
+	bindingAnnotations[finalizeCount] = "0"
+	binding.SetAnnotations(bindingAnnotations)
+	return r.Update(ctx, binding)
+}
+
+// Finalize removes the environment overlays generated for the given SnapshotEnvironmentBinding CR from the GitOps repositories of its components,
+// at the paths recorded in the binding status, and pushes the removal
+func (r *SnapshotEnvironmentBindingReconciler) Finalize(ctx context.Context, binding *appstudiov1alpha1.SnapshotEnvironmentBinding, ghClient *github.GitHubClient) error {
+	if len(binding.Status.Components) == 0 {
+		return nil
+	}
+	return r.pruneRemovedComponents(ghClient, binding, binding.Status.Components)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Test that the binding finalizer removes the environment overlays, and that the "finalize counter" lets a binding whose overlays
+// cannot be removed be deleted after 5 failed attempts
+func TestSnapshotEnvironmentBindingFinalizer(t *testing.T) {
+	bindingLookupKey := types.NamespacedName{Name: "test-binding", Namespace: "default"}
+
+	tests := []struct {
+		name          string
+		bindingPath   string
+		wantAttempts  int
+		wantFinalized bool
+	}{
+		{
+			name:         "Overlays removed on the first attempt",
+			bindingPath:  "components/component-a/overlays/staging",
+			wantAttempts: 1,
+		},
+		{
+			name:         "Finalizer removed after 5 failed attempts",
+			bindingPath:  "/",
+			wantAttempts: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := metav1.Now()
+			binding := &appstudiov1alpha1.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              bindingLookupKey.Name,
+					Namespace:         bindingLookupKey.Namespace,
+					Finalizers:        []string{bindingFinalizerName},
+					Annotations:       map[string]string{finalizeCount: "0"},
+					DeletionTimestamp: &now,
+				},
+				Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+					Application: "test-app",
+					Environment: "staging",
+				},
+				Status: appstudiov1alpha1.SnapshotEnvironmentBindingStatus{
+					Components: []appstudiov1alpha1.BindingComponentStatus{
+						{
+							Name: "component-a",
+							GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{
+								URL:    "https://github.com/org/repo",
+								Branch: "main",
+								Path:   tt.bindingPath,
+							},
+						},
+					},
+				},
+			}
+
+			fakeClient := NewFakeClient(t, binding)
+			r := &SnapshotEnvironmentBindingReconciler{
+				Client:            fakeClient,
+				Log:               ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
+				AppFS:             ioutils.NewMemoryFilesystem(),
+				Generator:         gitops.NewMockGenerator(),
+				GitHubTokenClient: github.MockGitHubTokenClient{},
+			}
+
+			for attempt := 1; attempt <= tt.wantAttempts; attempt++ {
+				_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: bindingLookupKey})
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+
+				finalizedBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{}
+				err = fakeClient.Get(context.Background(), bindingLookupKey, finalizedBinding)
+				isFinalized := err != nil || !containsString(finalizedBinding.GetFinalizers(), bindingFinalizerName)
+				assert.Equal(t, attempt == tt.wantAttempts, isFinalized, "unexpected finalizer state after attempt %d", attempt)
+			}
+		})
+	}
+}
+
+func TestSnapshotEnvironmentBindingAddFinalizer(t *testing.T) {
+	bindingLookupKey := types.NamespacedName{Name: "test-binding", Namespace: "default"}
+	binding := &appstudiov1alpha1.SnapshotEnvironmentBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bindingLookupKey.Name,
+			Namespace: bindingLookupKey.Namespace,
+		},
+		Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+			Application: "test-app",
+			Environment: "staging",
+		},
+	}
+
+	fakeClient := NewFakeClient(t, binding)
+	r := &SnapshotEnvironmentBindingReconciler{
+		Client:            fakeClient,
+		Log:               ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
+		AppFS:             ioutils.NewMemoryFilesystem(),
+		Generator:         gitops.NewMockGenerator(),
+		GitHubTokenClient: github.MockGitHubTokenClient{},
+	}
+
+	// the Environment does not exist, the finalizer is added before the reconcile fails
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: bindingLookupKey})
+	if err == nil {
+		t.Error("wanted error but got nil")
+	}
+
+	updatedBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{}
+	if err := fakeClient.Get(context.Background(), bindingLookupKey, updatedBinding); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Contains(t, updatedBinding.GetFinalizers(), bindingFinalizerName)
+	assert.Equal(t, "0", updatedBinding.GetAnnotations()[finalizeCount])
+}
@@ -0,0 +1,558 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	devfileAPIV1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	devfileParser "github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/spf13/afero"
+	"golang.org/x/exp/maps"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// hpaPatchFileName is the overlay patch overriding the HorizontalPodAutoscaler generated in the base
+	hpaPatchFileName = "hpa-patch.yaml"
+
+	// hpaFileName is the overlay resource for a HorizontalPodAutoscaler that is only declared for the environment
+	hpaFileName = "hpa.yaml"
+
+	// deploymentFileName is the base Deployment that the gitops generator library always writes
+	deploymentFileName = "deployment.yaml"
+
+	// deploymentPatchFileName is the overlay patch of the Deployment that the gitops generator library writes for every environment
+	deploymentPatchFileName = "deployment-patch.yaml"
+
+	// serviceFileName is the base Service that the gitops generator library writes when the component has a target port
+	serviceFileName = "service.yaml"
+
+	// knativeServiceFileName is the overlay resource for a Knative Service that replaces the base workload in an environment
+	knativeServiceFileName = "knative-service.yaml"
+
+	// deploymentDeletePatchFileName, serviceDeletePatchFileName and hpaDeletePatchFileName are the overlay patches
+	// removing the base resources that a Knative Service replaces
+	deploymentDeletePatchFileName = "deployment-delete-patch.yaml"
+	serviceDeletePatchFileName    = "service-delete-patch.yaml"
+	hpaDeletePatchFileName        = "hpa-delete-patch.yaml"
+
+	// pdbPatchFileName is the overlay patch overriding the PodDisruptionBudget generated in the base
+	pdbPatchFileName = "pdb-patch.yaml"
+
+	// pdbFileName is the overlay resource for a PodDisruptionBudget that is only declared for the environment
+	pdbFileName = "pdb.yaml"
+
+	// availabilityPatchFileName is the overlay patch setting the topology spread constraints and the anti-affinity of the workload
+	availabilityPatchFileName = "availability-patch.yaml"
+
+	// networkPolicyFileName is the overlay resource for the NetworkPolicy of a component
+	networkPolicyFileName = "networkpolicy.yaml"
+
+	// openShiftRouterNamespaceLabel and kubernetesRouterNamespace select the namespace of the default router or ingress controller
+	openShiftRouterNamespaceLabel = "network.openshift.io/policy-group"
+	kubernetesRouterNamespace     = "ingress-nginx"
+)
+
+// getAutoscalingOverrides returns the per-component autoscaling overrides set on the binding
+func getAutoscalingOverrides(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) (map[string]devfile.Autoscaling, error) {
+	overrides := make(map[string]devfile.Autoscaling)
+	if _, err := getJSONAnnotation(binding, AutoscalingOverridesAnnotation, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// getAvailabilityOverrides returns the per-component availability overrides set on the binding
+func getAvailabilityOverrides(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) (map[string]devfile.Availability, error) {
+	overrides := make(map[string]devfile.Availability)
+	if _, err := getJSONAnnotation(binding, AvailabilityOverridesAnnotation, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// getEnvironmentWorkload returns the workload configuration that the Environment runs its Components with, if the Environment sets one.
+// Only Knative Services can be selected per environment
+func getEnvironmentWorkload(environment *appstudiov1alpha1.Environment) (*devfile.Workload, error) {
+	var workload devfile.Workload
+	if isSet, err := getJSONAnnotation(environment, WorkloadAnnotation, &workload); err != nil || !isSet {
+		return nil, err
+	}
+	if workload.Kind != devfile.KnativeServiceWorkloadKind {
+		return nil, fmt.Errorf("invalid %s annotation on Environment %s: only the %s workload kind can be selected per environment", WorkloadAnnotation, environment.Name, devfile.KnativeServiceWorkloadKind)
+	}
+	return &workload, nil
+}
+
+// getDeployKubernetesComponent returns the devfile kubernetes component deployed by the Component, if any
+func getDeployKubernetesComponent(compDevfileData data.DevfileData, deployAssociatedComponents map[string]string) (*devfileAPIV1.Component, error) {
+	kubernetesComponents, err := compDevfileData.GetComponents(common.DevfileOptions{
+		ComponentOptions: common.ComponentOptions{
+			ComponentType: devfileAPIV1.KubernetesComponentType,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range kubernetesComponents {
+		if _, ok := deployAssociatedComponents[kubernetesComponents[i].Name]; ok {
+			return &kubernetesComponents[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// getComponentAutoscaling returns the autoscaling configuration declared in the devfile kubernetes component deployed by the Component, if any
+func getComponentAutoscaling(compDevfileData data.DevfileData, deployAssociatedComponents map[string]string) (*devfile.Autoscaling, error) {
+	kubernetesComponent, err := getDeployKubernetesComponent(compDevfileData, deployAssociatedComponents)
+	if err != nil || kubernetesComponent == nil {
+		return nil, err
+	}
+	return devfile.GetAutoscalingFromAttributes(kubernetesComponent.Attributes)
+}
+
+// getComponentAvailability returns the availability configuration declared in the devfile kubernetes component deployed by the Component, if any
+func getComponentAvailability(compDevfileData data.DevfileData, deployAssociatedComponents map[string]string) (*devfile.Availability, error) {
+	kubernetesComponent, err := getDeployKubernetesComponent(compDevfileData, deployAssociatedComponents)
+	if err != nil || kubernetesComponent == nil {
+		return nil, err
+	}
+	return devfile.GetAvailabilityFromAttributes(kubernetesComponent.Attributes)
+}
+
+// getComponentWorkload returns the workload configuration declared in the devfile kubernetes component deployed by the Component,
+// a Deployment workload is returned if the Component does not declare one
+func getComponentWorkload(compDevfileData data.DevfileData, deployAssociatedComponents map[string]string) (devfile.Workload, error) {
+	kubernetesComponent, err := getDeployKubernetesComponent(compDevfileData, deployAssociatedComponents)
+	if err != nil {
+		return devfile.Workload{}, err
+	}
+	if kubernetesComponent == nil {
+		return devfile.Workload{Kind: devfile.DeploymentWorkloadKind}, nil
+	}
+	return devfile.GetWorkloadFromAttributes(kubernetesComponent.Attributes)
+}
+
+// setComponentWorkload sets the workload configuration of the devfile kubernetes component deployed by the Component
+func setComponentWorkload(compDevfileData data.DevfileData, deployAssociatedComponents map[string]string, workload devfile.Workload) error {
+	kubernetesComponent, err := getDeployKubernetesComponent(compDevfileData, deployAssociatedComponents)
+	if err != nil {
+		return err
+	}
+	if kubernetesComponent == nil {
+		return fmt.Errorf("the devfile does not have a kubernetes component to deploy")
+	}
+	kubernetesComponent.Attributes = kubernetesComponent.Attributes.FromMap(map[string]interface{}{devfile.WorkloadKey: workload}, &err)
+	if err != nil {
+		return err
+	}
+	return compDevfileData.UpdateComponent(*kubernetesComponent)
+}
+
+// generateAutoscalingOverlay writes the environment specific HorizontalPodAutoscaler of the component into its overlay folder.
+// If the component already declares autoscaling, the override is merged on top and written as a patch of the base HorizontalPodAutoscaler,
+// otherwise the override is written as a new resource. Any previously generated autoscaling overlay is removed when the override is unset.
+// The names of the files that are part of the overlay are returned
+func generateAutoscalingOverlay(fs afero.Afero, overlayPath string, componentName string, labels map[string]string, workloadKind devfile.WorkloadKind, baseAutoscaling *devfile.Autoscaling, override *devfile.Autoscaling) ([]string, error) {
+	if override == nil {
+		for _, fileName := range []string{hpaPatchFileName, hpaFileName} {
+			if err := gitops.RemoveOverlayFile(fs, overlayPath, fileName); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	if baseAutoscaling != nil {
+		autoscaling := baseAutoscaling.Merge(*override)
+		if err := autoscaling.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid autoscaling override for component %s: %v", componentName, err)
+		}
+		if err := gitops.RemoveOverlayFile(fs, overlayPath, hpaFileName); err != nil {
+			return nil, err
+		}
+		hpa := devfile.GenerateHorizontalPodAutoscaler(componentName, labels, autoscaling, workloadKind)
+		return []string{hpaPatchFileName}, gitops.AddOverlayPatch(fs, overlayPath, hpaPatchFileName, hpa)
+	}
+
+	if workloadKind != devfile.DeploymentWorkloadKind && workloadKind != devfile.StatefulSetWorkloadKind {
+		return nil, fmt.Errorf("invalid autoscaling override for component %s: autoscaling is not supported for %s workloads", componentName, workloadKind)
+	}
+	if err := override.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid autoscaling override for component %s: %v", componentName, err)
+	}
+	if err := gitops.RemoveOverlayFile(fs, overlayPath, hpaPatchFileName); err != nil {
+		return nil, err
+	}
+	hpa := devfile.GenerateHorizontalPodAutoscaler(componentName, labels, *override, workloadKind)
+	return []string{hpaFileName}, gitops.AddOverlayResource(fs, overlayPath, hpaFileName, hpa)
+}
+
+// generateAvailabilityOverlay writes the environment specific availability of the component into its overlay folder. The override is merged on top
+// of the availability the component declares. The PodDisruptionBudget is written as a patch of the base PodDisruptionBudget if the base has one,
+// otherwise as a new resource, and the scheduling constraints as a patch of the workload replacing the ones of the base. Any previously generated
+// availability overlay is removed when the override is unset. The names of the files that are part of the overlay are returned
+func generateAvailabilityOverlay(fs afero.Afero, overlayPath string, componentName string, labels map[string]string, workloadKind devfile.WorkloadKind, baseAvailability *devfile.Availability, override *devfile.Availability) ([]string, error) {
+	if override == nil {
+		for _, fileName := range []string{pdbPatchFileName, pdbFileName, availabilityPatchFileName} {
+			if err := gitops.RemoveOverlayFile(fs, overlayPath, fileName); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	if workloadKind != devfile.DeploymentWorkloadKind && workloadKind != devfile.StatefulSetWorkloadKind {
+		return nil, fmt.Errorf("invalid availability override for component %s: availability is not supported for %s workloads", componentName, workloadKind)
+	}
+	if err := override.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid availability override for component %s: %v", componentName, err)
+	}
+	availability := *override
+	if baseAvailability != nil {
+		availability = baseAvailability.Merge(*override)
+	}
+
+	var files []string
+	pdbFiles := []string{pdbPatchFileName, pdbFileName}
+	if availability.HasDisruptionBudget() {
+		pdb := devfile.GeneratePodDisruptionBudget(componentName, labels, availability)
+		if baseAvailability != nil && baseAvailability.HasDisruptionBudget() {
+			// both bounds are written so that the bound of the base is cleared when the override sets the other one
+			pdbPatch := map[string]interface{}{
+				"apiVersion": pdb.APIVersion,
+				"kind":       pdb.Kind,
+				"metadata":   map[string]interface{}{"name": pdb.Name},
+				"spec": map[string]interface{}{
+					"minAvailable":   availability.MinAvailable,
+					"maxUnavailable": availability.MaxUnavailable,
+				},
+			}
+			if err := gitops.AddOverlayPatch(fs, overlayPath, pdbPatchFileName, pdbPatch); err != nil {
+				return nil, err
+			}
+			files = append(files, pdbPatchFileName)
+			pdbFiles = []string{pdbFileName}
+		} else {
+			if err := gitops.AddOverlayResource(fs, overlayPath, pdbFileName, pdb); err != nil {
+				return nil, err
+			}
+			files = append(files, pdbFileName)
+			pdbFiles = []string{pdbPatchFileName}
+		}
+	}
+	for _, fileName := range pdbFiles {
+		if err := gitops.RemoveOverlayFile(fs, overlayPath, fileName); err != nil {
+			return nil, err
+		}
+	}
+
+	if !availability.HasSchedulingConstraints() {
+		return files, gitops.RemoveOverlayFile(fs, overlayPath, availabilityPatchFileName)
+	}
+	var podSpec corev1.PodSpec
+	devfile.ApplyAvailability(&podSpec, componentName, availability)
+	podSpecPatch := make(map[string]interface{})
+	if len(podSpec.TopologySpreadConstraints) > 0 {
+		constraints := []interface{}{map[string]interface{}{"$patch": "replace"}}
+		for _, constraint := range podSpec.TopologySpreadConstraints {
+			constraints = append(constraints, constraint)
+		}
+		podSpecPatch["topologySpreadConstraints"] = constraints
+	}
+	if podSpec.Affinity != nil {
+		antiAffinity, err := toPatch(podSpec.Affinity.PodAntiAffinity)
+		if err != nil {
+			return nil, err
+		}
+		antiAffinity["$patch"] = "replace"
+		podSpecPatch["affinity"] = map[string]interface{}{"podAntiAffinity": antiAffinity}
+	}
+	workloadPatch := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       string(workloadKind),
+		"metadata":   map[string]interface{}{"name": componentName},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": podSpecPatch,
+			},
+		},
+	}
+	return append(files, availabilityPatchFileName), gitops.AddOverlayPatch(fs, overlayPath, availabilityPatchFileName, workloadPatch)
+}
+
+// toPatch converts a typed resource into a map that patch directives can be added to
+func toPatch(resource interface{}) (map[string]interface{}, error) {
+	resourceBytes, err := yaml.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	patch := make(map[string]interface{})
+	if err := yaml.Unmarshal(resourceBytes, &patch); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// getWorkloadPatchFileName returns the name of the overlay patch of the given workload kind, e.g. statefulset-patch.yaml
+func getWorkloadPatchFileName(workloadKind devfile.WorkloadKind) string {
+	return strings.ToLower(string(workloadKind)) + "-patch.yaml"
+}
+
+// generateWorkloadOverlay converts the Deployment patch written by the gitops generator library into a patch of the StatefulSet, Job or CronJob
+// that the component runs as, and removes the patches of the other workload kinds. The patched container is renamed to the main container of
+// the workload, since the library only knows the container name of a base Deployment. The template labels are added to the pod template of
+// the patch, whatever the workload kind. The names of the files that are part of the overlay are returned
+func generateWorkloadOverlay(fs afero.Afero, overlayPath string, workloadKind devfile.WorkloadKind, mainContainerName string, templateAnnotations map[string]string, templateLabels map[string]string) ([]string, error) {
+	for _, kind := range []devfile.WorkloadKind{devfile.StatefulSetWorkloadKind, devfile.JobWorkloadKind, devfile.CronJobWorkloadKind, devfile.KnativeServiceWorkloadKind} {
+		if kind != workloadKind {
+			if err := gitops.RemoveOverlayFile(fs, overlayPath, getWorkloadPatchFileName(kind)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if workloadKind == devfile.DeploymentWorkloadKind && len(templateLabels) == 0 {
+		return []string{deploymentPatchFileName}, nil
+	}
+
+	deploymentPatchBytes, err := fs.ReadFile(filepath.Join(overlayPath, deploymentPatchFileName))
+	if err != nil {
+		return nil, err
+	}
+	var deploymentPatch appsv1.Deployment
+	if err := yaml.Unmarshal(deploymentPatchBytes, &deploymentPatch); err != nil {
+		return nil, err
+	}
+	podTemplate := deploymentPatch.Spec.Template
+	if len(podTemplate.Spec.Containers) > 0 && mainContainerName != "" {
+		podTemplate.Spec.Containers[0].Name = mainContainerName
+	}
+	if len(templateAnnotations) > 0 {
+		podTemplate.ObjectMeta.Annotations = templateAnnotations
+	}
+	if len(templateLabels) > 0 {
+		if podTemplate.ObjectMeta.Labels == nil {
+			podTemplate.ObjectMeta.Labels = make(map[string]string)
+		}
+		maps.Copy(podTemplate.ObjectMeta.Labels, templateLabels)
+	}
+
+	var workloadPatch interface{}
+	switch workloadKind {
+	case devfile.DeploymentWorkloadKind:
+		deploymentPatch.Spec.Template = podTemplate
+		return []string{deploymentPatchFileName}, gitops.AddOverlayPatch(fs, overlayPath, deploymentPatchFileName, deploymentPatch)
+	case devfile.StatefulSetWorkloadKind:
+		workloadPatch = appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{Kind: string(workloadKind), APIVersion: "apps/v1"},
+			ObjectMeta: deploymentPatch.ObjectMeta,
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: deploymentPatch.Spec.Replicas,
+				Template: podTemplate,
+			},
+		}
+	case devfile.JobWorkloadKind:
+		workloadPatch = batchv1.Job{
+			TypeMeta:   metav1.TypeMeta{Kind: string(workloadKind), APIVersion: "batch/v1"},
+			ObjectMeta: deploymentPatch.ObjectMeta,
+			Spec:       batchv1.JobSpec{Template: podTemplate},
+		}
+	case devfile.CronJobWorkloadKind:
+		workloadPatch = batchv1.CronJob{
+			TypeMeta:   metav1.TypeMeta{Kind: string(workloadKind), APIVersion: "batch/v1"},
+			ObjectMeta: deploymentPatch.ObjectMeta,
+			Spec: batchv1.CronJobSpec{
+				JobTemplate: batchv1.JobTemplateSpec{
+					Spec: batchv1.JobSpec{Template: podTemplate},
+				},
+			},
+		}
+	case devfile.KnativeServiceWorkloadKind:
+		workloadPatch = devfile.KnativeService{
+			TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: devfile.KnativeServiceAPIVersion},
+			ObjectMeta: deploymentPatch.ObjectMeta,
+			Spec:       devfile.KnativeServiceSpec{Template: podTemplate},
+		}
+	default:
+		return nil, fmt.Errorf("workload kind %q is not supported", workloadKind)
+	}
+
+	if err := gitops.RemoveOverlayFile(fs, overlayPath, deploymentPatchFileName); err != nil {
+		return nil, err
+	}
+	patchFileName := getWorkloadPatchFileName(workloadKind)
+	return []string{patchFileName}, gitops.AddOverlayPatch(fs, overlayPath, patchFileName, workloadPatch)
+}
+
+// generateKnativeEnvironmentOverlay adds the Knative Service of a component that only runs as a Knative Service in the environment to its overlay,
+// along with the patches deleting the base Deployment, and the base Service and HorizontalPodAutoscaler if they exist. The overlay files are removed
+// when knativeService is nil. The names of the files that are part of the overlay are returned
+func generateKnativeEnvironmentOverlay(fs afero.Afero, overlayPath string, componentName string, knativeService interface{}, hasBaseAutoscaling bool) ([]string, error) {
+	if knativeService == nil {
+		for _, fileName := range []string{knativeServiceFileName, deploymentDeletePatchFileName, serviceDeletePatchFileName, hpaDeletePatchFileName} {
+			if err := gitops.RemoveOverlayFile(fs, overlayPath, fileName); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	if err := gitops.AddOverlayResource(fs, overlayPath, knativeServiceFileName, knativeService); err != nil {
+		return nil, err
+	}
+	if err := gitops.AddOverlayPatch(fs, overlayPath, deploymentDeletePatchFileName, getDeletePatch("apps/v1", "Deployment", componentName)); err != nil {
+		return nil, err
+	}
+	files := []string{knativeServiceFileName, deploymentDeletePatchFileName}
+
+	// kustomize fails on delete patches that do not match any resource, so only the base resources that exist are deleted
+	hasBaseService, err := fs.Exists(filepath.Join(overlayPath, "..", "..", "base", serviceFileName))
+	if err != nil {
+		return nil, err
+	}
+	deletePatches := []struct {
+		exists   bool
+		fileName string
+		patch    map[string]interface{}
+	}{
+		{hasBaseService, serviceDeletePatchFileName, getDeletePatch("v1", "Service", componentName)},
+		{hasBaseAutoscaling, hpaDeletePatchFileName, getDeletePatch("autoscaling/v2", "HorizontalPodAutoscaler", componentName)},
+	}
+	for _, deletePatch := range deletePatches {
+		if !deletePatch.exists {
+			if err := gitops.RemoveOverlayFile(fs, overlayPath, deletePatch.fileName); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := gitops.AddOverlayPatch(fs, overlayPath, deletePatch.fileName, deletePatch.patch); err != nil {
+			return nil, err
+		}
+		files = append(files, deletePatch.fileName)
+	}
+	return files, nil
+}
+
+// getDeletePatch returns a strategic merge patch deleting the resource with the given kind and name
+func getDeletePatch(apiVersion, kind, name string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"$patch": "delete",
+	}
+}
+
+// getComponentNetworkPolicy returns the network policy configuration declared in the devfile kubernetes component deployed by the Component, if any
+func getComponentNetworkPolicy(compDevfileData data.DevfileData, deployAssociatedComponents map[string]string) (*devfile.NetworkPolicy, error) {
+	kubernetesComponent, err := getDeployKubernetesComponent(compDevfileData, deployAssociatedComponents)
+	if err != nil || kubernetesComponent == nil {
+		return nil, err
+	}
+	return devfile.GetNetworkPolicyFromAttributes(kubernetesComponent.Attributes)
+}
+
+// getComponentEndpointPorts returns the public and internal ports of the devfile kubernetes component deployed by the Component.
+// The target port of the Component is public if the devfile does not have a public endpoint, since the gitops generator library
+// generates a Route or an Ingress for it
+func getComponentEndpointPorts(compDevfileData data.DevfileData, deployAssociatedComponents map[string]string, targetPort int) ([]int32, []int32, error) {
+	kubernetesComponent, err := getDeployKubernetesComponent(compDevfileData, deployAssociatedComponents)
+	if err != nil {
+		return nil, nil, err
+	}
+	var publicPorts, internalPorts []int32
+	if kubernetesComponent != nil {
+		publicPorts, internalPorts = devfile.GetEndpointPorts(*kubernetesComponent)
+	}
+	if len(publicPorts) == 0 && targetPort > 0 {
+		publicPorts = append(publicPorts, int32(targetPort))
+	}
+	return publicPorts, internalPorts, nil
+}
+
+// getRouterNamespaceLabels returns the labels selecting the namespace of the router or ingress controller of the Environment.
+// The namespace can be named with an annotation, otherwise the OpenShift router or the ingress-nginx controller is selected
+func getRouterNamespaceLabels(environment appstudiov1alpha1.Environment) map[string]string {
+	if namespace := environment.GetAnnotations()[RouterNamespaceAnnotation]; namespace != "" {
+		return map[string]string{corev1.LabelMetadataName: namespace}
+	}
+	if isKubernetesCluster(environment) {
+		return map[string]string{corev1.LabelMetadataName: kubernetesRouterNamespace}
+	}
+	return map[string]string{openShiftRouterNamespaceLabel: "ingress"}
+}
+
+// getNetworkPolicyDependents returns, for each Component of the binding, the Components of the binding that declare it as a dependency.
+// false is returned if none of the Components declare a network policy
+func (r *SnapshotEnvironmentBindingReconciler) getNetworkPolicyDependents(ctx context.Context, namespace string, components []appstudiov1alpha1.BindingComponent) (map[string][]string, bool, error) {
+	dependents := make(map[string][]string)
+	hasNetworkPolicy := false
+	for _, component := range components {
+		hasComponent := appstudiov1alpha1.Component{}
+		if err := r.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: namespace}, &hasComponent); err != nil {
+			return nil, false, err
+		}
+		if hasComponent.Spec.SkipGitOpsResourceGeneration || hasComponent.Status.Devfile == "" {
+			continue
+		}
+		compDevfileData, err := devfile.ParseDevfile(devfile.DevfileSrc{Data: hasComponent.Status.Devfile})
+		if err != nil {
+			return nil, false, err
+		}
+		deployAssociatedComponents, err := devfileParser.GetDeployComponents(compDevfileData)
+		if err != nil {
+			return nil, false, err
+		}
+		networkPolicy, err := getComponentNetworkPolicy(compDevfileData, deployAssociatedComponents)
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to get the network policy configuration of component %s: %v", component.Name, err)
+		}
+		if networkPolicy == nil {
+			continue
+		}
+		hasNetworkPolicy = true
+		for _, dependency := range networkPolicy.Dependencies {
+			dependents[dependency] = append(dependents[dependency], component.Name)
+		}
+	}
+	return dependents, hasNetworkPolicy, nil
+}
+
+// generateNetworkPolicyOverlay writes the NetworkPolicy of the component into its overlay folder, or removes it when networkPolicy is nil.
+// The names of the files that are part of the overlay are returned
+func generateNetworkPolicyOverlay(fs afero.Afero, overlayPath string, networkPolicy *networkingv1.NetworkPolicy) ([]string, error) {
+	if networkPolicy == nil {
+		return nil, gitops.RemoveOverlayFile(fs, overlayPath, networkPolicyFileName)
+	}
+	return []string{networkPolicyFileName}, gitops.AddOverlayResource(fs, overlayPath, networkPolicyFileName, *networkPolicy)
+}
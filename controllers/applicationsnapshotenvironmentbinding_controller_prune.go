@@ -0,0 +1,158 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/metrics"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/spf13/afero"
+)
+
+// getRemovedComponents returns the status entries of the components that are no longer in the spec of the binding
+func getRemovedComponents(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) []appstudiov1alpha1.BindingComponentStatus {
+	specComponents := make(map[string]bool)
+	for _, component := range binding.Spec.Components {
+		specComponents[component.Name] = true
+	}
+
+	var removedComponents []appstudiov1alpha1.BindingComponentStatus
+	for _, componentStatus := range binding.Status.Components {
+		if !specComponents[componentStatus.Name] {
+			removedComponents = append(removedComponents, componentStatus)
+		}
+	}
+	return removedComponents
+}
+
+// pruneComponentStatus returns the status entries of the binding without the entries of the removed components
+func pruneComponentStatus(componentStatuses []appstudiov1alpha1.BindingComponentStatus, removedComponents []appstudiov1alpha1.BindingComponentStatus) []appstudiov1alpha1.BindingComponentStatus {
+	removed := make(map[string]bool)
+	for _, removedComponent := range removedComponents {
+		removed[removedComponent.Name] = true
+	}
+
+	prunedStatuses := []appstudiov1alpha1.BindingComponentStatus{}
+	for _, componentStatus := range componentStatuses {
+		if !removed[componentStatus.Name] {
+			prunedStatuses = append(prunedStatuses, componentStatus)
+		}
+	}
+	return prunedStatuses
+}
+
+// gitOpsRepository is a GitOps repository branch that the components of a binding are synced to
+type gitOpsRepository struct {
+	url    string
+	branch string
+}
+
+// groupComponentsByRepository groups the status entries of the components by GitOps repository and branch, in the order of the status
+func groupComponentsByRepository(componentStatuses []appstudiov1alpha1.BindingComponentStatus) ([]gitOpsRepository, map[gitOpsRepository][]appstudiov1alpha1.BindingComponentStatus) {
+	var repositories []gitOpsRepository
+	repositoryComponents := make(map[gitOpsRepository][]appstudiov1alpha1.BindingComponentStatus)
+	for _, componentStatus := range componentStatuses {
+		repository := gitOpsRepository{url: componentStatus.GitOpsRepository.URL, branch: componentStatus.GitOpsRepository.Branch}
+		if _, ok := repositoryComponents[repository]; !ok {
+			repositories = append(repositories, repository)
+		}
+		repositoryComponents[repository] = append(repositoryComponents[repository], componentStatus)
+	}
+	return repositories, repositoryComponents
+}
+
+// getComponentEnvironmentPath returns the path of the GitOps resources of a component that are specific to the environment: the path recorded
+// in the status entry of the component, or the values file of the environment if the path is the Helm chart of the component, which is shared
+// by every environment. Paths that do not point to the overlay or the chart of the component are rejected
+func getComponentEnvironmentPath(componentStatus appstudiov1alpha1.BindingComponentStatus, environmentName string) (string, error) {
+	componentName := componentStatus.Name
+	bindingPath := componentStatus.GitOpsRepository.Path
+	if strings.Contains(bindingPath, "..") {
+		return "", fmt.Errorf("invalid GitOps repository path %s for the component %s", bindingPath, componentName)
+	}
+
+	bindingPath = filepath.Clean(bindingPath)
+	switch {
+	case strings.HasSuffix(bindingPath, filepath.Join("components", componentName, "overlays", environmentName)):
+		return bindingPath, nil
+	case strings.HasSuffix(bindingPath, gitops.GetHelmChartPath("", componentName)):
+		return filepath.Join(bindingPath, gitops.GetHelmEnvironmentValuesFileName(environmentName)), nil
+	}
+	return "", fmt.Errorf("invalid GitOps repository path %s for the component %s", bindingPath, componentName)
+}
+
+// removeComponentOverlay removes the environment overlay of a component removed from the binding from the GitOps repository cloned in repoPath
+func removeComponentOverlay(fs afero.Afero, repoPath string, componentStatus appstudiov1alpha1.BindingComponentStatus, environmentName string) error {
+	environmentPath, err := getComponentEnvironmentPath(componentStatus, environmentName)
+	if err != nil {
+		return err
+	}
+	return fs.RemoveAll(filepath.Join(repoPath, environmentPath))
+}
+
+// pruneRemovedComponents clones the GitOps repositories of the removed components, removes their environment overlays and pushes the changes,
+// with one commit per repository and branch. It is used for the components whose overlays could not be removed in the commit of a component
+// that is still bound, e.g. when every component was removed from the binding, and to remove every overlay of a deleted binding
+func (r *SnapshotEnvironmentBindingReconciler) pruneRemovedComponents(ghClient *github.GitHubClient, binding *appstudiov1alpha1.SnapshotEnvironmentBinding, removedComponents []appstudiov1alpha1.BindingComponentStatus) error {
+	applicationName := binding.Spec.Application
+	environmentName := binding.Spec.Environment
+
+	repositories, repositoryComponents := groupComponentsByRepository(removedComponents)
+	for _, repository := range repositories {
+		gitOpsRemoteURL, gitOpsBranch, _, err := util.ProcessGitOpsStatus(appstudiov1alpha1.GitOpsStatus{RepositoryURL: repository.url, Branch: repository.branch}, ghClient.Token)
+		if err != nil {
+			return err
+		}
+
+		tempDir, err := ioutils.CreateTempPath(binding.Name, r.AppFS)
+		if err != nil {
+			return fmt.Errorf("unable to create temp directory for gitops resources due to error: %v", err)
+		}
+
+		var componentNames []string
+		for _, removedComponent := range repositoryComponents[repository] {
+			componentNames = append(componentNames, removedComponent.Name)
+		}
+
+		metrics.ControllerGitRequest.With(prometheus.Labels{"controller": asebName, "tokenName": ghClient.TokenName, "operation": "CloneRepo"}).Inc()
+		err = r.Generator.CloneRepo(tempDir, gitOpsRemoteURL, applicationName, gitOpsBranch)
+		if err == nil {
+			for _, removedComponent := range repositoryComponents[repository] {
+				if err = removeComponentOverlay(r.AppFS, filepath.Join(tempDir, applicationName), removedComponent, environmentName); err != nil {
+					break
+				}
+			}
+		}
+		if err == nil {
+			metrics.ControllerGitRequest.With(prometheus.Labels{"controller": asebName, "tokenName": ghClient.TokenName, "operation": "CommitAndPush"}).Inc()
+			err = r.Generator.CommitAndPush(tempDir, applicationName, gitOpsRemoteURL, strings.Join(componentNames, ", "), gitOpsBranch, fmt.Sprintf("Remove %s environment overlays for components %s", environmentName, strings.Join(componentNames, ", ")))
+		}
+		_ = r.AppFS.RemoveAll(tempDir) // not worried with an err, its a best case attempt to delete the temp clone dir
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,372 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser/data"
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	gitopsgenv1alpha1 "github.com/redhat-developer/gitops-generator/api/v1alpha1"
+	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	devfileParser "github.com/devfile/library/v2/pkg/devfile/parser"
+)
+
+// environmentOverlay is a Component bound to an Environment, with everything needed to generate its environment overlay or Helm values
+type environmentOverlay struct {
+	component                   *appstudiov1alpha1.Component
+	configuration               appstudiov1alpha1.BindingComponentConfiguration
+	environment                 appstudiov1alpha1.Environment
+	applicationName             string
+	imageName                   string
+	hostname                    string
+	isKubernetesCluster         bool
+	devfileData                 data.DevfileData
+	deployAssociatedComponents  map[string]string
+	kubernetesResources         devfileParser.KubernetesResources
+	workload                    devfile.Workload
+	isEnvironmentKnativeService bool
+
+	// The configuration of the binding and of the other Components of the binding
+	secrets                 SecretReferences
+	autoscalingOverride     *devfile.Autoscaling
+	availabilityOverride    *devfile.Availability
+	networkPolicyDependents []string
+	podTemplateLabels       map[string]string
+}
+
+// newEnvironmentOverlay returns the overlay of the Component bound to the Environment at the image of the Snapshot. The environment workload,
+// if not nil, is the workload kind that the Environment runs the Deployment components as
+func newEnvironmentOverlay(log logr.Logger, component *appstudiov1alpha1.Component, bindingComponent appstudiov1alpha1.BindingComponent, environment appstudiov1alpha1.Environment,
+	environmentWorkload *devfile.Workload, snapshot appstudiov1alpha1.Snapshot) (*environmentOverlay, error) {
+	overlay := &environmentOverlay{
+		component:           component,
+		configuration:       bindingComponent.Configuration,
+		environment:         environment,
+		applicationName:     component.Spec.Application,
+		isKubernetesCluster: isKubernetesCluster(environment),
+	}
+
+	var clusterIngressDomain string
+	unsupportedConfig := environment.Spec.UnstableConfigurationFields
+	if unsupportedConfig != nil {
+		clusterIngressDomain = unsupportedConfig.IngressDomain
+	}
+
+	// Safeguard if Ingress Domain is empty on Kubernetes
+	if overlay.isKubernetesCluster && clusterIngressDomain == "" {
+		err := fmt.Errorf("ingress domain cannot be empty on a Kubernetes cluster")
+		log.Error(err, "unable to create an ingress resource on a Kubernetes cluster")
+		return nil, err
+	}
+
+	var err error
+	overlay.devfileData, err = devfile.ParseDevfile(devfile.DevfileSrc{Data: component.Status.Devfile})
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to parse the devfile from the status of the Component %s", component.Name))
+		return nil, fmt.Errorf("unable to parse the devfile from the status of the Component %s: %v", component.Name, err)
+	}
+
+	overlay.deployAssociatedComponents, err = devfileParser.GetDeployComponents(overlay.devfileData)
+	if err != nil {
+		log.Error(err, "unable to get deploy components")
+		return nil, err
+	}
+
+	overlay.workload, err = getComponentWorkload(overlay.devfileData, overlay.deployAssociatedComponents)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to get the workload configuration of %s", component.Name))
+		return nil, err
+	}
+
+	// The environment can run Deployment components as Knative Services, render the component as a Knative Service for its overlay
+	if environmentWorkload != nil && overlay.workload.Kind != environmentWorkload.Kind {
+		if overlay.workload.Kind != devfile.DeploymentWorkloadKind {
+			err := fmt.Errorf("component %s runs as a %s and cannot run as a %s in the environment %s", component.Name, overlay.workload.Kind, environmentWorkload.Kind, environment.Name)
+			log.Error(err, "")
+			return nil, err
+		}
+		if err := setComponentWorkload(overlay.devfileData, overlay.deployAssociatedComponents, *environmentWorkload); err != nil {
+			log.Error(err, fmt.Sprintf("unable to set the workload configuration of %s", component.Name))
+			return nil, err
+		}
+		overlay.workload = *environmentWorkload
+		overlay.isEnvironmentKnativeService = true
+	}
+
+	if overlay.isKubernetesCluster {
+		overlay.hostname, err = devfile.GetIngressHostName(component.Name, component.Namespace, clusterIngressDomain)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("unable to get generate a host name from an ingress domain for %s", component.Name))
+			return nil, err
+		}
+	}
+
+	overlay.kubernetesResources, err = devfile.GetResourceFromDevfile(log, overlay.devfileData, overlay.deployAssociatedComponents, component.Name, component.Spec.Application, component.Spec.ContainerImage, overlay.hostname)
+	if err != nil {
+		log.Error(err, "unable to get kubernetes resources from the devfile outerloop components")
+		return nil, err
+	}
+
+	for _, snapshotComponent := range snapshot.Spec.Components {
+		if snapshotComponent.Name == component.Name {
+			overlay.imageName = snapshotComponent.ContainerImage
+			break
+		}
+	}
+
+	if overlay.imageName == "" {
+		err := fmt.Errorf("application snapshot %s did not reference component %s", snapshot.Name, component.Name)
+		log.Error(err, "")
+		return nil, err
+	}
+	return overlay, nil
+}
+
+// getEnvVars returns the environment variables of the binding component and of the Environment
+func (o *environmentOverlay) getEnvVars() ([]corev1.EnvVar, []corev1.EnvVar) {
+	envVars := make([]corev1.EnvVar, 0)
+	for _, env := range o.configuration.Env {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  env.Name,
+			Value: env.Value,
+		})
+	}
+
+	environmentConfigEnvVars := make([]corev1.EnvVar, 0)
+	for _, env := range o.environment.Spec.Configuration.Env {
+		environmentConfigEnvVars = append(environmentConfigEnvVars, corev1.EnvVar{
+			Name:  env.Name,
+			Value: env.Value,
+		})
+	}
+	return envVars, environmentConfigEnvVars
+}
+
+// getResources returns the resource requirements of the binding component
+func (o *environmentOverlay) getResources() corev1.ResourceRequirements {
+	componentResources := corev1.ResourceRequirements{}
+	if o.configuration.Resources != nil {
+		componentResources = *o.configuration.Resources
+	}
+	return componentResources
+}
+
+// getHelmEnvironmentValues returns the Helm values of the environment of the component, the Helm chart of the component is generated by the component controller
+func getHelmEnvironmentValues(log logr.Logger, overlay *environmentOverlay) (gitops.HelmValues, error) {
+	envVars, environmentConfigEnvVars := overlay.getEnvVars()
+	helmValues := gitops.HelmValues{
+		Image:     overlay.imageName,
+		Env:       envVars,
+		Resources: overlay.getResources(),
+	}
+	// only add the environment level env vars that the component does not already set
+	for _, env := range environmentConfigEnvVars {
+		isPresent := false
+		for _, componentEnv := range envVars {
+			if componentEnv.Name == env.Name {
+				isPresent = true
+				break
+			}
+		}
+		if !isPresent {
+			helmValues.Env = append(helmValues.Env, env)
+		}
+	}
+	// secret env vars replace the plain env vars of the same name
+	helmValues.Env = setEnvVars(helmValues.Env, getSecretEnvVars(overlay.secrets.Env))
+	if overlay.configuration.Replicas > 0 {
+		replicas := int32(overlay.configuration.Replicas)
+		helmValues.Replicas = &replicas
+	}
+	// The values only configure the workload of the chart, the environment settings that need other resources are not supported
+	var unsupported string
+	switch {
+	case len(overlay.secrets.SealedSecrets) > 0 || len(overlay.secrets.ExternalSecrets) > 0:
+		unsupported = "references SealedSecrets or ExternalSecrets"
+	case overlay.autoscalingOverride != nil:
+		unsupported = "has an autoscaling override"
+	case overlay.availabilityOverride != nil:
+		unsupported = "has an availability override"
+	case overlay.isEnvironmentKnativeService:
+		unsupported = fmt.Sprintf("runs as a %s in the environment %s", devfile.KnativeServiceWorkloadKind, overlay.environment.Name)
+	}
+	if unsupported != "" {
+		err := fmt.Errorf("component %s %s, which is only supported with the %s GitOps format", overlay.component.Name, unsupported, gitops.KustomizeFormat)
+		log.Error(err, "")
+		return helmValues, err
+	}
+	return helmValues, nil
+}
+
+// generateKustomizeOverlay generates the environment overlay of the component in the GitOps repository cloned at the repository path, or clones it
+// there first if clone is true, and records the files of the overlay in componentGeneratedResources. The gitops generator library generates the overlay, and the
+// environment specific resources that the library does not handle are added to it
+func generateKustomizeOverlay(log logr.Logger, fs afero.Afero, generator gitopsgen.Generator, repoPath string, clone bool, remote, branch, context string,
+	environmentName string, overlay *environmentOverlay, componentGeneratedResources map[string][]string) error {
+	componentName := overlay.component.Name
+	applicationName := overlay.applicationName
+	workload := overlay.workload
+	kubernetesResources := overlay.kubernetesResources
+	envVars, environmentConfigEnvVars := overlay.getEnvVars()
+
+	kubeLabels := map[string]string{
+		"app.kubernetes.io/name":       componentName,
+		"app.kubernetes.io/instance":   componentName,
+		"app.kubernetes.io/part-of":    applicationName,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": "application-service",
+	}
+	genOptions := gitopsgenv1alpha1.GeneratorOptions{
+		Name:                componentName,
+		Replicas:            overlay.configuration.Replicas,
+		Resources:           overlay.getResources(),
+		BaseEnvVar:          envVars,
+		OverlayEnvVar:       environmentConfigEnvVars,
+		K8sLabels:           kubeLabels,
+		IsKubernetesCluster: overlay.isKubernetesCluster,
+		TargetPort:          overlay.component.Spec.TargetPort, // pass the target port to the gitops gen library as they may generate a route/ingress based on the target port if the devfile does not have an ingress/route or an endpoint
+	}
+
+	if !reflect.DeepEqual(kubernetesResources, devfileParser.KubernetesResources{}) {
+		genOptions.KubernetesResources.Routes = append(genOptions.KubernetesResources.Routes, kubernetesResources.Routes...)
+		genOptions.KubernetesResources.Ingresses = append(genOptions.KubernetesResources.Ingresses, kubernetesResources.Ingresses...)
+	}
+
+	if workload.Kind == devfile.KnativeServiceWorkloadKind {
+		// a Knative Service routes the traffic to the component, so the gitops generator library must not generate a Route or an Ingress
+		genOptions.TargetPort = 0
+	} else if overlay.isKubernetesCluster && len(genOptions.KubernetesResources.Ingresses) == 0 {
+		// provide the hostname for the component if there are no ingresses
+		// Gitops Generator Library will create the Ingress with the hostname
+		genOptions.Route = overlay.hostname
+	}
+
+	//Gitops functions return sanitized error messages
+	err := generator.GenerateOverlaysAndPush(filepath.Dir(repoPath), clone, remote, genOptions, filepath.Base(repoPath), environmentName, overlay.imageName, "", fs, branch, context, false, componentGeneratedResources)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to get generate gitops resources for %s", componentName))
+		return err
+	}
+
+	// Add the environment specific resources that the gitops generator library does not handle to the overlay
+	overlayPath := filepath.Join(repoPath, context, "components", componentName, "overlays", environmentName)
+	baseAutoscaling, err := getComponentAutoscaling(overlay.devfileData, overlay.deployAssociatedComponents)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to get the autoscaling configuration of %s", componentName))
+		return err
+	}
+	autoscalingOverride := overlay.autoscalingOverride
+	var templateAnnotations map[string]string
+	if workload.Kind == devfile.KnativeServiceWorkloadKind && autoscalingOverride != nil {
+		// Knative Services are autoscaled by Knative, the override is applied to the revision template annotations instead of a HorizontalPodAutoscaler
+		autoscaling := *autoscalingOverride
+		if baseAutoscaling != nil {
+			autoscaling = baseAutoscaling.Merge(autoscaling)
+		}
+		if err = autoscaling.Validate(); err == nil {
+			templateAnnotations, err = devfile.GetKnativeAutoscalingAnnotations(autoscaling)
+		}
+		if err != nil {
+			err = fmt.Errorf("invalid autoscaling override for component %s: %v", componentName, err)
+			log.Error(err, "")
+			return err
+		}
+		autoscalingOverride = nil
+	}
+	secretFiles, err := generateSecretsOverlay(fs, overlayPath, overlay.secrets)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to generate the secrets overlay for %s", componentName))
+		return err
+	}
+	workloadFiles, err := generateWorkloadOverlay(fs, overlayPath, workload.Kind, devfile.GetWorkloadMainContainerName(kubernetesResources, workload.Kind), templateAnnotations, overlay.podTemplateLabels)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to generate the %s overlay for %s", workload.Kind, componentName))
+		return err
+	}
+	// the gitops generator library always records the Deployment patch, replace it with the patch of the workload
+	componentGeneratedResources[componentName] = append(util.RemoveString(componentGeneratedResources[componentName], deploymentPatchFileName), workloadFiles...)
+	componentGeneratedResources[componentName] = append(componentGeneratedResources[componentName], secretFiles...)
+
+	var knativeService interface{}
+	if overlay.isEnvironmentKnativeService {
+		knativeService = devfile.GetWorkload(kubernetesResources, devfile.KnativeServiceWorkloadKind)
+	}
+	knativeFiles, err := generateKnativeEnvironmentOverlay(fs, overlayPath, componentName, knativeService, baseAutoscaling != nil)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to generate the Knative Service overlay for %s", componentName))
+		return err
+	}
+	componentGeneratedResources[componentName] = append(componentGeneratedResources[componentName], knativeFiles...)
+
+	overlayFiles, err := generateAutoscalingOverlay(fs, overlayPath, componentName, kubeLabels, workload.Kind, baseAutoscaling, autoscalingOverride)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to generate the autoscaling overlay for %s", componentName))
+		return err
+	}
+	componentGeneratedResources[componentName] = append(componentGeneratedResources[componentName], overlayFiles...)
+
+	baseAvailability, err := getComponentAvailability(overlay.devfileData, overlay.deployAssociatedComponents)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to get the availability configuration of %s", componentName))
+		return err
+	}
+	availabilityFiles, err := generateAvailabilityOverlay(fs, overlayPath, componentName, kubeLabels, workload.Kind, baseAvailability, overlay.availabilityOverride)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to generate the availability overlay for %s", componentName))
+		return err
+	}
+	componentGeneratedResources[componentName] = append(componentGeneratedResources[componentName], availabilityFiles...)
+
+	var networkPolicy *networkingv1.NetworkPolicy
+	componentNetworkPolicy, err := getComponentNetworkPolicy(overlay.devfileData, overlay.deployAssociatedComponents)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to get the network policy configuration of %s", componentName))
+		return err
+	}
+	// a Knative Service receives its traffic through the Knative networking layer, which the NetworkPolicy cannot describe
+	if componentNetworkPolicy != nil && workload.Kind != devfile.KnativeServiceWorkloadKind {
+		publicPorts, internalPorts, err := getComponentEndpointPorts(overlay.devfileData, overlay.deployAssociatedComponents, overlay.component.Spec.TargetPort)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("unable to get the endpoints of %s", componentName))
+			return err
+		}
+		generatedNetworkPolicy := devfile.GenerateNetworkPolicy(componentName, applicationName, kubeLabels, devfile.NetworkPolicyIngress{
+			PublicPorts:           publicPorts,
+			InternalPorts:         internalPorts,
+			RouterNamespaceLabels: getRouterNamespaceLabels(overlay.environment),
+			Dependents:            overlay.networkPolicyDependents,
+		})
+		networkPolicy = &generatedNetworkPolicy
+	}
+	networkPolicyFiles, err := generateNetworkPolicyOverlay(fs, overlayPath, networkPolicy)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to generate the network policy overlay for %s", componentName))
+		return err
+	}
+	componentGeneratedResources[componentName] = append(componentGeneratedResources[componentName], networkPolicyFiles...)
+	return nil
+}
@@ -0,0 +1,252 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/metrics"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const rolledBackConditionType = "RolledBack"
+
+// Rollback pins a SnapshotEnvironmentBinding to an earlier Snapshot, or to a commit of its deployment history
+type Rollback struct {
+	Snapshot string `json:"snapshot,omitempty"`
+	CommitID string `json:"commitID,omitempty"`
+}
+
+// getRollback returns the rollback set on the binding, or nil if the binding is not rolled back
+func getRollback(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) (*Rollback, error) {
+	rollback := Rollback{}
+	if ok, err := getJSONAnnotation(binding, RollbackAnnotation, &rollback); err != nil || !ok {
+		return nil, err
+	}
+	if (rollback.Snapshot == "") == (rollback.CommitID == "") {
+		return nil, fmt.Errorf("the %s annotation on %s must set either a snapshot or a commitID", RollbackAnnotation, binding.Name)
+	}
+	if rollback.CommitID != "" {
+		if err := gitops.ValidateCommitID(rollback.CommitID); err != nil {
+			return nil, fmt.Errorf("the %s annotation on %s is invalid: %v", RollbackAnnotation, binding.Name, err)
+		}
+	}
+	return &rollback, nil
+}
+
+// CompletedRollback is a rollback to a commit that was pushed, with the commit ID that each component of the binding was left at
+type CompletedRollback struct {
+	CommitID         string            `json:"commitID"`
+	ComponentCommits map[string]string `json:"componentCommits"`
+}
+
+// isRollbackCompleted returns true if the binding was already rolled back to the commit, and none of its components was synced since
+func isRollbackCompleted(binding *appstudiov1alpha1.SnapshotEnvironmentBinding, commitID string) (bool, error) {
+	completedRollback := CompletedRollback{}
+	if ok, err := getJSONAnnotation(binding, CompletedRollbackAnnotation, &completedRollback); err != nil || !ok {
+		return false, err
+	}
+	if completedRollback.CommitID != commitID || len(completedRollback.ComponentCommits) != len(binding.Status.Components) {
+		return false, nil
+	}
+	for _, componentStatus := range binding.Status.Components {
+		if completedRollback.ComponentCommits[componentStatus.Name] != componentStatus.GitOpsRepository.CommitID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// getDeploymentHistoryEntry returns the entry of the deployment history of the binding with the given commit ID, which may be abbreviated
+func getDeploymentHistoryEntry(binding *appstudiov1alpha1.SnapshotEnvironmentBinding, commitID string) (DeploymentHistoryEntry, error) {
+	deploymentHistory, err := getDeploymentHistory(binding)
+	if err != nil {
+		return DeploymentHistoryEntry{}, err
+	}
+	for _, entry := range deploymentHistory {
+		if strings.HasPrefix(entry.CommitID, commitID) {
+			return entry, nil
+		}
+	}
+	return DeploymentHistoryEntry{}, fmt.Errorf("commit %s is not in the deployment history of %s", commitID, binding.Name)
+}
+
+// setRollbackCondition sets the condition that reports the rollback of the binding
+func setRollbackCondition(binding *appstudiov1alpha1.SnapshotEnvironmentBinding, message string) {
+	meta.SetStatusCondition(&binding.Status.GitOpsRepoConditions, metav1.Condition{
+		Type:    rolledBackConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "OK",
+		Message: message,
+	})
+}
+
+// rollbackToCommit reverts the environment overlays, or Helm values files, of the components of the binding to their content at a commit of its deployment history,
+// and pushes the revert with one commit per repository and branch. The overlays are only updated by the following syncs once the rollback is
+// removed. The completed rollback is recorded on the binding, so the following reconciles of the pinned binding push nothing
+func (r *SnapshotEnvironmentBindingReconciler) rollbackToCommit(ctx context.Context, req ctrl.Request, binding *appstudiov1alpha1.SnapshotEnvironmentBinding, ghClient *github.GitHubClient, commitID string) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	environmentName := binding.Spec.Environment
+
+	// The rollback stays set on the binding once pushed, it is only pushed again if a component was synced since
+	isCompleted, err := isRollbackCompleted(binding, commitID)
+	if err != nil {
+		log.Error(err, "")
+		r.SetConditionAndUpdateCR(ctx, req, binding, err)
+		return ctrl.Result{}, err
+	} else if isCompleted {
+		log.Info(fmt.Sprintf("%v is already rolled back to commit %s", req.NamespacedName, commitID))
+		return ctrl.Result{}, nil
+	}
+
+	historyEntry, err := getDeploymentHistoryEntry(binding, commitID)
+	if err == nil && isDryRun(binding) {
+		err = fmt.Errorf("the rollback of %s to commit %s cannot be run in dry-run mode", binding.Name, commitID)
+	} else if err == nil && len(binding.Status.Components) == 0 {
+		err = fmt.Errorf("%s has no synced component to roll back", binding.Name)
+	}
+	if err != nil {
+		log.Error(err, "")
+		r.SetConditionAndUpdateCR(ctx, req, binding, err)
+		return ctrl.Result{}, err
+	}
+
+	// The images of the Snapshot are recorded in the deployed state if it still exists
+	snapshotImages := make(map[string]string)
+	snapshot := appstudiov1alpha1.Snapshot{}
+	if err := r.Get(ctx, types.NamespacedName{Name: historyEntry.Snapshot, Namespace: binding.Namespace}, &snapshot); err == nil {
+		for _, snapshotComponent := range snapshot.Spec.Components {
+			snapshotImages[snapshotComponent.Name] = snapshotComponent.ContainerImage
+		}
+	}
+
+	repositoryReverter := r.RepositoryReverter
+	if repositoryReverter == nil {
+		repositoryReverter = gitops.GitRepositoryReverter{}
+	}
+
+	rollbackTime := metav1.Now()
+	deployedComponents := make(map[string]DeployedComponent)
+	var lastCommitID string
+	repositories, repositoryComponents := groupComponentsByRepository(binding.Status.Components)
+	for _, repository := range repositories {
+		var componentNames, paths []string
+		for _, componentStatus := range repositoryComponents[repository] {
+			// Only the resources of the environment are reverted, the Helm chart of a component is shared by every environment
+			environmentPath, err := getComponentEnvironmentPath(componentStatus, environmentName)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("unable to roll back %v to commit %s", req.NamespacedName, commitID))
+				r.SetConditionAndUpdateCR(ctx, req, binding, err)
+				return ctrl.Result{}, err
+			}
+			componentNames = append(componentNames, componentStatus.Name)
+			paths = append(paths, environmentPath)
+		}
+
+		newCommitID, err := r.revertRepository(ghClient, binding, repository, repositoryReverter, commitID, paths,
+			fmt.Sprintf("Roll back %s environment overlays for components %s to commit %s", environmentName, strings.Join(componentNames, ", "), commitID))
+		if err != nil {
+			log.Error(err, fmt.Sprintf("unable to roll back %v to commit %s", req.NamespacedName, commitID))
+			r.SetConditionAndUpdateCR(ctx, req, binding, err)
+			return ctrl.Result{}, err
+		}
+
+		for i := range binding.Status.Components {
+			componentStatus := &binding.Status.Components[i]
+			if componentStatus.GitOpsRepository.URL == repository.url && componentStatus.GitOpsRepository.Branch == repository.branch {
+				componentStatus.GitOpsRepository.CommitID = newCommitID
+				deployedComponents[componentStatus.Name] = DeployedComponent{
+					Snapshot:  historyEntry.Snapshot,
+					Image:     snapshotImages[componentStatus.Name],
+					CommitID:  newCommitID,
+					Timestamp: rollbackTime,
+				}
+			}
+		}
+		lastCommitID = newCommitID
+	}
+
+	setRollbackCondition(binding, fmt.Sprintf("Rolled back to commit %s of the Snapshot %s", commitID, historyEntry.Snapshot))
+	err = r.Client.Status().Update(ctx, binding)
+	if err == nil {
+		err = r.updateDeployedState(ctx, binding, deployedComponents, nil, &DeploymentHistoryEntry{Snapshot: historyEntry.Snapshot, CommitID: lastCommitID, Timestamp: rollbackTime})
+	}
+	if err == nil {
+		completedRollback := CompletedRollback{CommitID: commitID, ComponentCommits: make(map[string]string)}
+		for _, componentStatus := range binding.Status.Components {
+			completedRollback.ComponentCommits[componentStatus.Name] = componentStatus.GitOpsRepository.CommitID
+		}
+		patch := client.MergeFrom(binding.DeepCopy())
+		if err = setJSONAnnotation(binding, CompletedRollbackAnnotation, completedRollback); err == nil {
+			err = r.Patch(ctx, binding, patch)
+		}
+	}
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to record the rollback of %v", req.NamespacedName))
+		r.SetConditionAndUpdateCR(ctx, req, binding, err)
+		return ctrl.Result{}, err
+	}
+
+	r.SetConditionAndUpdateCR(ctx, req, binding, nil)
+
+	log.Info(fmt.Sprintf("Finished rollback reconcile loop for %v", req.NamespacedName))
+	return ctrl.Result{}, nil
+}
+
+// revertRepository clones the GitOps repository, reverts the given paths to the commit, pushes the revert and returns the resulting commit ID
+func (r *SnapshotEnvironmentBindingReconciler) revertRepository(ghClient *github.GitHubClient, binding *appstudiov1alpha1.SnapshotEnvironmentBinding, repository gitOpsRepository, repositoryReverter gitops.RepositoryReverter, commitID string, paths []string, commitMessage string) (string, error) {
+	applicationName := binding.Spec.Application
+	gitOpsRemoteURL, gitOpsBranch, _, err := util.ProcessGitOpsStatus(appstudiov1alpha1.GitOpsStatus{RepositoryURL: repository.url, Branch: repository.branch}, ghClient.Token)
+	if err != nil {
+		return "", err
+	}
+
+	tempDir, err := ioutils.CreateTempPath(binding.Name, r.AppFS)
+	if err != nil {
+		return "", fmt.Errorf("unable to create temp directory for gitops resources due to error: %v", err)
+	}
+	defer func() {
+		_ = r.AppFS.RemoveAll(tempDir) // not worried with an err, its a best case attempt to delete the temp clone dir
+	}()
+	repoPath := filepath.Join(tempDir, applicationName)
+
+	metrics.ControllerGitRequest.With(prometheus.Labels{"controller": asebName, "tokenName": ghClient.TokenName, "operation": "CloneRepo"}).Inc()
+	if err := r.Generator.CloneRepo(tempDir, gitOpsRemoteURL, applicationName, gitOpsBranch); err != nil {
+		return "", err
+	}
+	if err := repositoryReverter.RevertPaths(r.AppFS, repoPath, commitID, paths); err != nil {
+		return "", err
+	}
+	metrics.ControllerGitRequest.With(prometheus.Labels{"controller": asebName, "tokenName": ghClient.TokenName, "operation": "CommitAndPush"}).Inc()
+	if err := r.Generator.CommitAndPush(tempDir, applicationName, gitOpsRemoteURL, binding.Name, gitOpsBranch, commitMessage); err != nil {
+		return "", err
+	}
+	metrics.ControllerGitRequest.With(prometheus.Labels{"controller": asebName, "tokenName": ghClient.TokenName, "operation": "GetCommitIDFromRepo"}).Inc()
+	return r.Generator.GetCommitIDFromRepo(r.AppFS, repoPath)
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGetRollback(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *Rollback
+		wantErr     bool
+	}{
+		{
+			name: "No rollback",
+		},
+		{
+			name:        "Rollback to a Snapshot",
+			annotations: map[string]string{RollbackAnnotation: `{"snapshot": "snapshot-1"}`},
+			want:        &Rollback{Snapshot: "snapshot-1"},
+		},
+		{
+			name:        "Rollback to a commit",
+			annotations: map[string]string{RollbackAnnotation: `{"commitID": "ca82a6d"}`},
+			want:        &Rollback{CommitID: "ca82a6d"},
+		},
+		{
+			name:        "Rollback to both a Snapshot and a commit",
+			annotations: map[string]string{RollbackAnnotation: `{"snapshot": "snapshot-1", "commitID": "ca82a6d"}`},
+			wantErr:     true,
+		},
+		{
+			name:        "Empty rollback",
+			annotations: map[string]string{RollbackAnnotation: `{}`},
+			wantErr:     true,
+		},
+		{
+			name:        "Invalid commit ID",
+			annotations: map[string]string{RollbackAnnotation: `{"commitID": "HEAD~1"}`},
+			wantErr:     true,
+		},
+		{
+			name:        "Invalid JSON",
+			annotations: map[string]string{RollbackAnnotation: `{"snapshot":`},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &appstudiov1alpha1.SnapshotEnvironmentBinding{ObjectMeta: metav1.ObjectMeta{Name: "test-binding", Annotations: tt.annotations}}
+			rollback, err := getRollback(binding)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, rollback)
+			}
+		})
+	}
+}
+
+func TestAddDeploymentHistoryEntry(t *testing.T) {
+	timestamp := metav1.NewTime(time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC).Local())
+	entry := func(i int) DeploymentHistoryEntry {
+		return DeploymentHistoryEntry{Snapshot: fmt.Sprintf("snapshot-%d", i), CommitID: fmt.Sprintf("commit-%d", i), Timestamp: timestamp}
+	}
+	history := func(from, to int) []DeploymentHistoryEntry {
+		var entries []DeploymentHistoryEntry
+		for i := from; i >= to; i-- {
+			entries = append(entries, entry(i))
+		}
+		return entries
+	}
+
+	tests := []struct {
+		name    string
+		history []DeploymentHistoryEntry
+		entry   DeploymentHistoryEntry
+		want    []DeploymentHistoryEntry
+	}{
+		{
+			name:  "First sync",
+			entry: entry(1),
+			want:  history(1, 1),
+		},
+		{
+			name:    "New sync is added first",
+			history: history(2, 1),
+			entry:   entry(3),
+			want:    history(3, 1),
+		},
+		{
+			name:    "Sync without a new commit is not recorded",
+			history: history(2, 1),
+			entry:   entry(2),
+			want:    history(2, 1),
+		},
+		{
+			name:    "Oldest sync is dropped",
+			history: history(maxDeploymentHistory, 1),
+			entry:   entry(maxDeploymentHistory + 1),
+			want:    history(maxDeploymentHistory+1, 2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &appstudiov1alpha1.SnapshotEnvironmentBinding{}
+			if tt.history != nil {
+				assert.NoError(t, setJSONAnnotation(binding, DeploymentHistoryAnnotation, tt.history))
+			}
+			assert.NoError(t, addDeploymentHistoryEntry(binding, tt.entry))
+
+			deploymentHistory, err := getDeploymentHistory(binding)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.want, deploymentHistory)
+		})
+	}
+}
+
+// recordingRepositoryReverter records the paths it reverts, and validates the commit ID like the mock reverter
+type recordingRepositoryReverter struct {
+	paths *[]string
+}
+
+// RevertPaths records the paths and returns an error for an invalid commit ID
+func (m recordingRepositoryReverter) RevertPaths(fs afero.Afero, repoPath string, commitID string, paths []string) error {
+	*m.paths = append(*m.paths, paths...)
+	return gitops.MockRepositoryReverter{}.RevertPaths(fs, repoPath, commitID, paths)
+}
+
+func TestRollbackToCommit(t *testing.T) {
+	bindingLookupKey := types.NamespacedName{Name: "test-binding", Namespace: "default"}
+	// the commit ID returned by the mock generator
+	rollbackCommitID := "ca82a6dff817ec66f44342007202690a93763949"
+	deploymentHistory := `[{"snapshot":"snapshot-2","commitID":"2222222222222222222222222222222222222222","timestamp":"2023-05-02T12:00:00Z"},` +
+		`{"snapshot":"snapshot-1","commitID":"1111111111111111111111111111111111111111","timestamp":"2023-05-01T12:00:00Z"}]`
+
+	componentStatus := func(name string, commitID string) appstudiov1alpha1.BindingComponentStatus {
+		return appstudiov1alpha1.BindingComponentStatus{
+			Name: name,
+			GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{
+				URL:      "https://github.com/org/repo",
+				Branch:   "main",
+				Path:     "components/" + name + "/overlays/staging",
+				CommitID: commitID,
+			},
+		}
+	}
+
+	helmComponentStatus := func(name string, commitID string) appstudiov1alpha1.BindingComponentStatus {
+		status := componentStatus(name, commitID)
+		status.GitOpsRepository.Path = "components/" + name + "/chart"
+		return status
+	}
+
+	tests := []struct {
+		name              string
+		annotations       map[string]string
+		componentStatuses []appstudiov1alpha1.BindingComponentStatus
+		commitID          string
+		wantErr           bool
+		wantPaths         []string
+		// wantSkipped is true if the rollback is already completed, and nothing is pushed
+		wantSkipped bool
+	}{
+		{
+			name:              "Rollback to a commit of the history",
+			annotations:       map[string]string{DeploymentHistoryAnnotation: deploymentHistory},
+			componentStatuses: []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a", "2222222222222222222222222222222222222222")},
+			commitID:          "1111111",
+			wantPaths:         []string{"components/component-a/overlays/staging"},
+		},
+		{
+			name:        "Rollback of a Helm chart only reverts the values file of the environment",
+			annotations: map[string]string{DeploymentHistoryAnnotation: deploymentHistory},
+			componentStatuses: []appstudiov1alpha1.BindingComponentStatus{
+				helmComponentStatus("component-a", "2222222222222222222222222222222222222222"),
+			},
+			commitID:  "1111111",
+			wantPaths: []string{"components/component-a/chart/values-staging.yaml"},
+		},
+		{
+			name: "Rollback already completed",
+			annotations: map[string]string{
+				DeploymentHistoryAnnotation: deploymentHistory,
+				CompletedRollbackAnnotation: `{"commitID":"1111111","componentCommits":{"component-a":"2222222222222222222222222222222222222222"}}`,
+			},
+			componentStatuses: []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a", "2222222222222222222222222222222222222222")},
+			commitID:          "1111111",
+			wantSkipped:       true,
+		},
+		{
+			name: "Component synced since the completed rollback",
+			annotations: map[string]string{
+				DeploymentHistoryAnnotation: deploymentHistory,
+				CompletedRollbackAnnotation: `{"commitID":"1111111","componentCommits":{"component-a":"1111111111111111111111111111111111111111"}}`,
+			},
+			componentStatuses: []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a", "2222222222222222222222222222222222222222")},
+			commitID:          "1111111",
+			wantPaths:         []string{"components/component-a/overlays/staging"},
+		},
+		{
+			name:              "Commit that is not in the history",
+			annotations:       map[string]string{DeploymentHistoryAnnotation: deploymentHistory},
+			componentStatuses: []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a", "2222222222222222222222222222222222222222")},
+			commitID:          "3333333",
+			wantErr:           true,
+		},
+		{
+			name:        "Binding without synced components",
+			annotations: map[string]string{DeploymentHistoryAnnotation: deploymentHistory},
+			commitID:    "1111111",
+			wantErr:     true,
+		},
+		{
+			name:              "Rollback in dry-run mode",
+			annotations:       map[string]string{DeploymentHistoryAnnotation: deploymentHistory, DryRunAnnotation: "true"},
+			componentStatuses: []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a", "2222222222222222222222222222222222222222")},
+			commitID:          "1111111",
+			wantErr:           true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &appstudiov1alpha1.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        bindingLookupKey.Name,
+					Namespace:   bindingLookupKey.Namespace,
+					Annotations: tt.annotations,
+				},
+				Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+					Application: "test-app",
+					Environment: "staging",
+				},
+				Status: appstudiov1alpha1.SnapshotEnvironmentBindingStatus{
+					Components: tt.componentStatuses,
+				},
+			}
+			snapshot := &appstudiov1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "snapshot-1",
+					Namespace: bindingLookupKey.Namespace,
+				},
+				Spec: appstudiov1alpha1.SnapshotSpec{
+					Application: "test-app",
+					Components:  []appstudiov1alpha1.SnapshotComponent{{Name: "component-a", ContainerImage: "quay.io/org/a:1"}},
+				},
+			}
+
+			fakeClient := NewFakeClient(t, binding, snapshot)
+			var revertedPaths []string
+			r := &SnapshotEnvironmentBindingReconciler{
+				Client:             fakeClient,
+				Log:                ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
+				AppFS:              ioutils.NewMemoryFilesystem(),
+				Generator:          gitops.NewMockGenerator(),
+				RepositoryReverter: recordingRepositoryReverter{paths: &revertedPaths},
+			}
+
+			_, err := r.rollbackToCommit(context.Background(), ctrl.Request{NamespacedName: bindingLookupKey}, binding, &github.GitHubClient{Token: "token"}, tt.commitID)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			}
+
+			updatedBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{}
+			if err := fakeClient.Get(context.Background(), bindingLookupKey, updatedBinding); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			condition := meta.FindStatusCondition(updatedBinding.Status.GitOpsRepoConditions, rolledBackConditionType)
+			assert.Equal(t, tt.wantPaths, revertedPaths)
+			if tt.wantSkipped {
+				assert.Nil(t, condition)
+				assert.Equal(t, tt.componentStatuses, updatedBinding.Status.Components)
+				assert.Equal(t, tt.annotations, updatedBinding.Annotations)
+				return
+			}
+			if tt.wantErr {
+				assert.Nil(t, condition)
+				return
+			}
+
+			if assert.NotNil(t, condition) {
+				assert.Equal(t, "Rolled back to commit 1111111 of the Snapshot snapshot-1", condition.Message)
+			}
+			assert.Equal(t, rollbackCommitID, updatedBinding.Status.Components[0].GitOpsRepository.CommitID)
+
+			history, err := getDeploymentHistory(updatedBinding)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			if assert.Len(t, history, 3) {
+				assert.Equal(t, "snapshot-1", history[0].Snapshot)
+				assert.Equal(t, rollbackCommitID, history[0].CommitID)
+			}
+
+			deployedState, err := getDeployedState(updatedBinding)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, "snapshot-1", deployedState["component-a"].Snapshot)
+			assert.Equal(t, "quay.io/org/a:1", deployedState["component-a"].Image)
+
+			isCompleted, err := isRollbackCompleted(updatedBinding, tt.commitID)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.True(t, isCompleted, "expected the rollback to be recorded as completed")
+		})
+	}
+}
@@ -0,0 +1,269 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/spf13/afero"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	sealedSecretKind         = "SealedSecret"
+	sealedSecretAPIVersion   = "bitnami.com/v1alpha1"
+	externalSecretKind       = "ExternalSecret"
+	externalSecretAPIGroup   = "external-secrets.io/"
+	sealedSecretFilePrefix   = "sealedsecret-"
+	externalSecretFilePrefix = "externalsecret-"
+)
+
+// SecretEnvVar is an env var of a component whose value is read from a key of a Secret of the target namespace
+type SecretEnvVar struct {
+	Name         string                   `json:"name"`
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// SecretReferences are the Secrets that the components of an environment reference. The values of the Secrets are never written in the
+// GitOps repository: env vars reference the keys of existing Secrets, SealedSecrets only hold encrypted data, and ExternalSecrets
+// only reference the keys of an external secret store
+type SecretReferences struct {
+	Env             []SecretEnvVar              `json:"env,omitempty"`
+	SealedSecrets   []unstructured.Unstructured `json:"sealedSecrets,omitempty"`
+	ExternalSecrets []unstructured.Unstructured `json:"externalSecrets,omitempty"`
+}
+
+// Merge returns the secret references with the given references merged on top: env vars are merged by name,
+// SealedSecrets and ExternalSecrets by resource name
+func (s SecretReferences) Merge(override SecretReferences) SecretReferences {
+	return SecretReferences{
+		Env:             mergeSecretEnv(s.Env, override.Env),
+		SealedSecrets:   mergeSecretResources(s.SealedSecrets, override.SealedSecrets),
+		ExternalSecrets: mergeSecretResources(s.ExternalSecrets, override.ExternalSecrets),
+	}
+}
+
+// Validate returns an error if a secret reference is incomplete, or if a SealedSecret or an ExternalSecret would write plaintext values
+func (s SecretReferences) Validate() error {
+	for _, env := range s.Env {
+		if env.Name == "" || env.SecretKeyRef.Name == "" || env.SecretKeyRef.Key == "" {
+			return fmt.Errorf("the secret env var %q must set a name and the name and key of its secretKeyRef", env.Name)
+		}
+	}
+	for _, sealedSecret := range s.SealedSecrets {
+		if err := validateSecretResource(sealedSecret, sealedSecretKind); err != nil {
+			return err
+		}
+		if sealedSecret.GetAPIVersion() != sealedSecretAPIVersion {
+			return fmt.Errorf("the SealedSecret %s must have the %s apiVersion", sealedSecret.GetName(), sealedSecretAPIVersion)
+		}
+		if encryptedData, _, _ := unstructured.NestedMap(sealedSecret.Object, "spec", "encryptedData"); len(encryptedData) == 0 {
+			return fmt.Errorf("the SealedSecret %s must set spec.encryptedData", sealedSecret.GetName())
+		}
+		for _, field := range []string{"data", "stringData"} {
+			if _, found, _ := unstructured.NestedFieldNoCopy(sealedSecret.Object, "spec", "template", field); found {
+				return fmt.Errorf("the SealedSecret %s must not set spec.template.%s, it would write plaintext values in the GitOps repository", sealedSecret.GetName(), field)
+			}
+		}
+	}
+	for _, externalSecret := range s.ExternalSecrets {
+		if err := validateSecretResource(externalSecret, externalSecretKind); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(externalSecret.GetAPIVersion(), externalSecretAPIGroup) {
+			return fmt.Errorf("the ExternalSecret %s must have an apiVersion of the %s group", externalSecret.GetName(), strings.TrimSuffix(externalSecretAPIGroup, "/"))
+		}
+		_, hasData, _ := unstructured.NestedFieldNoCopy(externalSecret.Object, "spec", "data")
+		_, hasDataFrom, _ := unstructured.NestedFieldNoCopy(externalSecret.Object, "spec", "dataFrom")
+		if !hasData && !hasDataFrom {
+			return fmt.Errorf("the ExternalSecret %s must set spec.data or spec.dataFrom", externalSecret.GetName())
+		}
+	}
+	return nil
+}
+
+// validateSecretResource returns an error if the resource is not of the given kind, or if its name cannot be used as a file name
+func validateSecretResource(resource unstructured.Unstructured, kind string) error {
+	if resource.GetKind() != kind {
+		return fmt.Errorf("a resource of kind %q is not a %s", resource.GetKind(), kind)
+	}
+	if errs := validation.IsDNS1123Subdomain(resource.GetName()); len(errs) > 0 {
+		return fmt.Errorf("invalid %s name %q: %s", kind, resource.GetName(), strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func mergeSecretEnv(env []SecretEnvVar, override []SecretEnvVar) []SecretEnvVar {
+	merged := append([]SecretEnvVar{}, env...)
+	for _, overrideEnv := range override {
+		isPresent := false
+		for i := range merged {
+			if merged[i].Name == overrideEnv.Name {
+				merged[i] = overrideEnv
+				isPresent = true
+				break
+			}
+		}
+		if !isPresent {
+			merged = append(merged, overrideEnv)
+		}
+	}
+	return merged
+}
+
+func mergeSecretResources(resources []unstructured.Unstructured, override []unstructured.Unstructured) []unstructured.Unstructured {
+	merged := append([]unstructured.Unstructured{}, resources...)
+	for _, overrideResource := range override {
+		isPresent := false
+		for i := range merged {
+			if merged[i].GetName() == overrideResource.GetName() {
+				merged[i] = overrideResource
+				isPresent = true
+				break
+			}
+		}
+		if !isPresent {
+			merged = append(merged, overrideResource)
+		}
+	}
+	return merged
+}
+
+// getEnvironmentSecrets returns the secret references that the Environment sets for all of its components
+func getEnvironmentSecrets(environment *appstudiov1alpha1.Environment) (SecretReferences, error) {
+	var secrets SecretReferences
+	if _, err := getJSONAnnotation(environment, SecretsAnnotation, &secrets); err != nil {
+		return secrets, err
+	}
+	if err := secrets.Validate(); err != nil {
+		return secrets, fmt.Errorf("invalid %s annotation on Environment %s: %v", SecretsAnnotation, environment.Name, err)
+	}
+	return secrets, nil
+}
+
+// getSecretOverrides returns the per-component secret references set on the binding
+func getSecretOverrides(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) (map[string]SecretReferences, error) {
+	overrides := make(map[string]SecretReferences)
+	if _, err := getJSONAnnotation(binding, SecretOverridesAnnotation, &overrides); err != nil {
+		return nil, err
+	}
+	for componentName, secrets := range overrides {
+		if err := secrets.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation on %s for component %s: %v", SecretOverridesAnnotation, binding.Name, componentName, err)
+		}
+	}
+	return overrides, nil
+}
+
+// getSecretEnvVars converts the secret env vars into env vars that read their value from the Secrets
+func getSecretEnvVars(secretEnv []SecretEnvVar) []corev1.EnvVar {
+	envVars := make([]corev1.EnvVar, 0, len(secretEnv))
+	for _, env := range secretEnv {
+		secretKeyRef := env.SecretKeyRef
+		envVars = append(envVars, corev1.EnvVar{
+			Name:      env.Name,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &secretKeyRef},
+		})
+	}
+	return envVars
+}
+
+// setEnvVars replaces the env vars of the same name with the given env vars, and appends the others
+func setEnvVars(envVars []corev1.EnvVar, overrides []corev1.EnvVar) []corev1.EnvVar {
+	for _, override := range overrides {
+		isPresent := false
+		for i := range envVars {
+			if envVars[i].Name == override.Name {
+				envVars[i] = override
+				isPresent = true
+				break
+			}
+		}
+		if !isPresent {
+			envVars = append(envVars, override)
+		}
+	}
+	return envVars
+}
+
+// generateSecretsOverlay adds the secret env vars to the main container of the Deployment patch written by the gitops generator library,
+// which only writes plain env values, and writes the SealedSecrets and ExternalSecrets into the overlay folder. The SealedSecrets and
+// ExternalSecrets that are no longer referenced are removed. The names of the files that are part of the overlay are returned
+func generateSecretsOverlay(fs afero.Afero, overlayPath string, secrets SecretReferences) ([]string, error) {
+	if len(secrets.Env) > 0 {
+		deploymentPatchPath := filepath.Join(overlayPath, deploymentPatchFileName)
+		deploymentPatchBytes, err := fs.ReadFile(deploymentPatchPath)
+		if err != nil {
+			return nil, err
+		}
+		var deploymentPatch appsv1.Deployment
+		if err := yaml.Unmarshal(deploymentPatchBytes, &deploymentPatch); err != nil {
+			return nil, err
+		}
+		if len(deploymentPatch.Spec.Template.Spec.Containers) == 0 {
+			return nil, fmt.Errorf("the %s overlay patch does not have a container to add the secret env vars to", deploymentPatchFileName)
+		}
+		container := &deploymentPatch.Spec.Template.Spec.Containers[0]
+		container.Env = setEnvVars(container.Env, getSecretEnvVars(secrets.Env))
+		if err := gitops.AddOverlayPatch(fs, overlayPath, deploymentPatchFileName, deploymentPatch); err != nil {
+			return nil, err
+		}
+	}
+
+	var overlayFiles []string
+	currentFiles := make(map[string]bool)
+	for _, secretResources := range []struct {
+		prefix    string
+		resources []unstructured.Unstructured
+	}{
+		{prefix: sealedSecretFilePrefix, resources: secrets.SealedSecrets},
+		{prefix: externalSecretFilePrefix, resources: secrets.ExternalSecrets},
+	} {
+		for _, resource := range secretResources.resources {
+			fileName := secretResources.prefix + resource.GetName() + ".yaml"
+			if err := gitops.AddOverlayResource(fs, overlayPath, fileName, resource.Object); err != nil {
+				return nil, err
+			}
+			currentFiles[fileName] = true
+			overlayFiles = append(overlayFiles, fileName)
+		}
+	}
+
+	files, err := fs.ReadDir(overlayPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		fileName := file.Name()
+		isSecretFile := strings.HasPrefix(fileName, sealedSecretFilePrefix) || strings.HasPrefix(fileName, externalSecretFilePrefix)
+		if isSecretFile && !currentFiles[fileName] {
+			if err := gitops.RemoveOverlayFile(fs, overlayPath, fileName); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Strings(overlayFiles)
+	return overlayFiles, nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/redhat-developer/gitops-generator/pkg/resources"
+	"github.com/redhat-developer/gitops-generator/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	testSealedSecret   = `{"apiVersion": "bitnami.com/v1alpha1", "kind": "SealedSecret", "metadata": {"name": "db"}, "spec": {"encryptedData": {"password": "AgBy3i4OJSWK+PiTySYZZA=="}}}`
+	testExternalSecret = `{"apiVersion": "external-secrets.io/v1beta1", "kind": "ExternalSecret", "metadata": {"name": "api"}, "spec": {"secretStoreRef": {"name": "vault"}, "data": [{"secretKey": "token", "remoteRef": {"key": "api/token"}}]}}`
+)
+
+func TestGetEnvironmentSecrets(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       SecretReferences
+		wantErr    string
+	}{
+		{
+			name: "No secrets annotation",
+		},
+		{
+			name:       "Secret env vars, SealedSecrets and ExternalSecrets",
+			annotation: `{"env": [{"name": "DB_PASSWORD", "secretKeyRef": {"name": "db", "key": "password"}}], "sealedSecrets": [` + testSealedSecret + `], "externalSecrets": [` + testExternalSecret + `]}`,
+			want: SecretReferences{
+				Env:             []SecretEnvVar{{Name: "DB_PASSWORD", SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}}},
+				SealedSecrets:   []unstructured.Unstructured{testUnstructured(t, testSealedSecret)},
+				ExternalSecrets: []unstructured.Unstructured{testUnstructured(t, testExternalSecret)},
+			},
+		},
+		{
+			name:       "Secret env var without a key",
+			annotation: `{"env": [{"name": "DB_PASSWORD", "secretKeyRef": {"name": "db"}}]}`,
+			wantErr:    "the secret env var \"DB_PASSWORD\" must set a name and the name and key of its secretKeyRef",
+		},
+		{
+			name:       "Plain Secret instead of a SealedSecret",
+			annotation: `{"sealedSecrets": [{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "db"}, "stringData": {"password": "hunter2"}}]}`,
+			wantErr:    "a resource of kind \"Secret\" is not a SealedSecret",
+		},
+		{
+			name:       "SealedSecret with a plaintext template",
+			annotation: `{"sealedSecrets": [{"apiVersion": "bitnami.com/v1alpha1", "kind": "SealedSecret", "metadata": {"name": "db"}, "spec": {"encryptedData": {"password": "AgBy"}, "template": {"stringData": {"user": "admin"}}}}]}`,
+			wantErr:    "must not set spec.template.stringData",
+		},
+		{
+			name:       "SealedSecret without encrypted data",
+			annotation: `{"sealedSecrets": [{"apiVersion": "bitnami.com/v1alpha1", "kind": "SealedSecret", "metadata": {"name": "db"}, "spec": {}}]}`,
+			wantErr:    "the SealedSecret db must set spec.encryptedData",
+		},
+		{
+			name:       "ExternalSecret with an invalid name",
+			annotation: `{"externalSecrets": [{"apiVersion": "external-secrets.io/v1beta1", "kind": "ExternalSecret", "metadata": {"name": "../api"}, "spec": {"data": []}}]}`,
+			wantErr:    "invalid ExternalSecret name \"../api\"",
+		},
+		{
+			name:       "ExternalSecret without data",
+			annotation: `{"externalSecrets": [{"apiVersion": "external-secrets.io/v1beta1", "kind": "ExternalSecret", "metadata": {"name": "api"}, "spec": {}}]}`,
+			wantErr:    "the ExternalSecret api must set spec.data or spec.dataFrom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environment := &appstudiov1alpha1.Environment{ObjectMeta: metav1.ObjectMeta{Name: "staging"}}
+			if tt.annotation != "" {
+				environment.Annotations = map[string]string{SecretsAnnotation: tt.annotation}
+			}
+
+			secrets, err := getEnvironmentSecrets(environment)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.want, secrets)
+		})
+	}
+}
+
+func TestSecretReferencesMerge(t *testing.T) {
+	secretEnv := func(name, secretName string) SecretEnvVar {
+		return SecretEnvVar{Name: name, SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: "key"}}
+	}
+	sealedSecret := testUnstructured(t, testSealedSecret)
+	overrideSealedSecret := sealedSecret.DeepCopy()
+	overrideSealedSecret.SetNamespace("override")
+
+	environmentSecrets := SecretReferences{
+		Env:           []SecretEnvVar{secretEnv("A", "environment"), secretEnv("B", "environment")},
+		SealedSecrets: []unstructured.Unstructured{sealedSecret},
+	}
+	merged := environmentSecrets.Merge(SecretReferences{
+		Env:             []SecretEnvVar{secretEnv("B", "component"), secretEnv("C", "component")},
+		SealedSecrets:   []unstructured.Unstructured{*overrideSealedSecret},
+		ExternalSecrets: []unstructured.Unstructured{testUnstructured(t, testExternalSecret)},
+	})
+
+	assert.Equal(t, []SecretEnvVar{secretEnv("A", "environment"), secretEnv("B", "component"), secretEnv("C", "component")}, merged.Env)
+	assert.Equal(t, []unstructured.Unstructured{*overrideSealedSecret}, merged.SealedSecrets)
+	assert.Equal(t, []unstructured.Unstructured{testUnstructured(t, testExternalSecret)}, merged.ExternalSecrets)
+	// the merged references do not change the environment references
+	assert.Equal(t, []SecretEnvVar{secretEnv("A", "environment"), secretEnv("B", "environment")}, environmentSecrets.Env)
+}
+
+func TestGenerateSecretsOverlay(t *testing.T) {
+	overlayPath := "/tmp/app/components/component-a/overlays/staging"
+	fs := ioutils.NewMemoryFilesystem()
+	deploymentPatch := appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "component-a"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "container-image",
+							Image: "quay.io/org/component-a:1",
+							Env:   []corev1.EnvVar{{Name: "DB_USER", Value: "admin"}, {Name: "DB_PASSWORD", Value: "changeme"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := gitops.AddOverlayPatch(fs, overlayPath, deploymentPatchFileName, deploymentPatch); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+
+	secrets := SecretReferences{
+		Env:             []SecretEnvVar{{Name: "DB_PASSWORD", SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}}},
+		SealedSecrets:   []unstructured.Unstructured{testUnstructured(t, testSealedSecret)},
+		ExternalSecrets: []unstructured.Unstructured{testUnstructured(t, testExternalSecret)},
+	}
+	files, err := generateSecretsOverlay(fs, overlayPath, secrets)
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, []string{"externalsecret-api.yaml", "sealedsecret-db.yaml"}, files)
+
+	var k resources.Kustomization
+	if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, "kustomization.yaml"), &k); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, []string{"externalsecret-api.yaml", "sealedsecret-db.yaml"}, k.Resources)
+	assert.Equal(t, []string{deploymentPatchFileName}, k.Patches)
+
+	var generatedPatch appsv1.Deployment
+	if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, deploymentPatchFileName), &generatedPatch); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "DB_USER", Value: "admin"},
+		{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}}},
+	}, generatedPatch.Spec.Template.Spec.Containers[0].Env)
+
+	// no secret value is written in the overlay
+	for _, fileName := range append(files, deploymentPatchFileName) {
+		content, err := fs.ReadFile(filepath.Join(overlayPath, fileName))
+		if err != nil {
+			t.Fatalf("got unexpected error %v", err)
+		}
+		assert.False(t, strings.Contains(string(content), "changeme"), "expected no plaintext secret value in %s", fileName)
+	}
+
+	// the SealedSecrets and ExternalSecrets that are no longer referenced are removed
+	files, err = generateSecretsOverlay(fs, overlayPath, SecretReferences{SealedSecrets: secrets.SealedSecrets})
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, []string{"sealedsecret-db.yaml"}, files)
+	exist, _ := fs.Exists(filepath.Join(overlayPath, "externalsecret-api.yaml"))
+	assert.False(t, exist, "expected the ExternalSecret to be removed")
+	k = resources.Kustomization{}
+	if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, "kustomization.yaml"), &k); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, []string{"sealedsecret-db.yaml"}, k.Resources)
+}
+
+// testUnstructured returns the unstructured resource of the JSON manifest
+func testUnstructured(t *testing.T, manifest string) unstructured.Unstructured {
+	var resource unstructured.Unstructured
+	if err := resource.UnmarshalJSON([]byte(manifest)); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	return resource
+}
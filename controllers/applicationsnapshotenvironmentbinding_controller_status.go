@@ -0,0 +1,128 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeployedComponent is the deployed state of a Component of a SnapshotEnvironmentBinding: the Snapshot and the image
+// that were rendered in its environment overlay, the commit that was pushed and when it was pushed
+type DeployedComponent struct {
+	Snapshot  string      `json:"snapshot"`
+	Image     string      `json:"image"`
+	CommitID  string      `json:"commitID"`
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// setComponentStatus replaces the status entry of the component with the given entry, or appends it if the component has no entry yet
+func setComponentStatus(componentStatuses []appstudiov1alpha1.BindingComponentStatus, componentStatus appstudiov1alpha1.BindingComponentStatus) []appstudiov1alpha1.BindingComponentStatus {
+	for i := range componentStatuses {
+		if componentStatuses[i].Name == componentStatus.Name {
+			componentStatuses[i] = componentStatus
+			return componentStatuses
+		}
+	}
+	return append(componentStatuses, componentStatus)
+}
+
+// getDeployedState returns the deployed state recorded on the binding, keyed by Component name
+func getDeployedState(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) (map[string]DeployedComponent, error) {
+	deployedState := make(map[string]DeployedComponent)
+	if _, err := getJSONAnnotation(binding, DeployedStateAnnotation, &deployedState); err != nil {
+		return nil, err
+	}
+	return deployedState, nil
+}
+
+// DeploymentHistoryEntry is a sync of a SnapshotEnvironmentBinding in its deployment history: the Snapshot that was rendered,
+// the commit that was pushed and when it was pushed
+type DeploymentHistoryEntry struct {
+	Snapshot  string      `json:"snapshot"`
+	CommitID  string      `json:"commitID"`
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// maxDeploymentHistory bounds the number of entries of the deployment history of a binding
+const maxDeploymentHistory = 10
+
+// getDeploymentHistory returns the deployment history recorded on the binding, the most recent entry first
+func getDeploymentHistory(binding *appstudiov1alpha1.SnapshotEnvironmentBinding) ([]DeploymentHistoryEntry, error) {
+	var deploymentHistory []DeploymentHistoryEntry
+	if _, err := getJSONAnnotation(binding, DeploymentHistoryAnnotation, &deploymentHistory); err != nil {
+		return nil, err
+	}
+	return deploymentHistory, nil
+}
+
+// addDeploymentHistoryEntry adds the entry at the top of the deployment history of the binding, and drops the oldest entries beyond
+// maxDeploymentHistory. A sync that did not push a new commit for the same Snapshot is not recorded again
+func addDeploymentHistoryEntry(binding *appstudiov1alpha1.SnapshotEnvironmentBinding, entry DeploymentHistoryEntry) error {
+	deploymentHistory, err := getDeploymentHistory(binding)
+	if err != nil {
+		deploymentHistory = nil
+	}
+	if len(deploymentHistory) > 0 && deploymentHistory[0].Snapshot == entry.Snapshot && deploymentHistory[0].CommitID == entry.CommitID {
+		return nil
+	}
+
+	deploymentHistory = append([]DeploymentHistoryEntry{entry}, deploymentHistory...)
+	if len(deploymentHistory) > maxDeploymentHistory {
+		deploymentHistory = deploymentHistory[:maxDeploymentHistory]
+	}
+	return setJSONAnnotation(binding, DeploymentHistoryAnnotation, deploymentHistory)
+}
+
+// setDeployedState records the deployed state of the synced components on the binding, and drops the state of the removed components.
+// The state of the other components is kept. An invalid annotation is replaced, since it is only written by the controller
+func setDeployedState(binding *appstudiov1alpha1.SnapshotEnvironmentBinding, deployedComponents map[string]DeployedComponent, removedComponents []appstudiov1alpha1.BindingComponentStatus) error {
+	deployedState, err := getDeployedState(binding)
+	if err != nil {
+		deployedState = make(map[string]DeployedComponent)
+	}
+	for componentName, deployedComponent := range deployedComponents {
+		deployedState[componentName] = deployedComponent
+	}
+	for _, removedComponent := range removedComponents {
+		delete(deployedState, removedComponent.Name)
+	}
+	return setJSONAnnotation(binding, DeployedStateAnnotation, deployedState)
+}
+
+// updateDeployedState records the deployed state of the synced and removed components on the binding, and adds the sync to the
+// deployment history if an entry is given
+func (r *SnapshotEnvironmentBindingReconciler) updateDeployedState(ctx context.Context, binding *appstudiov1alpha1.SnapshotEnvironmentBinding, deployedComponents map[string]DeployedComponent, removedComponents []appstudiov1alpha1.BindingComponentStatus, historyEntry *DeploymentHistoryEntry) error {
+	if len(deployedComponents) == 0 && len(removedComponents) == 0 && historyEntry == nil {
+		return nil
+	}
+
+	patch := client.MergeFrom(binding.DeepCopy())
+	if len(deployedComponents) > 0 || len(removedComponents) > 0 {
+		if err := setDeployedState(binding, deployedComponents, removedComponents); err != nil {
+			return err
+		}
+	}
+	if historyEntry != nil {
+		if err := addDeploymentHistoryEntry(binding, *historyEntry); err != nil {
+			return err
+		}
+	}
+	return r.Patch(ctx, binding, patch)
+}
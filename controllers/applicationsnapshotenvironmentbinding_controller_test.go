@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/gomega"
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -172,10 +173,26 @@ var _ = Describe("SnapshotEnvironmentBinding controller", func() {
 			Expect(createdBinding.Status.Components[0].GitOpsRepository.URL).Should(Equal(hasComp.Status.GitOps.RepositoryURL))
 			Expect(createdBinding.Status.Components[0].GitOpsRepository.CommitID).Should(Equal("ca82a6dff817ec66f44342007202690a93763949"))
 			bindingLabels := createdBinding.GetLabels()
-			// If no prior labels exist, SEB controllers should only add 2 label entries
-			Expect(len(bindingLabels)).Should(Equal(2))
+			// If no prior labels exist, SEB controllers should only add 3 label entries
+			Expect(len(bindingLabels)).Should(Equal(3))
 			Expect(bindingLabels["appstudio.application"]).Should(Equal(applicationName))
 			Expect(bindingLabels["appstudio.environment"]).Should(Equal(environmentName))
+			Expect(bindingLabels["appstudio.snapshot"]).Should(Equal(snapshotName))
+
+			// the deployed state records the snapshot and the image rendered for the component
+			deployedState, err := getDeployedState(createdBinding)
+			Expect(err).Should(BeNil())
+			Expect(deployedState[componentName].Snapshot).Should(Equal(snapshotName))
+			Expect(deployedState[componentName].Image).Should(Equal("image1"))
+			Expect(deployedState[componentName].CommitID).Should(Equal("ca82a6dff817ec66f44342007202690a93763949"))
+			Expect(deployedState[componentName].Timestamp.Time.IsZero()).Should(BeFalse())
+
+			// the sync is recorded in the deployment history
+			deploymentHistory, err := getDeploymentHistory(createdBinding)
+			Expect(err).Should(BeNil())
+			Expect(deploymentHistory).Should(HaveLen(1))
+			Expect(deploymentHistory[0].Snapshot).Should(Equal(snapshotName))
+			Expect(deploymentHistory[0].CommitID).Should(Equal("ca82a6dff817ec66f44342007202690a93763949"))
 
 			// check the list of generated gitops resources to make sure we account for every one
 			for _, generatedResource := range createdBinding.Status.Components[0].GitOpsRepository.GeneratedResources {
@@ -1025,10 +1042,11 @@ var _ = Describe("SnapshotEnvironmentBinding controller", func() {
 
 			bindingLabels := createdBinding.GetLabels()
 			// SEB controller should preserve the existing labels
-			Expect(len(bindingLabels)).Should(Equal(3))
+			Expect(len(bindingLabels)).Should(Equal(4))
 			Expect(bindingLabels["test"]).Should(Equal("true"))
 			Expect(bindingLabels["appstudio.application"]).Should(Equal(applicationName))
 			Expect(bindingLabels["appstudio.environment"]).Should(Equal(environmentName))
+			Expect(bindingLabels["appstudio.snapshot"]).Should(Equal(snapshotName))
 
 			createdBinding.Spec.Components[0].Configuration.Replicas = int(newReplicas)
 
@@ -1085,9 +1103,6 @@ var _ = Describe("SnapshotEnvironmentBinding controller", func() {
 				Spec: appstudiov1alpha1.ApplicationSpec{
 					DisplayName: DisplayName,
 					Description: Description,
-					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
-						URL: "http://foo.com/?foo\nbar",
-					},
 				},
 			}
 
@@ -1102,6 +1117,9 @@ var _ = Describe("SnapshotEnvironmentBinding controller", func() {
 				return len(fetchedHasApp.Status.Conditions) > 0
 			}, timeout, interval).Should(BeTrue())
 
+			// Set the bad gitops repository url in the devfile, as the Application rejects it in its spec
+			fetchedHasApp = setAppGitOpsRepositoryURL(hasAppLookupKey, "http://foo.com/?foo\nbar")
+
 			hasComp := createAndFetchSimpleComponent(componentName, HASAppNamespace, ComponentName, applicationName, SampleRepoLink, false)
 			// Make sure the devfile model was properly set in Component
 			Expect(hasComp.Status.Devfile).Should(Not(Equal("")))
@@ -2197,10 +2215,11 @@ var _ = Describe("SnapshotEnvironmentBinding controller", func() {
 			Expect(createdBinding.Status.Components[0].GitOpsRepository.URL).Should(Equal(hasComp.Status.GitOps.RepositoryURL))
 			Expect(createdBinding.Status.Components[0].GitOpsRepository.CommitID).Should(Equal("ca82a6dff817ec66f44342007202690a93763949"))
 			bindingLabels := createdBinding.GetLabels()
-			// If no prior labels exist, SEB controllers should only add 2 label entries
-			Expect(len(bindingLabels)).Should(Equal(2))
+			// If no prior labels exist, SEB controllers should only add 3 label entries
+			Expect(len(bindingLabels)).Should(Equal(3))
 			Expect(bindingLabels["appstudio.application"]).Should(Equal(applicationName))
 			Expect(bindingLabels["appstudio.environment"]).Should(Equal(environmentName))
+			Expect(bindingLabels["appstudio.snapshot"]).Should(Equal(snapshotName))
 
 			// check the list of generated gitops resources to make sure we account for every one
 			for _, generatedResource := range createdBinding.Status.Components[0].GitOpsRepository.GeneratedResources {
@@ -2620,10 +2639,11 @@ schemaVersion: 2.2.0`
 			Expect(createdBinding.Status.Components[0].GitOpsRepository.URL).Should(Equal(hasComp.Status.GitOps.RepositoryURL))
 			Expect(createdBinding.Status.Components[0].GitOpsRepository.CommitID).Should(Equal("ca82a6dff817ec66f44342007202690a93763949"))
 			bindingLabels := createdBinding.GetLabels()
-			// If no prior labels exist, SEB controllers should only add 2 label entries
-			Expect(len(bindingLabels)).Should(Equal(2))
+			// If no prior labels exist, SEB controllers should only add 3 label entries
+			Expect(len(bindingLabels)).Should(Equal(3))
 			Expect(bindingLabels["appstudio.application"]).Should(Equal(applicationName))
 			Expect(bindingLabels["appstudio.environment"]).Should(Equal(environmentName))
+			Expect(bindingLabels["appstudio.snapshot"]).Should(Equal(snapshotName))
 
 			// check the list of generated gitops resources to make sure we account for every one
 			for _, generatedResource := range createdBinding.Status.Components[0].GitOpsRepository.GeneratedResources {
@@ -2649,6 +2669,413 @@ schemaVersion: 2.2.0`
 		})
 	})
 
+	Context("Update SnapshotEnvironmentBinding by removing a component", func() {
+		It("Should prune the overlays and the status of the removed component", func() {
+			ctx := context.Background()
+
+			applicationName := HASAppName + "18"
+			componentName := HASCompName + "18"
+			secondComponentName := HASCompName + "18-2"
+			snapshotName := HASSnapshotName + "18"
+			bindingName := HASBindingName + "18"
+			environmentName := "staging" + "18"
+
+			createAndFetchSimpleApp(applicationName, HASAppNamespace, DisplayName, Description)
+			hasComp := createAndFetchSimpleComponent(componentName, HASAppNamespace, ComponentName, applicationName, SampleRepoLink, false)
+			secondComp := createAndFetchSimpleComponent(secondComponentName, HASAppNamespace, secondComponentName, applicationName, SampleRepoLink, false)
+			// Make sure the devfile model was properly set in Component
+			Expect(hasComp.Status.Devfile).Should(Not(Equal("")))
+			Expect(secondComp.Status.Devfile).Should(Not(Equal("")))
+
+			appSnapshot := &appstudiov1alpha1.Snapshot{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Snapshot",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      snapshotName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.SnapshotSpec{
+					Application:        applicationName,
+					DisplayName:        "My Snapshot",
+					DisplayDescription: "My Snapshot",
+					Components: []appstudiov1alpha1.SnapshotComponent{
+						{
+							Name:           componentName,
+							ContainerImage: "image1",
+						},
+						{
+							Name:           secondComponentName,
+							ContainerImage: "image2",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, appSnapshot)).Should(Succeed())
+
+			appSnapshotLookupKey := types.NamespacedName{Name: snapshotName, Namespace: HASAppNamespace}
+			createdAppSnapshot := &appstudiov1alpha1.Snapshot{}
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), appSnapshotLookupKey, createdAppSnapshot)
+				return len(createdAppSnapshot.Spec.Components) > 0
+			}, timeout, interval).Should(BeTrue())
+
+			stagingEnv := &appstudiov1alpha1.Environment{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Environment",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      environmentName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.EnvironmentSpec{
+					Type:               "POC",
+					DisplayName:        DisplayName,
+					DeploymentStrategy: appstudiov1alpha1.DeploymentStrategy_AppStudioAutomated,
+				},
+			}
+			Expect(k8sClient.Create(ctx, stagingEnv)).Should(Succeed())
+
+			appBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "SnapshotEnvironmentBinding",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      bindingName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+					Application: applicationName,
+					Environment: environmentName,
+					Snapshot:    snapshotName,
+					Components: []appstudiov1alpha1.BindingComponent{
+						{
+							Name: componentName,
+						},
+						{
+							Name: secondComponentName,
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, appBinding)).Should(Succeed())
+
+			bindingLookupKey := types.NamespacedName{Name: bindingName, Namespace: HASAppNamespace}
+			createdBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{}
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), bindingLookupKey, createdBinding)
+				return len(createdBinding.Status.GitOpsRepoConditions) > 0 && len(createdBinding.Status.Components) == 2
+			}, timeout, interval).Should(BeTrue())
+
+			// Remove the second component from the binding
+			createdBinding.Spec.Components = createdBinding.Spec.Components[:1]
+			Expect(k8sClient.Update(ctx, createdBinding)).Should(Succeed())
+
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), bindingLookupKey, createdBinding)
+				return len(createdBinding.Status.Components) == 1
+			}, timeout, interval).Should(BeTrue())
+
+			Expect(createdBinding.Status.Components[0].Name).Should(Equal(componentName))
+			Expect(createdBinding.Status.GitOpsRepoConditions[len(createdBinding.Status.GitOpsRepoConditions)-1].Message).Should(Equal("GitOps repository sync successful"))
+
+			// Delete the specified HASComp resources
+			hasCompLookupKey := types.NamespacedName{Name: componentName, Namespace: HASAppNamespace}
+			deleteHASCompCR(hasCompLookupKey)
+			secondCompLookupKey := types.NamespacedName{Name: secondComponentName, Namespace: HASAppNamespace}
+			deleteHASCompCR(secondCompLookupKey)
+
+			// Delete the specified HASApp resource
+			hasAppLookupKey := types.NamespacedName{Name: applicationName, Namespace: HASAppNamespace}
+			deleteHASAppCR(hasAppLookupKey)
+
+			// Delete the specified binding
+			deleteBinding(bindingLookupKey)
+
+			// Delete the specified snapshot
+			deleteSnapshot(appSnapshotLookupKey)
+
+			// Delete the specified environment
+			stagingEnvLookupKey := types.NamespacedName{Name: environmentName, Namespace: HASAppNamespace}
+			deleteEnvironment(stagingEnvLookupKey)
+		})
+	})
+
+	Context("Create SnapshotEnvironmentBinding in dry-run mode", func() {
+		It("Should report the diff in a ConfigMap without updating the component status", func() {
+			ctx := context.Background()
+
+			applicationName := HASAppName + "19"
+			componentName := HASCompName + "19"
+			snapshotName := HASSnapshotName + "19"
+			bindingName := HASBindingName + "19"
+			environmentName := "staging" + "19"
+
+			createAndFetchSimpleApp(applicationName, HASAppNamespace, DisplayName, Description)
+			hasComp := createAndFetchSimpleComponent(componentName, HASAppNamespace, ComponentName, applicationName, SampleRepoLink, false)
+			// Make sure the devfile model was properly set in Component
+			Expect(hasComp.Status.Devfile).Should(Not(Equal("")))
+
+			appSnapshot := &appstudiov1alpha1.Snapshot{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Snapshot",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      snapshotName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.SnapshotSpec{
+					Application:        applicationName,
+					DisplayName:        "My Snapshot",
+					DisplayDescription: "My Snapshot",
+					Components: []appstudiov1alpha1.SnapshotComponent{
+						{
+							Name:           componentName,
+							ContainerImage: "image1",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, appSnapshot)).Should(Succeed())
+
+			appSnapshotLookupKey := types.NamespacedName{Name: snapshotName, Namespace: HASAppNamespace}
+			createdAppSnapshot := &appstudiov1alpha1.Snapshot{}
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), appSnapshotLookupKey, createdAppSnapshot)
+				return len(createdAppSnapshot.Spec.Components) > 0
+			}, timeout, interval).Should(BeTrue())
+
+			stagingEnv := &appstudiov1alpha1.Environment{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Environment",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      environmentName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.EnvironmentSpec{
+					Type:               "POC",
+					DisplayName:        DisplayName,
+					DeploymentStrategy: appstudiov1alpha1.DeploymentStrategy_AppStudioAutomated,
+				},
+			}
+			Expect(k8sClient.Create(ctx, stagingEnv)).Should(Succeed())
+
+			appBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "SnapshotEnvironmentBinding",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      bindingName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						DryRunAnnotation: "true",
+					},
+				},
+				Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+					Application: applicationName,
+					Environment: environmentName,
+					Snapshot:    snapshotName,
+					Components: []appstudiov1alpha1.BindingComponent{
+						{
+							Name: componentName,
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, appBinding)).Should(Succeed())
+
+			bindingLookupKey := types.NamespacedName{Name: bindingName, Namespace: HASAppNamespace}
+			createdBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{}
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), bindingLookupKey, createdBinding)
+				return meta.FindStatusCondition(createdBinding.Status.GitOpsRepoConditions, "GitOpsResourcesDryRun") != nil
+			}, timeout, interval).Should(BeTrue())
+
+			// Nothing is pushed, so the component status and the deployed state are not recorded
+			Expect(createdBinding.Status.Components).Should(BeEmpty())
+			Expect(createdBinding.GetAnnotations()).ShouldNot(HaveKey(DeployedStateAnnotation))
+
+			configMapLookupKey := types.NamespacedName{Name: bindingName + "-dry-run", Namespace: HASAppNamespace}
+			dryRunConfigMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, configMapLookupKey, dryRunConfigMap)).Should(Succeed())
+			Expect(dryRunConfigMap.Data["files"]).Should(ContainSubstring(fmt.Sprintf("components/%s/overlays/%s/deployment-patch.yaml", componentName, environmentName)))
+			Expect(dryRunConfigMap.Data["truncated"]).Should(Equal("false"))
+			Expect(dryRunConfigMap.OwnerReferences).Should(HaveLen(1))
+			Expect(dryRunConfigMap.OwnerReferences[0].Name).Should(Equal(bindingName))
+
+			// Disabling the dry-run syncs the binding
+			createdBinding.Annotations[DryRunAnnotation] = "false"
+			Expect(k8sClient.Update(ctx, createdBinding)).Should(Succeed())
+
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), bindingLookupKey, createdBinding)
+				return len(createdBinding.Status.Components) == 1
+			}, timeout, interval).Should(BeTrue())
+			Expect(createdBinding.Status.Components[0].GitOpsRepository.CommitID).Should(Equal("ca82a6dff817ec66f44342007202690a93763949"))
+
+			// Delete the specified HASComp resource
+			hasCompLookupKey := types.NamespacedName{Name: componentName, Namespace: HASAppNamespace}
+			deleteHASCompCR(hasCompLookupKey)
+
+			// Delete the specified HASApp resource
+			hasAppLookupKey := types.NamespacedName{Name: applicationName, Namespace: HASAppNamespace}
+			deleteHASAppCR(hasAppLookupKey)
+
+			// Delete the specified binding
+			deleteBinding(bindingLookupKey)
+
+			// Delete the specified snapshot
+			deleteSnapshot(appSnapshotLookupKey)
+
+			// Delete the specified environment
+			stagingEnvLookupKey := types.NamespacedName{Name: environmentName, Namespace: HASAppNamespace}
+			deleteEnvironment(stagingEnvLookupKey)
+		})
+	})
+
+	Context("Roll back SnapshotEnvironmentBinding to an earlier Snapshot", func() {
+		It("Should render the earlier Snapshot until the rollback is removed", func() {
+			ctx := context.Background()
+
+			applicationName := HASAppName + "20"
+			componentName := HASCompName + "20"
+			snapshotName := HASSnapshotName + "20"
+			earlierSnapshotName := HASSnapshotName + "20-earlier"
+			bindingName := HASBindingName + "20"
+			environmentName := "staging" + "20"
+
+			createAndFetchSimpleApp(applicationName, HASAppNamespace, DisplayName, Description)
+			hasComp := createAndFetchSimpleComponent(componentName, HASAppNamespace, ComponentName, applicationName, SampleRepoLink, false)
+			// Make sure the devfile model was properly set in Component
+			Expect(hasComp.Status.Devfile).Should(Not(Equal("")))
+
+			for snapshot, image := range map[string]string{earlierSnapshotName: "image1", snapshotName: "image2"} {
+				appSnapshot := &appstudiov1alpha1.Snapshot{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: "appstudio.redhat.com/v1alpha1",
+						Kind:       "Snapshot",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      snapshot,
+						Namespace: HASAppNamespace,
+					},
+					Spec: appstudiov1alpha1.SnapshotSpec{
+						Application:        applicationName,
+						DisplayName:        "My Snapshot",
+						DisplayDescription: "My Snapshot",
+						Components: []appstudiov1alpha1.SnapshotComponent{
+							{
+								Name:           componentName,
+								ContainerImage: image,
+							},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, appSnapshot)).Should(Succeed())
+			}
+
+			stagingEnv := &appstudiov1alpha1.Environment{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Environment",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      environmentName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.EnvironmentSpec{
+					Type:               "POC",
+					DisplayName:        DisplayName,
+					DeploymentStrategy: appstudiov1alpha1.DeploymentStrategy_AppStudioAutomated,
+				},
+			}
+			Expect(k8sClient.Create(ctx, stagingEnv)).Should(Succeed())
+
+			appBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "SnapshotEnvironmentBinding",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      bindingName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+					Application: applicationName,
+					Environment: environmentName,
+					Snapshot:    snapshotName,
+					Components: []appstudiov1alpha1.BindingComponent{
+						{
+							Name: componentName,
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, appBinding)).Should(Succeed())
+
+			bindingLookupKey := types.NamespacedName{Name: bindingName, Namespace: HASAppNamespace}
+			createdBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{}
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), bindingLookupKey, createdBinding)
+				return len(createdBinding.Status.GitOpsRepoConditions) > 0 && len(createdBinding.Status.Components) == 1
+			}, timeout, interval).Should(BeTrue())
+
+			// Roll back to the earlier Snapshot
+			createdBinding.Annotations[RollbackAnnotation] = fmt.Sprintf(`{"snapshot": "%s"}`, earlierSnapshotName)
+			Expect(k8sClient.Update(ctx, createdBinding)).Should(Succeed())
+
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), bindingLookupKey, createdBinding)
+				return meta.IsStatusConditionTrue(createdBinding.Status.GitOpsRepoConditions, "RolledBack")
+			}, timeout, interval).Should(BeTrue())
+
+			Eventually(func() string {
+				k8sClient.Get(context.Background(), bindingLookupKey, createdBinding)
+				deployedState, _ := getDeployedState(createdBinding)
+				return deployedState[componentName].Image
+			}, timeout, interval).Should(Equal("image1"))
+			Expect(createdBinding.Spec.Snapshot).Should(Equal(snapshotName))
+
+			// Removing the rollback renders the Snapshot of the spec again
+			delete(createdBinding.Annotations, RollbackAnnotation)
+			Expect(k8sClient.Update(ctx, createdBinding)).Should(Succeed())
+
+			Eventually(func() bool {
+				k8sClient.Get(context.Background(), bindingLookupKey, createdBinding)
+				deployedState, _ := getDeployedState(createdBinding)
+				return deployedState[componentName].Image == "image2" && meta.FindStatusCondition(createdBinding.Status.GitOpsRepoConditions, "RolledBack") == nil
+			}, timeout, interval).Should(BeTrue())
+
+			// Delete the specified HASComp resource
+			hasCompLookupKey := types.NamespacedName{Name: componentName, Namespace: HASAppNamespace}
+			deleteHASCompCR(hasCompLookupKey)
+
+			// Delete the specified HASApp resource
+			hasAppLookupKey := types.NamespacedName{Name: applicationName, Namespace: HASAppNamespace}
+			deleteHASAppCR(hasAppLookupKey)
+
+			// Delete the specified binding
+			deleteBinding(bindingLookupKey)
+
+			// Delete the specified snapshots
+			deleteSnapshot(types.NamespacedName{Name: snapshotName, Namespace: HASAppNamespace})
+			deleteSnapshot(types.NamespacedName{Name: earlierSnapshotName, Namespace: HASAppNamespace})
+
+			// Delete the specified environment
+			stagingEnvLookupKey := types.NamespacedName{Name: environmentName, Namespace: HASAppNamespace}
+			deleteEnvironment(stagingEnvLookupKey)
+		})
+	})
 })
 
 // deleteBinding deletes the specified binding resource and verifies it was properly deleted
@@ -0,0 +1,1221 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	devfileParser "github.com/devfile/library/v2/pkg/devfile/parser"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/redhat-developer/gitops-generator/pkg/resources"
+	"github.com/redhat-developer/gitops-generator/pkg/yaml"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGetAutoscalingOverrides(t *testing.T) {
+	maxReplicas := int32(6)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]devfile.Autoscaling
+		wantErr     bool
+	}{
+		{
+			name: "No overrides",
+			want: map[string]devfile.Autoscaling{},
+		},
+		{
+			name: "Override for a single component",
+			annotations: map[string]string{
+				AutoscalingOverridesAnnotation: `{"component-a": {"maxReplicas": 6}}`,
+			},
+			want: map[string]devfile.Autoscaling{
+				"component-a": {MaxReplicas: maxReplicas},
+			},
+		},
+		{
+			name: "Malformed overrides",
+			annotations: map[string]string{
+				AutoscalingOverridesAnnotation: `["component-a"]`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := appstudiov1alpha1.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "binding",
+					Annotations: tt.annotations,
+				},
+			}
+			overrides, err := getAutoscalingOverrides(&binding)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, overrides, "autoscaling overrides did not match")
+			}
+		})
+	}
+}
+
+func TestGenerateAutoscalingOverlay(t *testing.T) {
+	minReplicas := int32(1)
+	overrideMinReplicas := int32(3)
+	cpuUtilization := int32(80)
+	overlayPath := "/tmp/app/components/component-a/overlays/staging"
+	labels := map[string]string{"app.kubernetes.io/instance": "component-a"}
+
+	tests := []struct {
+		name            string
+		workloadKind    devfile.WorkloadKind
+		baseAutoscaling *devfile.Autoscaling
+		override        *devfile.Autoscaling
+		wantFiles       []string
+		wantPatches     []string
+		wantResources   []string
+		wantHPA         *autoscalingv2.HorizontalPodAutoscaler
+		wantErr         bool
+	}{
+		{
+			name:            "Override the autoscaling declared by the component",
+			baseAutoscaling: &devfile.Autoscaling{MinReplicas: &minReplicas, MaxReplicas: 2, TargetCPUUtilizationPercentage: &cpuUtilization},
+			override:        &devfile.Autoscaling{MinReplicas: &overrideMinReplicas, MaxReplicas: 10},
+			wantFiles:       []string{hpaPatchFileName},
+			wantPatches:     []string{hpaPatchFileName},
+			wantHPA: func() *autoscalingv2.HorizontalPodAutoscaler {
+				hpa := devfile.GenerateHorizontalPodAutoscaler("component-a", labels, devfile.Autoscaling{MinReplicas: &overrideMinReplicas, MaxReplicas: 10, TargetCPUUtilizationPercentage: &cpuUtilization}, devfile.DeploymentWorkloadKind)
+				return &hpa
+			}(),
+		},
+		{
+			name:          "Autoscale a component only in the environment",
+			override:      &devfile.Autoscaling{MaxReplicas: 4},
+			wantFiles:     []string{hpaFileName},
+			wantResources: []string{hpaFileName},
+			wantHPA: func() *autoscalingv2.HorizontalPodAutoscaler {
+				hpa := devfile.GenerateHorizontalPodAutoscaler("component-a", labels, devfile.Autoscaling{MaxReplicas: 4}, devfile.DeploymentWorkloadKind)
+				return &hpa
+			}(),
+		},
+		{
+			name:          "Autoscale a StatefulSet component only in the environment",
+			workloadKind:  devfile.StatefulSetWorkloadKind,
+			override:      &devfile.Autoscaling{MaxReplicas: 4},
+			wantFiles:     []string{hpaFileName},
+			wantResources: []string{hpaFileName},
+			wantHPA: func() *autoscalingv2.HorizontalPodAutoscaler {
+				hpa := devfile.GenerateHorizontalPodAutoscaler("component-a", labels, devfile.Autoscaling{MaxReplicas: 4}, devfile.StatefulSetWorkloadKind)
+				return &hpa
+			}(),
+		},
+		{
+			name:         "Autoscale a Job component",
+			workloadKind: devfile.JobWorkloadKind,
+			override:     &devfile.Autoscaling{MaxReplicas: 4},
+			wantErr:      true,
+		},
+		{
+			name:            "No override",
+			baseAutoscaling: &devfile.Autoscaling{MaxReplicas: 2},
+		},
+		{
+			name:            "Override lowers max replicas below the component min replicas",
+			baseAutoscaling: &devfile.Autoscaling{MinReplicas: &overrideMinReplicas, MaxReplicas: 5},
+			override:        &devfile.Autoscaling{MaxReplicas: 2},
+			wantErr:         true,
+		},
+		{
+			name:     "Override without max replicas for a component that is not autoscaled",
+			override: &devfile.Autoscaling{MinReplicas: &minReplicas},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			kustomizePath := filepath.Join(overlayPath, "kustomization.yaml")
+			err := yaml.MarshalItemToFile(fs, kustomizePath, resources.Kustomization{Resources: []string{"../../base"}})
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			workloadKind := tt.workloadKind
+			if workloadKind == "" {
+				workloadKind = devfile.DeploymentWorkloadKind
+			}
+			files, err := generateAutoscalingOverlay(fs, overlayPath, "component-a", labels, workloadKind, tt.baseAutoscaling, tt.override)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.wantFiles, files)
+
+				var k resources.Kustomization
+				if err := yaml.UnMarshalItemFromFile(fs, kustomizePath, &k); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Equal(t, append([]string{"../../base"}, tt.wantResources...), k.Resources)
+				assert.Equal(t, tt.wantPatches, k.Patches)
+
+				if tt.wantHPA != nil {
+					var hpa autoscalingv2.HorizontalPodAutoscaler
+					if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, files[0]), &hpa); err != nil {
+						t.Fatalf("got unexpected error %v", err)
+					}
+					assert.Equal(t, *tt.wantHPA, hpa)
+				}
+
+				// Unsetting the override removes the generated overlay files
+				_, err = generateAutoscalingOverlay(fs, overlayPath, "component-a", labels, workloadKind, tt.baseAutoscaling, nil)
+				if err != nil {
+					t.Errorf("got unexpected error %v", err)
+				}
+				for _, fileName := range []string{hpaFileName, hpaPatchFileName} {
+					exist, _ := fs.Exists(filepath.Join(overlayPath, fileName))
+					assert.False(t, exist, "expected %s to be removed", fileName)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAvailabilityOverrides(t *testing.T) {
+	minAvailable := intstr.FromInt(2)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]devfile.Availability
+		wantErr     bool
+	}{
+		{
+			name: "No overrides",
+			want: map[string]devfile.Availability{},
+		},
+		{
+			name: "Override for a single component",
+			annotations: map[string]string{
+				AvailabilityOverridesAnnotation: `{"component-a": {"minAvailable": 2, "antiAffinity": "required"}}`,
+			},
+			want: map[string]devfile.Availability{
+				"component-a": {MinAvailable: &minAvailable, AntiAffinity: devfile.RequiredAntiAffinity},
+			},
+		},
+		{
+			name: "Malformed overrides",
+			annotations: map[string]string{
+				AvailabilityOverridesAnnotation: `{"component-a": {"minAvailable": true}}`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := appstudiov1alpha1.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "binding",
+					Annotations: tt.annotations,
+				},
+			}
+			overrides, err := getAvailabilityOverrides(&binding)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, overrides, "availability overrides did not match")
+			}
+		})
+	}
+}
+
+func TestGenerateAvailabilityOverlay(t *testing.T) {
+	minAvailable := intstr.FromInt(1)
+	maxUnavailable := intstr.FromString("50%")
+	overlayPath := "/tmp/app/components/component-a/overlays/staging"
+	labels := map[string]string{"app.kubernetes.io/instance": "component-a"}
+
+	tests := []struct {
+		name             string
+		workloadKind     devfile.WorkloadKind
+		baseAvailability *devfile.Availability
+		override         *devfile.Availability
+		wantFiles        []string
+		wantPatches      []string
+		wantResources    []string
+		check            func(t *testing.T, fs afero.Afero)
+		wantErr          bool
+	}{
+		{
+			name:             "Override the disruption budget declared by the component",
+			baseAvailability: &devfile.Availability{MinAvailable: &minAvailable},
+			override:         &devfile.Availability{MaxUnavailable: &maxUnavailable},
+			wantFiles:        []string{pdbPatchFileName},
+			wantPatches:      []string{pdbPatchFileName},
+			check: func(t *testing.T, fs afero.Afero) {
+				pdbPatch := make(map[string]interface{})
+				if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, pdbPatchFileName), &pdbPatch); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Equal(t, "PodDisruptionBudget", pdbPatch["kind"])
+				// the bound declared by the component is cleared
+				assert.Equal(t, map[string]interface{}{"minAvailable": nil, "maxUnavailable": "50%"}, pdbPatch["spec"])
+			},
+		},
+		{
+			name:          "Disruption budget only in the environment",
+			workloadKind:  devfile.StatefulSetWorkloadKind,
+			override:      &devfile.Availability{MinAvailable: &minAvailable},
+			wantFiles:     []string{pdbFileName},
+			wantResources: []string{pdbFileName},
+			check: func(t *testing.T, fs afero.Afero) {
+				var pdb policyv1.PodDisruptionBudget
+				if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, pdbFileName), &pdb); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Equal(t, devfile.GeneratePodDisruptionBudget("component-a", labels, devfile.Availability{MinAvailable: &minAvailable}), pdb)
+			},
+		},
+		{
+			name:             "Scheduling constraints replace the constraints declared by the component",
+			workloadKind:     devfile.StatefulSetWorkloadKind,
+			baseAvailability: &devfile.Availability{TopologySpread: []devfile.TopologyDomain{devfile.HostTopologyDomain}},
+			override:         &devfile.Availability{TopologySpread: []devfile.TopologyDomain{devfile.ZoneTopologyDomain}, AntiAffinity: devfile.RequiredAntiAffinity},
+			wantFiles:        []string{availabilityPatchFileName},
+			wantPatches:      []string{availabilityPatchFileName},
+			check: func(t *testing.T, fs afero.Afero) {
+				workloadPatch := make(map[string]interface{})
+				if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, availabilityPatchFileName), &workloadPatch); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Equal(t, "StatefulSet", workloadPatch["kind"])
+				podSpec := workloadPatch["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+				constraints := podSpec["topologySpreadConstraints"].([]interface{})
+				if assert.Len(t, constraints, 2) {
+					assert.Equal(t, map[string]interface{}{"$patch": "replace"}, constraints[0])
+					assert.Equal(t, "topology.kubernetes.io/zone", constraints[1].(map[string]interface{})["topologyKey"])
+				}
+				antiAffinity := podSpec["affinity"].(map[string]interface{})["podAntiAffinity"].(map[string]interface{})
+				assert.Equal(t, "replace", antiAffinity["$patch"])
+				assert.Contains(t, antiAffinity, "requiredDuringSchedulingIgnoredDuringExecution")
+			},
+		},
+		{
+			name:         "Availability of a Job component",
+			workloadKind: devfile.JobWorkloadKind,
+			override:     &devfile.Availability{MinAvailable: &minAvailable},
+			wantErr:      true,
+		},
+		{
+			name:             "Override sets both disruption budget bounds",
+			baseAvailability: &devfile.Availability{TopologySpread: []devfile.TopologyDomain{devfile.ZoneTopologyDomain}},
+			override:         &devfile.Availability{MinAvailable: &minAvailable, MaxUnavailable: &maxUnavailable},
+			wantErr:          true,
+		},
+		{
+			name:             "No override",
+			baseAvailability: &devfile.Availability{MinAvailable: &minAvailable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			kustomizePath := filepath.Join(overlayPath, "kustomization.yaml")
+			err := yaml.MarshalItemToFile(fs, kustomizePath, resources.Kustomization{Resources: []string{"../../base"}})
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			workloadKind := tt.workloadKind
+			if workloadKind == "" {
+				workloadKind = devfile.DeploymentWorkloadKind
+			}
+			files, err := generateAvailabilityOverlay(fs, overlayPath, "component-a", labels, workloadKind, tt.baseAvailability, tt.override)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.wantFiles, files)
+
+				var k resources.Kustomization
+				if err := yaml.UnMarshalItemFromFile(fs, kustomizePath, &k); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Equal(t, append([]string{"../../base"}, tt.wantResources...), k.Resources)
+				assert.Equal(t, tt.wantPatches, k.Patches)
+
+				if tt.check != nil {
+					tt.check(t, fs)
+				}
+
+				// Unsetting the override removes the generated overlay files
+				_, err = generateAvailabilityOverlay(fs, overlayPath, "component-a", labels, workloadKind, tt.baseAvailability, nil)
+				if err != nil {
+					t.Errorf("got unexpected error %v", err)
+				}
+				for _, fileName := range []string{pdbFileName, pdbPatchFileName, availabilityPatchFileName} {
+					exist, _ := fs.Exists(filepath.Join(overlayPath, fileName))
+					assert.False(t, exist, "expected %s to be removed", fileName)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateWorkloadOverlay(t *testing.T) {
+	overlayPath := "/tmp/app/components/component-a/overlays/staging"
+	replicas := int32(2)
+	deploymentPatch := appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "component-a"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "container-image", Image: "quay.io/org/component-a:1.0"}},
+				},
+			},
+		},
+	}
+	wantTemplate := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "quay.io/org/component-a:1.0"}},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		workloadKind        devfile.WorkloadKind
+		templateAnnotations map[string]string
+		templateLabels      map[string]string
+		wantFiles           []string
+		wantPatch           interface{}
+	}{
+		{
+			name:         "Deployment patch is kept",
+			workloadKind: devfile.DeploymentWorkloadKind,
+			wantFiles:    []string{deploymentPatchFileName},
+		},
+		{
+			name:           "Deployment patch with template labels",
+			workloadKind:   devfile.DeploymentWorkloadKind,
+			templateLabels: map[string]string{"app.kubernetes.io/part-of": "application-a"},
+			wantFiles:      []string{deploymentPatchFileName},
+			wantPatch: &appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "component-a"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app.kubernetes.io/part-of": "application-a"}},
+						Spec:       wantTemplate.Spec,
+					},
+				},
+			},
+		},
+		{
+			name:         "StatefulSet patch",
+			workloadKind: devfile.StatefulSetWorkloadKind,
+			wantFiles:    []string{"statefulset-patch.yaml"},
+			wantPatch: &appsv1.StatefulSet{
+				TypeMeta:   metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "component-a"},
+				Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, Template: wantTemplate},
+			},
+		},
+		{
+			name:         "CronJob patch",
+			workloadKind: devfile.CronJobWorkloadKind,
+			wantFiles:    []string{"cronjob-patch.yaml"},
+			wantPatch: &batchv1.CronJob{
+				TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "component-a"},
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: wantTemplate}},
+				},
+			},
+		},
+		{
+			name:                "Knative Service patch with autoscaling annotations",
+			workloadKind:        devfile.KnativeServiceWorkloadKind,
+			templateAnnotations: map[string]string{"autoscaling.knative.dev/max-scale": "4"},
+			wantFiles:           []string{"knativeservice-patch.yaml"},
+			wantPatch: &devfile.KnativeService{
+				TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "serving.knative.dev/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "component-a"},
+				Spec: devfile.KnativeServiceSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"autoscaling.knative.dev/max-scale": "4"}},
+						Spec:       wantTemplate.Spec,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			kustomizePath := filepath.Join(overlayPath, "kustomization.yaml")
+			err := yaml.MarshalItemToFile(fs, kustomizePath, resources.Kustomization{Resources: []string{"../../base"}, Patches: []string{deploymentPatchFileName}})
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			if err := yaml.MarshalItemToFile(fs, filepath.Join(overlayPath, deploymentPatchFileName), deploymentPatch); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			files, err := generateWorkloadOverlay(fs, overlayPath, tt.workloadKind, "app", tt.templateAnnotations, tt.templateLabels)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantFiles, files)
+
+			var k resources.Kustomization
+			if err := yaml.UnMarshalItemFromFile(fs, kustomizePath, &k); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantFiles, k.Patches)
+
+			if tt.wantPatch != nil {
+				if tt.workloadKind != devfile.DeploymentWorkloadKind {
+					exist, _ := fs.Exists(filepath.Join(overlayPath, deploymentPatchFileName))
+					assert.False(t, exist, "expected the Deployment patch to be removed")
+				}
+
+				patch := reflect.New(reflect.TypeOf(tt.wantPatch).Elem()).Interface()
+				if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, files[0]), patch); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Equal(t, tt.wantPatch, patch)
+			}
+		})
+	}
+}
+
+func TestGetEnvironmentWorkload(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *devfile.Workload
+		wantErr     bool
+	}{
+		{
+			name: "No workload annotation",
+		},
+		{
+			name:        "Knative Service environment",
+			annotations: map[string]string{WorkloadAnnotation: `{"kind": "KnativeService"}`},
+			want:        &devfile.Workload{Kind: devfile.KnativeServiceWorkloadKind},
+		},
+		{
+			name:        "StatefulSet environment",
+			annotations: map[string]string{WorkloadAnnotation: `{"kind": "StatefulSet"}`},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environment := appstudiov1alpha1.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "staging",
+					Annotations: tt.annotations,
+				},
+			}
+			workload, err := getEnvironmentWorkload(&environment)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, workload, "environment workload did not match")
+			}
+		})
+	}
+}
+
+func TestGenerateKnativeEnvironmentOverlay(t *testing.T) {
+	basePath := "/tmp/app/components/component-a/base"
+	overlayPath := "/tmp/app/components/component-a/overlays/staging"
+	knativeService := devfile.KnativeService{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "serving.knative.dev/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "component-a"},
+	}
+
+	tests := []struct {
+		name               string
+		hasBaseService     bool
+		hasBaseAutoscaling bool
+		wantFiles          []string
+		wantPatches        []string
+	}{
+		{
+			name:        "Delete the base Deployment",
+			wantFiles:   []string{knativeServiceFileName, deploymentDeletePatchFileName},
+			wantPatches: []string{deploymentDeletePatchFileName},
+		},
+		{
+			name:               "Delete the base Deployment, Service and HorizontalPodAutoscaler",
+			hasBaseService:     true,
+			hasBaseAutoscaling: true,
+			wantFiles:          []string{knativeServiceFileName, deploymentDeletePatchFileName, serviceDeletePatchFileName, hpaDeletePatchFileName},
+			wantPatches:        []string{deploymentDeletePatchFileName, hpaDeletePatchFileName, serviceDeletePatchFileName},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			kustomizePath := filepath.Join(overlayPath, "kustomization.yaml")
+			err := yaml.MarshalItemToFile(fs, kustomizePath, resources.Kustomization{Resources: []string{"../../base"}})
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			if tt.hasBaseService {
+				if err := fs.WriteFile(filepath.Join(basePath, serviceFileName), []byte("kind: Service"), 0644); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+			}
+
+			files, err := generateKnativeEnvironmentOverlay(fs, overlayPath, "component-a", knativeService, tt.hasBaseAutoscaling)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantFiles, files)
+
+			var k resources.Kustomization
+			if err := yaml.UnMarshalItemFromFile(fs, kustomizePath, &k); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, []string{"../../base", knativeServiceFileName}, k.Resources)
+			assert.Equal(t, tt.wantPatches, k.Patches)
+
+			var deletePatch map[string]interface{}
+			if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, deploymentDeletePatchFileName), &deletePatch); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, "delete", deletePatch["$patch"])
+			assert.Equal(t, "Deployment", deletePatch["kind"])
+
+			// Switching the environment back removes the Knative Service overlay
+			if _, err := generateKnativeEnvironmentOverlay(fs, overlayPath, "component-a", nil, tt.hasBaseAutoscaling); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			var updatedK resources.Kustomization
+			if err := yaml.UnMarshalItemFromFile(fs, kustomizePath, &updatedK); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, []string{"../../base"}, updatedK.Resources)
+			assert.Empty(t, updatedK.Patches)
+		})
+	}
+}
+
+func TestGetGitOpsFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        gitops.Format
+		wantErr     bool
+	}{
+		{
+			name: "No format annotation",
+			want: gitops.KustomizeFormat,
+		},
+		{
+			name:        "Helm format",
+			annotations: map[string]string{GitOpsFormatAnnotation: "helm"},
+			want:        gitops.HelmFormat,
+		},
+		{
+			name:        "Unsupported format",
+			annotations: map[string]string{GitOpsFormatAnnotation: "jsonnet"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-application",
+					Annotations: tt.annotations,
+				},
+			}
+			format, err := getGitOpsFormat(&application)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, format, "GitOps format did not match")
+			}
+		})
+	}
+}
+
+func TestGetHelmChartResources(t *testing.T) {
+	deployment := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "component-a"}}
+	service := corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "component-a"}}
+	job := batchv1.Job{TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"}, ObjectMeta: metav1.ObjectMeta{Name: "component-a"}}
+	configMap := corev1.ConfigMap{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: "config"}}
+
+	tests := []struct {
+		name          string
+		resources     devfileParser.KubernetesResources
+		workloadKind  devfile.WorkloadKind
+		wantWorkload  interface{}
+		wantResources []interface{}
+	}{
+		{
+			name:          "Deployment and Service",
+			resources:     devfileParser.KubernetesResources{Deployments: []appsv1.Deployment{deployment}, Services: []corev1.Service{service}},
+			workloadKind:  devfile.DeploymentWorkloadKind,
+			wantWorkload:  &deployment,
+			wantResources: []interface{}{service},
+		},
+		{
+			name:          "Job and ConfigMap",
+			resources:     devfileParser.KubernetesResources{Others: []interface{}{configMap, job}},
+			workloadKind:  devfile.JobWorkloadKind,
+			wantWorkload:  &job,
+			wantResources: []interface{}{configMap},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workload, otherResources := getHelmChartResources(tt.resources, tt.workloadKind, "component-a", "application-a", "image")
+			assert.Equal(t, tt.wantWorkload, workload, "workload did not match")
+			assert.Equal(t, tt.wantResources, otherResources, "other resources did not match")
+		})
+	}
+
+	t.Run("No workload", func(t *testing.T) {
+		workload, otherResources := getHelmChartResources(devfileParser.KubernetesResources{}, devfile.DeploymentWorkloadKind, "component-a", "application-a", "image")
+		defaultDeployment := devfile.GenerateDeploymentTemplate("component-a", "application-a", "image")
+		assert.Equal(t, &defaultDeployment, workload, "workload did not match")
+		assert.Empty(t, otherResources)
+	})
+}
+
+func TestGetHelmEnvironmentValues(t *testing.T) {
+	replicas := int32(2)
+	maxReplicas := int32(4)
+	overlay := func(update func(overlay *environmentOverlay)) *environmentOverlay {
+		o := &environmentOverlay{
+			component: &appstudiov1alpha1.Component{ObjectMeta: metav1.ObjectMeta{Name: "component-a"}},
+			configuration: appstudiov1alpha1.BindingComponentConfiguration{
+				Replicas: 2,
+				Env:      []appstudiov1alpha1.EnvVarPair{{Name: "FOO", Value: "binding"}},
+			},
+			environment: appstudiov1alpha1.Environment{
+				ObjectMeta: metav1.ObjectMeta{Name: "staging"},
+				Spec: appstudiov1alpha1.EnvironmentSpec{
+					Configuration: appstudiov1alpha1.EnvironmentConfiguration{
+						Env: []appstudiov1alpha1.EnvVarPair{{Name: "FOO", Value: "environment"}, {Name: "BAR", Value: "environment"}},
+					},
+				},
+			},
+			imageName: "quay.io/test/component-a:v2",
+		}
+		if update != nil {
+			update(o)
+		}
+		return o
+	}
+
+	tests := []struct {
+		name       string
+		overlay    *environmentOverlay
+		wantValues gitops.HelmValues
+		wantErr    string
+	}{
+		{
+			name:    "Image, replicas and env",
+			overlay: overlay(nil),
+			wantValues: gitops.HelmValues{
+				Image:    "quay.io/test/component-a:v2",
+				Replicas: &replicas,
+				Env:      []corev1.EnvVar{{Name: "FOO", Value: "binding"}, {Name: "BAR", Value: "environment"}},
+			},
+		},
+		{
+			name: "SealedSecrets",
+			overlay: overlay(func(o *environmentOverlay) {
+				o.secrets.SealedSecrets = []unstructured.Unstructured{{}}
+			}),
+			wantErr: "component component-a references SealedSecrets or ExternalSecrets, which is only supported with the kustomize GitOps format",
+		},
+		{
+			name: "Autoscaling override",
+			overlay: overlay(func(o *environmentOverlay) {
+				o.autoscalingOverride = &devfile.Autoscaling{MaxReplicas: maxReplicas}
+			}),
+			wantErr: "component component-a has an autoscaling override, which is only supported with the kustomize GitOps format",
+		},
+		{
+			name: "Availability override",
+			overlay: overlay(func(o *environmentOverlay) {
+				o.availabilityOverride = &devfile.Availability{}
+			}),
+			wantErr: "component component-a has an availability override, which is only supported with the kustomize GitOps format",
+		},
+		{
+			name: "Knative Service in the environment",
+			overlay: overlay(func(o *environmentOverlay) {
+				o.isEnvironmentKnativeService = true
+			}),
+			wantErr: "component component-a runs as a KnativeService in the environment staging, which is only supported with the kustomize GitOps format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := getHelmEnvironmentValues(ctrl.Log, tt.overlay)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantValues, values)
+		})
+	}
+}
+
+func TestGetRouterNamespaceLabels(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment appstudiov1alpha1.Environment
+		want        map[string]string
+	}{
+		{
+			name: "OpenShift environment",
+			want: map[string]string{"network.openshift.io/policy-group": "ingress"},
+		},
+		{
+			name: "Kubernetes environment",
+			environment: appstudiov1alpha1.Environment{
+				Spec: appstudiov1alpha1.EnvironmentSpec{
+					UnstableConfigurationFields: &appstudiov1alpha1.UnstableEnvironmentConfiguration{
+						ClusterType: appstudiov1alpha1.ConfigurationClusterType_Kubernetes,
+					},
+				},
+			},
+			want: map[string]string{"kubernetes.io/metadata.name": "ingress-nginx"},
+		},
+		{
+			name: "Router namespace annotation",
+			environment: appstudiov1alpha1.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{RouterNamespaceAnnotation: "custom-router"},
+				},
+			},
+			want: map[string]string{"kubernetes.io/metadata.name": "custom-router"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getRouterNamespaceLabels(tt.environment))
+		})
+	}
+}
+
+func TestGenerateNetworkPolicyOverlay(t *testing.T) {
+	overlayPath := "/tmp/app/components/component-a/overlays/staging"
+	fs := ioutils.NewMemoryFilesystem()
+	networkPolicy := devfile.GenerateNetworkPolicy("component-a", "application-a", nil, devfile.NetworkPolicyIngress{PublicPorts: []int32{8080}})
+
+	files, err := generateNetworkPolicyOverlay(fs, overlayPath, &networkPolicy)
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, []string{networkPolicyFileName}, files)
+	var k resources.Kustomization
+	if err := yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, "kustomization.yaml"), &k); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, []string{networkPolicyFileName}, k.Resources)
+
+	files, err = generateNetworkPolicyOverlay(fs, overlayPath, nil)
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Empty(t, files)
+	exist, _ := fs.Exists(filepath.Join(overlayPath, networkPolicyFileName))
+	assert.False(t, exist, "expected the NetworkPolicy to be removed")
+}
+
+func TestGetRemovedComponents(t *testing.T) {
+	componentStatus := func(name string) appstudiov1alpha1.BindingComponentStatus {
+		return appstudiov1alpha1.BindingComponentStatus{
+			Name: name,
+			GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{
+				URL:    "https://github.com/org/gitops-repo",
+				Branch: "main",
+				Path:   filepath.Join("components", name, "overlays", "staging"),
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		specComponents []string
+		statuses       []appstudiov1alpha1.BindingComponentStatus
+		wantRemoved    []appstudiov1alpha1.BindingComponentStatus
+		wantStatuses   []appstudiov1alpha1.BindingComponentStatus
+	}{
+		{
+			name:           "No component removed",
+			specComponents: []string{"component-a", "component-b"},
+			statuses:       []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a")},
+			wantStatuses:   []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a")},
+		},
+		{
+			name:           "Component removed from the binding",
+			specComponents: []string{"component-b"},
+			statuses:       []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a"), componentStatus("component-b"), componentStatus("component-c")},
+			wantRemoved:    []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a"), componentStatus("component-c")},
+			wantStatuses:   []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-b")},
+		},
+		{
+			name:         "Every component removed from the binding",
+			statuses:     []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a")},
+			wantRemoved:  []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a")},
+			wantStatuses: []appstudiov1alpha1.BindingComponentStatus{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := appstudiov1alpha1.SnapshotEnvironmentBinding{
+				Status: appstudiov1alpha1.SnapshotEnvironmentBindingStatus{
+					Components: tt.statuses,
+				},
+			}
+			for _, name := range tt.specComponents {
+				binding.Spec.Components = append(binding.Spec.Components, appstudiov1alpha1.BindingComponent{Name: name})
+			}
+
+			removedComponents := getRemovedComponents(&binding)
+			assert.Equal(t, tt.wantRemoved, removedComponents)
+			assert.Equal(t, tt.wantStatuses, pruneComponentStatus(binding.Status.Components, removedComponents))
+		})
+	}
+}
+
+func TestRemoveComponentOverlay(t *testing.T) {
+	repoPath := "/tmp/binding/test-app"
+	componentStatus := func(path string) appstudiov1alpha1.BindingComponentStatus {
+		return appstudiov1alpha1.BindingComponentStatus{
+			Name:             "component-a",
+			GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{Path: path},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		componentStatus appstudiov1alpha1.BindingComponentStatus
+		wantRemoved     []string
+		wantKept        []string
+		wantErr         bool
+	}{
+		{
+			name:            "Kustomize overlay",
+			componentStatus: componentStatus("/components/component-a/overlays/staging"),
+			wantRemoved:     []string{"components/component-a/overlays/staging/kustomization.yaml"},
+			wantKept: []string{
+				"components/component-a/base/kustomization.yaml",
+				"components/component-a/overlays/production/kustomization.yaml",
+				"components/component-b/overlays/staging/kustomization.yaml",
+			},
+		},
+		{
+			name:            "Helm values of the environment",
+			componentStatus: componentStatus("context/components/component-a/chart"),
+			wantRemoved:     []string{"context/components/component-a/chart/values-staging.yaml"},
+			wantKept: []string{
+				"context/components/component-a/chart/values.yaml",
+				"context/components/component-a/chart/values-production.yaml",
+			},
+		},
+		{
+			name:            "Path of another component",
+			componentStatus: componentStatus("/components/component-b/overlays/staging"),
+			wantErr:         true,
+		},
+		{
+			name:            "Path outside of the repository",
+			componentStatus: componentStatus("../../components/component-a/overlays/staging"),
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			for _, file := range append(append([]string{}, tt.wantRemoved...), tt.wantKept...) {
+				if err := fs.WriteFile(filepath.Join(repoPath, file), []byte{}, 0644); err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+			}
+
+			err := removeComponentOverlay(fs, repoPath, tt.componentStatus, "staging")
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				for _, file := range tt.wantRemoved {
+					exist, _ := fs.Exists(filepath.Join(repoPath, file))
+					assert.False(t, exist, "expected %s to be removed", file)
+				}
+				for _, file := range tt.wantKept {
+					exist, _ := fs.Exists(filepath.Join(repoPath, file))
+					assert.True(t, exist, "expected %s to be kept", file)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneRemovedComponents(t *testing.T) {
+	binding := appstudiov1alpha1.SnapshotEnvironmentBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "binding",
+		},
+		Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+			Application: "test-app",
+			Environment: "staging",
+		},
+	}
+	componentStatus := func(name string, url string) appstudiov1alpha1.BindingComponentStatus {
+		return appstudiov1alpha1.BindingComponentStatus{
+			Name: name,
+			GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{
+				URL:    url,
+				Branch: "main",
+				Path:   filepath.Join("components", name, "overlays", "staging"),
+			},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		removedComponents []appstudiov1alpha1.BindingComponentStatus
+		wantErr           bool
+	}{
+		{
+			name:              "No removed component",
+			removedComponents: nil,
+		},
+		{
+			name: "Removed components in several repositories",
+			removedComponents: []appstudiov1alpha1.BindingComponentStatus{
+				componentStatus("component-a", "https://github.com/org/repo-a"),
+				componentStatus("component-b", "https://github.com/org/repo-b"),
+				componentStatus("component-c", "https://github.com/org/repo-a"),
+			},
+		},
+		{
+			name:              "Removed component without a repository",
+			removedComponents: []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a", "")},
+			wantErr:           true,
+		},
+		{
+			name:              "Removed component with an invalid path",
+			removedComponents: []appstudiov1alpha1.BindingComponentStatus{{Name: "component-a", GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{URL: "https://github.com/org/repo-a", Path: "/"}}},
+			wantErr:           true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			r := &SnapshotEnvironmentBindingReconciler{
+				Log:       ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
+				AppFS:     fs,
+				Generator: gitops.NewMockGenerator(),
+			}
+
+			err := r.pruneRemovedComponents(&github.GitHubClient{Token: "token"}, &binding, tt.removedComponents)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			}
+
+			// the temporary clones are always removed
+			tempDirs, _ := afero.Glob(fs, filepath.Join(os.TempDir(), "binding*"))
+			assert.Empty(t, tempDirs)
+		})
+	}
+}
+
+func TestSetComponentStatus(t *testing.T) {
+	componentStatus := func(name string, commitID string) appstudiov1alpha1.BindingComponentStatus {
+		return appstudiov1alpha1.BindingComponentStatus{
+			Name: name,
+			GitOpsRepository: appstudiov1alpha1.BindingComponentGitOpsRepository{
+				URL:      "https://github.com/org/repo",
+				Branch:   "main",
+				Path:     filepath.Join("components", name, "overlays", "staging"),
+				CommitID: commitID,
+			},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		componentStatuses []appstudiov1alpha1.BindingComponentStatus
+		componentStatus   appstudiov1alpha1.BindingComponentStatus
+		want              []appstudiov1alpha1.BindingComponentStatus
+	}{
+		{
+			name:            "First sync of the component",
+			componentStatus: componentStatus("component-a", "commit-1"),
+			want:            []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a", "commit-1")},
+		},
+		{
+			name:              "Existing status entry is updated with the new commit",
+			componentStatuses: []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a", "commit-1"), componentStatus("component-b", "commit-1")},
+			componentStatus:   componentStatus("component-a", "commit-2"),
+			want:              []appstudiov1alpha1.BindingComponentStatus{componentStatus("component-a", "commit-2"), componentStatus("component-b", "commit-1")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, setComponentStatus(tt.componentStatuses, tt.componentStatus))
+		})
+	}
+}
+
+func TestUpdateDeployedState(t *testing.T) {
+	// the timestamps are unmarshalled in the local time zone
+	syncTime := metav1.NewTime(time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC).Local())
+	previousSyncTime := metav1.NewTime(time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC).Local())
+	previousState := `{"component-a":{"snapshot":"snapshot-1","image":"quay.io/org/a:1","commitID":"commit-1","timestamp":"2023-04-01T12:00:00Z"},` +
+		`"component-b":{"snapshot":"snapshot-1","image":"quay.io/org/b:1","commitID":"commit-1","timestamp":"2023-04-01T12:00:00Z"},` +
+		`"component-c":{"snapshot":"snapshot-1","image":"quay.io/org/c:1","commitID":"commit-1","timestamp":"2023-04-01T12:00:00Z"}}`
+
+	tests := []struct {
+		name               string
+		annotations        map[string]string
+		deployedComponents map[string]DeployedComponent
+		removedComponents  []appstudiov1alpha1.BindingComponentStatus
+		want               map[string]DeployedComponent
+	}{
+		{
+			name: "First sync of the binding",
+			deployedComponents: map[string]DeployedComponent{
+				"component-a": {Snapshot: "snapshot-2", Image: "quay.io/org/a:2", CommitID: "commit-2", Timestamp: syncTime},
+			},
+			want: map[string]DeployedComponent{
+				"component-a": {Snapshot: "snapshot-2", Image: "quay.io/org/a:2", CommitID: "commit-2", Timestamp: syncTime},
+			},
+		},
+		{
+			name:        "Synced components are updated and removed components are dropped",
+			annotations: map[string]string{DeployedStateAnnotation: previousState},
+			deployedComponents: map[string]DeployedComponent{
+				"component-a": {Snapshot: "snapshot-2", Image: "quay.io/org/a:2", CommitID: "commit-2", Timestamp: syncTime},
+			},
+			removedComponents: []appstudiov1alpha1.BindingComponentStatus{{Name: "component-c"}},
+			want: map[string]DeployedComponent{
+				"component-a": {Snapshot: "snapshot-2", Image: "quay.io/org/a:2", CommitID: "commit-2", Timestamp: syncTime},
+				"component-b": {Snapshot: "snapshot-1", Image: "quay.io/org/b:1", CommitID: "commit-1", Timestamp: previousSyncTime},
+			},
+		},
+		{
+			name:        "Invalid deployed state is replaced",
+			annotations: map[string]string{DeployedStateAnnotation: "{"},
+			deployedComponents: map[string]DeployedComponent{
+				"component-a": {Snapshot: "snapshot-2", Image: "quay.io/org/a:2", CommitID: "commit-2", Timestamp: syncTime},
+			},
+			want: map[string]DeployedComponent{
+				"component-a": {Snapshot: "snapshot-2", Image: "quay.io/org/a:2", CommitID: "commit-2", Timestamp: syncTime},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &appstudiov1alpha1.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-binding",
+					Namespace:   "default",
+					Annotations: tt.annotations,
+				},
+			}
+			fakeClient := NewFakeClient(t, binding)
+			r := &SnapshotEnvironmentBindingReconciler{
+				Client: fakeClient,
+				Log:    ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
+			}
+
+			err := r.updateDeployedState(context.Background(), binding, tt.deployedComponents, tt.removedComponents, nil)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			updatedBinding := &appstudiov1alpha1.SnapshotEnvironmentBinding{}
+			if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: "test-binding", Namespace: "default"}, updatedBinding); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			deployedState, err := getDeployedState(updatedBinding)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.want, deployedState)
+		})
+	}
+}
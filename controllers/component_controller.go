@@ -49,6 +49,7 @@ import (
 	"github.com/go-logr/logr"
 
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
 	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
 	"github.com/redhat-appstudio/application-service/pkg/github"
 	logutil "github.com/redhat-appstudio/application-service/pkg/log"
@@ -195,7 +196,7 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 				_ = r.SetGitOpsGeneratedConditionAndUpdateCR(ctx, req, &component, fmt.Errorf("%v: %v", errMsg, err))
 				return ctrl.Result{}, err
 			}
-			if err := r.generateGitops(ctx, ghClient, &component, compDevfileData); err != nil {
+			if err := r.generateGitops(ctx, ghClient, &component, &hasApplication, compDevfileData); err != nil {
 				errMsg := fmt.Sprintf("Unable to generate gitops resources for component %v", req.NamespacedName)
 				log.Error(err, errMsg)
 				_ = r.SetGitOpsGeneratedConditionAndUpdateCR(ctx, req, &component, fmt.Errorf("%v: %v", errMsg, err))
@@ -452,7 +453,7 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 			// Generate and push the gitops resources
 			if !component.Spec.SkipGitOpsResourceGeneration {
-				if err := r.generateGitops(ctx, ghClient, &component, compDevfileData); err != nil {
+				if err := r.generateGitops(ctx, ghClient, &component, &hasApplication, compDevfileData); err != nil {
 					errMsg := fmt.Sprintf("Unable to generate gitops resources for component %v", req.NamespacedName)
 					log.Error(err, errMsg)
 					_ = r.SetGitOpsGeneratedConditionAndUpdateCR(ctx, req, &component, fmt.Errorf("%v: %v", errMsg, err))
@@ -543,7 +544,7 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 			// Generate and push the gitops resources, if necessary.
 			if !component.Spec.SkipGitOpsResourceGeneration {
-				if err := r.generateGitops(ctx, ghClient, &component, hasCompDevfileData); err != nil {
+				if err := r.generateGitops(ctx, ghClient, &component, &hasApplication, hasCompDevfileData); err != nil {
 					errMsg := fmt.Sprintf("Unable to generate gitops resources for component %v", req.NamespacedName)
 					log.Error(err, errMsg)
 					_ = r.SetGitOpsGeneratedConditionAndUpdateCR(ctx, req, &component, fmt.Errorf("%v: %v", errMsg, err))
@@ -572,7 +573,7 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 // generateGitops retrieves the necessary information about a Component's gitops repository (URL, branch, context)
 // and attempts to use the GitOps package to generate gitops resources based on that component
-func (r *ComponentReconciler) generateGitops(ctx context.Context, ghClient *github.GitHubClient, component *appstudiov1alpha1.Component, compDevfileData data.DevfileData) error {
+func (r *ComponentReconciler) generateGitops(ctx context.Context, ghClient *github.GitHubClient, component *appstudiov1alpha1.Component, application *appstudiov1alpha1.Application, compDevfileData data.DevfileData) error {
 	log := ctrl.LoggerFrom(ctx)
 
 	gitOpsURL, gitOpsBranch, gitOpsContext, err := util.ProcessGitOpsStatus(component.Status.GitOps, ghClient.Token)
@@ -602,14 +603,49 @@ func (r *ComponentReconciler) generateGitops(ctx context.Context, ghClient *gith
 	// Generate and push the gitops resources
 	mappedGitOpsComponent := util.GetMappedGitOpsComponent(*component, kubernetesResources)
 
-	//add the token name to the metrics.  When we add more tokens and rotate, we can determine how evenly distributed the requests are
-	metrics.ControllerGitRequest.With(prometheus.Labels{"controller": componentName, "tokenName": ghClient.TokenName, "operation": "CloneGenerateAndPush"}).Inc()
-	err = r.Generator.CloneGenerateAndPush(tempDir, gitOpsURL, mappedGitOpsComponent, r.AppFS, gitOpsBranch, gitOpsContext, false)
+	gitOpsFormat, err := getGitOpsFormat(application)
 	if err != nil {
-		log.Error(err, "unable to generate gitops resources due to error")
+		log.Error(err, "unable to get the GitOps format of the application")
 		return err
 	}
 
+	// The workload kind decides which base resources are written to the GitOps repository
+	workload, err := getComponentWorkload(compDevfileData, deployAssociatedComponents)
+	if err != nil {
+		log.Error(err, "unable to get the workload configuration")
+		return err
+	}
+
+	if gitOpsFormat == gitops.HelmFormat {
+		metrics.ControllerGitRequest.With(prometheus.Labels{"controller": componentName, "tokenName": ghClient.TokenName, "operation": "CloneRepo"}).Inc()
+		err = r.Generator.CloneRepo(tempDir, gitOpsURL, mappedGitOpsComponent.Name, gitOpsBranch)
+		if err != nil {
+			log.Error(err, "unable to clone the gitops repository due to error")
+			return err
+		}
+
+		helmWorkload, helmResources := getHelmChartResources(kubernetesResources, workload.Kind, component.Name, component.Spec.Application, component.Spec.ContainerImage)
+		chartPath := gitops.GetHelmChartPath(filepath.Join(tempDir, mappedGitOpsComponent.Name, gitOpsContext), mappedGitOpsComponent.Name)
+		if err := gitops.GenerateHelmChart(r.AppFS, chartPath, mappedGitOpsComponent.Name, helmWorkload, helmResources); err != nil {
+			log.Error(err, "unable to generate the Helm chart of the component")
+			return err
+		}
+	} else {
+		//add the token name to the metrics.  When we add more tokens and rotate, we can determine how evenly distributed the requests are
+		metrics.ControllerGitRequest.With(prometheus.Labels{"controller": componentName, "tokenName": ghClient.TokenName, "operation": "CloneGenerateAndPush"}).Inc()
+		err = r.Generator.CloneGenerateAndPush(tempDir, gitOpsURL, mappedGitOpsComponent, r.AppFS, gitOpsBranch, gitOpsContext, false)
+		if err != nil {
+			log.Error(err, "unable to generate gitops resources due to error")
+			return err
+		}
+
+		basePath := filepath.Join(tempDir, component.Name, gitOpsContext, "components", mappedGitOpsComponent.Name, "base")
+		if err := removeBaseWorkload(r.AppFS, basePath, workload.Kind); err != nil {
+			log.Error(err, "unable to remove the base resources of the Deployment")
+			return err
+		}
+	}
+
 	//Gitops functions return sanitized error messages
 	metrics.ControllerGitRequest.With(prometheus.Labels{"controller": componentName, "tokenName": ghClient.TokenName, "operation": "CommitAndPush"}).Inc()
 	err = r.Generator.CommitAndPush(tempDir, "", gitOpsURL, mappedGitOpsComponent.Name, gitOpsBranch, "Generating GitOps resources")
@@ -634,6 +670,21 @@ func (r *ComponentReconciler) generateGitops(ctx context.Context, ghClient *gith
 	return r.AppFS.RemoveAll(tempDir)
 }
 
+// removeBaseWorkload removes the base Deployment that the gitops generator library always generates if the component runs as another
+// workload kind, and the base Service too if it runs as a Knative Service, which routes the traffic to the component itself
+func removeBaseWorkload(fs afero.Afero, basePath string, workloadKind devfile.WorkloadKind) error {
+	if workloadKind == devfile.DeploymentWorkloadKind {
+		return nil
+	}
+	if err := gitops.RemoveOverlayFile(fs, basePath, deploymentFileName); err != nil {
+		return err
+	}
+	if workloadKind == devfile.KnativeServiceWorkloadKind {
+		return gitops.RemoveOverlayFile(fs, basePath, serviceFileName)
+	}
+	return nil
+}
+
 // setGitopsStatus adds the necessary gitops info (url, branch, context) to the component CR status
 func setGitopsStatus(component *appstudiov1alpha1.Component, devfileData data.DevfileData) error {
 	var err error
@@ -676,7 +727,7 @@ func setGitopsStatus(component *appstudiov1alpha1.Component, devfileData data.De
 func (r *ComponentReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	log := ctrl.LoggerFrom(ctx).WithName("controllers").WithName("Component")
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&appstudiov1alpha1.Component{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&appstudiov1alpha1.Component{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, annotationsChangedPredicate(AutoscalingAnnotation, ProbesAnnotation, ContainersAnnotation, WorkloadAnnotation, AvailabilityAnnotation, NetworkPolicyAnnotation)))).
 		WithOptions(controller.Options{
 			RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Duration(500*time.Millisecond), time.Duration(1000*time.Second)),
 		}).WithEventFilter(predicate.Funcs{
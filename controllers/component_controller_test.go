@@ -776,9 +776,6 @@ var _ = Describe("Component controller", func() {
 				Spec: appstudiov1alpha1.ApplicationSpec{
 					DisplayName: DisplayName,
 					Description: Description,
-					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
-						URL: "https://github.com/redhat-appstudio-appdata/!@#$%U%I$F    DFDN##",
-					},
 				},
 			}
 
@@ -790,6 +787,9 @@ var _ = Describe("Component controller", func() {
 				return len(createdHasApp.Status.Conditions) > 0
 			}, timeout, interval).Should(BeTrue())
 
+			// Set the invalid gitops repository url in the devfile, as the Application rejects it in its spec
+			createdHasApp = setAppGitOpsRepositoryURL(hasAppLookupKey, "https://github.com/redhat-appstudio-appdata/!@#$%U%I$F    DFDN##")
+
 			hasComp := &appstudiov1alpha1.Component{
 				TypeMeta: metav1.TypeMeta{
 					APIVersion: "appstudio.redhat.com/v1alpha1",
@@ -321,11 +321,12 @@ func TestGenerateGitops(t *testing.T) {
 	}
 
 	tests := []struct {
-		name       string
-		reconciler *ComponentReconciler
-		fs         afero.Afero
-		component  *appstudiov1alpha1.Component
-		wantErr    bool
+		name        string
+		reconciler  *ComponentReconciler
+		fs          afero.Afero
+		component   *appstudiov1alpha1.Component
+		application *appstudiov1alpha1.Application
+		wantErr     bool
 	}{
 		{
 			name:       "Simple application component, no errors",
@@ -351,6 +352,64 @@ func TestGenerateGitops(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:       "Application component with a Helm chart",
+			reconciler: r,
+			fs:         appFS,
+			component: &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: componentSpec,
+				Status: appstudiov1alpha1.ComponentStatus{
+					GitOps: appstudiov1alpha1.GitOpsStatus{
+						RepositoryURL: "https://github.com/test/repo",
+						Branch:        "main",
+						Context:       "/test",
+					},
+				},
+			},
+			application: &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-app",
+					Annotations: map[string]string{GitOpsFormatAnnotation: "helm"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:       "Application with an invalid GitOps format",
+			reconciler: r,
+			fs:         appFS,
+			component: &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: componentSpec,
+				Status: appstudiov1alpha1.ComponentStatus{
+					GitOps: appstudiov1alpha1.GitOpsStatus{
+						RepositoryURL: "https://github.com/test/repo",
+					},
+				},
+			},
+			application: &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-app",
+					Annotations: map[string]string{GitOpsFormatAnnotation: "jsonnet"},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name:       "Invalid application component, no labels",
 			reconciler: r,
@@ -557,7 +616,11 @@ func TestGenerateGitops(t *testing.T) {
 				Client:    github.GetMockedClient(),
 				TokenName: "some-token",
 			}
-			err := tt.reconciler.generateGitops(ctx, mockedClient, tt.component, mockDevfileData)
+			application := tt.application
+			if application == nil {
+				application = &appstudiov1alpha1.Application{}
+			}
+			err := tt.reconciler.generateGitops(ctx, mockedClient, tt.component, application, mockDevfileData)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("TestGenerateGitops() unexpected error: %v", err)
 			}
@@ -44,7 +44,6 @@ import (
 	"github.com/redhat-appstudio/application-service/pkg/spi"
 	"github.com/redhat-appstudio/application-service/pkg/util"
 	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
-	"github.com/redhat-developer/alizer/go/pkg/apis/model"
 	"github.com/spf13/afero"
 )
 
@@ -142,7 +141,6 @@ func (r *ComponentDetectionQueryReconciler) Reconcile(ctx context.Context, req c
 		dockerfileContextMap := make(map[string]string)
 		componentPortsMap := make(map[string][]int)
 		context := source.Context
-		var components []model.Component
 
 		if context == "" {
 			context = "./"
@@ -188,7 +186,6 @@ func (r *ComponentDetectionQueryReconciler) Reconcile(ctx context.Context, req c
 		componentDetectionQuery.Spec.GitSource.Revision = source.Revision
 
 		if source.DevfileURL == "" {
-			isMultiComponent := false
 			isDockerfilePresent := false
 			isDevfilePresent := false
 			log.Info(fmt.Sprintf("Attempting to read a devfile from the URL %s... %v", source.URL, req.NamespacedName))
@@ -259,47 +256,18 @@ func (r *ComponentDetectionQueryReconciler) Reconcile(ctx context.Context, req c
 				componentPath = path.Join(clonePath, context)
 			}
 
-			// Clone the repo if no Dockerfile or Containerfile present
-			if !isDockerfilePresent {
-				log.Info(fmt.Sprintf("Unable to find devfile, Dockerfile or Containerfile under root directory, run Alizer to detect components... %v", req.NamespacedName))
-
-				if !isDevfilePresent {
-					components, err = r.AlizerClient.DetectComponents(componentPath)
-					if err != nil {
-						log.Error(err, fmt.Sprintf("Unable to detect components using Alizer for repo %v, under path %v... %v ", source.URL, componentPath, req.NamespacedName))
-						r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, copiedCDQ, err)
-						return ctrl.Result{}, nil
-					}
-					log.Info(fmt.Sprintf("components detected %v... %v", components, req.NamespacedName))
-					// If no devfile and no Dockerfile or Containerfile present in the root
-					// case 1: no components been detected by Alizer, might still has subfolders contains Dockerfile or Containerfile. Need to scan repo
-					// case 2: one or more than 1 compinents been detected by Alizer, and the first one in the list is under sub-folder. Need to scan repo.
-					if len(components) == 0 || (len(components) != 0 && path.Clean(components[0].Path) != path.Clean(componentPath)) {
-						isMultiComponent = true
-					}
-				}
+			isMultiComponent, err := devfile.IsMultiComponent(log, r.AlizerClient, componentPath, isDevfilePresent, isDockerfilePresent)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to detect components using Alizer for repo %v, under path %v... %v ", source.URL, componentPath, req.NamespacedName))
+				r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, copiedCDQ, err)
+				return ctrl.Result{}, nil
 			}
 
-			// Logic to read multiple components in from git
-			if isMultiComponent {
-				log.Info(fmt.Sprintf("Since this is a multi-component, attempt will be made to read only level 1 dir for devfiles... %v", req.NamespacedName))
-
-				devfilesMap, devfilesURLMap, dockerfileContextMap, componentPortsMap, err = devfile.ScanRepo(log, r.AlizerClient, componentPath, r.DevfileRegistryURL, source)
-				if err != nil {
-					if _, ok := err.(*devfile.NoDevfileFound); !ok {
-						log.Error(err, fmt.Sprintf("Unable to find devfile(s) in repo %s due to an error %s, exiting reconcile loop %v", source.URL, err.Error(), req.NamespacedName))
-						r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, copiedCDQ, err)
-						return ctrl.Result{}, nil
-					}
-				}
-			} else {
-				log.Info(fmt.Sprintf("Since this is not a multi-component, attempt will be made to read devfile at the root dir... %v", req.NamespacedName))
-				err := devfile.AnalyzePath(log, r.AlizerClient, componentPath, context, r.DevfileRegistryURL, devfilesMap, devfilesURLMap, dockerfileContextMap, componentPortsMap, isDevfilePresent, isDockerfilePresent)
-				if err != nil {
-					log.Error(err, fmt.Sprintf("Unable to analyze path %s for a devfile, Dockerfile or Containerfile %v", componentPath, req.NamespacedName))
-					r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, copiedCDQ, err)
-					return ctrl.Result{}, nil
-				}
+			devfilesMap, devfilesURLMap, dockerfileContextMap, componentPortsMap, err = devfile.DetectLocalComponents(log, r.AlizerClient, componentPath, context, r.DevfileRegistryURL, source, devfilesMap, devfilesURLMap, dockerfileContextMap, componentPortsMap, isMultiComponent, isDevfilePresent, isDockerfilePresent)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to find devfile(s) in repo %s under path %s due to an error %s, exiting reconcile loop %v", source.URL, componentPath, err.Error(), req.NamespacedName))
+				r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, copiedCDQ, err)
+				return ctrl.Result{}, nil
 			}
 		} else {
 			log.Info(fmt.Sprintf("devfile was explicitly specified at %s %v", source.DevfileURL, req.NamespacedName))
@@ -0,0 +1,72 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	devfileParser "github.com/devfile/library/v2/pkg/devfile/parser"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+)
+
+// getGitOpsFormat returns the format of the GitOps resources of the Application, kustomize unless the Application selects Helm charts
+func getGitOpsFormat(application *appstudiov1alpha1.Application) (gitops.Format, error) {
+	format := gitops.Format(application.GetAnnotations()[GitOpsFormatAnnotation])
+	switch format {
+	case "":
+		return gitops.KustomizeFormat, nil
+	case gitops.KustomizeFormat, gitops.HelmFormat:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid %s annotation on Application %s: %q must be either %s or %s", GitOpsFormatAnnotation, application.Name, format, gitops.KustomizeFormat, gitops.HelmFormat)
+}
+
+// getHelmChartResources splits the resources of a component into its workload, which the Helm chart values configure, and the other resources of the chart.
+// If the resources do not have a workload of the given kind, a Deployment is generated for the component like the gitops generator library does
+func getHelmChartResources(kubernetesResources devfileParser.KubernetesResources, workloadKind devfile.WorkloadKind, componentName, applicationName, image string) (interface{}, []interface{}) {
+	var otherResources []interface{}
+	workload := devfile.GetWorkload(kubernetesResources, workloadKind)
+	if workload == nil {
+		deployment := devfile.GenerateDeploymentTemplate(componentName, applicationName, image)
+		workload = &deployment
+	}
+
+	for i, deployment := range kubernetesResources.Deployments {
+		if i > 0 || workloadKind != devfile.DeploymentWorkloadKind {
+			otherResources = append(otherResources, deployment)
+		}
+	}
+	for _, service := range kubernetesResources.Services {
+		otherResources = append(otherResources, service)
+	}
+	for _, route := range kubernetesResources.Routes {
+		otherResources = append(otherResources, route)
+	}
+	for _, ingress := range kubernetesResources.Ingresses {
+		otherResources = append(otherResources, ingress)
+	}
+	isWorkloadFound := workloadKind == devfile.DeploymentWorkloadKind
+	for _, other := range kubernetesResources.Others {
+		if !isWorkloadFound && devfile.GetWorkload(devfileParser.KubernetesResources{Others: []interface{}{other}}, workloadKind) != nil {
+			isWorkloadFound = true
+			continue
+		}
+		otherResources = append(otherResources, other)
+	}
+	return workload, otherResources
+}
@@ -63,3 +63,62 @@ func MapToBindingByBoundObjectName(cl client.Client, objectType, label string) f
 		return req
 	}
 }
+
+// MapComponentToBindings maps the Component to the Bindings of its Application that bind the Component.
+// The Bindings of the Application are listed using the appstudio.application label, and filtered on their components
+func MapComponentToBindings(cl client.Client) func(object client.Object) []reconcile.Request {
+	return func(obj client.Object) []reconcile.Request {
+		mapperLog := ctrl.Log.WithName("MapComponentToBindings")
+		log := mapperLog.WithValues("name", obj.GetName()).WithValues("namespace", obj.GetNamespace()).WithValues("controllerKind", obj.GetObjectKind())
+		ctx := context.Background()
+
+		component, ok := obj.(*appstudiov1alpha1.Component)
+		if !ok || component.Spec.Application == "" {
+			return []reconcile.Request{}
+		}
+
+		bindingList := &appstudiov1alpha1.SnapshotEnvironmentBindingList{}
+		err := cl.List(ctx, bindingList,
+			client.InNamespace(obj.GetNamespace()),
+			client.MatchingLabels{"appstudio.application": component.Spec.Application})
+		if err != nil {
+			log.Error(err, fmt.Sprintf("unable to list SnapshotEnvironmentBinding for a Component object %s", obj.GetName()))
+			return []reconcile.Request{}
+		}
+
+		req := []reconcile.Request{}
+		for _, item := range bindingList.Items {
+			for _, bindingComponent := range item.Spec.Components {
+				if bindingComponent.Name == component.Name {
+					req = append(req, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Namespace: item.Namespace,
+							Name:      item.Name,
+						},
+					})
+					log.Info(fmt.Sprintf("The corresponding SnapshotEnvironmentBinding %s will be reconciled", item.Name))
+					break
+				}
+			}
+		}
+		if len(req) == 0 {
+			log.Info(fmt.Sprintf("no SnapshotEnvironmentBinding found for a Component object %s", obj.GetName()))
+		}
+		return req
+	}
+}
+
+// MapToApplication maps the Component or the SnapshotEnvironmentBinding to its Application
+func MapToApplication(obj client.Object) []reconcile.Request {
+	var applicationName string
+	switch object := obj.(type) {
+	case *appstudiov1alpha1.Component:
+		applicationName = object.Spec.Application
+	case *appstudiov1alpha1.SnapshotEnvironmentBinding:
+		applicationName = object.Spec.Application
+	}
+	if applicationName == "" {
+		return []reconcile.Request{}
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: applicationName}}}
+}
@@ -73,6 +73,7 @@ func TestMapToBindingByBoundObject(t *testing.T) {
 			Labels: map[string]string{
 				"appstudio.environment": staging,
 				"appstudio.application": applicationName,
+				"appstudio.snapshot":    snapshotName,
 			},
 		},
 		Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
@@ -101,6 +102,7 @@ func TestMapToBindingByBoundObject(t *testing.T) {
 			Labels: map[string]string{
 				"appstudio.environment": dev,
 				"appstudio.application": applicationName2,
+				"appstudio.snapshot":    snapshotName2,
 			},
 		},
 		Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
@@ -129,6 +131,7 @@ func TestMapToBindingByBoundObject(t *testing.T) {
 			Labels: map[string]string{
 				"appstudio.environment": staging,
 				"appstudio.application": applicationName2,
+				"appstudio.snapshot":    snapshotName,
 			},
 		},
 		Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
@@ -238,6 +241,62 @@ func TestMapToBindingByBoundObject(t *testing.T) {
 		require.Empty(t, requests)
 	})
 
+	t.Run("should return two Binding requests for the Snapshot", func(t *testing.T) {
+		snapshot := &appstudiov1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      snapshotName,
+				Namespace: Namespace,
+			},
+		}
+
+		// when
+		requests := MapToBindingByBoundObjectName(fakeClient, "Snapshot", "appstudio.snapshot")(snapshot)
+
+		// then
+		require.Len(t, requests, 2) // binding4 is not returned because binding4 does not have a label matching the snapshot
+		assert.Contains(t, requests, newRequest(binding1.Name))
+		assert.Contains(t, requests, newRequest(binding3.Name))
+	})
+
+	t.Run("should return the Binding requests of the Component Application", func(t *testing.T) {
+		component := &appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      componentName,
+				Namespace: Namespace,
+			},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName: ComponentName,
+				Application:   applicationName2,
+			},
+		}
+
+		// when
+		requests := MapComponentToBindings(fakeClient)(component)
+
+		// then
+		require.Len(t, requests, 1) // binding2 is not returned because it does not bind the component
+		assert.Contains(t, requests, newRequest(binding3.Name))
+	})
+
+	t.Run("should return no Binding requests for a Component that is not bound", func(t *testing.T) {
+		component := &appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      componentName2,
+				Namespace: Namespace,
+			},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName: ComponentName,
+				Application:   applicationName,
+			},
+		}
+
+		// when
+		requests := MapComponentToBindings(fakeClient)(component)
+
+		// then
+		require.Empty(t, requests)
+	})
+
 	t.Run("should return no Binding requests when Binding list fails", func(t *testing.T) {
 		fakeClient.MockList = func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
 			return fmt.Errorf("some error")
@@ -247,6 +306,15 @@ func TestMapToBindingByBoundObject(t *testing.T) {
 
 		// then
 		require.Empty(t, requests)
+
+		// when
+		requests = MapComponentToBindings(fakeClient)(&appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: componentName, Namespace: Namespace},
+			Spec:       appstudiov1alpha1.ComponentSpec{Application: applicationName},
+		})
+
+		// then
+		require.Empty(t, requests)
 	})
 }
 
@@ -293,3 +361,48 @@ func (c *FakeClient) Get(ctx context.Context, key types.NamespacedName, obj clie
 	}
 	return c.Client.Get(ctx, key, obj)
 }
+
+func TestMapToApplication(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  client.Object
+		want []reconcile.Request
+	}{
+		{
+			name: "Component",
+			obj: &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+				Spec:       appstudiov1alpha1.ComponentSpec{Application: "test-app"},
+			},
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "test-app", Namespace: "default"}}},
+		},
+		{
+			name: "SnapshotEnvironmentBinding",
+			obj: &appstudiov1alpha1.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-binding", Namespace: "default"},
+				Spec:       appstudiov1alpha1.SnapshotEnvironmentBindingSpec{Application: "test-app"},
+			},
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "test-app", Namespace: "default"}}},
+		},
+		{
+			name: "Component without Application",
+			obj: &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+			},
+			want: []reconcile.Request{},
+		},
+		{
+			name: "Other object",
+			obj: &appstudiov1alpha1.Environment{
+				ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "default"},
+			},
+			want: []reconcile.Request{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MapToApplication(tt.obj))
+		})
+	}
+}
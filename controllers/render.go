@@ -0,0 +1,134 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"path/filepath"
+
+	devfileParser "github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
+	"github.com/spf13/afero"
+)
+
+// RenderOptions are the inputs of the offline render of the GitOps resources of a Component
+type RenderOptions struct {
+	// Component is the Component to render, with its devfile in its status
+	Component appstudiov1alpha1.Component
+	// Environment renders the environment overlay of the Component too, or its Helm values, if not nil
+	Environment *appstudiov1alpha1.Environment
+	// Snapshot is the Snapshot the Component is bound to the Environment at. The Component is bound at its own image if nil
+	Snapshot *appstudiov1alpha1.Snapshot
+	// Format is the format of the GitOps resources, kustomize if empty
+	Format gitops.Format
+	// Context is the directory of the GitOps repository the resources are written in
+	Context string
+}
+
+// RenderGitOps writes the GitOps resources that the Component controller would push to the GitOps repository of the Component, and that the
+// SnapshotEnvironmentBinding controller would push for a binding of the Component alone to the Environment of the options, to the repository path.
+// Nothing is cloned or pushed, so the resources can be previewed and compared to golden files
+func RenderGitOps(log logr.Logger, fs afero.Afero, repoPath string, options RenderOptions) error {
+	component := options.Component
+	gitOpsFolder := filepath.Join(repoPath, options.Context)
+
+	compDevfileData, err := devfile.ParseDevfile(devfile.DevfileSrc{Data: component.Status.Devfile})
+	if err != nil {
+		return fmt.Errorf("unable to parse the devfile of the Component %s: %v", component.Name, err)
+	}
+	deployAssociatedComponents, err := devfileParser.GetDeployComponents(compDevfileData)
+	if err != nil {
+		return err
+	}
+	kubernetesResources, err := devfile.GetResourceFromDevfile(log, compDevfileData, deployAssociatedComponents, component.Name, component.Spec.Application, component.Spec.ContainerImage, "")
+	if err != nil {
+		return err
+	}
+	mappedGitOpsComponent := util.GetMappedGitOpsComponent(component, kubernetesResources)
+	workload, err := getComponentWorkload(compDevfileData, deployAssociatedComponents)
+	if err != nil {
+		return err
+	}
+
+	// The base resources, as generated by the Component controller
+	chartPath := gitops.GetHelmChartPath(gitOpsFolder, mappedGitOpsComponent.Name)
+	if options.Format == gitops.HelmFormat {
+		helmWorkload, helmResources := getHelmChartResources(kubernetesResources, workload.Kind, component.Name, component.Spec.Application, component.Spec.ContainerImage)
+		if err := gitops.GenerateHelmChart(fs, chartPath, mappedGitOpsComponent.Name, helmWorkload, helmResources); err != nil {
+			return err
+		}
+	} else {
+		basePath := filepath.Join(gitOpsFolder, "components", mappedGitOpsComponent.Name, "base")
+		if err := gitopsgen.Generate(fs, gitOpsFolder, basePath, mappedGitOpsComponent); err != nil {
+			return err
+		}
+		if err := removeBaseWorkload(fs, basePath, workload.Kind); err != nil {
+			return err
+		}
+	}
+
+	if options.Environment == nil {
+		return nil
+	}
+	environment := *options.Environment
+
+	// The overlay of the environment, as generated by the SnapshotEnvironmentBinding controller
+	snapshot := appstudiov1alpha1.Snapshot{
+		Spec: appstudiov1alpha1.SnapshotSpec{
+			Application: component.Spec.Application,
+			Components:  []appstudiov1alpha1.SnapshotComponent{{Name: component.Name, ContainerImage: component.Spec.ContainerImage}},
+		},
+	}
+	if options.Snapshot != nil {
+		snapshot = *options.Snapshot
+	}
+	environmentWorkload, err := getEnvironmentWorkload(&environment)
+	if err != nil {
+		return err
+	}
+	environmentSecrets, err := getEnvironmentSecrets(&environment)
+	if err != nil {
+		return err
+	}
+	overlay, err := newEnvironmentOverlay(log, &component, appstudiov1alpha1.BindingComponent{Name: component.Name}, environment, environmentWorkload, snapshot)
+	if err != nil {
+		return err
+	}
+	overlay.secrets = environmentSecrets
+
+	if options.Format == gitops.HelmFormat {
+		helmValues, err := getHelmEnvironmentValues(log, overlay)
+		if err != nil {
+			return err
+		}
+		_, err = gitops.GenerateHelmEnvironmentValues(fs, chartPath, environment.Name, helmValues)
+		return err
+	}
+
+	networkPolicy, err := getComponentNetworkPolicy(compDevfileData, deployAssociatedComponents)
+	if err != nil {
+		return err
+	}
+	if networkPolicy != nil {
+		overlay.podTemplateLabels = map[string]string{devfile.PartOfLabel: component.Spec.Application}
+	}
+	return generateKustomizeOverlay(log, fs, gitopsgen.NewGitopsGenWithLogger(log), repoPath, false, "", "", options.Context, environment.Name, overlay, make(map[string][]string))
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestRenderGitOps(t *testing.T) {
+	renderDevfile := `
+schemaVersion: 2.2.0
+metadata:
+  name: backend
+components:
+- name: kubernetes-deploy
+  attributes:
+    deployment/container-port: 8080
+  kubernetes:
+    deployByDefault: false
+    inlined: |-
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: backend
+      spec:
+        template:
+          spec:
+            containers:
+            - image: quay.io/test/backend:latest
+              name: backend
+commands:
+- id: deployk8s
+  apply:
+    component: kubernetes-deploy
+    group:
+      kind: deploy
+      isDefault: true
+`
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ComponentName:  "backend",
+			Application:    "petclinic",
+			ContainerImage: "quay.io/test/backend:latest",
+			TargetPort:     8080,
+		},
+		Status: appstudiov1alpha1.ComponentStatus{Devfile: renderDevfile},
+	}
+	environment := &appstudiov1alpha1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "default"},
+		Spec: appstudiov1alpha1.EnvironmentSpec{
+			Configuration: appstudiov1alpha1.EnvironmentConfiguration{
+				Env: []appstudiov1alpha1.EnvVarPair{{Name: "LOG_LEVEL", Value: "debug"}},
+			},
+		},
+	}
+	snapshot := &appstudiov1alpha1.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "petclinic-snapshot", Namespace: "default"},
+		Spec: appstudiov1alpha1.SnapshotSpec{
+			Application: "petclinic",
+			Components:  []appstudiov1alpha1.SnapshotComponent{{Name: "backend", ContainerImage: "quay.io/test/backend:v2"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		options   RenderOptions
+		wantFiles []string
+		// wantFile is a file expected to contain wantContent
+		wantFile    string
+		wantContent string
+		wantErr     string
+	}{
+		{
+			name:    "Base",
+			options: RenderOptions{Component: component},
+			wantFiles: []string{
+				"components/backend/base/deployment.yaml",
+				"components/backend/base/kustomization.yaml",
+				"components/backend/base/service.yaml",
+			},
+		},
+		{
+			name:    "Base and environment overlay",
+			options: RenderOptions{Component: component, Environment: environment, Snapshot: snapshot, Context: "gitops"},
+			wantFiles: []string{
+				"gitops/components/backend/base/deployment.yaml",
+				"gitops/components/backend/base/kustomization.yaml",
+				"gitops/components/backend/base/service.yaml",
+				"gitops/components/backend/overlays/staging/deployment-patch.yaml",
+				"gitops/components/backend/overlays/staging/kustomization.yaml",
+				"gitops/components/backend/overlays/staging/route.yaml",
+			},
+			wantFile:    "gitops/components/backend/overlays/staging/deployment-patch.yaml",
+			wantContent: "quay.io/test/backend:v2",
+		},
+		{
+			name:    "Helm chart and values",
+			options: RenderOptions{Component: component, Environment: environment, Format: gitops.HelmFormat},
+			wantFiles: []string{
+				"components/backend/chart/Chart.yaml",
+				"components/backend/chart/templates/deployment-backend.yaml",
+				"components/backend/chart/values-staging.yaml",
+				"components/backend/chart/values.yaml",
+			},
+		},
+		{
+			name:    "Snapshot without the Component",
+			options: RenderOptions{Component: component, Environment: environment, Snapshot: &appstudiov1alpha1.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "other-snapshot"}}},
+			wantErr: "application snapshot other-snapshot did not reference component backend",
+		},
+		{
+			name: "Invalid devfile",
+			options: RenderOptions{Component: appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend"},
+				Status:     appstudiov1alpha1.ComponentStatus{Devfile: "schemaVersion: 2.2.0\ncomponents: invalid"},
+			}},
+			wantErr: "unable to parse the devfile of the Component backend",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			err := RenderGitOps(ctrl.Log, fs, "/repo", tt.options)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			var files []string
+			err = fs.Walk("/repo", func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					relPath, _ := filepath.Rel("/repo", path)
+					files = append(files, relPath)
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			sort.Strings(files)
+			assert.Equal(t, tt.wantFiles, files)
+			if tt.wantFile != "" {
+				content, err := fs.ReadFile(filepath.Join("/repo", tt.wantFile))
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Contains(t, string(content), tt.wantContent)
+			}
+		})
+	}
+}
@@ -121,12 +121,14 @@ func setupTestEnv() {
 	gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 	err = (&SnapshotEnvironmentBindingReconciler{
-		Client:            k8sManager.GetClient(),
-		Scheme:            k8sManager.GetScheme(),
-		Log:               ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
-		Generator:         gitops.NewMockGenerator(),
-		AppFS:             ioutils.NewMemoryFilesystem(),
-		GitHubTokenClient: mockGhTokenClient,
+		Client:             k8sManager.GetClient(),
+		Scheme:             k8sManager.GetScheme(),
+		Log:                ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
+		Generator:          gitops.NewMockGenerator(),
+		AppFS:              ioutils.NewMemoryFilesystem(),
+		GitHubTokenClient:  mockGhTokenClient,
+		RepositoryDiffer:   gitops.MockRepositoryDiffer{},
+		RepositoryReverter: gitops.MockRepositoryReverter{},
 	}).SetupWithManager(ctx, k8sManager)
 	gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
@@ -18,7 +18,9 @@ package controllers
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -30,6 +32,8 @@ import (
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
 	"github.com/redhat-appstudio/application-service/pkg/util"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -198,6 +202,149 @@ func (r *ComponentReconciler) updateComponentDevfileModel(req ctrl.Request, hasC
 			}
 		}
 
+		// Update for autoscaling
+		var autoscaling devfile.Autoscaling
+		if isSet, err := getJSONAnnotation(&component, AutoscalingAnnotation, &autoscaling); err != nil {
+			return err
+		} else if isSet {
+			if err := autoscaling.Validate(); err != nil {
+				return fmt.Errorf("invalid %s annotation: %v", AutoscalingAnnotation, err)
+			}
+			currentAutoscaling, err := devfile.GetAutoscalingFromAttributes(kubernetesComponent.Attributes)
+			if err != nil {
+				return err
+			}
+			if currentAutoscaling == nil || !reflect.DeepEqual(*currentAutoscaling, autoscaling) {
+				log.Info(fmt.Sprintf("setting devfile component %s attribute autoscaling with max replicas %v", kubernetesComponent.Name, autoscaling.MaxReplicas))
+				kubernetesComponent.Attributes = kubernetesComponent.Attributes.FromMap(map[string]interface{}{devfile.AutoscalingKey: autoscaling}, &err)
+				if err != nil {
+					return err
+				}
+				compUpdateRequired = true
+			}
+		}
+
+		// Update for probes
+		var probes devfile.Probes
+		if isSet, err := getJSONAnnotation(&component, ProbesAnnotation, &probes); err != nil {
+			return err
+		} else if isSet {
+			if err := probes.Validate(); err != nil {
+				return fmt.Errorf("invalid %s annotation: %v", ProbesAnnotation, err)
+			}
+			currentProbes, err := devfile.GetProbesFromAttributes(kubernetesComponent.Attributes)
+			if err != nil {
+				return err
+			}
+			if currentProbes == nil || !reflect.DeepEqual(*currentProbes, probes) {
+				log.Info(fmt.Sprintf("setting devfile component %s attribute probes", kubernetesComponent.Name))
+				kubernetesComponent.Attributes = kubernetesComponent.Attributes.FromMap(map[string]interface{}{devfile.ProbesKey: probes}, &err)
+				if err != nil {
+					return err
+				}
+				compUpdateRequired = true
+			}
+		}
+
+		// Update for sidecars and init containers
+		var podContainers devfile.PodContainers
+		if isSet, err := getJSONAnnotation(&component, ContainersAnnotation, &podContainers); err != nil {
+			return err
+		} else if isSet {
+			if err := podContainers.Validate(); err != nil {
+				return fmt.Errorf("invalid %s annotation: %v", ContainersAnnotation, err)
+			}
+			currentPodContainers, err := devfile.GetPodContainersFromAttributes(kubernetesComponent.Attributes)
+			if err != nil {
+				return err
+			}
+			if currentPodContainers == nil || !reflect.DeepEqual(*currentPodContainers, podContainers) {
+				log.Info(fmt.Sprintf("setting devfile component %s attribute containers with %d sidecars and %d init containers", kubernetesComponent.Name, len(podContainers.Sidecars), len(podContainers.InitContainers)))
+				kubernetesComponent.Attributes = kubernetesComponent.Attributes.FromMap(map[string]interface{}{devfile.ContainersKey: podContainers}, &err)
+				if err != nil {
+					return err
+				}
+				compUpdateRequired = true
+			}
+		}
+
+		// Update for the workload kind
+		var workload devfile.Workload
+		if isSet, err := getJSONAnnotation(&component, WorkloadAnnotation, &workload); err != nil {
+			return err
+		} else if isSet {
+			if err := workload.Validate(); err != nil {
+				return fmt.Errorf("invalid %s annotation: %v", WorkloadAnnotation, err)
+			}
+			if workload.Kind == "" {
+				workload.Kind = devfile.DeploymentWorkloadKind
+			}
+			currentWorkload, err := devfile.GetWorkloadFromAttributes(kubernetesComponent.Attributes)
+			if err != nil {
+				return err
+			}
+			if !reflect.DeepEqual(currentWorkload, workload) {
+				log.Info(fmt.Sprintf("setting devfile component %s attribute workload to %s", kubernetesComponent.Name, workload.Kind))
+				kubernetesComponent.Attributes = kubernetesComponent.Attributes.FromMap(map[string]interface{}{devfile.WorkloadKey: workload}, &err)
+				if err != nil {
+					return err
+				}
+				compUpdateRequired = true
+			}
+		}
+
+		// Update for the availability
+		var availability devfile.Availability
+		if isSet, err := getJSONAnnotation(&component, AvailabilityAnnotation, &availability); err != nil {
+			return err
+		} else if isSet {
+			if err := availability.Validate(); err != nil {
+				return fmt.Errorf("invalid %s annotation: %v", AvailabilityAnnotation, err)
+			}
+			currentAvailability, err := devfile.GetAvailabilityFromAttributes(kubernetesComponent.Attributes)
+			if err != nil {
+				return err
+			}
+			if currentAvailability == nil || !reflect.DeepEqual(*currentAvailability, availability) {
+				log.Info(fmt.Sprintf("setting devfile component %s attribute availability", kubernetesComponent.Name))
+				kubernetesComponent.Attributes = kubernetesComponent.Attributes.FromMap(map[string]interface{}{devfile.AvailabilityKey: availability}, &err)
+				if err != nil {
+					return err
+				}
+				compUpdateRequired = true
+			}
+		}
+
+		// Update for the network policy
+		var networkPolicy devfile.NetworkPolicy
+		if isSet, err := getJSONAnnotation(&component, NetworkPolicyAnnotation, &networkPolicy); err != nil {
+			return err
+		} else if isSet {
+			if err := networkPolicy.Validate(); err != nil {
+				return fmt.Errorf("invalid %s annotation: %v", NetworkPolicyAnnotation, err)
+			}
+			currentNetworkPolicy, err := devfile.GetNetworkPolicyFromAttributes(kubernetesComponent.Attributes)
+			if err != nil {
+				return err
+			}
+			if currentNetworkPolicy == nil || !reflect.DeepEqual(*currentNetworkPolicy, networkPolicy) {
+				log.Info(fmt.Sprintf("setting devfile component %s attribute network policy with %d dependencies", kubernetesComponent.Name, len(networkPolicy.Dependencies)))
+				kubernetesComponent.Attributes = kubernetesComponent.Attributes.FromMap(map[string]interface{}{devfile.NetworkPolicyKey: networkPolicy}, &err)
+				if err != nil {
+					return err
+				}
+				compUpdateRequired = true
+			}
+		}
+
+		// Remove the attributes whose annotation was removed from the Component
+		if changed, err := syncAnnotationAttributes(&kubernetesComponent, component.GetAnnotations()); err != nil {
+			return err
+		} else if changed {
+			log.Info(fmt.Sprintf("updating devfile component %s attributes copied from the Component annotations", kubernetesComponent.Name))
+			compUpdateRequired = true
+		}
+
 		if compUpdateRequired {
 			// Update the devfileComponent once it has been updated with the Component data
 			log.Info(fmt.Sprintf("updating devfile component name %s ...", kubernetesComponent.Name))
@@ -211,6 +358,51 @@ func (r *ComponentReconciler) updateComponentDevfileModel(req ctrl.Request, hasC
 	return nil
 }
 
+// annotationAttributeKeys are the keys of the devfile component attributes copied from the annotations of the Component
+var annotationAttributeKeys = map[string]string{
+	AutoscalingAnnotation:   devfile.AutoscalingKey,
+	ProbesAnnotation:        devfile.ProbesKey,
+	ContainersAnnotation:    devfile.ContainersKey,
+	WorkloadAnnotation:      devfile.WorkloadKey,
+	AvailabilityAnnotation:  devfile.AvailabilityKey,
+	NetworkPolicyAnnotation: devfile.NetworkPolicyKey,
+}
+
+// syncAnnotationAttributes records the keys of the attributes of the devfile component that are copied from the annotations of the Component,
+// and removes the recorded attributes whose annotation was removed, so that the configuration is removed with its annotation. The attributes
+// set in the devfile itself are kept. true is returned if the devfile component changed
+func syncAnnotationAttributes(kubernetesComponent *devfileAPIV1.Component, componentAnnotations map[string]string) (bool, error) {
+	var recordedKeys []string
+	if err := kubernetesComponent.Attributes.GetInto(devfile.AnnotationAttributesKey, &recordedKeys); err != nil {
+		if _, ok := err.(*attributes.KeyNotFoundError); !ok {
+			return false, err
+		}
+	}
+
+	annotations := maps.Keys(annotationAttributeKeys)
+	sort.Strings(annotations)
+	var keys []string
+	for _, annotation := range annotations {
+		key := annotationAttributeKeys[annotation]
+		if componentAnnotations[annotation] != "" {
+			keys = append(keys, key)
+		} else if slices.Contains(recordedKeys, key) {
+			delete(kubernetesComponent.Attributes, key)
+		}
+	}
+	if reflect.DeepEqual(keys, recordedKeys) {
+		return false, nil
+	}
+
+	if len(keys) == 0 {
+		delete(kubernetesComponent.Attributes, devfile.AnnotationAttributesKey)
+		return true, nil
+	}
+	var err error
+	kubernetesComponent.Attributes = kubernetesComponent.Attributes.FromMap(map[string]interface{}{devfile.AnnotationAttributesKey: keys}, &err)
+	return true, err
+}
+
 func (r *ComponentReconciler) updateApplicationDevfileModel(hasAppDevfileData data.DevfileData, component appstudiov1alpha1.Component) error {
 
 	if component.Spec.Source.GitSource != nil {
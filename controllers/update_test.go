@@ -30,7 +30,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -546,6 +548,265 @@ func TestUpdateComponentDevfileModel(t *testing.T) {
 	}
 }
 
+func TestUpdateComponentDevfileModelAnnotations(t *testing.T) {
+	minReplicas := int32(2)
+	minAvailable := intstr.FromString("50%")
+	kubernetesComponent := devfileAPIV1.Component{
+		Name: "component1",
+		ComponentUnion: devfileAPIV1.ComponentUnion{
+			Kubernetes: &devfileAPIV1.KubernetesComponent{
+				K8sLikeComponent: devfileAPIV1.K8sLikeComponent{
+					K8sLikeComponentLocation: devfileAPIV1.K8sLikeComponentLocation{
+						Uri: "testLocation",
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		attributes      map[string]interface{}
+		wantAutoscaling *devfilePkg.Autoscaling
+		wantProbes      *devfilePkg.Probes
+		wantContainers  *devfilePkg.PodContainers
+		wantWorkload    devfilePkg.Workload
+		wantAvailable   *devfilePkg.Availability
+		wantNetwork     *devfilePkg.NetworkPolicy
+		wantRecorded    []string
+		wantErr         bool
+	}{
+		{
+			name: "No annotations",
+		},
+		{
+			name: "Removed annotations are removed from the devfile",
+			attributes: map[string]interface{}{
+				devfilePkg.AutoscalingKey:          devfilePkg.Autoscaling{MaxReplicas: 4},
+				devfilePkg.ProbesKey:               devfilePkg.Probes{Readiness: &devfilePkg.Probe{Path: "/ready"}},
+				devfilePkg.ContainersKey:           devfilePkg.PodContainers{Main: "app"},
+				devfilePkg.WorkloadKey:             devfilePkg.Workload{Kind: devfilePkg.CronJobWorkloadKind, Schedule: "0 * * * *"},
+				devfilePkg.AvailabilityKey:         devfilePkg.Availability{MinAvailable: &minAvailable},
+				devfilePkg.NetworkPolicyKey:        devfilePkg.NetworkPolicy{Dependencies: []string{"backend"}},
+				devfilePkg.AnnotationAttributesKey: []string{devfilePkg.AutoscalingKey, devfilePkg.AvailabilityKey, devfilePkg.ContainersKey, devfilePkg.NetworkPolicyKey, devfilePkg.ProbesKey, devfilePkg.WorkloadKey},
+			},
+		},
+		{
+			name: "Attributes set in the devfile are kept",
+			attributes: map[string]interface{}{
+				devfilePkg.AutoscalingKey: devfilePkg.Autoscaling{MaxReplicas: 4},
+			},
+			wantAutoscaling: &devfilePkg.Autoscaling{MaxReplicas: 4},
+		},
+		{
+			name: "Only the removed annotation is removed from the devfile",
+			annotations: map[string]string{
+				NetworkPolicyAnnotation: `{"dependencies": ["backend"]}`,
+			},
+			attributes: map[string]interface{}{
+				devfilePkg.AutoscalingKey:          devfilePkg.Autoscaling{MaxReplicas: 4},
+				devfilePkg.NetworkPolicyKey:        devfilePkg.NetworkPolicy{Dependencies: []string{"backend"}},
+				devfilePkg.AnnotationAttributesKey: []string{devfilePkg.AutoscalingKey, devfilePkg.NetworkPolicyKey},
+			},
+			wantNetwork:  &devfilePkg.NetworkPolicy{Dependencies: []string{"backend"}},
+			wantRecorded: []string{devfilePkg.NetworkPolicyKey},
+		},
+		{
+			name: "Autoscaling annotation is copied to the devfile",
+			annotations: map[string]string{
+				AutoscalingAnnotation: `{"minReplicas": 2, "maxReplicas": 4}`,
+			},
+			wantAutoscaling: &devfilePkg.Autoscaling{
+				MinReplicas: &minReplicas,
+				MaxReplicas: 4,
+			},
+			wantRecorded: []string{devfilePkg.AutoscalingKey},
+		},
+		{
+			name: "Malformed autoscaling annotation",
+			annotations: map[string]string{
+				AutoscalingAnnotation: `{"maxReplicas": "four"}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid autoscaling bounds",
+			annotations: map[string]string{
+				AutoscalingAnnotation: `{"minReplicas": 5, "maxReplicas": 4}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Probes annotation is copied to the devfile",
+			annotations: map[string]string{
+				ProbesAnnotation: `{"readiness": {"path": "/ready"}, "liveness": {"type": "tcp"}}`,
+			},
+			wantProbes: &devfilePkg.Probes{
+				Readiness: &devfilePkg.Probe{Path: "/ready"},
+				Liveness:  &devfilePkg.Probe{Type: devfilePkg.TCPProbeType},
+			},
+			wantRecorded: []string{devfilePkg.ProbesKey},
+		},
+		{
+			name: "Invalid probe type",
+			annotations: map[string]string{
+				ProbesAnnotation: `{"readiness": {"type": "grpc"}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Containers annotation is copied to the devfile",
+			annotations: map[string]string{
+				ContainersAnnotation: `{"main": "app", "sidecars": [{"name": "proxy", "image": "quay.io/org/proxy:1.0"}]}`,
+			},
+			wantContainers: &devfilePkg.PodContainers{
+				Main:     "app",
+				Sidecars: []devfilePkg.Container{{Name: "proxy", Image: "quay.io/org/proxy:1.0"}},
+			},
+			wantRecorded: []string{devfilePkg.ContainersKey},
+		},
+		{
+			name: "Sidecar named after the main container",
+			annotations: map[string]string{
+				ContainersAnnotation: `{"main": "app", "sidecars": [{"name": "app", "image": "quay.io/org/proxy:1.0"}]}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Workload annotation is copied to the devfile",
+			annotations: map[string]string{
+				WorkloadAnnotation: `{"kind": "CronJob", "schedule": "0 * * * *"}`,
+			},
+			wantWorkload: devfilePkg.Workload{Kind: devfilePkg.CronJobWorkloadKind, Schedule: "0 * * * *"},
+			wantRecorded: []string{devfilePkg.WorkloadKey},
+		},
+		{
+			name: "Unsupported workload kind",
+			annotations: map[string]string{
+				WorkloadAnnotation: `{"kind": "DaemonSet"}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Availability annotation is copied to the devfile",
+			annotations: map[string]string{
+				AvailabilityAnnotation: `{"minAvailable": "50%", "topologySpread": ["zone"]}`,
+			},
+			wantAvailable: &devfilePkg.Availability{
+				MinAvailable:   &minAvailable,
+				TopologySpread: []devfilePkg.TopologyDomain{devfilePkg.ZoneTopologyDomain},
+			},
+			wantRecorded: []string{devfilePkg.AvailabilityKey},
+		},
+		{
+			name: "Availability with both disruption budget bounds",
+			annotations: map[string]string{
+				AvailabilityAnnotation: `{"minAvailable": 1, "maxUnavailable": 1}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Network policy annotation is copied to the devfile",
+			annotations: map[string]string{
+				NetworkPolicyAnnotation: `{"dependencies": ["backend"]}`,
+			},
+			wantNetwork:  &devfilePkg.NetworkPolicy{Dependencies: []string{"backend"}},
+			wantRecorded: []string{devfilePkg.NetworkPolicyKey},
+		},
+		{
+			name: "Empty network policy dependency",
+			annotations: map[string]string{
+				NetworkPolicyAnnotation: `{"dependencies": [""]}`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testComponent := *kubernetesComponent.DeepCopy()
+			if tt.attributes != nil {
+				var err error
+				testComponent.Attributes = attributes.Attributes{}.FromMap(tt.attributes, &err)
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+			}
+			devfileData := &v2.DevfileV2{
+				Devfile: devfileAPIV1.Devfile{
+					DevWorkspaceTemplateSpec: devfileAPIV1.DevWorkspaceTemplateSpec{
+						DevWorkspaceTemplateSpecContent: devfileAPIV1.DevWorkspaceTemplateSpecContent{
+							Components: []devfileAPIV1.Component{testComponent},
+						},
+					},
+				},
+			}
+			component := appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "componentName",
+					Annotations: tt.annotations,
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "componentName",
+					Application:   "applicationName",
+				},
+			}
+
+			r := ComponentReconciler{
+				Log: ctrl.Log.WithName("TestUpdateComponentDevfileModelAnnotations"),
+			}
+			err := r.updateComponentDevfileModel(ctrl.Request{}, devfileData, component)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				components, err := devfileData.GetComponents(common.DevfileOptions{})
+				if err != nil {
+					t.Errorf("got unexpected error %v", err)
+				}
+				autoscaling, err := devfilePkg.GetAutoscalingFromAttributes(components[0].Attributes)
+				if err != nil {
+					t.Errorf("got unexpected error %v", err)
+				}
+				assert.Equal(t, tt.wantAutoscaling, autoscaling, "autoscaling configuration did not match")
+				probes, err := devfilePkg.GetProbesFromAttributes(components[0].Attributes)
+				if err != nil {
+					t.Errorf("got unexpected error %v", err)
+				}
+				assert.Equal(t, tt.wantProbes, probes, "probes configuration did not match")
+				podContainers, err := devfilePkg.GetPodContainersFromAttributes(components[0].Attributes)
+				if err != nil {
+					t.Errorf("got unexpected error %v", err)
+				}
+				assert.Equal(t, tt.wantContainers, podContainers, "containers configuration did not match")
+				workload, err := devfilePkg.GetWorkloadFromAttributes(components[0].Attributes)
+				if err != nil {
+					t.Errorf("got unexpected error %v", err)
+				}
+				if tt.wantWorkload.Kind == "" {
+					tt.wantWorkload.Kind = devfilePkg.DeploymentWorkloadKind
+				}
+				assert.Equal(t, tt.wantWorkload, workload, "workload configuration did not match")
+				availability, err := devfilePkg.GetAvailabilityFromAttributes(components[0].Attributes)
+				if err != nil {
+					t.Errorf("got unexpected error %v", err)
+				}
+				assert.Equal(t, tt.wantAvailable, availability, "availability configuration did not match")
+				networkPolicy, err := devfilePkg.GetNetworkPolicyFromAttributes(components[0].Attributes)
+				if err != nil {
+					t.Errorf("got unexpected error %v", err)
+				}
+				assert.Equal(t, tt.wantNetwork, networkPolicy, "network policy configuration did not match")
+				var recorded []string
+				_ = components[0].Attributes.GetInto(devfilePkg.AnnotationAttributesKey, &recorded)
+				assert.Equal(t, tt.wantRecorded, recorded, "recorded annotation attributes did not match")
+			}
+		})
+	}
+}
+
 func TestUpdateComponentStub(t *testing.T) {
 	var err error
 	envAttributes := attributes.Attributes{}.FromMap(map[string]interface{}{devfilePkg.ContainerENVKey: []corev1.EnvVar{{Name: "name1", Value: "value1"}}}, &err)
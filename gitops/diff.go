@@ -0,0 +1,115 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// RepositoryDiff is the difference between the working tree of a GitOps repository clone and the head of its branch
+type RepositoryDiff struct {
+	// Files lists the changed files with their git status, e.g. "A components/comp/overlays/staging/deployment-patch.yaml"
+	Files []string
+
+	// Additions and Deletions are the number of added and deleted lines
+	Additions int
+	Deletions int
+
+	// Diff is the unified diff, truncated at a line boundary if it is larger than the maximum size
+	Diff      string
+	Truncated bool
+}
+
+// Summary returns a one-line summary of the diff, in the format of git diff --shortstat
+func (d RepositoryDiff) Summary() string {
+	if len(d.Files) == 0 {
+		return "no changes"
+	}
+	return fmt.Sprintf("%d file(s) changed, %d insertion(s)(+), %d deletion(s)(-)", len(d.Files), d.Additions, d.Deletions)
+}
+
+// RepositoryDiffer computes the diff of a GitOps repository clone against the head of its branch
+type RepositoryDiffer interface {
+	GetRepositoryDiff(fs afero.Afero, repoPath string, maxDiffSize int) (RepositoryDiff, error)
+}
+
+// GitRepositoryDiffer computes the diff of a GitOps repository clone with the git command
+type GitRepositoryDiffer struct {
+}
+
+// GetRepositoryDiff stages every change of the working tree of the clone in repoPath, including new and removed files,
+// and returns its diff against the head of the branch. Nothing is committed
+func (g GitRepositoryDiffer) GetRepositoryDiff(fs afero.Afero, repoPath string, maxDiffSize int) (RepositoryDiff, error) {
+	var repositoryDiff RepositoryDiff
+	if out, err := executeGit(repoPath, "add", "-A"); err != nil {
+		return repositoryDiff, fmt.Errorf("failed to stage the changes of the repository in %q %q: %s", repoPath, string(out), err)
+	}
+
+	out, err := executeGit(repoPath, "diff", "--cached", "--name-status")
+	if err != nil {
+		return repositoryDiff, fmt.Errorf("failed to list the changed files of the repository in %q %q: %s", repoPath, string(out), err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			repositoryDiff.Files = append(repositoryDiff.Files, strings.Join(strings.Fields(line), " "))
+		}
+	}
+
+	out, err = executeGit(repoPath, "diff", "--cached", "--numstat")
+	if err != nil {
+		return repositoryDiff, fmt.Errorf("failed to count the changed lines of the repository in %q %q: %s", repoPath, string(out), err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		// binary files are reported with "-" instead of line counts
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if additions, err := strconv.Atoi(fields[0]); err == nil {
+			repositoryDiff.Additions += additions
+		}
+		if deletions, err := strconv.Atoi(fields[1]); err == nil {
+			repositoryDiff.Deletions += deletions
+		}
+	}
+
+	out, err = executeGit(repoPath, "diff", "--cached")
+	if err != nil {
+		return repositoryDiff, fmt.Errorf("failed to compute the diff of the repository in %q %q: %s", repoPath, string(out), err)
+	}
+	repositoryDiff.Diff, repositoryDiff.Truncated = truncateDiff(string(out), maxDiffSize)
+	return repositoryDiff, nil
+}
+
+// truncateDiff truncates the diff to the last complete line that fits in maxDiffSize bytes
+func truncateDiff(diff string, maxDiffSize int) (string, bool) {
+	if len(diff) <= maxDiffSize {
+		return diff, false
+	}
+	return diff[:strings.LastIndex(diff[:maxDiffSize], "\n")+1], true
+}
+
+/* #nosec G204 -- only git is executed, with arguments that are set by the callers in this file */
+func executeGit(repoPath string, args ...string) ([]byte, error) {
+	c := exec.Command("git", args...)
+	c.Dir = repoPath
+	return c.CombinedOutput()
+}
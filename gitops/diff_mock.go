@@ -0,0 +1,62 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// MockRepositoryDiffer reports every file of the repository as added, since the mock generator does not clone the repository
+type MockRepositoryDiffer struct {
+}
+
+// GetRepositoryDiff returns a diff that adds every file found under repoPath in the filesystem
+func (m MockRepositoryDiffer) GetRepositoryDiff(fs afero.Afero, repoPath string, maxDiffSize int) (RepositoryDiff, error) {
+	var repositoryDiff RepositoryDiff
+	var diff strings.Builder
+	err := fs.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relativePath, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return err
+		}
+		content, err := fs.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		repositoryDiff.Files = append(repositoryDiff.Files, "A "+relativePath)
+		repositoryDiff.Additions += len(lines)
+		fmt.Fprintf(&diff, "diff --git a/%[1]s b/%[1]s\nnew file mode 100644\n--- /dev/null\n+++ b/%[1]s\n@@ -0,0 +1,%[2]d @@\n", relativePath, len(lines))
+		for _, line := range lines {
+			fmt.Fprintf(&diff, "+%s\n", line)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return repositoryDiff, err
+	}
+	repositoryDiff.Diff, repositoryDiff.Truncated = truncateDiff(diff.String(), maxDiffSize)
+	return repositoryDiff, nil
+}
@@ -0,0 +1,155 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// runGit runs a git command in dir, with a fixed identity so that the test does not depend on the git configuration
+func runGit(t *testing.T, dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	out, err := c.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %s: %v", args, string(out), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newBareRepository creates a local bare repository whose main branch holds the overlay of a component, and returns its path
+func newBareRepository(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	runGit(t, dir, "init", "--bare", remote)
+
+	seed := filepath.Join(dir, "seed")
+	runGit(t, dir, "clone", remote, seed)
+	runGit(t, seed, "checkout", "-b", "main")
+	overlayPath := filepath.Join(seed, "components", "comp", "overlays", "staging")
+	assert.NoError(t, os.MkdirAll(overlayPath, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(overlayPath, "kustomization.yaml"), []byte("resources:\n- ../../base\npatches:\n- path: deployment-patch.yaml\n"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(overlayPath, "deployment-patch.yaml"), []byte("spec:\n  replicas: 1\n"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(overlayPath, "hpa.yaml"), []byte("spec:\n  maxReplicas: 3\n"), 0600))
+	runGit(t, seed, "add", "-A")
+	runGit(t, seed, "commit", "-m", "Generate staging environment overlays for component comp")
+	runGit(t, seed, "push", "origin", "main")
+	return remote
+}
+
+func TestGetRepositoryDiff(t *testing.T) {
+	remote := newBareRepository(t)
+	head := runGit(t, filepath.Dir(remote), "--git-dir", remote, "rev-parse", "main")
+
+	tests := []struct {
+		name          string
+		changeClone   func(overlayPath string)
+		maxDiffSize   int
+		wantFiles     []string
+		wantAdditions int
+		wantDeletions int
+		wantDiff      []string
+		wantTruncated bool
+	}{
+		{
+			name:        "No changes",
+			changeClone: func(overlayPath string) {},
+			maxDiffSize: 1024,
+		},
+		{
+			name: "Modified, added and removed overlay files",
+			changeClone: func(overlayPath string) {
+				assert.NoError(t, os.WriteFile(filepath.Join(overlayPath, "deployment-patch.yaml"), []byte("spec:\n  replicas: 2\n"), 0600))
+				assert.NoError(t, os.WriteFile(filepath.Join(overlayPath, "pdb.yaml"), []byte("spec:\n  minAvailable: 1\n"), 0600))
+				assert.NoError(t, os.Remove(filepath.Join(overlayPath, "hpa.yaml")))
+			},
+			maxDiffSize: 4096,
+			wantFiles: []string{
+				"M components/comp/overlays/staging/deployment-patch.yaml",
+				"D components/comp/overlays/staging/hpa.yaml",
+				"A components/comp/overlays/staging/pdb.yaml",
+			},
+			wantAdditions: 3,
+			wantDeletions: 3,
+			wantDiff:      []string{"-  replicas: 1\n", "+  replicas: 2\n", "+++ b/components/comp/overlays/staging/pdb.yaml\n"},
+		},
+		{
+			name: "Diff larger than the maximum size",
+			changeClone: func(overlayPath string) {
+				assert.NoError(t, os.WriteFile(filepath.Join(overlayPath, "deployment-patch.yaml"), []byte("spec:\n  replicas: 2\n"), 0600))
+			},
+			maxDiffSize:   200,
+			wantFiles:     []string{"M components/comp/overlays/staging/deployment-patch.yaml"},
+			wantAdditions: 1,
+			wantDeletions: 1,
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clone := filepath.Join(t.TempDir(), "clone")
+			runGit(t, filepath.Dir(clone), "clone", "--branch", "main", remote, clone)
+			tt.changeClone(filepath.Join(clone, "components", "comp", "overlays", "staging"))
+
+			repositoryDiff, err := GitRepositoryDiffer{}.GetRepositoryDiff(ioutils.NewFilesystem(), clone, tt.maxDiffSize)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantFiles, repositoryDiff.Files)
+			assert.Equal(t, tt.wantAdditions, repositoryDiff.Additions)
+			assert.Equal(t, tt.wantDeletions, repositoryDiff.Deletions)
+			assert.Equal(t, tt.wantTruncated, repositoryDiff.Truncated)
+			assert.LessOrEqual(t, len(repositoryDiff.Diff), tt.maxDiffSize)
+			if tt.wantTruncated {
+				assert.True(t, strings.HasPrefix(repositoryDiff.Diff, "diff --git"))
+				assert.True(t, strings.HasSuffix(repositoryDiff.Diff, "\n"), "the diff should be truncated at a line boundary")
+			}
+			for _, wantDiff := range tt.wantDiff {
+				assert.Contains(t, repositoryDiff.Diff, wantDiff)
+			}
+
+			// nothing is committed or pushed
+			assert.Equal(t, head, runGit(t, clone, "rev-parse", "HEAD"))
+			assert.Equal(t, head, runGit(t, filepath.Dir(remote), "--git-dir", remote, "rev-parse", "main"))
+		})
+	}
+}
+
+func TestMockRepositoryDiffer(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	overlayPath := filepath.Join("/tmp", "app", "components", "comp", "overlays", "staging")
+	assert.NoError(t, fs.WriteFile(filepath.Join(overlayPath, "deployment-patch.yaml"), []byte("spec:\n  replicas: 2\n"), 0600))
+
+	repositoryDiff, err := MockRepositoryDiffer{}.GetRepositoryDiff(fs, filepath.Join("/tmp", "app"), 1024)
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, []string{"A components/comp/overlays/staging/deployment-patch.yaml"}, repositoryDiff.Files)
+	assert.Equal(t, "1 file(s) changed, 2 insertion(s)(+), 0 deletion(s)(-)", repositoryDiff.Summary())
+	assert.Contains(t, repositoryDiff.Diff, "+  replicas: 2\n")
+}
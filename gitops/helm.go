@@ -0,0 +1,302 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Format is the layout of the GitOps resources written to a GitOps repository
+type Format string
+
+const (
+	// KustomizeFormat writes a kustomize base per component and a kustomize overlay per environment, the default
+	KustomizeFormat Format = "kustomize"
+
+	// HelmFormat writes a Helm chart per component and a values file per environment
+	HelmFormat Format = "helm"
+)
+
+const (
+	helmChartFileName   = "Chart.yaml"
+	helmValuesFileName  = "values.yaml"
+	helmTemplatesFolder = "templates"
+
+	// helmPlaceholderPrefix marks the workload fields that are replaced by references to the chart values once the workload is serialized
+	helmPlaceholderPrefix = "__HELM_VALUES_"
+)
+
+// helmPlaceholderRegex matches the serialized workload fields holding a placeholder, capturing the indentation,
+// the sequence item marker if the field is the first of a container, the field and the placeholder name
+var helmPlaceholderRegex = regexp.MustCompile(`(?m)^( *)(- )?(\w+): ` + helmPlaceholderPrefix + `(\w+)__$`)
+
+// HelmValues are the values of a component Helm chart, which the environment values files override
+type HelmValues struct {
+	// Image is the image of the main container
+	Image string `json:"image,omitempty"`
+
+	// Replicas is the number of replicas of the workload, only used by Deployments and StatefulSets
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Env is the environment of the main container
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Resources are the compute resources of the main container
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// HelmChart is the Chart.yaml of a component Helm chart
+type HelmChart struct {
+	APIVersion  string `json:"apiVersion"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Version     string `json:"version"`
+}
+
+// GetHelmChartPath returns the path of the Helm chart of a component in the GitOps folder of a repository
+func GetHelmChartPath(gitOpsFolder string, componentName string) string {
+	return filepath.Join(gitOpsFolder, "components", componentName, "chart")
+}
+
+// GetHelmEnvironmentValuesFileName returns the name of the values file of an environment in a component Helm chart
+func GetHelmEnvironmentValuesFileName(environmentName string) string {
+	return fmt.Sprintf("values-%s.yaml", environmentName)
+}
+
+// GenerateHelmChart writes the Helm chart of a component into the chart folder, replacing any existing templates.
+// The image, env and resources of the first container of the workload, and its replicas if it has any, are read from the chart values
+// and their current values become the chart defaults. The other resources are written as static templates
+func GenerateHelmChart(fs afero.Afero, chartFolder string, componentName string, workload interface{}, otherResources []interface{}) error {
+	workloadMap, err := toUnstructured(workload)
+	if err != nil {
+		return err
+	}
+	values, err := templateWorkload(workloadMap)
+	if err != nil {
+		return fmt.Errorf("unable to template the workload of component %s: %v", componentName, err)
+	}
+	workloadBytes, err := yaml.Marshal(workloadMap)
+	if err != nil {
+		return err
+	}
+	workloadTemplate := helmPlaceholderRegex.ReplaceAllStringFunc(string(workloadBytes), replaceHelmPlaceholder)
+
+	templatesFolder := filepath.Join(chartFolder, helmTemplatesFolder)
+	if err := fs.RemoveAll(templatesFolder); err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(templatesFolder, 0755); err != nil {
+		return err
+	}
+	if err := fs.WriteFile(filepath.Join(templatesFolder, getHelmTemplateFileName(workloadMap)), []byte(workloadTemplate), 0644); err != nil {
+		return err
+	}
+	for _, resource := range otherResources {
+		resourceMap, err := toUnstructured(resource)
+		if err != nil {
+			return err
+		}
+		resourceBytes, err := yaml.Marshal(resourceMap)
+		if err != nil {
+			return err
+		}
+		if err := fs.WriteFile(filepath.Join(templatesFolder, getHelmTemplateFileName(resourceMap)), resourceBytes, 0644); err != nil {
+			return err
+		}
+	}
+
+	chart := HelmChart{
+		APIVersion:  "v2",
+		Name:        componentName,
+		Description: fmt.Sprintf("Helm chart of the %s component", componentName),
+		Type:        "application",
+		Version:     "0.1.0",
+	}
+	if err := writeYAMLFile(fs, filepath.Join(chartFolder, helmChartFileName), chart); err != nil {
+		return err
+	}
+	return writeYAMLFile(fs, filepath.Join(chartFolder, helmValuesFileName), values)
+}
+
+// GenerateHelmEnvironmentValues writes the values file of an environment into a component Helm chart.
+// The overrides are merged on top of the chart values, env vars are merged by name so that the values file holds the complete env.
+// The name of the values file is returned
+func GenerateHelmEnvironmentValues(fs afero.Afero, chartFolder string, environmentName string, overrides HelmValues) (string, error) {
+	var values HelmValues
+	valuesBytes, err := fs.ReadFile(filepath.Join(chartFolder, helmValuesFileName))
+	if err != nil {
+		return "", fmt.Errorf("unable to read the values of the Helm chart in %s: %v", chartFolder, err)
+	}
+	if err := yaml.Unmarshal(valuesBytes, &values); err != nil {
+		return "", err
+	}
+
+	if overrides.Image != "" {
+		values.Image = overrides.Image
+	}
+	if overrides.Replicas != nil && values.Replicas != nil {
+		values.Replicas = overrides.Replicas
+	}
+	for _, env := range overrides.Env {
+		isPresent := false
+		for i := range values.Env {
+			if values.Env[i].Name == env.Name {
+				isPresent = true
+				values.Env[i] = env
+				break
+			}
+		}
+		if !isPresent {
+			values.Env = append(values.Env, env)
+		}
+	}
+	for resourceName, quantity := range overrides.Resources.Limits {
+		if values.Resources.Limits == nil {
+			values.Resources.Limits = make(corev1.ResourceList)
+		}
+		values.Resources.Limits[resourceName] = quantity
+	}
+	for resourceName, quantity := range overrides.Resources.Requests {
+		if values.Resources.Requests == nil {
+			values.Resources.Requests = make(corev1.ResourceList)
+		}
+		values.Resources.Requests[resourceName] = quantity
+	}
+
+	valuesFileName := GetHelmEnvironmentValuesFileName(environmentName)
+	return valuesFileName, writeYAMLFile(fs, filepath.Join(chartFolder, valuesFileName), values)
+}
+
+// templateWorkload replaces the image, env and resources of the first container of the workload, and its replicas, with placeholders.
+// The values of the replaced fields are returned
+func templateWorkload(workload map[string]interface{}) (HelmValues, error) {
+	var values HelmValues
+	spec, _ := workload["spec"].(map[string]interface{})
+	if spec == nil {
+		return values, fmt.Errorf("the workload does not have a spec")
+	}
+
+	podSpecPath := []string{"template", "spec"}
+	switch workload["kind"] {
+	case "Deployment", "StatefulSet":
+		replicas := int32(1)
+		if currentReplicas, ok := spec["replicas"].(float64); ok {
+			replicas = int32(currentReplicas)
+		}
+		values.Replicas = &replicas
+		spec["replicas"] = helmPlaceholderPrefix + "REPLICAS__"
+	case "CronJob":
+		podSpecPath = []string{"jobTemplate", "spec", "template", "spec"}
+	}
+
+	podSpec := spec
+	for _, field := range podSpecPath {
+		podSpec, _ = podSpec[field].(map[string]interface{})
+		if podSpec == nil {
+			return values, fmt.Errorf("the workload does not have a pod template")
+		}
+	}
+	containers, _ := podSpec["containers"].([]interface{})
+	if len(containers) == 0 {
+		return values, fmt.Errorf("the workload does not have any container")
+	}
+	container, _ := containers[0].(map[string]interface{})
+
+	// round trip the container to read the values of the fields with their types
+	var mainContainer corev1.Container
+	containerBytes, err := yaml.Marshal(container)
+	if err != nil {
+		return values, err
+	}
+	if err := yaml.Unmarshal(containerBytes, &mainContainer); err != nil {
+		return values, err
+	}
+	values.Image = mainContainer.Image
+	values.Env = mainContainer.Env
+	values.Resources = mainContainer.Resources
+
+	container["image"] = helmPlaceholderPrefix + "IMAGE__"
+	container["env"] = helmPlaceholderPrefix + "ENV__"
+	container["resources"] = helmPlaceholderPrefix + "RESOURCES__"
+	return values, nil
+}
+
+// replaceHelmPlaceholder replaces a serialized placeholder field with the template reading the field from the chart values
+func replaceHelmPlaceholder(line string) string {
+	match := helmPlaceholderRegex.FindStringSubmatch(line)
+	indent, itemMarker, field, placeholder := match[1], match[2], match[3], match[4]
+	switch placeholder {
+	case "IMAGE":
+		return fmt.Sprintf("%s%s%s: {{ .Values.image | quote }}", indent, itemMarker, field)
+	case "REPLICAS":
+		return fmt.Sprintf("%s%s%s: {{ .Values.replicas }}", indent, itemMarker, field)
+	}
+
+	// env and resources are only set when the values have any, the first field of a sequence item keeps the item marker on its own line
+	fieldIndent := indent
+	prefix := indent
+	if itemMarker != "" {
+		fieldIndent = indent + "  "
+		prefix = indent + "-"
+	}
+	valuesIndent := len(fieldIndent)
+	if placeholder == "RESOURCES" {
+		valuesIndent += 2
+	}
+	return fmt.Sprintf("%[1]s{{- with .Values.%[2]s }}\n%[3]s%[2]s:\n%[3]s{{- toYaml . | nindent %[4]d }}\n%[3]s{{- end }}", prefix, field, fieldIndent, valuesIndent)
+}
+
+// getHelmTemplateFileName returns the name of the template of a resource, built from its kind and name
+func getHelmTemplateFileName(resource map[string]interface{}) string {
+	kind, _ := resource["kind"].(string)
+	var name string
+	if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+		name, _ = metadata["name"].(string)
+	}
+	return strings.ToLower(fmt.Sprintf("%s-%s.yaml", kind, name))
+}
+
+// toUnstructured converts a typed resource into its unstructured representation
+func toUnstructured(resource interface{}) (map[string]interface{}, error) {
+	resourceBytes, err := yaml.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var resourceMap map[string]interface{}
+	if err := yaml.Unmarshal(resourceBytes, &resourceMap); err != nil {
+		return nil, err
+	}
+	return resourceMap, nil
+}
+
+func writeYAMLFile(fs afero.Afero, path string, content interface{}) error {
+	contentBytes, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fs.WriteFile(path, contentBytes, 0644)
+}
@@ -0,0 +1,256 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// renderHelmTemplate renders a chart template with the subset of the Helm template functions used by the generated charts
+func renderHelmTemplate(t *testing.T, fs afero.Afero, templatePath string, values HelmValues) []byte {
+	templateBytes, err := fs.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("unable to read the template %s: %v", templatePath, err)
+	}
+	valuesMap, err := toUnstructured(values)
+	if err != nil {
+		t.Fatalf("unable to convert the values: %v", err)
+	}
+	funcs := template.FuncMap{
+		"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+		"toYaml": func(v interface{}) string {
+			out, _ := yaml.Marshal(v)
+			return strings.TrimSuffix(string(out), "\n")
+		},
+		"nindent": func(spaces int, s string) string {
+			padding := strings.Repeat(" ", spaces)
+			return "\n" + padding + strings.ReplaceAll(s, "\n", "\n"+padding)
+		},
+	}
+	tmpl, err := template.New(templatePath).Funcs(funcs).Parse(string(templateBytes))
+	if err != nil {
+		t.Fatalf("unable to parse the template %s: %v", templatePath, err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, map[string]interface{}{"Values": valuesMap}); err != nil {
+		t.Fatalf("unable to render the template %s: %v", templatePath, err)
+	}
+	return rendered.Bytes()
+}
+
+func TestGenerateHelmChart(t *testing.T) {
+	replicas := int32(2)
+	newReplicas := int32(4)
+	deployment := appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "testcomponent"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "container-image",
+							Image: "quay.io/org/image:1.0",
+							Env:   []corev1.EnvVar{{Name: "FOO", Value: "foo"}},
+							Resources: corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+							},
+						},
+						{
+							Name:  "sidecar",
+							Image: "quay.io/org/sidecar:1.0",
+						},
+					},
+				},
+			},
+		},
+	}
+	cronJob := batchv1.CronJob{
+		TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "testcomponent"},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "0 * * * *",
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "report", Image: "quay.io/org/report:1.0"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	service := corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "testcomponent"},
+	}
+
+	tests := []struct {
+		name           string
+		workload       interface{}
+		otherResources []interface{}
+		overrides      HelmValues
+		wantValues     HelmValues
+		wantTemplates  []string
+		check          func(t *testing.T, rendered []byte)
+		wantErr        bool
+	}{
+		{
+			name:           "Deployment with an environment override",
+			workload:       deployment,
+			otherResources: []interface{}{service},
+			overrides: HelmValues{
+				Image:    "quay.io/org/image:2.0",
+				Replicas: &newReplicas,
+				Env:      []corev1.EnvVar{{Name: "FOO", Value: "bar"}, {Name: "ENV", Value: "staging"}},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+				},
+			},
+			wantValues: HelmValues{
+				Image:    "quay.io/org/image:1.0",
+				Replicas: &replicas,
+				Env:      []corev1.EnvVar{{Name: "FOO", Value: "foo"}},
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+			wantTemplates: []string{"deployment-testcomponent.yaml", "service-testcomponent.yaml"},
+			check: func(t *testing.T, rendered []byte) {
+				var renderedDeployment appsv1.Deployment
+				if err := yaml.Unmarshal(rendered, &renderedDeployment); err != nil {
+					t.Fatalf("unable to unmarshal the rendered Deployment: %v\n%s", err, rendered)
+				}
+				assert.Equal(t, &newReplicas, renderedDeployment.Spec.Replicas)
+				containers := renderedDeployment.Spec.Template.Spec.Containers
+				if assert.Len(t, containers, 2) {
+					assert.Equal(t, "quay.io/org/image:2.0", containers[0].Image)
+					assert.Equal(t, []corev1.EnvVar{{Name: "FOO", Value: "bar"}, {Name: "ENV", Value: "staging"}}, containers[0].Env)
+					assert.True(t, resource.MustParse("1").Equal(containers[0].Resources.Limits[corev1.ResourceCPU]))
+					assert.True(t, resource.MustParse("512Mi").Equal(containers[0].Resources.Requests[corev1.ResourceMemory]))
+					assert.Equal(t, "quay.io/org/sidecar:1.0", containers[1].Image)
+				}
+			},
+		},
+		{
+			name:          "CronJob without env and resources",
+			workload:      cronJob,
+			overrides:     HelmValues{Image: "quay.io/org/report:2.0", Replicas: &newReplicas},
+			wantValues:    HelmValues{Image: "quay.io/org/report:1.0"},
+			wantTemplates: []string{"cronjob-testcomponent.yaml"},
+			check: func(t *testing.T, rendered []byte) {
+				var renderedCronJob batchv1.CronJob
+				if err := yaml.Unmarshal(rendered, &renderedCronJob); err != nil {
+					t.Fatalf("unable to unmarshal the rendered CronJob: %v\n%s", err, rendered)
+				}
+				assert.Equal(t, "0 * * * *", renderedCronJob.Spec.Schedule)
+				container := renderedCronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+				assert.Equal(t, "quay.io/org/report:2.0", container.Image)
+				assert.Empty(t, container.Env)
+				assert.Empty(t, container.Resources)
+			},
+		},
+		{
+			name:     "Workload without containers",
+			workload: appsv1.Deployment{TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"}, ObjectMeta: metav1.ObjectMeta{Name: "testcomponent"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			chartFolder := GetHelmChartPath("/tmp/repo", "testcomponent")
+
+			err := GenerateHelmChart(fs, chartFolder, "testcomponent", tt.workload, tt.otherResources)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+				return
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+				return
+			} else if err != nil {
+				return
+			}
+
+			var values HelmValues
+			valuesBytes, err := fs.ReadFile(filepath.Join(chartFolder, helmValuesFileName))
+			if err != nil {
+				t.Fatalf("unable to read the chart values: %v", err)
+			}
+			if err := yaml.Unmarshal(valuesBytes, &values); err != nil {
+				t.Fatalf("unable to unmarshal the chart values: %v", err)
+			}
+			assert.Equal(t, tt.wantValues.Image, values.Image)
+			assert.Equal(t, tt.wantValues.Replicas, values.Replicas)
+			assert.Equal(t, tt.wantValues.Env, values.Env)
+			assert.Equal(t, len(tt.wantValues.Resources.Limits), len(values.Resources.Limits))
+
+			templates, err := fs.ReadDir(filepath.Join(chartFolder, helmTemplatesFolder))
+			if err != nil {
+				t.Fatalf("unable to read the chart templates: %v", err)
+			}
+			var templateNames []string
+			for _, template := range templates {
+				templateNames = append(templateNames, template.Name())
+			}
+			assert.ElementsMatch(t, tt.wantTemplates, templateNames)
+			exists, err := fs.Exists(filepath.Join(chartFolder, helmChartFileName))
+			assert.NoError(t, err)
+			assert.True(t, exists, "expected the Chart.yaml")
+
+			valuesFile, err := GenerateHelmEnvironmentValues(fs, chartFolder, "staging", tt.overrides)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, "values-staging.yaml", valuesFile)
+			var environmentValues HelmValues
+			valuesBytes, err = fs.ReadFile(filepath.Join(chartFolder, valuesFile))
+			if err != nil {
+				t.Fatalf("unable to read the environment values: %v", err)
+			}
+			if err := yaml.Unmarshal(valuesBytes, &environmentValues); err != nil {
+				t.Fatalf("unable to unmarshal the environment values: %v", err)
+			}
+
+			tt.check(t, renderHelmTemplate(t, fs, filepath.Join(chartFolder, helmTemplatesFolder, tt.wantTemplates[0]), environmentValues))
+		})
+	}
+}
+
+func TestGenerateHelmEnvironmentValuesWithoutChart(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	if _, err := GenerateHelmEnvironmentValues(fs, GetHelmChartPath("/tmp/repo", "testcomponent"), "staging", HelmValues{}); err == nil {
+		t.Error("wanted error but got nil")
+	}
+}
@@ -0,0 +1,104 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	"github.com/redhat-developer/gitops-generator/pkg/resources"
+	"github.com/redhat-developer/gitops-generator/pkg/yaml"
+	"github.com/spf13/afero"
+)
+
+// AddOverlayPatch writes the patch into the overlay folder and adds it to the patches of the overlay kustomization
+func AddOverlayPatch(fs afero.Afero, overlayPath string, fileName string, patch interface{}) error {
+	return addOverlayFile(fs, overlayPath, fileName, patch, true)
+}
+
+// AddOverlayResource writes the resource into the overlay folder and adds it to the resources of the overlay kustomization
+func AddOverlayResource(fs afero.Afero, overlayPath string, fileName string, resource interface{}) error {
+	return addOverlayFile(fs, overlayPath, fileName, resource, false)
+}
+
+// RemoveOverlayFile removes the file from the overlay folder, along with any reference to it in the overlay kustomization.
+// It is a no-op if the file does not exist
+func RemoveOverlayFile(fs afero.Afero, overlayPath string, fileName string) error {
+	filePath := filepath.Join(overlayPath, fileName)
+	exist, err := fs.Exists(filePath)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return nil
+	}
+	if err := fs.Remove(filePath); err != nil {
+		return fmt.Errorf("failed to delete %s file in folder %q: %s", fileName, overlayPath, err)
+	}
+
+	k, err := readOverlayKustomization(fs, overlayPath)
+	if err != nil {
+		return err
+	}
+	k.Resources = util.RemoveString(k.Resources, fileName)
+	k.Patches = util.RemoveString(k.Patches, fileName)
+
+	return yaml.MarshalItemToFile(fs, filepath.Join(overlayPath, kustomizeFileName), k)
+}
+
+func addOverlayFile(fs afero.Afero, overlayPath string, fileName string, item interface{}, isPatch bool) error {
+	k, err := readOverlayKustomization(fs, overlayPath)
+	if err != nil {
+		return err
+	}
+	if isPatch {
+		k.AddPatches(fileName)
+	} else {
+		k.AddResources(fileName)
+	}
+
+	files := map[string]interface{}{
+		fileName:          item,
+		kustomizeFileName: k,
+	}
+	if _, err := yaml.WriteResources(fs, overlayPath, files); err != nil {
+		return fmt.Errorf("failed to write %s to the overlay folder %q: %s", fileName, overlayPath, err)
+	}
+
+	return nil
+}
+
+// readOverlayKustomization reads the kustomization of the overlay folder, or returns a new one if it does not exist yet
+func readOverlayKustomization(fs afero.Afero, overlayPath string) (resources.Kustomization, error) {
+	k := resources.Kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+	}
+
+	kustomizePath := filepath.Join(overlayPath, kustomizeFileName)
+	exist, err := fs.Exists(kustomizePath)
+	if err != nil {
+		return k, err
+	}
+	if exist {
+		if err := yaml.UnMarshalItemFromFile(fs, kustomizePath, &k); err != nil {
+			return k, fmt.Errorf("failed to unmarshal items from %q: %v", kustomizePath, err)
+		}
+	}
+
+	return k, nil
+}
@@ -0,0 +1,116 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/redhat-developer/gitops-generator/pkg/resources"
+	"github.com/redhat-developer/gitops-generator/pkg/testutils"
+	"github.com/redhat-developer/gitops-generator/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOverlayFiles(t *testing.T) {
+	hpa := autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "autoscaling/v2",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testcomponent",
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			MaxReplicas: 3,
+		},
+	}
+
+	tests := []struct {
+		name              string
+		existingKustomize *resources.Kustomization
+		isPatch           bool
+		wantResources     []string
+		wantPatches       []string
+	}{
+		{
+			name: "Add a patch to an existing overlay",
+			existingKustomize: &resources.Kustomization{
+				Resources: []string{"../../base"},
+				Patches:   []string{"deployment-patch.yaml"},
+			},
+			isPatch:       true,
+			wantResources: []string{"../../base"},
+			wantPatches:   []string{"deployment-patch.yaml", "hpa.yaml"},
+		},
+		{
+			name: "Add a resource to an existing overlay",
+			existingKustomize: &resources.Kustomization{
+				Resources: []string{"../../base"},
+				Patches:   []string{"deployment-patch.yaml"},
+			},
+			wantResources: []string{"../../base", "hpa.yaml"},
+			wantPatches:   []string{"deployment-patch.yaml"},
+		},
+		{
+			name:          "Add a resource to an overlay without a kustomization",
+			wantResources: []string{"hpa.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			overlayPath := "/test/components/testcomponent/overlays/staging"
+			kustomizePath := filepath.Join(overlayPath, kustomizeFileName)
+			if tt.existingKustomize != nil {
+				testutils.AssertNoError(t, yaml.MarshalItemToFile(fs, kustomizePath, tt.existingKustomize))
+			}
+
+			if tt.isPatch {
+				testutils.AssertNoError(t, AddOverlayPatch(fs, overlayPath, "hpa.yaml", hpa))
+			} else {
+				testutils.AssertNoError(t, AddOverlayResource(fs, overlayPath, "hpa.yaml", hpa))
+			}
+
+			var k resources.Kustomization
+			testutils.AssertNoError(t, yaml.UnMarshalItemFromFile(fs, kustomizePath, &k))
+			assert.Equal(t, tt.wantResources, k.Resources)
+			assert.Equal(t, tt.wantPatches, k.Patches)
+
+			var writtenHPA autoscalingv2.HorizontalPodAutoscaler
+			testutils.AssertNoError(t, yaml.UnMarshalItemFromFile(fs, filepath.Join(overlayPath, "hpa.yaml"), &writtenHPA))
+			assert.Equal(t, hpa, writtenHPA)
+
+			// Removing the file should drop every reference to it
+			testutils.AssertNoError(t, RemoveOverlayFile(fs, overlayPath, "hpa.yaml"))
+			exist, err := fs.Exists(filepath.Join(overlayPath, "hpa.yaml"))
+			testutils.AssertNoError(t, err)
+			assert.False(t, exist, "Expected hpa.yaml to be removed from the overlay")
+
+			k = resources.Kustomization{}
+			testutils.AssertNoError(t, yaml.UnMarshalItemFromFile(fs, kustomizePath, &k))
+			assert.NotContains(t, k.Resources, "hpa.yaml")
+			assert.NotContains(t, k.Patches, "hpa.yaml")
+
+			// Removing a file that does not exist is a no-op
+			testutils.AssertNoError(t, RemoveOverlayFile(fs, overlayPath, "hpa.yaml"))
+		})
+	}
+}
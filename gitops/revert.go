@@ -0,0 +1,74 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+var commitIDRegex = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// RepositoryReverter reverts paths of a GitOps repository clone to their content at an earlier commit
+type RepositoryReverter interface {
+	RevertPaths(fs afero.Afero, repoPath string, commitID string, paths []string) error
+}
+
+// GitRepositoryReverter reverts paths of a GitOps repository clone with the git command
+type GitRepositoryReverter struct {
+}
+
+// RevertPaths replaces the content of the given paths, relative to the root of the clone in repoPath, with their content at the given commit.
+// The paths that did not exist at the commit are removed. The changes are staged but not committed
+func (g GitRepositoryReverter) RevertPaths(fs afero.Afero, repoPath string, commitID string, paths []string) error {
+	if err := ValidateCommitID(commitID); err != nil {
+		return err
+	}
+	if out, err := executeGit(repoPath, "cat-file", "-e", commitID+"^{commit}"); err != nil {
+		return fmt.Errorf("commit %s does not exist in the repository in %q %q: %s", commitID, repoPath, string(out), err)
+	}
+
+	for _, path := range paths {
+		path = filepath.Clean(strings.TrimPrefix(path, "/"))
+		if path == "." || strings.HasPrefix(path, "..") {
+			return fmt.Errorf("invalid path %s to revert in the repository in %q", path, repoPath)
+		}
+
+		// remove the files that were added after the commit, before the files of the commit are checked out
+		if out, err := executeGit(repoPath, "rm", "-r", "-q", "--ignore-unmatch", "--", path); err != nil {
+			return fmt.Errorf("failed to remove %s from the repository in %q %q: %s", path, repoPath, string(out), err)
+		}
+		if _, err := executeGit(repoPath, "cat-file", "-e", commitID+":"+path); err != nil {
+			continue
+		}
+		if out, err := executeGit(repoPath, "checkout", commitID, "--", path); err != nil {
+			return fmt.Errorf("failed to check out %s at commit %s in the repository in %q %q: %s", path, commitID, repoPath, string(out), err)
+		}
+	}
+	return nil
+}
+
+// ValidateCommitID returns an error if the commit ID is not an abbreviated or full hexadecimal git commit ID
+func ValidateCommitID(commitID string) error {
+	if !commitIDRegex.MatchString(commitID) {
+		return fmt.Errorf("invalid commit ID %q, a hexadecimal commit ID of 7 to 40 characters is expected", commitID)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"github.com/spf13/afero"
+)
+
+// MockRepositoryReverter validates the commit ID but does not change the repository, since the mock generator does not clone it
+type MockRepositoryReverter struct {
+}
+
+// RevertPaths returns an error for an invalid commit ID, and succeeds otherwise
+func (m MockRepositoryReverter) RevertPaths(fs afero.Afero, repoPath string, commitID string, paths []string) error {
+	return ValidateCommitID(commitID)
+}
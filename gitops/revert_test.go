@@ -0,0 +1,151 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevertPaths(t *testing.T) {
+	remote := newBareRepository(t)
+	dir := filepath.Dir(remote)
+	firstCommit := runGit(t, dir, "--git-dir", remote, "rev-parse", "main")
+
+	// a second commit updates the staging overlay, and adds a file and the production overlay
+	seed := filepath.Join(dir, "seed")
+	stagingPath := filepath.Join(seed, "components", "comp", "overlays", "staging")
+	productionPath := filepath.Join(seed, "components", "comp", "overlays", "production")
+	assert.NoError(t, os.WriteFile(filepath.Join(stagingPath, "deployment-patch.yaml"), []byte("spec:\n  replicas: 2\n"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(stagingPath, "pdb.yaml"), []byte("spec:\n  minAvailable: 1\n"), 0600))
+	assert.NoError(t, os.MkdirAll(productionPath, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(productionPath, "deployment-patch.yaml"), []byte("spec:\n  replicas: 3\n"), 0600))
+	runGit(t, seed, "add", "-A")
+	runGit(t, seed, "commit", "-m", "Generate environment overlays for component comp")
+	runGit(t, seed, "push", "origin", "main")
+
+	tests := []struct {
+		name      string
+		commitID  string
+		paths     []string
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name:     "Overlay reverted to the earlier commit",
+			commitID: firstCommit,
+			paths:    []string{"/components/comp/overlays/staging"},
+			wantFiles: []string{
+				"M components/comp/overlays/staging/deployment-patch.yaml",
+				"D components/comp/overlays/staging/pdb.yaml",
+			},
+		},
+		{
+			name:     "Overlay that did not exist at the earlier commit is removed",
+			commitID: firstCommit[:7],
+			paths:    []string{"components/comp/overlays/production"},
+			wantFiles: []string{
+				"D components/comp/overlays/production/deployment-patch.yaml",
+			},
+		},
+		{
+			name:     "Unknown commit",
+			commitID: "0123456789abcdef0123456789abcdef01234567",
+			paths:    []string{"components/comp/overlays/staging"},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid commit ID",
+			commitID: "--all",
+			paths:    []string{"components/comp/overlays/staging"},
+			wantErr:  true,
+		},
+		{
+			name:     "Path outside of the repository",
+			commitID: firstCommit,
+			paths:    []string{"../remote.git"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clone := filepath.Join(t.TempDir(), "clone")
+			runGit(t, filepath.Dir(clone), "clone", "--branch", "main", remote, clone)
+
+			fs := ioutils.NewFilesystem()
+			err := GitRepositoryReverter{}.RevertPaths(fs, clone, tt.commitID, tt.paths)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				repositoryDiff, err := GitRepositoryDiffer{}.GetRepositoryDiff(fs, clone, 4096)
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				assert.Equal(t, tt.wantFiles, repositoryDiff.Files)
+			}
+		})
+	}
+}
+
+func TestRevertPathsHelmEnvironmentValues(t *testing.T) {
+	remote := newBareRepository(t)
+	dir := filepath.Dir(remote)
+	seed := filepath.Join(dir, "seed")
+	chartPath := filepath.Join(seed, GetHelmChartPath("", "comp"))
+	stagingValues := filepath.Join(chartPath, GetHelmEnvironmentValuesFileName("staging"))
+	productionValues := filepath.Join(chartPath, GetHelmEnvironmentValuesFileName("production"))
+
+	// the chart is generated for both environments, and then both environments are synced again
+	assert.NoError(t, os.MkdirAll(filepath.Join(chartPath, "templates"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartPath, "templates", "deployment.yaml"), []byte("kind: Deployment\n"), 0600))
+	assert.NoError(t, os.WriteFile(stagingValues, []byte("image: quay.io/org/comp:1\n"), 0600))
+	assert.NoError(t, os.WriteFile(productionValues, []byte("image: quay.io/org/comp:1\n"), 0600))
+	runGit(t, seed, "add", "-A")
+	runGit(t, seed, "commit", "-m", "Generate the Helm chart of component comp")
+	firstCommit := runGit(t, seed, "rev-parse", "HEAD")
+	assert.NoError(t, os.WriteFile(filepath.Join(chartPath, "templates", "deployment.yaml"), []byte("kind: Deployment\nspec: {}\n"), 0600))
+	assert.NoError(t, os.WriteFile(stagingValues, []byte("image: quay.io/org/comp:2\n"), 0600))
+	assert.NoError(t, os.WriteFile(productionValues, []byte("image: quay.io/org/comp:2\n"), 0600))
+	runGit(t, seed, "add", "-A")
+	runGit(t, seed, "commit", "-m", "Sync the staging and production environments of component comp")
+	runGit(t, seed, "push", "origin", "main")
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, filepath.Dir(clone), "clone", "--branch", "main", remote, clone)
+	fs := ioutils.NewFilesystem()
+	valuesPath := filepath.Join(GetHelmChartPath("", "comp"), GetHelmEnvironmentValuesFileName("staging"))
+	if err := (GitRepositoryReverter{}).RevertPaths(fs, clone, firstCommit, []string{valuesPath}); err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+
+	repositoryDiff, err := GitRepositoryDiffer{}.GetRepositoryDiff(fs, clone, 4096)
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, []string{"M components/comp/chart/values-staging.yaml"}, repositoryDiff.Files)
+	productionContent, err := os.ReadFile(filepath.Join(clone, GetHelmChartPath("", "comp"), GetHelmEnvironmentValuesFileName("production")))
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Equal(t, "image: quay.io/org/comp:2\n", string(productionContent))
+}
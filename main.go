@@ -41,6 +41,7 @@ import (
 
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/application-service/controllers"
+	"github.com/redhat-appstudio/application-service/gitops"
 	"github.com/redhat-appstudio/application-service/pkg/devfile"
 	"github.com/redhat-appstudio/application-service/pkg/github"
 	"github.com/redhat-appstudio/application-service/pkg/spi"
@@ -124,6 +125,23 @@ func main() {
 		ghOrg = "redhat-appstudio-appdata"
 	}
 
+	// Retrieve the visibility policy of the generated GitOps repositories, private by default
+	gitOpsRepoVisibility, err := github.ParseRepositoryVisibility(os.Getenv("GITOPS_REPO_VISIBILITY"))
+	if err != nil {
+		setupLog.Error(err, "invalid GITOPS_REPO_VISIBILITY")
+		os.Exit(1)
+	}
+
+	// Retrieve the naming policy of the generated GitOps repositories, named "{namespace}-{application}" by default
+	gitOpsRepoNaming, err := github.ParseRepositoryNamingPolicy(os.Getenv("GITOPS_REPO_NAME_TEMPLATE"), os.Getenv("GITOPS_REPO_NAME_MAX_LENGTH"), os.Getenv("GITOPS_REPO_NAME_BLOCKED_WORDS"))
+	if err != nil {
+		setupLog.Error(err, "invalid GitOps repository naming policy")
+		os.Exit(1)
+	}
+
+	// Retrieve the GitHub orgs that the generated GitOps repositories can be migrated to, only the org of the controller by default
+	gitOpsRepoMigrationOrgs := github.ParseMigrationOrgs(os.Getenv("GITOPS_REPO_MIGRATION_ORGS"), ghOrg)
+
 	// Retrieve the option to specify a custom devfile registry
 	devfileRegistryURL := os.Getenv("DEVFILE_REGISTRY_URL")
 	if devfileRegistryURL == "" {
@@ -140,15 +158,27 @@ func main() {
 	setupLog.Info(fmt.Sprintf("There are %v token(s) available", len(github.Clients)))
 
 	if err = (&controllers.ApplicationReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		Log:               ctrl.Log.WithName("controllers").WithName("Application"),
-		GitHubTokenClient: ghTokenClient,
-		GitHubOrg:         ghOrg,
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("Application"),
+		GitHubTokenClient:       ghTokenClient,
+		GitHubOrg:               ghOrg,
+		GitOpsRepoVisibility:    gitOpsRepoVisibility,
+		GitOpsRepoNaming:        gitOpsRepoNaming,
+		GitOpsRepoMigrationOrgs: gitOpsRepoMigrationOrgs,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Application")
 		os.Exit(1)
 	}
+	if err = mgr.Add(&controllers.GitOpsRepositorySweeper{
+		Log:                     ctrl.Log.WithName("controllers").WithName("GitOpsRepositorySweeper"),
+		GitHubTokenClient:       ghTokenClient,
+		GitHubOrg:               ghOrg,
+		GitOpsRepoMigrationOrgs: gitOpsRepoMigrationOrgs,
+	}); err != nil {
+		setupLog.Error(err, "unable to add the GitOps repository sweeper")
+		os.Exit(1)
+	}
 	if err = (&controllers.ComponentReconciler{
 		Client:            mgr.GetClient(),
 		Scheme:            mgr.GetScheme(),
@@ -188,12 +218,14 @@ func main() {
 	}
 
 	if err = (&controllers.SnapshotEnvironmentBindingReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		Log:               ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
-		Generator:         gitopsgen.NewGitopsGen(),
-		AppFS:             ioutils.NewFilesystem(),
-		GitHubTokenClient: ghTokenClient,
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Log:                ctrl.Log.WithName("controllers").WithName("SnapshotEnvironmentBinding"),
+		Generator:          gitopsgen.NewGitopsGen(),
+		AppFS:              ioutils.NewFilesystem(),
+		GitHubTokenClient:  ghTokenClient,
+		RepositoryDiffer:   gitops.GitRepositoryDiffer{},
+		RepositoryReverter: gitops.GitRepositoryReverter{},
 	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SnapshotEnvironmentBinding")
 		os.Exit(1)
@@ -0,0 +1,301 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package appbundle exports an Application with its Components, Environments, SnapshotEnvironmentBindings and the Snapshots they
+// deploy into a portable bundle, and imports the bundle into another namespace
+package appbundle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/controllers"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// APIVersion is the version of the bundle format
+	APIVersion = "appstudio.redhat.com/v1alpha1"
+
+	// Kind is the kind of the bundle
+	Kind = "ApplicationBundle"
+)
+
+// clusterAnnotations are the annotations written by the controllers or by kubectl, which describe the state of the resources
+// in their cluster, and the one-off operations requested on them. They are dropped from the bundle
+var clusterAnnotations = append([]string{"kubectl.kubernetes.io/last-applied-configuration"}, controllers.ClusterStateAnnotations...)
+
+// Bundle is an Application with its Components, the SnapshotEnvironmentBindings of the Application, the Environments they bind to
+// and the Snapshots they deploy. The resources have no namespace and no status
+type Bundle struct {
+	APIVersion   string                                         `json:"apiVersion"`
+	Kind         string                                         `json:"kind"`
+	Application  appstudiov1alpha1.Application                  `json:"application"`
+	Components   []appstudiov1alpha1.Component                  `json:"components,omitempty"`
+	Environments []appstudiov1alpha1.Environment                `json:"environments,omitempty"`
+	Snapshots    []appstudiov1alpha1.Snapshot                   `json:"snapshots,omitempty"`
+	Bindings     []appstudiov1alpha1.SnapshotEnvironmentBinding `json:"bindings,omitempty"`
+}
+
+// GitOpsRepository is the GitOps repository that an imported Application is bound to
+type GitOpsRepository struct {
+	URL     string
+	Branch  string
+	Context string
+}
+
+// ImportOptions are the options of the import of a bundle
+type ImportOptions struct {
+	// Namespace is the namespace the resources are created in
+	Namespace string
+	// Application is the name of the imported Application, the name of the exported Application if empty
+	Application string
+	// Components renames the Components, keyed by their name in the bundle
+	Components map[string]string
+	// Environments renames the Environments, keyed by their name in the bundle
+	Environments map[string]string
+	// GitOpsRepository binds the imported Application to an existing GitOps repository. The Application gets a new
+	// generated repository if nil, unless it was bound to a user-supplied repository when it was exported
+	GitOpsRepository *GitOpsRepository
+}
+
+// Export returns the bundle of the Application of the namespace
+func Export(ctx context.Context, cl client.Client, namespace string, name string) (*Bundle, error) {
+	bundle := &Bundle{APIVersion: APIVersion, Kind: Kind}
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &bundle.Application); err != nil {
+		return nil, fmt.Errorf("unable to get Application %s: %v", name, err)
+	}
+	bundle.Application = appstudiov1alpha1.Application{
+		TypeMeta:   metav1.TypeMeta{APIVersion: appstudiov1alpha1.GroupVersion.String(), Kind: "Application"},
+		ObjectMeta: exportObjectMeta(bundle.Application.ObjectMeta),
+		Spec:       bundle.Application.Spec,
+	}
+
+	var componentList appstudiov1alpha1.ComponentList
+	if err := cl.List(ctx, &componentList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("unable to list the Components of Application %s: %v", name, err)
+	}
+	for _, component := range componentList.Items {
+		if component.Spec.Application != name {
+			continue
+		}
+		bundle.Components = append(bundle.Components, appstudiov1alpha1.Component{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appstudiov1alpha1.GroupVersion.String(), Kind: "Component"},
+			ObjectMeta: exportObjectMeta(component.ObjectMeta),
+			Spec:       component.Spec,
+		})
+	}
+
+	var bindingList appstudiov1alpha1.SnapshotEnvironmentBindingList
+	if err := cl.List(ctx, &bindingList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("unable to list the SnapshotEnvironmentBindings of Application %s: %v", name, err)
+	}
+	environments := make(map[string]bool)
+	snapshots := make(map[string]bool)
+	for _, binding := range bindingList.Items {
+		if binding.Spec.Application != name {
+			continue
+		}
+		bundle.Bindings = append(bundle.Bindings, appstudiov1alpha1.SnapshotEnvironmentBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appstudiov1alpha1.GroupVersion.String(), Kind: "SnapshotEnvironmentBinding"},
+			ObjectMeta: exportObjectMeta(binding.ObjectMeta),
+			Spec:       binding.Spec,
+		})
+		environments[binding.Spec.Environment] = true
+		if binding.Spec.Snapshot != "" {
+			snapshots[binding.Spec.Snapshot] = true
+		}
+	}
+
+	for environmentName := range environments {
+		var environment appstudiov1alpha1.Environment
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: environmentName}, &environment); err != nil {
+			return nil, fmt.Errorf("unable to get Environment %s: %v", environmentName, err)
+		}
+		bundle.Environments = append(bundle.Environments, appstudiov1alpha1.Environment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appstudiov1alpha1.GroupVersion.String(), Kind: "Environment"},
+			ObjectMeta: exportObjectMeta(environment.ObjectMeta),
+			Spec:       environment.Spec,
+		})
+	}
+
+	for snapshotName := range snapshots {
+		var snapshot appstudiov1alpha1.Snapshot
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: snapshotName}, &snapshot); err != nil {
+			return nil, fmt.Errorf("unable to get Snapshot %s: %v", snapshotName, err)
+		}
+		bundle.Snapshots = append(bundle.Snapshots, appstudiov1alpha1.Snapshot{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appstudiov1alpha1.GroupVersion.String(), Kind: "Snapshot"},
+			ObjectMeta: exportObjectMeta(snapshot.ObjectMeta),
+			Spec:       snapshot.Spec,
+		})
+	}
+	sort.Slice(bundle.Components, func(i, j int) bool { return bundle.Components[i].Name < bundle.Components[j].Name })
+	sort.Slice(bundle.Environments, func(i, j int) bool { return bundle.Environments[i].Name < bundle.Environments[j].Name })
+	sort.Slice(bundle.Snapshots, func(i, j int) bool { return bundle.Snapshots[i].Name < bundle.Snapshots[j].Name })
+	sort.Slice(bundle.Bindings, func(i, j int) bool { return bundle.Bindings[i].Name < bundle.Bindings[j].Name })
+	return bundle, nil
+}
+
+// Import creates the resources of the bundle in the namespace of the options, renamed and bound to the GitOps repository of the options,
+// and returns them. The Environments that already exist in the namespace are reused, as they can be shared by several Applications
+func Import(ctx context.Context, cl client.Client, bundle *Bundle, options ImportOptions) ([]client.Object, error) {
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+	objs := bundle.renamed(options)
+
+	var created []client.Object
+	for _, obj := range objs {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if err := cl.Create(ctx, obj); err != nil {
+			if _, ok := obj.(*appstudiov1alpha1.Environment); ok && k8sErrors.IsAlreadyExists(err) {
+				continue
+			}
+			return created, fmt.Errorf("unable to create %s %s: %v", kind, obj.GetName(), err)
+		}
+		created = append(created, obj)
+	}
+	return created, nil
+}
+
+// Validate returns an error if the bundle is not a bundle of a supported version
+func (b *Bundle) Validate() error {
+	if b.APIVersion != APIVersion || b.Kind != Kind {
+		return fmt.Errorf("unsupported bundle %s %s, expected %s %s", b.APIVersion, b.Kind, APIVersion, Kind)
+	}
+	if b.Application.Name == "" {
+		return fmt.Errorf("the bundle has no Application")
+	}
+	return nil
+}
+
+// Marshal returns the YAML of the bundle
+func Marshal(bundle *Bundle) ([]byte, error) {
+	return yaml.Marshal(bundle)
+}
+
+// Unmarshal returns the bundle of the YAML, and an error if it is not a bundle of a supported version
+func Unmarshal(data []byte) (*Bundle, error) {
+	var bundle Bundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("unable to parse the bundle: %v", err)
+	}
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// renamed returns copies of the resources of the bundle in the namespace of the options, renamed and bound to the GitOps repository
+// of the options, in the order they are created in
+func (b *Bundle) renamed(options ImportOptions) []client.Object {
+	applicationName := rename(b.Application.Name, map[string]string{b.Application.Name: options.Application})
+	var objs []client.Object
+
+	for i := range b.Environments {
+		environment := b.Environments[i].DeepCopy()
+		environment.Namespace = options.Namespace
+		environment.Name = rename(environment.Name, options.Environments)
+		if environment.Spec.ParentEnvironment != "" {
+			environment.Spec.ParentEnvironment = rename(environment.Spec.ParentEnvironment, options.Environments)
+		}
+		objs = append(objs, environment)
+	}
+
+	application := b.Application.DeepCopy()
+	application.Namespace = options.Namespace
+	application.Name = applicationName
+	if options.GitOpsRepository != nil {
+		application.Spec.GitOpsRepository = appstudiov1alpha1.ApplicationGitRepository{
+			URL:     options.GitOpsRepository.URL,
+			Branch:  options.GitOpsRepository.Branch,
+			Context: options.GitOpsRepository.Context,
+		}
+	}
+	objs = append(objs, application)
+
+	for i := range b.Components {
+		component := b.Components[i].DeepCopy()
+		component.Namespace = options.Namespace
+		component.Name = rename(component.Name, options.Components)
+		component.Spec.ComponentName = rename(component.Spec.ComponentName, options.Components)
+		component.Spec.Application = applicationName
+		objs = append(objs, component)
+	}
+
+	for i := range b.Snapshots {
+		snapshot := b.Snapshots[i].DeepCopy()
+		snapshot.Namespace = options.Namespace
+		snapshot.Spec.Application = applicationName
+		for j := range snapshot.Spec.Components {
+			snapshot.Spec.Components[j].Name = rename(snapshot.Spec.Components[j].Name, options.Components)
+		}
+		objs = append(objs, snapshot)
+	}
+
+	for i := range b.Bindings {
+		binding := b.Bindings[i].DeepCopy()
+		binding.Namespace = options.Namespace
+		binding.Spec.Application = applicationName
+		binding.Spec.Environment = rename(binding.Spec.Environment, options.Environments)
+		for j := range binding.Spec.Components {
+			binding.Spec.Components[j].Name = rename(binding.Spec.Components[j].Name, options.Components)
+		}
+		// The Bindings are looked up by these labels
+		if _, ok := binding.Labels["appstudio.application"]; ok {
+			binding.Labels["appstudio.application"] = applicationName
+		}
+		if _, ok := binding.Labels["appstudio.environment"]; ok {
+			binding.Labels["appstudio.environment"] = binding.Spec.Environment
+		}
+		objs = append(objs, binding)
+	}
+	return objs
+}
+
+// rename returns the new name of the resource in the renames, or its name if it is not renamed
+func rename(name string, renames map[string]string) string {
+	if newName := renames[name]; newName != "" {
+		return newName
+	}
+	return name
+}
+
+// exportObjectMeta returns the metadata of the resource without its namespace, the fields set by the API server, and the annotations
+// that describe its state in the cluster
+func exportObjectMeta(objectMeta metav1.ObjectMeta) metav1.ObjectMeta {
+	exported := metav1.ObjectMeta{
+		Name:        objectMeta.Name,
+		Labels:      objectMeta.Labels,
+		Annotations: make(map[string]string),
+	}
+	for key, value := range objectMeta.Annotations {
+		exported.Annotations[key] = value
+	}
+	for _, key := range clusterAnnotations {
+		delete(exported.Annotations, key)
+	}
+	if len(exported.Annotations) == 0 {
+		exported.Annotations = nil
+	}
+	return exported
+}
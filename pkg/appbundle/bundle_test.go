@@ -0,0 +1,329 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appbundle
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// getTestObjects returns an Application with two Components bound to the staging Environment by a Snapshot, in the namespace
+func getTestObjects(namespace string) []client.Object {
+	return []client.Object{
+		&appstudiov1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "petclinic",
+				Namespace: namespace,
+				Annotations: map[string]string{
+					"appstudio.openshift.io/gitops-repository-retention": "archive",
+					"appstudio.openshift.io/health":                      `{"components":{"total":2}}`,
+				},
+			},
+			Spec: appstudiov1alpha1.ApplicationSpec{DisplayName: "Pet Clinic"},
+		},
+		&appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: namespace},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName: "backend",
+				Application:   "petclinic",
+				Source: appstudiov1alpha1.ComponentSource{
+					ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+						GitSource: &appstudiov1alpha1.GitSource{URL: "https://github.com/devfile-samples/devfile-sample-java-springboot-basic"},
+					},
+				},
+			},
+		},
+		&appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: namespace},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName:  "frontend",
+				Application:    "petclinic",
+				ContainerImage: "quay.io/test/frontend:latest",
+			},
+		},
+		&appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName:  "other",
+				Application:    "other-app",
+				ContainerImage: "quay.io/test/other:latest",
+			},
+		},
+		&appstudiov1alpha1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: namespace},
+			Spec: appstudiov1alpha1.EnvironmentSpec{
+				DisplayName:        "Staging",
+				DeploymentStrategy: appstudiov1alpha1.DeploymentStrategy_AppStudioAutomated,
+			},
+		},
+		&appstudiov1alpha1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: namespace},
+			Spec: appstudiov1alpha1.EnvironmentSpec{
+				DisplayName:        "Production",
+				DeploymentStrategy: appstudiov1alpha1.DeploymentStrategy_AppStudioAutomated,
+			},
+		},
+		&appstudiov1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "petclinic-snapshot", Namespace: namespace},
+			Spec: appstudiov1alpha1.SnapshotSpec{
+				Application: "petclinic",
+				Components: []appstudiov1alpha1.SnapshotComponent{
+					{Name: "backend", ContainerImage: "quay.io/test/backend:1234"},
+					{Name: "frontend", ContainerImage: "quay.io/test/frontend:latest"},
+				},
+			},
+		},
+		&appstudiov1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "unbound-snapshot", Namespace: namespace},
+			Spec:       appstudiov1alpha1.SnapshotSpec{Application: "petclinic"},
+		},
+		&appstudiov1alpha1.SnapshotEnvironmentBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "petclinic-staging",
+				Namespace: namespace,
+				Labels: map[string]string{
+					"appstudio.application": "petclinic",
+					"appstudio.environment": "staging",
+				},
+				Annotations: map[string]string{
+					"appstudio.openshift.io/deployment-history": `[{"snapshot": "petclinic-snapshot", "commitID": "1234"}]`,
+					"appstudio.openshift.io/dry-run":            "true",
+					"appstudio.openshift.io/rollback":           `{"commitID": "1234"}`,
+				},
+			},
+			Spec: appstudiov1alpha1.SnapshotEnvironmentBindingSpec{
+				Application: "petclinic",
+				Environment: "staging",
+				Snapshot:    "petclinic-snapshot",
+				Components: []appstudiov1alpha1.BindingComponent{
+					{Name: "backend"},
+					{Name: "frontend"},
+				},
+			},
+		},
+	}
+}
+
+// newFakeClient returns a fake client holding the given objects
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	require.NoError(t, appstudiov1alpha1.AddToScheme(scheme.Scheme))
+	var runtimeObjs []runtime.Object
+	for _, obj := range objs {
+		runtimeObjs = append(runtimeObjs, obj)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(runtimeObjs...).Build()
+}
+
+func TestExport(t *testing.T) {
+	cl := newFakeClient(t, getTestObjects("source")...)
+
+	bundle, err := Export(context.Background(), cl, "source", "petclinic")
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+
+	assert.Equal(t, APIVersion, bundle.APIVersion)
+	assert.Equal(t, Kind, bundle.Kind)
+	assert.Equal(t, metav1.ObjectMeta{
+		Name:        "petclinic",
+		Annotations: map[string]string{"appstudio.openshift.io/gitops-repository-retention": "archive"},
+	}, bundle.Application.ObjectMeta)
+	assert.Equal(t, "Application", bundle.Application.Kind)
+
+	var componentNames []string
+	for _, component := range bundle.Components {
+		componentNames = append(componentNames, component.Name)
+		assert.Empty(t, component.Namespace)
+		assert.Empty(t, component.ResourceVersion)
+	}
+	assert.Equal(t, []string{"backend", "frontend"}, componentNames)
+
+	// Only the Environments bound to the Application are exported
+	if assert.Len(t, bundle.Environments, 1) {
+		assert.Equal(t, "staging", bundle.Environments[0].Name)
+	}
+	if assert.Len(t, bundle.Bindings, 1) {
+		assert.Equal(t, "petclinic-staging", bundle.Bindings[0].Name)
+		assert.Nil(t, bundle.Bindings[0].Annotations)
+	}
+	// Only the Snapshots deployed by the Bindings are exported
+	if assert.Len(t, bundle.Snapshots, 1) {
+		assert.Equal(t, "petclinic-snapshot", bundle.Snapshots[0].Name)
+		assert.Equal(t, "Snapshot", bundle.Snapshots[0].Kind)
+		assert.Empty(t, bundle.Snapshots[0].Namespace)
+	}
+
+	_, err = Export(context.Background(), cl, "source", "missing-app")
+	assert.ErrorContains(t, err, "unable to get Application missing-app")
+
+	var objsWithoutSnapshot []client.Object
+	for _, obj := range getTestObjects("source") {
+		if _, ok := obj.(*appstudiov1alpha1.Snapshot); !ok {
+			objsWithoutSnapshot = append(objsWithoutSnapshot, obj)
+		}
+	}
+	_, err = Export(context.Background(), newFakeClient(t, objsWithoutSnapshot...), "source", "petclinic")
+	assert.ErrorContains(t, err, "unable to get Snapshot petclinic-snapshot")
+}
+
+func TestImport(t *testing.T) {
+	source := newFakeClient(t, getTestObjects("source")...)
+	bundle, err := Export(context.Background(), source, "source", "petclinic")
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		existing   []client.Object
+		options    ImportOptions
+		wantNames  []string
+		wantErr    string
+		wantGitOps appstudiov1alpha1.ApplicationGitRepository
+	}{
+		{
+			name:      "Imported with the same names",
+			options:   ImportOptions{Namespace: "target"},
+			wantNames: []string{"staging", "petclinic", "backend", "frontend", "petclinic-snapshot", "petclinic-staging"},
+		},
+		{
+			name: "Imported with renames and bound to a GitOps repository",
+			options: ImportOptions{
+				Namespace:        "target",
+				Application:      "petclinic-copy",
+				Components:       map[string]string{"backend": "api"},
+				Environments:     map[string]string{"staging": "qa"},
+				GitOpsRepository: &GitOpsRepository{URL: "https://github.com/testorg/petclinic-gitops", Branch: "main"},
+			},
+			wantNames:  []string{"qa", "petclinic-copy", "api", "frontend", "petclinic-snapshot", "petclinic-staging"},
+			wantGitOps: appstudiov1alpha1.ApplicationGitRepository{URL: "https://github.com/testorg/petclinic-gitops", Branch: "main"},
+		},
+		{
+			name: "Existing Environment is reused",
+			existing: []client.Object{
+				&appstudiov1alpha1.Environment{ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "target"}},
+			},
+			options:   ImportOptions{Namespace: "target"},
+			wantNames: []string{"petclinic", "backend", "frontend", "petclinic-snapshot", "petclinic-staging"},
+		},
+		{
+			name: "Application already exists",
+			existing: []client.Object{
+				&appstudiov1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "petclinic", Namespace: "target"}},
+			},
+			options: ImportOptions{Namespace: "target"},
+			wantErr: "unable to create Application petclinic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := newFakeClient(t, tt.existing...)
+
+			created, err := Import(context.Background(), cl, bundle, tt.options)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			var createdNames []string
+			for _, obj := range created {
+				createdNames = append(createdNames, obj.GetName())
+				assert.Equal(t, "target", obj.GetNamespace())
+			}
+			assert.Equal(t, tt.wantNames, createdNames)
+
+			applicationName := rename("petclinic", map[string]string{"petclinic": tt.options.Application})
+			var application appstudiov1alpha1.Application
+			if err := cl.Get(context.Background(), types.NamespacedName{Namespace: "target", Name: applicationName}, &application); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantGitOps, application.Spec.GitOpsRepository)
+
+			var binding appstudiov1alpha1.SnapshotEnvironmentBinding
+			if err := cl.Get(context.Background(), types.NamespacedName{Namespace: "target", Name: "petclinic-staging"}, &binding); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			environmentName := rename("staging", tt.options.Environments)
+			assert.Equal(t, applicationName, binding.Spec.Application)
+			assert.Equal(t, environmentName, binding.Spec.Environment)
+			assert.Equal(t, map[string]string{"appstudio.application": applicationName, "appstudio.environment": environmentName}, binding.Labels)
+			assert.Equal(t, rename("backend", tt.options.Components), binding.Spec.Components[0].Name)
+
+			var snapshot appstudiov1alpha1.Snapshot
+			if err := cl.Get(context.Background(), types.NamespacedName{Namespace: "target", Name: binding.Spec.Snapshot}, &snapshot); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, applicationName, snapshot.Spec.Application)
+			assert.Equal(t, rename("backend", tt.options.Components), snapshot.Spec.Components[0].Name)
+			assert.Equal(t, "frontend", snapshot.Spec.Components[1].Name)
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "Bundle",
+			data: "apiVersion: appstudio.redhat.com/v1alpha1\nkind: ApplicationBundle\napplication:\n  metadata:\n    name: petclinic\n",
+		},
+		{
+			name:    "Unsupported version",
+			data:    "apiVersion: appstudio.redhat.com/v1beta1\nkind: ApplicationBundle\napplication:\n  metadata:\n    name: petclinic\n",
+			wantErr: "unsupported bundle appstudio.redhat.com/v1beta1 ApplicationBundle",
+		},
+		{
+			name:    "No Application",
+			data:    "apiVersion: appstudio.redhat.com/v1alpha1\nkind: ApplicationBundle\n",
+			wantErr: "the bundle has no Application",
+		},
+		{
+			name:    "Invalid YAML",
+			data:    "apiVersion: [",
+			wantErr: "unable to parse the bundle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := Unmarshal([]byte(tt.data))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, "petclinic", bundle.Application.Name)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appbundle
+
+import (
+	"context"
+	"go/build"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var (
+	k8sClient client.Client
+	testEnv   *envtest.Environment
+)
+
+func TestAPIs(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t,
+		"Application Bundle Suite")
+}
+
+var _ = BeforeSuite(func() {
+	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+	applicationAPIDepVersion := "v0.0.0-20230509152222-ef5c4dcebc94"
+
+	By("bootstrapping test environment")
+	testEnv = &envtest.Environment{
+		CRDDirectoryPaths: []string{
+			filepath.Join(build.Default.GOPATH, "pkg", "mod", "github.com", "redhat-appstudio", "application-api@"+applicationAPIDepVersion, "manifests"),
+		},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	cfg, err := testEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(cfg).NotTo(BeNil())
+
+	err = appstudiov1alpha1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(k8sClient).NotTo(BeNil())
+}, 60)
+
+var _ = AfterSuite(func() {
+	By("tearing down the test environment")
+	err := testEnv.Stop()
+	Expect(err).NotTo(HaveOccurred())
+})
+
+var _ = Describe("Application bundle", func() {
+
+	createNamespace := func(name string) {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		Expect(k8sClient.Create(context.Background(), namespace)).Should(Succeed())
+	}
+
+	Context("Export an Application and import it into another namespace", func() {
+		It("Should recreate the Application, its Components, Environments, Snapshots and bindings", func() {
+			ctx := context.Background()
+			createNamespace("bundle-source")
+			createNamespace("bundle-target")
+			for _, obj := range getTestObjects("bundle-source") {
+				Expect(k8sClient.Create(ctx, obj)).Should(Succeed())
+			}
+
+			bundle, err := Export(ctx, k8sClient, "bundle-source", "petclinic")
+			Expect(err).NotTo(HaveOccurred())
+			data, err := Marshal(bundle)
+			Expect(err).NotTo(HaveOccurred())
+
+			importedBundle, err := Unmarshal(data)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = Import(ctx, k8sClient, importedBundle, ImportOptions{Namespace: "bundle-target"})
+			Expect(err).NotTo(HaveOccurred())
+
+			// The bundle of the imported Application is the same as the one of the exported Application
+			roundTripBundle, err := Export(ctx, k8sClient, "bundle-target", "petclinic")
+			Expect(err).NotTo(HaveOccurred())
+			roundTripData, err := Marshal(roundTripBundle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(roundTripData)).Should(Equal(string(data)))
+		})
+
+		It("Should recreate the Application with renames and a GitOps repository", func() {
+			ctx := context.Background()
+			createNamespace("bundle-renamed")
+
+			bundle, err := Export(ctx, k8sClient, "bundle-source", "petclinic")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = Import(ctx, k8sClient, bundle, ImportOptions{
+				Namespace:        "bundle-renamed",
+				Application:      "petclinic-copy",
+				Components:       map[string]string{"backend": "api"},
+				Environments:     map[string]string{"staging": "qa"},
+				GitOpsRepository: &GitOpsRepository{URL: "https://github.com/testorg/petclinic-gitops"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			renamedBundle, err := Export(ctx, k8sClient, "bundle-renamed", "petclinic-copy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renamedBundle.Application.Spec.GitOpsRepository.URL).Should(Equal("https://github.com/testorg/petclinic-gitops"))
+			Expect(renamedBundle.Components).Should(HaveLen(2))
+			Expect(renamedBundle.Components[0].Name).Should(Equal("api"))
+			Expect(renamedBundle.Components[0].Spec.Application).Should(Equal("petclinic-copy"))
+			Expect(renamedBundle.Environments).Should(HaveLen(1))
+			Expect(renamedBundle.Environments[0].Name).Should(Equal("qa"))
+			Expect(renamedBundle.Bindings).Should(HaveLen(1))
+			Expect(renamedBundle.Bindings[0].Spec.Environment).Should(Equal("qa"))
+			Expect(renamedBundle.Bindings[0].Spec.Components[0].Name).Should(Equal("api"))
+			Expect(renamedBundle.Snapshots).Should(HaveLen(1))
+			Expect(renamedBundle.Snapshots[0].Spec.Application).Should(Equal("petclinic-copy"))
+			Expect(renamedBundle.Snapshots[0].Spec.Components[0].Name).Should(Equal("api"))
+		})
+	})
+})
@@ -0,0 +1,146 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"fmt"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Autoscaling describes the replica bounds and utilization targets used to autoscale a Component
+type Autoscaling struct {
+	// MinReplicas is the lower bound of replicas the autoscaler can scale down to
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper bound of replicas the autoscaler can scale up to
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+
+	// TargetCPUUtilizationPercentage is the target average CPU utilization across all pods
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage is the target average memory utilization across all pods
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// Validate checks that the autoscaling bounds and targets are consistent
+func (a Autoscaling) Validate() error {
+	if a.MaxReplicas < 1 {
+		return fmt.Errorf("autoscaling maxReplicas must be greater than 0")
+	}
+	if a.MinReplicas != nil {
+		if *a.MinReplicas < 1 {
+			return fmt.Errorf("autoscaling minReplicas must be greater than 0")
+		}
+		if *a.MinReplicas > a.MaxReplicas {
+			return fmt.Errorf("autoscaling minReplicas %d cannot be greater than maxReplicas %d", *a.MinReplicas, a.MaxReplicas)
+		}
+	}
+	if a.TargetCPUUtilizationPercentage != nil && *a.TargetCPUUtilizationPercentage < 1 {
+		return fmt.Errorf("autoscaling targetCPUUtilizationPercentage must be greater than 0")
+	}
+	if a.TargetMemoryUtilizationPercentage != nil && *a.TargetMemoryUtilizationPercentage < 1 {
+		return fmt.Errorf("autoscaling targetMemoryUtilizationPercentage must be greater than 0")
+	}
+
+	return nil
+}
+
+// Merge returns a copy of the autoscaling configuration with every field that is set in override replaced
+func (a Autoscaling) Merge(override Autoscaling) Autoscaling {
+	merged := a
+	if override.MinReplicas != nil {
+		merged.MinReplicas = override.MinReplicas
+	}
+	if override.MaxReplicas > 0 {
+		merged.MaxReplicas = override.MaxReplicas
+	}
+	if override.TargetCPUUtilizationPercentage != nil {
+		merged.TargetCPUUtilizationPercentage = override.TargetCPUUtilizationPercentage
+	}
+	if override.TargetMemoryUtilizationPercentage != nil {
+		merged.TargetMemoryUtilizationPercentage = override.TargetMemoryUtilizationPercentage
+	}
+
+	return merged
+}
+
+// GetAutoscalingFromAttributes returns the autoscaling configuration stored in the devfile component attributes.
+// nil is returned if the component does not have an autoscaling configuration
+func GetAutoscalingFromAttributes(componentAttributes attributes.Attributes) (*Autoscaling, error) {
+	var autoscaling Autoscaling
+	err := componentAttributes.GetInto(AutoscalingKey, &autoscaling)
+	if err != nil {
+		if _, ok := err.(*attributes.KeyNotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := autoscaling.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &autoscaling, nil
+}
+
+// GenerateHorizontalPodAutoscaler generates an autoscaling/v2 HorizontalPodAutoscaler that scales the Deployment or StatefulSet with the given name
+func GenerateHorizontalPodAutoscaler(name string, labels map[string]string, autoscaling Autoscaling, workloadKind WorkloadKind) autoscalingv2.HorizontalPodAutoscaler {
+	var metrics []autoscalingv2.MetricSpec
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, getResourceUtilizationMetric(corev1.ResourceCPU, *autoscaling.TargetCPUUtilizationPercentage))
+	}
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, getResourceUtilizationMetric(corev1.ResourceMemory, *autoscaling.TargetMemoryUtilizationPercentage))
+	}
+
+	return autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "autoscaling/v2",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				Kind:       string(workloadKind),
+				Name:       name,
+				APIVersion: "apps/v1",
+			},
+			MinReplicas: autoscaling.MinReplicas,
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+}
+
+func getResourceUtilizationMetric(resourceName corev1.ResourceName, averageUtilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: resourceName,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &averageUtilization,
+			},
+		},
+	}
+}
@@ -0,0 +1,357 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	parser "github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGetAutoscalingFromAttributes(t *testing.T) {
+	minReplicas := int32(2)
+	cpuUtilization := int32(75)
+
+	var err error
+	validAttributes := attributes.Attributes{}.FromMap(map[string]interface{}{
+		AutoscalingKey: Autoscaling{
+			MinReplicas:                    &minReplicas,
+			MaxReplicas:                    5,
+			TargetCPUUtilizationPercentage: &cpuUtilization,
+		},
+	}, &err)
+	if err != nil {
+		t.Error(err)
+	}
+
+	invalidBoundsAttributes := attributes.Attributes{}.FromMap(map[string]interface{}{
+		AutoscalingKey: Autoscaling{
+			MinReplicas: &minReplicas,
+			MaxReplicas: 1,
+		},
+	}, &err)
+	if err != nil {
+		t.Error(err)
+	}
+
+	tests := []struct {
+		name       string
+		attributes attributes.Attributes
+		want       *Autoscaling
+		wantErr    bool
+	}{
+		{
+			name:       "No autoscaling attribute",
+			attributes: attributes.Attributes{}.PutInteger(ReplicaKey, 1),
+		},
+		{
+			name:       "Valid autoscaling attribute",
+			attributes: validAttributes,
+			want: &Autoscaling{
+				MinReplicas:                    &minReplicas,
+				MaxReplicas:                    5,
+				TargetCPUUtilizationPercentage: &cpuUtilization,
+			},
+		},
+		{
+			name:       "Min replicas greater than max replicas",
+			attributes: invalidBoundsAttributes,
+			wantErr:    true,
+		},
+		{
+			name:       "Malformed autoscaling attribute",
+			attributes: attributes.Attributes{}.PutString(AutoscalingKey, "five"),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			autoscaling, err := GetAutoscalingFromAttributes(tt.attributes)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, autoscaling, "autoscaling configuration did not match")
+			}
+		})
+	}
+}
+
+func TestAutoscalingMerge(t *testing.T) {
+	minReplicas := int32(1)
+	overrideMinReplicas := int32(3)
+	cpuUtilization := int32(80)
+	memoryUtilization := int32(60)
+
+	base := Autoscaling{
+		MinReplicas:                    &minReplicas,
+		MaxReplicas:                    4,
+		TargetCPUUtilizationPercentage: &cpuUtilization,
+	}
+
+	tests := []struct {
+		name     string
+		override Autoscaling
+		want     Autoscaling
+	}{
+		{
+			name:     "Empty override",
+			override: Autoscaling{},
+			want:     base,
+		},
+		{
+			name: "Override bounds and add a memory target",
+			override: Autoscaling{
+				MinReplicas:                       &overrideMinReplicas,
+				MaxReplicas:                       10,
+				TargetMemoryUtilizationPercentage: &memoryUtilization,
+			},
+			want: Autoscaling{
+				MinReplicas:                       &overrideMinReplicas,
+				MaxReplicas:                       10,
+				TargetCPUUtilizationPercentage:    &cpuUtilization,
+				TargetMemoryUtilizationPercentage: &memoryUtilization,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, base.Merge(tt.override), "merged autoscaling configuration did not match")
+		})
+	}
+}
+
+func TestGenerateHorizontalPodAutoscaler(t *testing.T) {
+	minReplicas := int32(2)
+	cpuUtilization := int32(75)
+	memoryUtilization := int32(50)
+	labels := map[string]string{
+		"app.kubernetes.io/instance": "component-sample",
+	}
+
+	tests := []struct {
+		name        string
+		autoscaling Autoscaling
+		wantMetrics []autoscalingv2.MetricSpec
+	}{
+		{
+			name: "No utilization targets",
+			autoscaling: Autoscaling{
+				MaxReplicas: 3,
+			},
+		},
+		{
+			name: "CPU and memory utilization targets",
+			autoscaling: Autoscaling{
+				MinReplicas:                       &minReplicas,
+				MaxReplicas:                       3,
+				TargetCPUUtilizationPercentage:    &cpuUtilization,
+				TargetMemoryUtilizationPercentage: &memoryUtilization,
+			},
+			wantMetrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name: corev1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{
+							Type:               autoscalingv2.UtilizationMetricType,
+							AverageUtilization: &cpuUtilization,
+						},
+					},
+				},
+				{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name: corev1.ResourceMemory,
+						Target: autoscalingv2.MetricTarget{
+							Type:               autoscalingv2.UtilizationMetricType,
+							AverageUtilization: &memoryUtilization,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hpa := GenerateHorizontalPodAutoscaler("component-sample", labels, tt.autoscaling, DeploymentWorkloadKind)
+			want := autoscalingv2.HorizontalPodAutoscaler{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "HorizontalPodAutoscaler",
+					APIVersion: "autoscaling/v2",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "component-sample",
+					Labels: labels,
+				},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+						Kind:       "Deployment",
+						Name:       "component-sample",
+						APIVersion: "apps/v1",
+					},
+					MinReplicas: tt.autoscaling.MinReplicas,
+					MaxReplicas: tt.autoscaling.MaxReplicas,
+					Metrics:     tt.wantMetrics,
+				},
+			}
+			assert.Equal(t, want, hpa, "HorizontalPodAutoscaler did not match")
+		})
+	}
+}
+
+func TestGetResourceFromDevfileWithAutoscaling(t *testing.T) {
+	autoscaledDevfile := `
+commands:
+- apply:
+    component: kubernetes-deploy
+  id: deployk8s
+- composite:
+    commands:
+    - deployk8s
+    group:
+      isDefault: true
+      kind: deploy
+    parallel: false
+  id: deploy
+components:
+- attributes:
+    deployment/autoscaling:
+      maxReplicas: 5
+      minReplicas: 2
+      targetCPUUtilizationPercentage: 70
+  kubernetes:
+    deployByDefault: false
+    inlined: |-
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: deploy-sample
+      spec:
+        template:
+          spec:
+            containers:
+            - image: quay.io/redhat-appstudio/user-workload:application-service-system-component-sample
+              name: container-image
+  name: kubernetes-deploy
+metadata:
+  name: java-springboot
+schemaVersion: 2.2.0`
+
+	invalidAutoscalingDevfile := `
+components:
+- attributes:
+    deployment/autoscaling:
+      maxReplicas: 0
+  kubernetes:
+    deployByDefault: false
+    inlined: |-
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: deploy-sample
+      spec:
+        template:
+          spec:
+            containers:
+            - image: quay.io/redhat-appstudio/user-workload:application-service-system-component-sample
+              name: container-image
+  name: kubernetes-deploy
+metadata:
+  name: java-springboot
+schemaVersion: 2.2.0`
+
+	minReplicas := int32(2)
+	cpuUtilization := int32(70)
+
+	tests := []struct {
+		name          string
+		devfileString string
+		wantHPA       *autoscalingv2.HorizontalPodAutoscaler
+		wantErr       bool
+	}{
+		{
+			name:          "Autoscaled component generates a HorizontalPodAutoscaler",
+			devfileString: autoscaledDevfile,
+			wantHPA: &autoscalingv2.HorizontalPodAutoscaler{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "HorizontalPodAutoscaler",
+					APIVersion: "autoscaling/v2",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "component-sample",
+					Labels: generateK8sLabels("component-sample", "application-sample"),
+				},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+						Kind:       "Deployment",
+						Name:       "component-sample",
+						APIVersion: "apps/v1",
+					},
+					MinReplicas: &minReplicas,
+					MaxReplicas: 5,
+					Metrics: []autoscalingv2.MetricSpec{
+						getResourceUtilizationMetric(corev1.ResourceCPU, cpuUtilization),
+					},
+				},
+			},
+		},
+		{
+			name:          "Invalid autoscaling bounds",
+			devfileString: invalidAutoscalingDevfile,
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileData, err := ParseDevfile(DevfileSrc{Data: tt.devfileString})
+			if err != nil {
+				t.Errorf("TestGetResourceFromDevfileWithAutoscaling() unexpected parse error: %v", err)
+			}
+			deployAssociatedComponents, err := parser.GetDeployComponents(devfileData)
+			if err != nil {
+				t.Errorf("TestGetResourceFromDevfileWithAutoscaling() unexpected get deploy components error: %v", err)
+			}
+			logger := ctrl.Log.WithName("TestGetResourceFromDevfileWithAutoscaling")
+
+			actualResources, err := GetResourceFromDevfile(logger, devfileData, deployAssociatedComponents, "component-sample", "application-sample", "image1", "")
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("TestGetResourceFromDevfileWithAutoscaling() unexpected error: %v", err)
+			} else if err == nil {
+				var actualHPA *autoscalingv2.HorizontalPodAutoscaler
+				for _, other := range actualResources.Others {
+					if hpa, ok := other.(autoscalingv2.HorizontalPodAutoscaler); ok {
+						actualHPA = &hpa
+					}
+				}
+				assert.Equal(t, tt.wantHPA, actualHPA, "HorizontalPodAutoscaler did not match")
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"fmt"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// TopologyDomain is a failure domain that the pods of a Component are spread across
+type TopologyDomain string
+
+const (
+	ZoneTopologyDomain TopologyDomain = "zone"
+	HostTopologyDomain TopologyDomain = "host"
+)
+
+// AntiAffinity is how strictly the pods of a Component avoid running on the same host
+type AntiAffinity string
+
+const (
+	PreferredAntiAffinity AntiAffinity = "preferred"
+	RequiredAntiAffinity  AntiAffinity = "required"
+)
+
+// Availability describes the disruption budget and the scheduling constraints that keep a multi-replica Component available
+type Availability struct {
+	// MinAvailable is the number or percentage of pods that must stay available during voluntary disruptions
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods that can be unavailable during voluntary disruptions
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// TopologySpread are the failure domains, zone or host, that the pods are evenly spread across
+	TopologySpread []TopologyDomain `json:"topologySpread,omitempty"`
+
+	// AntiAffinity keeps the pods off the hosts already running a pod of the Component, preferred or required
+	AntiAffinity AntiAffinity `json:"antiAffinity,omitempty"`
+}
+
+// Validate checks that at most one disruption budget bound is set and that the scheduling constraints are supported
+func (a Availability) Validate() error {
+	if a.MinAvailable != nil && a.MaxUnavailable != nil {
+		return fmt.Errorf("availability minAvailable and maxUnavailable cannot be set together")
+	}
+	for _, domain := range a.TopologySpread {
+		if domain != ZoneTopologyDomain && domain != HostTopologyDomain {
+			return fmt.Errorf("availability topologySpread %q must be either %s or %s", domain, ZoneTopologyDomain, HostTopologyDomain)
+		}
+	}
+	switch a.AntiAffinity {
+	case "", PreferredAntiAffinity, RequiredAntiAffinity:
+	default:
+		return fmt.Errorf("availability antiAffinity %q must be either %s or %s", a.AntiAffinity, PreferredAntiAffinity, RequiredAntiAffinity)
+	}
+
+	return nil
+}
+
+// HasDisruptionBudget returns true if a PodDisruptionBudget is generated for the availability configuration
+func (a Availability) HasDisruptionBudget() bool {
+	return a.MinAvailable != nil || a.MaxUnavailable != nil
+}
+
+// HasSchedulingConstraints returns true if the availability configuration sets topology spread constraints or anti-affinity
+func (a Availability) HasSchedulingConstraints() bool {
+	return len(a.TopologySpread) > 0 || a.AntiAffinity != ""
+}
+
+// Merge returns a copy of the availability configuration with every field that is set in override replaced.
+// Setting one of the disruption budget bounds in override replaces the other bound
+func (a Availability) Merge(override Availability) Availability {
+	merged := a
+	if override.MinAvailable != nil {
+		merged.MinAvailable = override.MinAvailable
+		merged.MaxUnavailable = nil
+	}
+	if override.MaxUnavailable != nil {
+		merged.MaxUnavailable = override.MaxUnavailable
+		merged.MinAvailable = nil
+	}
+	if override.TopologySpread != nil {
+		merged.TopologySpread = override.TopologySpread
+	}
+	if override.AntiAffinity != "" {
+		merged.AntiAffinity = override.AntiAffinity
+	}
+
+	return merged
+}
+
+// GetAvailabilityFromAttributes returns the availability configuration stored in the devfile component attributes.
+// nil is returned if the component does not have an availability configuration
+func GetAvailabilityFromAttributes(componentAttributes attributes.Attributes) (*Availability, error) {
+	var availability Availability
+	err := componentAttributes.GetInto(AvailabilityKey, &availability)
+	if err != nil {
+		if _, ok := err.(*attributes.KeyNotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := availability.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &availability, nil
+}
+
+// GeneratePodDisruptionBudget generates a policy/v1 PodDisruptionBudget for the pods of the Component with the given name
+func GeneratePodDisruptionBudget(name string, labels map[string]string, availability Availability) policyv1.PodDisruptionBudget {
+	return policyv1.PodDisruptionBudget{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   availability.MinAvailable,
+			MaxUnavailable: availability.MaxUnavailable,
+			Selector: &v1.LabelSelector{
+				MatchLabels: getMatchLabel(name),
+			},
+		},
+	}
+}
+
+// ApplyAvailability sets the topology spread constraints and the pod anti-affinity of the availability configuration on the pod spec
+// of the Component with the given name. Pods are spread with a max skew of 1, without blocking the scheduling if the spread cannot be met
+func ApplyAvailability(podSpec *corev1.PodSpec, name string, availability Availability) {
+	selector := &v1.LabelSelector{
+		MatchLabels: getMatchLabel(name),
+	}
+
+	var constraints []corev1.TopologySpreadConstraint
+	for _, domain := range availability.TopologySpread {
+		constraints = append(constraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       getTopologyKey(domain),
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     selector,
+		})
+	}
+	if constraints != nil {
+		podSpec.TopologySpreadConstraints = constraints
+	}
+
+	if availability.AntiAffinity != "" {
+		term := corev1.PodAffinityTerm{
+			LabelSelector: selector,
+			TopologyKey:   corev1.LabelHostname,
+		}
+		antiAffinity := &corev1.PodAntiAffinity{}
+		if availability.AntiAffinity == RequiredAntiAffinity {
+			antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = []corev1.PodAffinityTerm{term}
+		} else {
+			antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = []corev1.WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: term}}
+		}
+		if podSpec.Affinity == nil {
+			podSpec.Affinity = &corev1.Affinity{}
+		}
+		podSpec.Affinity.PodAntiAffinity = antiAffinity
+	}
+}
+
+func getTopologyKey(domain TopologyDomain) string {
+	if domain == ZoneTopologyDomain {
+		return corev1.LabelTopologyZone
+	}
+	return corev1.LabelHostname
+}
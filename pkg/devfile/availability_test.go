@@ -0,0 +1,286 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	parser "github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGetAvailabilityFromAttributes(t *testing.T) {
+	minAvailable := intstr.FromInt(1)
+	maxUnavailable := intstr.FromString("25%")
+
+	var err error
+
+	tests := []struct {
+		name       string
+		attributes attributes.Attributes
+		want       *Availability
+		wantErr    bool
+	}{
+		{
+			name:       "No availability attribute",
+			attributes: attributes.Attributes{}.PutInteger(ReplicaKey, 1),
+		},
+		{
+			name: "Disruption budget and scheduling constraints",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{AvailabilityKey: Availability{
+				MaxUnavailable: &maxUnavailable,
+				TopologySpread: []TopologyDomain{ZoneTopologyDomain},
+				AntiAffinity:   PreferredAntiAffinity,
+			}}, &err),
+			want: &Availability{
+				MaxUnavailable: &maxUnavailable,
+				TopologySpread: []TopologyDomain{ZoneTopologyDomain},
+				AntiAffinity:   PreferredAntiAffinity,
+			},
+		},
+		{
+			name:       "Both disruption budget bounds",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{AvailabilityKey: Availability{MinAvailable: &minAvailable, MaxUnavailable: &maxUnavailable}}, &err),
+			wantErr:    true,
+		},
+		{
+			name:       "Unsupported topology domain",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{AvailabilityKey: Availability{TopologySpread: []TopologyDomain{"region"}}}, &err),
+			wantErr:    true,
+		},
+		{
+			name:       "Unsupported anti-affinity",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{AvailabilityKey: Availability{AntiAffinity: "always"}}, &err),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			availability, err := GetAvailabilityFromAttributes(tt.attributes)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, availability, "availability configuration did not match")
+			}
+		})
+	}
+}
+
+func TestAvailabilityMerge(t *testing.T) {
+	minAvailable := intstr.FromInt(1)
+	maxUnavailable := intstr.FromString("25%")
+
+	base := Availability{
+		MinAvailable:   &minAvailable,
+		TopologySpread: []TopologyDomain{ZoneTopologyDomain},
+	}
+
+	merged := base.Merge(Availability{MaxUnavailable: &maxUnavailable, AntiAffinity: RequiredAntiAffinity})
+	assert.Equal(t, Availability{
+		MaxUnavailable: &maxUnavailable,
+		TopologySpread: []TopologyDomain{ZoneTopologyDomain},
+		AntiAffinity:   RequiredAntiAffinity,
+	}, merged)
+	assert.NoError(t, merged.Validate())
+
+	merged = base.Merge(Availability{TopologySpread: []TopologyDomain{}})
+	assert.Equal(t, &minAvailable, merged.MinAvailable)
+	assert.False(t, merged.HasSchedulingConstraints(), "an empty topology spread override should clear the base spread")
+}
+
+func TestApplyAvailability(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/instance": "component-sample"}}
+
+	tests := []struct {
+		name         string
+		availability Availability
+		podSpec      corev1.PodSpec
+		want         corev1.PodSpec
+	}{
+		{
+			name:         "Spread across zones and hosts",
+			availability: Availability{TopologySpread: []TopologyDomain{ZoneTopologyDomain, HostTopologyDomain}},
+			want: corev1.PodSpec{
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+					{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: selector},
+					{MaxSkew: 1, TopologyKey: "kubernetes.io/hostname", WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: selector},
+				},
+			},
+		},
+		{
+			name:         "Required anti-affinity keeps the node affinity",
+			availability: Availability{AntiAffinity: RequiredAntiAffinity},
+			podSpec:      corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}},
+			want: corev1.PodSpec{
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{},
+					PodAntiAffinity: &corev1.PodAntiAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+							{LabelSelector: selector, TopologyKey: "kubernetes.io/hostname"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:         "Preferred anti-affinity",
+			availability: Availability{AntiAffinity: PreferredAntiAffinity},
+			want: corev1.PodSpec{
+				Affinity: &corev1.Affinity{
+					PodAntiAffinity: &corev1.PodAntiAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+							{Weight: 100, PodAffinityTerm: corev1.PodAffinityTerm{LabelSelector: selector, TopologyKey: "kubernetes.io/hostname"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podSpec := tt.podSpec
+			ApplyAvailability(&podSpec, "component-sample", tt.availability)
+			assert.Equal(t, tt.want, podSpec)
+		})
+	}
+}
+
+func TestGetResourceFromDevfileWithAvailability(t *testing.T) {
+	availabilityDevfile := `
+components:
+- attributes:
+    deployment/replicas: 3
+    deployment/workload:
+      kind: %s
+    deployment/availability:
+      minAvailable: 2
+      topologySpread:
+      - zone
+      antiAffinity: preferred
+  kubernetes:
+    deployByDefault: false
+    inlined: |-
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: deploy-sample
+      spec:
+        template:
+          spec:
+            containers:
+            - image: quay.io/redhat-appstudio/user-workload:application-service-system-component-sample
+              name: app
+  name: kubernetes-deploy
+metadata:
+  name: java-springboot
+schemaVersion: 2.2.0`
+
+	minAvailable := intstr.FromInt(2)
+	wantPDB := policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "component-sample",
+			Labels: generateK8sLabels("component-sample", "application-sample"),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: getMatchLabel("component-sample"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		workloadKind WorkloadKind
+		wantErr      bool
+	}{
+		{
+			name:         "Deployment with a pod disruption budget",
+			workloadKind: DeploymentWorkloadKind,
+		},
+		{
+			name:         "StatefulSet with a pod disruption budget",
+			workloadKind: StatefulSetWorkloadKind,
+		},
+		{
+			name:         "Availability is not supported for Jobs",
+			workloadKind: JobWorkloadKind,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileData, err := ParseDevfile(DevfileSrc{Data: fmt.Sprintf(availabilityDevfile, tt.workloadKind)})
+			if err != nil {
+				t.Errorf("TestGetResourceFromDevfileWithAvailability() unexpected parse error: %v", err)
+			}
+			deployAssociatedComponents, err := parser.GetDeployComponents(devfileData)
+			if err != nil {
+				t.Errorf("TestGetResourceFromDevfileWithAvailability() unexpected get deploy components error: %v", err)
+			}
+			logger := ctrl.Log.WithName("TestGetResourceFromDevfileWithAvailability")
+
+			actualResources, err := GetResourceFromDevfile(logger, devfileData, deployAssociatedComponents, "component-sample", "application-sample", "image1", "")
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("TestGetResourceFromDevfileWithAvailability() unexpected error: %v", err)
+			} else if err == nil {
+				var actualPDB *policyv1.PodDisruptionBudget
+				for _, other := range actualResources.Others {
+					if pdb, ok := other.(policyv1.PodDisruptionBudget); ok {
+						actualPDB = &pdb
+					}
+				}
+				assert.Equal(t, &wantPDB, actualPDB, "PodDisruptionBudget did not match")
+
+				var podSpec corev1.PodSpec
+				if tt.workloadKind == DeploymentWorkloadKind {
+					podSpec = actualResources.Deployments[0].Spec.Template.Spec
+				} else {
+					statefulSet, ok := GetWorkload(actualResources, StatefulSetWorkloadKind).(*appsv1.StatefulSet)
+					if !assert.True(t, ok, "expected a StatefulSet") {
+						return
+					}
+					podSpec = statefulSet.Spec.Template.Spec
+				}
+				if assert.Len(t, podSpec.TopologySpreadConstraints, 1) {
+					assert.Equal(t, "topology.kubernetes.io/zone", podSpec.TopologySpreadConstraints[0].TopologyKey)
+				}
+				if assert.NotNil(t, podSpec.Affinity) && assert.NotNil(t, podSpec.Affinity.PodAntiAffinity) {
+					assert.Len(t, podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 1)
+				}
+			}
+		})
+	}
+}
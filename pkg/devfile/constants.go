@@ -45,4 +45,26 @@ const (
 
 	// ContainerENVKey is the key to reference container environment variables
 	ContainerENVKey = "deployment/containerENV"
+
+	// AutoscalingKey is the key to reference the horizontal pod autoscaler configuration
+	AutoscalingKey = "deployment/autoscaling"
+
+	// ProbesKey is the key to reference the container liveness, readiness and startup probes
+	ProbesKey = "deployment/probes"
+
+	// ContainersKey is the key to reference the main container name, the sidecars and the init containers
+	ContainersKey = "deployment/containers"
+
+	// WorkloadKey is the key to reference the workload kind, Deployment, StatefulSet, Job or CronJob, and the CronJob schedule
+	WorkloadKey = "deployment/workload"
+
+	// AvailabilityKey is the key to reference the pod disruption budget, topology spread and anti-affinity configuration
+	AvailabilityKey = "deployment/availability"
+
+	// NetworkPolicyKey is the key to reference the network policy configuration, the sibling Components that the Component sends traffic to
+	NetworkPolicyKey = "deployment/networkPolicy"
+
+	// AnnotationAttributesKey is the key to reference the keys of the attributes copied from the annotations of the Component,
+	// which are removed with their annotation
+	AnnotationAttributesKey = "deployment/annotationAttributes"
 )
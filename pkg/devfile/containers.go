@@ -0,0 +1,207 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"fmt"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Container describes a sidecar or an init container of a Component
+type Container struct {
+	// Name is the name of the container, a container with the same name in the devfile Deployment is updated in place
+	Name string `json:"name"`
+
+	// Image is the container image, required unless the container is already declared in the devfile Deployment
+	Image string `json:"image,omitempty"`
+
+	Command   []string                    `json:"command,omitempty"`
+	Args      []string                    `json:"args,omitempty"`
+	Env       []corev1.EnvVar             `json:"env,omitempty"`
+	Ports     []corev1.ContainerPort      `json:"ports,omitempty"`
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// PodContainers describes the containers of a Component besides its application container
+type PodContainers struct {
+	// Main is the name of the application container that the Component image, port, env and resources are applied to.
+	// Defaults to the first container of the Deployment that is not a sidecar
+	Main string `json:"main,omitempty"`
+
+	// Sidecars are the containers running alongside the application container
+	Sidecars []Container `json:"sidecars,omitempty"`
+
+	// InitContainers are the containers run to completion before the application container starts
+	InitContainers []Container `json:"initContainers,omitempty"`
+}
+
+// Validate checks that the containers are named uniquely and do not clash with the application container
+func (p PodContainers) Validate() error {
+	names := make(map[string]bool)
+	for _, container := range append(append([]Container{}, p.Sidecars...), p.InitContainers...) {
+		if errs := validation.IsDNS1123Label(container.Name); len(errs) > 0 {
+			return fmt.Errorf("container name %q is invalid: %v", container.Name, errs)
+		}
+		if names[container.Name] {
+			return fmt.Errorf("container name %q is declared more than once", container.Name)
+		}
+		if container.Name == p.Main {
+			return fmt.Errorf("container name %q is already used by the main container", container.Name)
+		}
+		names[container.Name] = true
+	}
+	return nil
+}
+
+// GetPodContainersFromAttributes returns the containers configuration stored in the devfile component attributes.
+// nil is returned if the component does not have a containers configuration
+func GetPodContainersFromAttributes(componentAttributes attributes.Attributes) (*PodContainers, error) {
+	var podContainers PodContainers
+	err := componentAttributes.GetInto(ContainersKey, &podContainers)
+	if err != nil {
+		if _, ok := err.(*attributes.KeyNotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := podContainers.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &podContainers, nil
+}
+
+// ApplyPodContainers adds the sidecars and init containers to the pod spec.
+// Containers that already exist in the pod spec are updated with the fields that are set, so sidecars declared in the devfile are kept
+func ApplyPodContainers(podSpec *corev1.PodSpec, podContainers PodContainers) error {
+	var err error
+	if podSpec.Containers, err = mergeContainers(podSpec.Containers, podContainers.Sidecars); err != nil {
+		return err
+	}
+	if podSpec.InitContainers, err = mergeContainers(podSpec.InitContainers, podContainers.InitContainers); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetMainContainerIndex returns the index of the application container in the pod containers
+func GetMainContainerIndex(containers []corev1.Container, podContainers *PodContainers) (int, error) {
+	if podContainers == nil {
+		return 0, nil
+	}
+
+	if podContainers.Main != "" {
+		for i, container := range containers {
+			if container.Name == podContainers.Main {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("the main container %q is not declared in the Deployment", podContainers.Main)
+	}
+
+	sidecars := make(map[string]bool)
+	for _, sidecar := range podContainers.Sidecars {
+		sidecars[sidecar.Name] = true
+	}
+	for i, container := range containers {
+		if !sidecars[container.Name] {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("the Deployment does not have a container besides its sidecars")
+}
+
+func mergeContainers(containers []corev1.Container, declared []Container) ([]corev1.Container, error) {
+	for _, declaredContainer := range declared {
+		isPresent := false
+		for i := range containers {
+			if containers[i].Name == declaredContainer.Name {
+				isPresent = true
+				updateContainer(&containers[i], declaredContainer)
+				break
+			}
+		}
+
+		if !isPresent {
+			if declaredContainer.Image == "" {
+				return nil, fmt.Errorf("an image is required for the container %q", declaredContainer.Name)
+			}
+			container := corev1.Container{
+				Name:            declaredContainer.Name,
+				ImagePullPolicy: corev1.PullAlways,
+			}
+			updateContainer(&container, declaredContainer)
+			containers = append(containers, container)
+		}
+	}
+	return containers, nil
+}
+
+// updateContainer sets the fields of the declared container on the container, env vars and ports are merged by name and port
+func updateContainer(container *corev1.Container, declared Container) {
+	if declared.Image != "" {
+		container.Image = declared.Image
+	}
+	if len(declared.Command) > 0 {
+		container.Command = declared.Command
+	}
+	if len(declared.Args) > 0 {
+		container.Args = declared.Args
+	}
+
+	for _, env := range declared.Env {
+		isPresent := false
+		for i := range container.Env {
+			if container.Env[i].Name == env.Name {
+				isPresent = true
+				container.Env[i] = env
+			}
+		}
+		if !isPresent {
+			container.Env = append(container.Env, env)
+		}
+	}
+
+	for _, port := range declared.Ports {
+		isPresent := false
+		for _, containerPort := range container.Ports {
+			if containerPort.ContainerPort == port.ContainerPort {
+				isPresent = true
+				break
+			}
+		}
+		if !isPresent {
+			container.Ports = append(container.Ports, port)
+		}
+	}
+
+	for resourceName, quantity := range declared.Resources.Limits {
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = make(corev1.ResourceList)
+		}
+		container.Resources.Limits[resourceName] = quantity
+	}
+	for resourceName, quantity := range declared.Resources.Requests {
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = make(corev1.ResourceList)
+		}
+		container.Resources.Requests[resourceName] = quantity
+	}
+}
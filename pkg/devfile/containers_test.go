@@ -0,0 +1,243 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	parser "github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGetPodContainersFromAttributes(t *testing.T) {
+	var err error
+	podContainers := PodContainers{
+		Main: "app",
+		Sidecars: []Container{
+			{Name: "log-shipper", Image: "quay.io/org/log-shipper:1.0"},
+		},
+		InitContainers: []Container{
+			{Name: "migrate", Image: "quay.io/org/migrate:1.0", Command: []string{"./migrate"}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		attributes attributes.Attributes
+		want       *PodContainers
+		wantErr    bool
+	}{
+		{
+			name:       "No containers attribute",
+			attributes: attributes.Attributes{}.PutInteger(ReplicaKey, 1),
+		},
+		{
+			name:       "Valid containers attribute",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{ContainersKey: podContainers}, &err),
+			want:       &podContainers,
+		},
+		{
+			name: "Duplicate container names",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{ContainersKey: PodContainers{
+				Sidecars:       []Container{{Name: "proxy", Image: "proxy"}},
+				InitContainers: []Container{{Name: "proxy", Image: "proxy"}},
+			}}, &err),
+			wantErr: true,
+		},
+		{
+			name: "Invalid container name",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{ContainersKey: PodContainers{
+				Sidecars: []Container{{Name: "Log_Shipper", Image: "log-shipper"}},
+			}}, &err),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podContainers, err := GetPodContainersFromAttributes(tt.attributes)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, podContainers, "containers configuration did not match")
+			}
+		})
+	}
+}
+
+func TestGetMainContainerIndex(t *testing.T) {
+	containers := []corev1.Container{{Name: "proxy"}, {Name: "app"}, {Name: "log-shipper"}}
+
+	tests := []struct {
+		name          string
+		podContainers *PodContainers
+		want          int
+		wantErr       bool
+	}{
+		{
+			name: "No containers configuration",
+			want: 0,
+		},
+		{
+			name:          "Explicit main container",
+			podContainers: &PodContainers{Main: "log-shipper"},
+			want:          2,
+		},
+		{
+			name:          "First container that is not a sidecar",
+			podContainers: &PodContainers{Sidecars: []Container{{Name: "proxy"}}},
+			want:          1,
+		},
+		{
+			name:          "Main container not in the Deployment",
+			podContainers: &PodContainers{Main: "missing"},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := GetMainContainerIndex(containers, tt.podContainers)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, index, "main container index did not match")
+			}
+		})
+	}
+}
+
+func TestApplyPodContainers(t *testing.T) {
+	tests := []struct {
+		name               string
+		podContainers      PodContainers
+		wantContainers     []corev1.Container
+		wantInitContainers []corev1.Container
+		wantErr            bool
+	}{
+		{
+			name: "Add a sidecar and an init container, update an existing sidecar",
+			podContainers: PodContainers{
+				Sidecars: []Container{
+					{Name: "proxy", Image: "quay.io/org/proxy:2.0", Env: []corev1.EnvVar{{Name: "UPSTREAM", Value: "localhost:8080"}}},
+					{Name: "log-shipper", Image: "quay.io/org/log-shipper:1.0", Ports: []corev1.ContainerPort{{ContainerPort: 24224}},
+						Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")}}},
+				},
+				InitContainers: []Container{
+					{Name: "migrate", Image: "quay.io/org/migrate:1.0", Args: []string{"--up"}},
+				},
+			},
+			wantContainers: []corev1.Container{
+				{Name: "app", Image: "app"},
+				{Name: "proxy", Image: "quay.io/org/proxy:2.0", Env: []corev1.EnvVar{{Name: "UPSTREAM", Value: "localhost:8080"}}},
+				{Name: "log-shipper", Image: "quay.io/org/log-shipper:1.0", ImagePullPolicy: corev1.PullAlways, Ports: []corev1.ContainerPort{{ContainerPort: 24224}},
+					Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")}}},
+			},
+			wantInitContainers: []corev1.Container{
+				{Name: "migrate", Image: "quay.io/org/migrate:1.0", ImagePullPolicy: corev1.PullAlways, Args: []string{"--up"}},
+			},
+		},
+		{
+			name: "New sidecar without an image",
+			podContainers: PodContainers{
+				Sidecars: []Container{{Name: "log-shipper"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podSpec := corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: "app"}, {Name: "proxy", Image: "quay.io/org/proxy:1.0"}},
+			}
+			err := ApplyPodContainers(&podSpec, tt.podContainers)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.wantContainers, podSpec.Containers, "containers did not match")
+				assert.Equal(t, tt.wantInitContainers, podSpec.InitContainers, "init containers did not match")
+			}
+		})
+	}
+}
+
+func TestGetResourceFromDevfileWithSidecars(t *testing.T) {
+	sidecarDevfile := `
+components:
+- attributes:
+    deployment/container-port: 8080
+    deployment/containers:
+      main: app
+      sidecars:
+      - name: log-shipper
+        image: quay.io/org/log-shipper:1.0
+  kubernetes:
+    deployByDefault: false
+    inlined: |-
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: deploy-sample
+      spec:
+        template:
+          spec:
+            containers:
+            - image: quay.io/org/proxy:1.0
+              name: proxy
+            - image: quay.io/redhat-appstudio/user-workload:application-service-system-component-sample
+              name: app
+  name: kubernetes-deploy
+metadata:
+  name: java-springboot
+schemaVersion: 2.2.0`
+
+	devfileData, err := ParseDevfile(DevfileSrc{Data: sidecarDevfile})
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	deployAssociatedComponents, err := parser.GetDeployComponents(devfileData)
+	if err != nil {
+		t.Fatalf("unexpected get deploy components error: %v", err)
+	}
+
+	logger := ctrl.Log.WithName("TestGetResourceFromDevfileWithSidecars")
+	actualResources, err := GetResourceFromDevfile(logger, devfileData, deployAssociatedComponents, "component-sample", "application-sample", "image1", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	containers := actualResources.Deployments[0].Spec.Template.Spec.Containers
+	if assert.Len(t, containers, 3) {
+		// the application container is moved first and gets the Component image and port, the sidecars are kept as is
+		assert.Equal(t, "app", containers[0].Name)
+		assert.Equal(t, "image1", containers[0].Image)
+		assert.Equal(t, []corev1.ContainerPort{{ContainerPort: 8080}}, containers[0].Ports)
+		assert.Equal(t, corev1.Container{Name: "proxy", Image: "quay.io/org/proxy:1.0"}, containers[1])
+		assert.Equal(t, "log-shipper", containers[2].Name)
+		assert.Equal(t, "quay.io/org/log-shipper:1.0", containers[2].Image)
+	}
+}
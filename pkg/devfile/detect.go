@@ -262,6 +262,44 @@ func AnalyzePath(log logr.Logger, a Alizer, localpath, context, devfileRegistryU
 	return nil
 }
 
+// IsMultiComponent returns true if the components of the local path must be searched for in its sub-folders, this is a helper func used by
+// the CDQ controller and hasctl once they looked for a devfile and a Dockerfile at the root of the path. The sub-folders are searched if neither
+// was found at the root, and Alizer detects no component at the root either
+func IsMultiComponent(log logr.Logger, a Alizer, localpath string, isDevfilePresent, isDockerfilePresent bool) (bool, error) {
+	if isDevfilePresent || isDockerfilePresent {
+		return false, nil
+	}
+	log.Info(fmt.Sprintf("Unable to find devfile, Dockerfile or Containerfile under root directory, run Alizer to detect components of %s", localpath))
+	components, err := a.DetectComponents(localpath)
+	if err != nil {
+		return false, err
+	}
+	log.Info(fmt.Sprintf("components detected %v", components))
+	// case 1: no components been detected by Alizer, might still has subfolders contains Dockerfile or Containerfile. Need to scan repo
+	// case 2: one or more than 1 compinents been detected by Alizer, and the first one in the list is under sub-folder. Need to scan repo.
+	return len(components) == 0 || path.Clean(components[0].Path) != path.Clean(localpath), nil
+}
+
+// DetectLocalComponents detects the components of the local path for the given context, this is a helper func used by the CDQ controller and hasctl.
+// The sub-folders of the path are scanned with ScanRepo if isMultiComponent is true, and the maps returned by ScanRepo are returned. Otherwise the path is
+// analyzed with AnalyzePath, and the given maps are updated and returned
+func DetectLocalComponents(log logr.Logger, a Alizer, localpath, context, devfileRegistryURL string, source appstudiov1alpha1.GitSource, devfileMapFromRepo map[string][]byte, devfilesURLMapFromRepo, dockerfileContextMapFromRepo map[string]string, componentPortsMapFromRepo map[string][]int, isMultiComponent, isDevfilePresent, isDockerfilePresent bool) (map[string][]byte, map[string]string, map[string]string, map[string][]int, error) {
+	if !isMultiComponent {
+		log.Info(fmt.Sprintf("Since this is not a multi-component, attempt will be made to read devfile at the root dir %s", localpath))
+		err := AnalyzePath(log, a, localpath, context, devfileRegistryURL, devfileMapFromRepo, devfilesURLMapFromRepo, dockerfileContextMapFromRepo, componentPortsMapFromRepo, isDevfilePresent, isDockerfilePresent)
+		return devfileMapFromRepo, devfilesURLMapFromRepo, dockerfileContextMapFromRepo, componentPortsMapFromRepo, err
+	}
+
+	log.Info(fmt.Sprintf("Since this is a multi-component, attempt will be made to read only level 1 dir for devfiles of %s", localpath))
+	devfileMapFromRepo, devfilesURLMapFromRepo, dockerfileContextMapFromRepo, componentPortsMapFromRepo, err := ScanRepo(log, a, localpath, devfileRegistryURL, source)
+	if err != nil {
+		if _, ok := err.(*NoDevfileFound); !ok {
+			return nil, nil, nil, nil, err
+		}
+	}
+	return devfileMapFromRepo, devfilesURLMapFromRepo, dockerfileContextMapFromRepo, componentPortsMapFromRepo, nil
+}
+
 // SearchForDockerfile searches for a Dockerfile from a devfile image component.
 // If no Dockerfile is found, nil will be returned.
 func SearchForDockerfile(devfileBytes []byte) (*v1alpha2.DockerfileImage, error) {
@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/go-logr/logr"
 	"github.com/redhat-appstudio/application-service/pkg/util"
 	"github.com/redhat-developer/alizer/go/pkg/apis/model"
 )
@@ -259,3 +260,53 @@ components:
 		})
 	}
 }
+
+func TestIsMultiComponent(t *testing.T) {
+
+	tests := []struct {
+		name                string
+		localpath           string
+		isDevfilePresent    bool
+		isDockerfilePresent bool
+		want                bool
+		wantErr             bool
+	}{
+		{
+			name:             "Devfile at the root",
+			localpath:        "/tmp/empty",
+			isDevfilePresent: true,
+		},
+		{
+			name:                "Dockerfile at the root",
+			localpath:           "/tmp/empty",
+			isDockerfilePresent: true,
+		},
+		{
+			name:      "Component detected at the root",
+			localpath: "/tmp/devfile-sample-nodejs-basic",
+		},
+		{
+			name:      "No component detected at the root",
+			localpath: "/tmp/empty",
+			want:      true,
+		},
+		{
+			name:      "Alizer error",
+			localpath: "/tmp/errorAnalyze",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isMultiComponent, err := IsMultiComponent(logr.Discard(), MockAlizerClient{}, tt.localpath, tt.isDevfilePresent, tt.isDockerfilePresent)
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected err: %+v", err)
+			} else if tt.wantErr && err == nil {
+				t.Errorf("Expected error but got nil")
+			} else if isMultiComponent != tt.want {
+				t.Errorf("Expected multi-component %v, but got %v", tt.want, isMultiComponent)
+			}
+		})
+	}
+}
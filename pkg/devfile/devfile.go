@@ -159,23 +159,86 @@ func GetResourceFromDevfile(log logr.Logger, devfileData data.DevfileData, deplo
 					}
 				}
 
-				if len(resources.Deployments) > 0 {
-					// update for replica
-					currentReplica := int32(component.Attributes.GetNumber(ReplicaKey, &err))
+				// update for replica
+				currentReplica := int32(component.Attributes.GetNumber(ReplicaKey, &err))
+				if err != nil {
+					if _, ok := err.(*attributes.KeyNotFoundError); !ok {
+						return parser.KubernetesResources{}, err
+					}
+				}
+
+				// Set the RevisionHistoryLimit for all Deployments to 0, if it's unset
+				// If set, leave it alone
+				for i := range resources.Deployments {
+					if resources.Deployments[i].Spec.RevisionHistoryLimit == nil {
+						resources.Deployments[i].Spec.RevisionHistoryLimit = &util.RevisionHistoryLimit
+					}
+				}
+
+				var workload Workload
+				workload, err = GetWorkloadFromAttributes(component.Attributes)
+				if err != nil {
+					return parser.KubernetesResources{}, err
+				}
+
+				if workload.Kind != DeploymentWorkloadKind {
+					// the component runs as a StatefulSet, Job or CronJob, converted from the devfile Deployment if the devfile does not define one
+					typedWorkload, err := extractWorkload(&resources, workload, GenerateDeploymentTemplate(compName, appName, image))
 					if err != nil {
-						if _, ok := err.(*attributes.KeyNotFoundError); !ok {
-							return parser.KubernetesResources{}, err
+						return parser.KubernetesResources{}, err
+					}
+					updateWorkload(typedWorkload, workload, compName, k8sLabels, matchLabels, currentReplica)
+					if err := updatePodTemplate(component, GetWorkloadPodTemplate(typedWorkload), image, currentPort, currentENV); err != nil {
+						return parser.KubernetesResources{}, err
+					}
+
+					autoscaling, err := GetAutoscalingFromAttributes(component.Attributes)
+					if err != nil {
+						return parser.KubernetesResources{}, err
+					}
+					if autoscaling != nil {
+						switch workload.Kind {
+						case StatefulSetWorkloadKind:
+							resources.Others = append(resources.Others, GenerateHorizontalPodAutoscaler(compName, k8sLabels, *autoscaling, workload.Kind))
+						case KnativeServiceWorkloadKind:
+							// Knative Services are autoscaled by Knative, configured through the revision template annotations
+							knativeAnnotations, err := GetKnativeAutoscalingAnnotations(*autoscaling)
+							if err != nil {
+								return parser.KubernetesResources{}, err
+							}
+							podTemplate := GetWorkloadPodTemplate(typedWorkload)
+							if podTemplate.ObjectMeta.Annotations == nil {
+								podTemplate.ObjectMeta.Annotations = make(map[string]string)
+							}
+							maps.Copy(podTemplate.ObjectMeta.Annotations, knativeAnnotations)
+						default:
+							return parser.KubernetesResources{}, fmt.Errorf("autoscaling is not supported for %s workloads", workload.Kind)
 						}
 					}
 
-					// Set the RevisionHistoryLimit for all Deployments to 0, if it's unset
-					// If set, leave it alone
-					for i := range resources.Deployments {
-						if resources.Deployments[i].Spec.RevisionHistoryLimit == nil {
-							resources.Deployments[i].Spec.RevisionHistoryLimit = &util.RevisionHistoryLimit
+					availability, err := GetAvailabilityFromAttributes(component.Attributes)
+					if err != nil {
+						return parser.KubernetesResources{}, err
+					}
+					if availability != nil {
+						if workload.Kind != StatefulSetWorkloadKind {
+							return parser.KubernetesResources{}, fmt.Errorf("availability is not supported for %s workloads", workload.Kind)
+						}
+						ApplyAvailability(&GetWorkloadPodTemplate(typedWorkload).Spec, compName, *availability)
+						if availability.HasDisruptionBudget() {
+							resources.Others = append(resources.Others, GeneratePodDisruptionBudget(compName, k8sLabels, *availability))
 						}
 					}
 
+					if workload.Kind == KnativeServiceWorkloadKind {
+						// a Knative Service routes the traffic to its revisions, a separate Service, Route or Ingress is not generated
+						resources.Services = nil
+						resources.Routes = nil
+						resources.Ingresses = nil
+					}
+
+					resources.Others = append(resources.Others, dereferenceWorkload(typedWorkload))
+				} else if len(resources.Deployments) > 0 {
 					// replace the deployment metadata.name to use the component name
 					resources.Deployments[0].ObjectMeta.Name = compName
 
@@ -206,156 +269,31 @@ func GetResourceFromDevfile(log logr.Logger, devfileData data.DevfileData, deplo
 						resources.Deployments[0].Spec.Replicas = &currentReplica
 					}
 
-					if len(resources.Deployments[0].Spec.Template.Spec.Containers) > 0 {
-						if image != "" {
-							resources.Deployments[0].Spec.Template.Spec.Containers[0].Image = image
-						}
-
-						if currentPort > 0 {
-							containerPort := corev1.ContainerPort{
-								ContainerPort: int32(currentPort),
-							}
-
-							isPresent := false
-							for _, port := range resources.Deployments[0].Spec.Template.Spec.Containers[0].Ports {
-								if port.ContainerPort == containerPort.ContainerPort {
-									isPresent = true
-									break
-								}
-							}
-
-							if !isPresent {
-								resources.Deployments[0].Spec.Template.Spec.Containers[0].Ports = append(resources.Deployments[0].Spec.Template.Spec.Containers[0].Ports, containerPort)
-							}
-
-							if resources.Deployments[0].Spec.Template.Spec.Containers[0].ReadinessProbe != nil && resources.Deployments[0].Spec.Template.Spec.Containers[0].ReadinessProbe.ProbeHandler.TCPSocket != nil {
-								resources.Deployments[0].Spec.Template.Spec.Containers[0].ReadinessProbe.ProbeHandler.TCPSocket.Port.IntVal = int32(currentPort)
-							}
-
-							if resources.Deployments[0].Spec.Template.Spec.Containers[0].LivenessProbe != nil && resources.Deployments[0].Spec.Template.Spec.Containers[0].LivenessProbe.ProbeHandler.HTTPGet != nil {
-								resources.Deployments[0].Spec.Template.Spec.Containers[0].LivenessProbe.ProbeHandler.HTTPGet.Port.IntVal = int32(currentPort)
-							}
-						}
-
-						for _, devfileEnv := range currentENV {
-							isPresent := false
-							for i, containerEnv := range resources.Deployments[0].Spec.Template.Spec.Containers[0].Env {
-								if containerEnv.Name == devfileEnv.Name {
-									isPresent = true
-									resources.Deployments[0].Spec.Template.Spec.Containers[0].Env[i].Value = devfileEnv.Value
-								}
-							}
-
-							if !isPresent {
-								resources.Deployments[0].Spec.Template.Spec.Containers[0].Env = append(resources.Deployments[0].Spec.Template.Spec.Containers[0].Env, devfileEnv)
-							}
-						}
-
-						// Update for limits
-						cpuLimit := component.Attributes.GetString(CpuLimitKey, &err)
-						if err != nil {
-							if _, ok := err.(*attributes.KeyNotFoundError); !ok {
-								return parser.KubernetesResources{}, err
-							}
-						}
-
-						memoryLimit := component.Attributes.GetString(MemoryLimitKey, &err)
-						if err != nil {
-							if _, ok := err.(*attributes.KeyNotFoundError); !ok {
-								return parser.KubernetesResources{}, err
-							}
-						}
-
-						storageLimit := component.Attributes.GetString(StorageLimitKey, &err)
-						if err != nil {
-							if _, ok := err.(*attributes.KeyNotFoundError); !ok {
-								return parser.KubernetesResources{}, err
-							}
-						}
-
-						containerLimits := resources.Deployments[0].Spec.Template.Spec.Containers[0].Resources.Limits
-						if len(containerLimits) == 0 {
-							containerLimits = make(corev1.ResourceList)
-						}
-
-						if cpuLimit != "" && cpuLimit != "0" {
-							cpuLimitQuantity, err := resource.ParseQuantity(cpuLimit)
-							if err != nil {
-								return parser.KubernetesResources{}, err
-							}
-							containerLimits[corev1.ResourceCPU] = cpuLimitQuantity
-						}
-
-						if memoryLimit != "" && memoryLimit != "0" {
-							memoryLimitQuantity, err := resource.ParseQuantity(memoryLimit)
-							if err != nil {
-								return parser.KubernetesResources{}, err
-							}
-							containerLimits[corev1.ResourceMemory] = memoryLimitQuantity
-						}
-
-						if storageLimit != "" && storageLimit != "0" {
-							storageLimitQuantity, err := resource.ParseQuantity(storageLimit)
-							if err != nil {
-								return parser.KubernetesResources{}, err
-							}
-							containerLimits[corev1.ResourceStorage] = storageLimitQuantity
-						}
-
-						resources.Deployments[0].Spec.Template.Spec.Containers[0].Resources.Limits = containerLimits
-
-						// Update for requests
-						cpuRequest := component.Attributes.GetString(CpuRequestKey, &err)
-						if err != nil {
-							if _, ok := err.(*attributes.KeyNotFoundError); !ok {
-								return parser.KubernetesResources{}, err
-							}
-						}
-
-						memoryRequest := component.Attributes.GetString(MemoryRequestKey, &err)
-						if err != nil {
-							if _, ok := err.(*attributes.KeyNotFoundError); !ok {
-								return parser.KubernetesResources{}, err
-							}
-						}
-
-						storageRequest := component.Attributes.GetString(StorageRequestKey, &err)
-						if err != nil {
-							if _, ok := err.(*attributes.KeyNotFoundError); !ok {
-								return parser.KubernetesResources{}, err
-							}
-						}
-
-						containerRequests := resources.Deployments[0].Spec.Template.Spec.Containers[0].Resources.Requests
-						if len(containerRequests) == 0 {
-							containerRequests = make(corev1.ResourceList)
-						}
-
-						if cpuRequest != "" && cpuRequest != "0" {
-							cpuRequestQuantity, err := resource.ParseQuantity(cpuRequest)
-							if err != nil {
-								return parser.KubernetesResources{}, err
-							}
-							containerRequests[corev1.ResourceCPU] = cpuRequestQuantity
-						}
+					// generate a horizontal pod autoscaler for the deployment if the component declares autoscaling
+					var autoscaling *Autoscaling
+					autoscaling, err = GetAutoscalingFromAttributes(component.Attributes)
+					if err != nil {
+						return parser.KubernetesResources{}, err
+					}
+					if autoscaling != nil {
+						resources.Others = append(resources.Others, GenerateHorizontalPodAutoscaler(compName, k8sLabels, *autoscaling, DeploymentWorkloadKind))
+					}
 
-						if memoryRequest != "" && memoryRequest != "0" {
-							memoryRequestQuantity, err := resource.ParseQuantity(memoryRequest)
-							if err != nil {
-								return parser.KubernetesResources{}, err
-							}
-							containerRequests[corev1.ResourceMemory] = memoryRequestQuantity
-						}
+					if err := updatePodTemplate(component, &resources.Deployments[0].Spec.Template, image, currentPort, currentENV); err != nil {
+						return parser.KubernetesResources{}, err
+					}
 
-						if storageRequest != "" && storageRequest != "0" {
-							storageRequestQuantity, err := resource.ParseQuantity(storageRequest)
-							if err != nil {
-								return parser.KubernetesResources{}, err
-							}
-							containerRequests[corev1.ResourceStorage] = storageRequestQuantity
+					// spread the pods and generate a pod disruption budget if the component declares its availability
+					var availability *Availability
+					availability, err = GetAvailabilityFromAttributes(component.Attributes)
+					if err != nil {
+						return parser.KubernetesResources{}, err
+					}
+					if availability != nil {
+						ApplyAvailability(&resources.Deployments[0].Spec.Template.Spec, compName, *availability)
+						if availability.HasDisruptionBudget() {
+							resources.Others = append(resources.Others, GeneratePodDisruptionBudget(compName, k8sLabels, *availability))
 						}
-
-						resources.Deployments[0].Spec.Template.Spec.Containers[0].Resources.Requests = containerRequests
 					}
 				}
 
@@ -466,6 +404,202 @@ func GetResourceFromDevfile(log logr.Logger, devfileData data.DevfileData, deplo
 	return appendedResources, err
 }
 
+// updatePodTemplate applies the sidecars, image, port, probes, env and resources of the devfile kubernetes component to the workload pod template
+func updatePodTemplate(component v1alpha2.Component, podTemplate *corev1.PodTemplateSpec, image string, currentPort int, currentENV []corev1.EnvVar) error {
+	var err error
+	// Add the sidecars and init containers and find the application container
+	var podContainers *PodContainers
+	podContainers, err = GetPodContainersFromAttributes(component.Attributes)
+	if err != nil {
+		return err
+	}
+	if podContainers != nil {
+		if err := ApplyPodContainers(&podTemplate.Spec, *podContainers); err != nil {
+			return err
+		}
+	}
+
+	if len(podTemplate.Spec.Containers) > 0 {
+		mainContainerIndex, err := GetMainContainerIndex(podTemplate.Spec.Containers, podContainers)
+		if err != nil {
+			return err
+		}
+		// the gitops generator library patches the first container of the Deployment in the environment overlays,
+		// so move the application container to the front of the list
+		containers := podTemplate.Spec.Containers
+		if mainContainerIndex > 0 {
+			mainContainerSpec := containers[mainContainerIndex]
+			copy(containers[1:mainContainerIndex+1], containers[0:mainContainerIndex])
+			containers[0] = mainContainerSpec
+		}
+		mainContainer := &containers[0]
+
+		if image != "" {
+			mainContainer.Image = image
+		}
+
+		if currentPort > 0 {
+			containerPort := corev1.ContainerPort{
+				ContainerPort: int32(currentPort),
+			}
+
+			isPresent := false
+			for _, port := range mainContainer.Ports {
+				if port.ContainerPort == containerPort.ContainerPort {
+					isPresent = true
+					break
+				}
+			}
+
+			if !isPresent {
+				mainContainer.Ports = append(mainContainer.Ports, containerPort)
+			}
+
+			if mainContainer.ReadinessProbe != nil && mainContainer.ReadinessProbe.ProbeHandler.TCPSocket != nil {
+				mainContainer.ReadinessProbe.ProbeHandler.TCPSocket.Port.IntVal = int32(currentPort)
+			}
+
+			if mainContainer.LivenessProbe != nil && mainContainer.LivenessProbe.ProbeHandler.HTTPGet != nil {
+				mainContainer.LivenessProbe.ProbeHandler.HTTPGet.Port.IntVal = int32(currentPort)
+			}
+		}
+
+		// Update for probes
+		var probes *Probes
+		probes, err = GetProbesFromAttributes(component.Attributes)
+		if err != nil {
+			return err
+		}
+		if probes != nil {
+			if err := ApplyProbes(mainContainer, *probes, currentPort); err != nil {
+				return err
+			}
+		}
+
+		for _, devfileEnv := range currentENV {
+			isPresent := false
+			for i, containerEnv := range mainContainer.Env {
+				if containerEnv.Name == devfileEnv.Name {
+					isPresent = true
+					mainContainer.Env[i].Value = devfileEnv.Value
+				}
+			}
+
+			if !isPresent {
+				mainContainer.Env = append(mainContainer.Env, devfileEnv)
+			}
+		}
+
+		// Update for limits
+		cpuLimit := component.Attributes.GetString(CpuLimitKey, &err)
+		if err != nil {
+			if _, ok := err.(*attributes.KeyNotFoundError); !ok {
+				return err
+			}
+		}
+
+		memoryLimit := component.Attributes.GetString(MemoryLimitKey, &err)
+		if err != nil {
+			if _, ok := err.(*attributes.KeyNotFoundError); !ok {
+				return err
+			}
+		}
+
+		storageLimit := component.Attributes.GetString(StorageLimitKey, &err)
+		if err != nil {
+			if _, ok := err.(*attributes.KeyNotFoundError); !ok {
+				return err
+			}
+		}
+
+		containerLimits := mainContainer.Resources.Limits
+		if len(containerLimits) == 0 {
+			containerLimits = make(corev1.ResourceList)
+		}
+
+		if cpuLimit != "" && cpuLimit != "0" {
+			cpuLimitQuantity, err := resource.ParseQuantity(cpuLimit)
+			if err != nil {
+				return err
+			}
+			containerLimits[corev1.ResourceCPU] = cpuLimitQuantity
+		}
+
+		if memoryLimit != "" && memoryLimit != "0" {
+			memoryLimitQuantity, err := resource.ParseQuantity(memoryLimit)
+			if err != nil {
+				return err
+			}
+			containerLimits[corev1.ResourceMemory] = memoryLimitQuantity
+		}
+
+		if storageLimit != "" && storageLimit != "0" {
+			storageLimitQuantity, err := resource.ParseQuantity(storageLimit)
+			if err != nil {
+				return err
+			}
+			containerLimits[corev1.ResourceStorage] = storageLimitQuantity
+		}
+
+		mainContainer.Resources.Limits = containerLimits
+
+		// Update for requests
+		cpuRequest := component.Attributes.GetString(CpuRequestKey, &err)
+		if err != nil {
+			if _, ok := err.(*attributes.KeyNotFoundError); !ok {
+				return err
+			}
+		}
+
+		memoryRequest := component.Attributes.GetString(MemoryRequestKey, &err)
+		if err != nil {
+			if _, ok := err.(*attributes.KeyNotFoundError); !ok {
+				return err
+			}
+		}
+
+		storageRequest := component.Attributes.GetString(StorageRequestKey, &err)
+		if err != nil {
+			if _, ok := err.(*attributes.KeyNotFoundError); !ok {
+				return err
+			}
+		}
+
+		containerRequests := mainContainer.Resources.Requests
+		if len(containerRequests) == 0 {
+			containerRequests = make(corev1.ResourceList)
+		}
+
+		if cpuRequest != "" && cpuRequest != "0" {
+			cpuRequestQuantity, err := resource.ParseQuantity(cpuRequest)
+			if err != nil {
+				return err
+			}
+			containerRequests[corev1.ResourceCPU] = cpuRequestQuantity
+		}
+
+		if memoryRequest != "" && memoryRequest != "0" {
+			memoryRequestQuantity, err := resource.ParseQuantity(memoryRequest)
+			if err != nil {
+				return err
+			}
+			containerRequests[corev1.ResourceMemory] = memoryRequestQuantity
+		}
+
+		if storageRequest != "" && storageRequest != "0" {
+			storageRequestQuantity, err := resource.ParseQuantity(storageRequest)
+			if err != nil {
+				return err
+			}
+			containerRequests[corev1.ResourceStorage] = storageRequestQuantity
+		}
+
+		mainContainer.Resources.Requests = containerRequests
+	}
+
+	return nil
+}
+
 // GetIngressFromEndpoint gets an ingress resource from the devfile endpoint information
 func GetIngressFromEndpoint(name, serviceName, port, path string, secure bool, annotations map[string]string, hostname string) (networkingv1.Ingress, error) {
 
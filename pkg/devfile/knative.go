@@ -0,0 +1,77 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// KnativeServiceAPIVersion is the API version of the generated Knative Services
+	KnativeServiceAPIVersion = "serving.knative.dev/v1"
+
+	knativeAutoscalingClassAnnotation  = "autoscaling.knative.dev/class"
+	knativeAutoscalingMetricAnnotation = "autoscaling.knative.dev/metric"
+	knativeAutoscalingTargetAnnotation = "autoscaling.knative.dev/target"
+	knativeMinScaleAnnotation          = "autoscaling.knative.dev/min-scale"
+	knativeMaxScaleAnnotation          = "autoscaling.knative.dev/max-scale"
+
+	// knativeHPAAutoscalingClass is the Knative autoscaler class that scales on CPU or memory utilization
+	knativeHPAAutoscalingClass = "hpa.autoscaling.knative.dev"
+)
+
+// KnativeService is the subset of a serving.knative.dev/v1 Service that is generated for a Component.
+// The revision template is serialized like a pod template, since the Knative revision spec inlines the pod spec
+type KnativeService struct {
+	v1.TypeMeta   `json:",inline"`
+	v1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KnativeServiceSpec `json:"spec,omitempty"`
+}
+
+// KnativeServiceSpec is the spec of a Knative Service
+type KnativeServiceSpec struct {
+	// Template is the template of the revisions that the Knative Service creates
+	Template corev1.PodTemplateSpec `json:"template,omitempty"`
+}
+
+// GetKnativeAutoscalingAnnotations returns the revision template annotations that configure the Knative autoscaler.
+// Knative scales on request concurrency by default, a CPU utilization target switches to the HPA autoscaler class.
+// Memory utilization targets are rejected, since Knative expects an absolute memory target
+func GetKnativeAutoscalingAnnotations(autoscaling Autoscaling) (map[string]string, error) {
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		return nil, fmt.Errorf("a Knative Service cannot autoscale on the memory utilization percentage")
+	}
+
+	annotations := map[string]string{
+		knativeMaxScaleAnnotation: strconv.Itoa(int(autoscaling.MaxReplicas)),
+	}
+	if autoscaling.MinReplicas != nil {
+		annotations[knativeMinScaleAnnotation] = strconv.Itoa(int(*autoscaling.MinReplicas))
+	}
+
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		annotations[knativeAutoscalingClassAnnotation] = knativeHPAAutoscalingClass
+		annotations[knativeAutoscalingMetricAnnotation] = "cpu"
+		annotations[knativeAutoscalingTargetAnnotation] = strconv.Itoa(int(*autoscaling.TargetCPUUtilizationPercentage))
+	}
+
+	return annotations, nil
+}
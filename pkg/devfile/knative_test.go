@@ -0,0 +1,61 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetKnativeAutoscalingAnnotations(t *testing.T) {
+	minReplicas := int32(1)
+	utilization := int32(60)
+
+	tests := []struct {
+		name        string
+		autoscaling Autoscaling
+		want        map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "Concurrency based autoscaling",
+			autoscaling: Autoscaling{MinReplicas: &minReplicas, MaxReplicas: 3},
+			want: map[string]string{
+				"autoscaling.knative.dev/min-scale": "1",
+				"autoscaling.knative.dev/max-scale": "3",
+			},
+		},
+		{
+			name:        "Memory utilization target",
+			autoscaling: Autoscaling{MaxReplicas: 3, TargetMemoryUtilizationPercentage: &utilization},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations, err := GetKnativeAutoscalingAnnotations(tt.autoscaling)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, annotations, "knative autoscaling annotations did not match")
+			}
+		})
+	}
+}
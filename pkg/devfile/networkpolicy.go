@@ -0,0 +1,196 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/attributes"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// PartOfLabel is the label holding the name of the Application that a Component is part of
+	PartOfLabel = "app.kubernetes.io/part-of"
+
+	// instanceLabel is the label holding the name of the Component, used to select its pods
+	instanceLabel = "app.kubernetes.io/instance"
+)
+
+// NetworkPolicy describes the network policy generated for a Component
+type NetworkPolicy struct {
+	// Dependencies are the names of the sibling Components of the same Application that the Component sends traffic to
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// Validate checks that the dependencies are valid Component names
+func (n NetworkPolicy) Validate() error {
+	for _, dependency := range n.Dependencies {
+		if dependency == "" {
+			return fmt.Errorf("network policy dependencies cannot be empty")
+		}
+	}
+	return nil
+}
+
+// NetworkPolicyIngress describes the traffic that the NetworkPolicy of a Component admits
+type NetworkPolicyIngress struct {
+	// PublicPorts are the ports exposed through a Route or an Ingress, reachable from the router namespace and the Application
+	PublicPorts []int32
+
+	// InternalPorts are the ports only reachable from the Application
+	InternalPorts []int32
+
+	// RouterNamespaceLabels select the namespace of the router or ingress controller
+	RouterNamespaceLabels map[string]string
+
+	// Dependents are the sibling Components that declare the Component as a dependency. If set, the internal ports are
+	// only reachable from the dependents instead of every pod of the Application
+	Dependents []string
+}
+
+// GetNetworkPolicyFromAttributes returns the network policy configuration stored in the devfile component attributes.
+// nil is returned if the component does not have a network policy configuration
+func GetNetworkPolicyFromAttributes(componentAttributes attributes.Attributes) (*NetworkPolicy, error) {
+	var networkPolicy NetworkPolicy
+	err := componentAttributes.GetInto(NetworkPolicyKey, &networkPolicy)
+	if err != nil {
+		if _, ok := err.(*attributes.KeyNotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := networkPolicy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &networkPolicy, nil
+}
+
+// GetEndpointPorts returns the target ports of the public and internal endpoints of the devfile kubernetes component.
+// Endpoints without an exposure are public, like the Routes and Ingresses generated for them
+func GetEndpointPorts(component v1alpha2.Component) (publicPorts []int32, internalPorts []int32) {
+	if component.Kubernetes == nil {
+		return nil, nil
+	}
+	for _, endpoint := range component.Kubernetes.Endpoints {
+		switch endpoint.Exposure {
+		case v1alpha2.NoneEndpointExposure:
+			continue
+		case v1alpha2.InternalEndpointExposure:
+			internalPorts = appendPort(internalPorts, int32(endpoint.TargetPort))
+		default:
+			publicPorts = appendPort(publicPorts, int32(endpoint.TargetPort))
+		}
+	}
+	return publicPorts, internalPorts
+}
+
+// GenerateNetworkPolicy generates a networking.k8s.io/v1 NetworkPolicy restricting the ingress traffic of the pods of the Component.
+// The public ports admit traffic from the router namespace and from the pods of the Application, the internal ports from the pods of the
+// Application or from the dependents only. Any other ingress traffic is denied
+func GenerateNetworkPolicy(name string, applicationName string, labels map[string]string, ingress NetworkPolicyIngress) networkingv1.NetworkPolicy {
+	applicationPeer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &v1.LabelSelector{
+			MatchLabels: map[string]string{PartOfLabel: applicationName},
+		},
+	}
+
+	// the rules must be set even if empty, an empty list of rules denies all the ingress traffic
+	rules := []networkingv1.NetworkPolicyIngressRule{}
+	if len(ingress.PublicPorts) > 0 {
+		rules = append(rules, networkingv1.NetworkPolicyIngressRule{
+			Ports: getNetworkPolicyPorts(ingress.PublicPorts),
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &v1.LabelSelector{
+						MatchLabels: ingress.RouterNamespaceLabels,
+					},
+				},
+				applicationPeer,
+			},
+		})
+	}
+	if len(ingress.InternalPorts) > 0 {
+		internalPeer := applicationPeer
+		if len(ingress.Dependents) > 0 {
+			dependents := append([]string{}, ingress.Dependents...)
+			sort.Strings(dependents)
+			internalPeer = networkingv1.NetworkPolicyPeer{
+				PodSelector: &v1.LabelSelector{
+					MatchLabels: map[string]string{PartOfLabel: applicationName},
+					MatchExpressions: []v1.LabelSelectorRequirement{
+						{
+							Key:      instanceLabel,
+							Operator: v1.LabelSelectorOpIn,
+							Values:   dependents,
+						},
+					},
+				},
+			}
+		}
+		rules = append(rules, networkingv1.NetworkPolicyIngressRule{
+			Ports: getNetworkPolicyPorts(ingress.InternalPorts),
+			From:  []networkingv1.NetworkPolicyPeer{internalPeer},
+		})
+	}
+
+	return networkingv1.NetworkPolicy{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: v1.LabelSelector{
+				MatchLabels: getMatchLabel(name),
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     rules,
+		},
+	}
+}
+
+func getNetworkPolicyPorts(ports []int32) []networkingv1.NetworkPolicyPort {
+	var policyPorts []networkingv1.NetworkPolicyPort
+	for _, port := range ports {
+		protocol := corev1.ProtocolTCP
+		targetPort := intstr.FromInt(int(port))
+		policyPorts = append(policyPorts, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &targetPort,
+		})
+	}
+	return policyPorts
+}
+
+func appendPort(ports []int32, port int32) []int32 {
+	for _, existingPort := range ports {
+		if existingPort == port {
+			return ports
+		}
+	}
+	return append(ports, port)
+}
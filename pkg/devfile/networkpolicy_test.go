@@ -0,0 +1,167 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/attributes"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestGetNetworkPolicyFromAttributes(t *testing.T) {
+	var err error
+
+	tests := []struct {
+		name       string
+		attributes attributes.Attributes
+		want       *NetworkPolicy
+		wantErr    bool
+	}{
+		{
+			name:       "No network policy attribute",
+			attributes: attributes.Attributes{}.PutInteger(ReplicaKey, 1),
+		},
+		{
+			name:       "Network policy with dependencies",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{NetworkPolicyKey: NetworkPolicy{Dependencies: []string{"backend"}}}, &err),
+			want:       &NetworkPolicy{Dependencies: []string{"backend"}},
+		},
+		{
+			name:       "Empty dependency",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{NetworkPolicyKey: NetworkPolicy{Dependencies: []string{""}}}, &err),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			networkPolicy, err := GetNetworkPolicyFromAttributes(tt.attributes)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, networkPolicy, "network policy configuration did not match")
+			}
+		})
+	}
+}
+
+func TestGetEndpointPorts(t *testing.T) {
+	component := v1alpha2.Component{
+		Name: "kubernetes-deploy",
+		ComponentUnion: v1alpha2.ComponentUnion{
+			Kubernetes: &v1alpha2.KubernetesComponent{
+				K8sLikeComponent: v1alpha2.K8sLikeComponent{
+					Endpoints: []v1alpha2.Endpoint{
+						{Name: "http", TargetPort: 8080},
+						{Name: "https", TargetPort: 8443, Exposure: v1alpha2.PublicEndpointExposure},
+						{Name: "metrics", TargetPort: 9090, Exposure: v1alpha2.InternalEndpointExposure},
+						{Name: "debug", TargetPort: 5858, Exposure: v1alpha2.NoneEndpointExposure},
+						{Name: "http-admin", TargetPort: 8080, Path: "/admin"},
+					},
+				},
+			},
+		},
+	}
+
+	publicPorts, internalPorts := GetEndpointPorts(component)
+	assert.Equal(t, []int32{8080, 8443}, publicPorts)
+	assert.Equal(t, []int32{9090}, internalPorts)
+}
+
+func TestGenerateNetworkPolicy(t *testing.T) {
+	tcp := corev1.ProtocolTCP
+	publicPort := intstr.FromInt(8080)
+	internalPort := intstr.FromInt(9090)
+	routerNamespaceLabels := map[string]string{"network.openshift.io/policy-group": "ingress"}
+	applicationPeer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &v1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/part-of": "application-a"}},
+	}
+
+	tests := []struct {
+		name    string
+		ingress NetworkPolicyIngress
+		want    []networkingv1.NetworkPolicyIngressRule
+	}{
+		{
+			name:    "No ports denies all ingress traffic",
+			ingress: NetworkPolicyIngress{RouterNamespaceLabels: routerNamespaceLabels},
+			want:    []networkingv1.NetworkPolicyIngressRule{},
+		},
+		{
+			name: "Public and internal ports",
+			ingress: NetworkPolicyIngress{
+				PublicPorts:           []int32{8080},
+				InternalPorts:         []int32{9090},
+				RouterNamespaceLabels: routerNamespaceLabels,
+			},
+			want: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &publicPort}},
+					From: []networkingv1.NetworkPolicyPeer{
+						{NamespaceSelector: &v1.LabelSelector{MatchLabels: routerNamespaceLabels}},
+						applicationPeer,
+					},
+				},
+				{
+					Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &internalPort}},
+					From:  []networkingv1.NetworkPolicyPeer{applicationPeer},
+				},
+			},
+		},
+		{
+			name: "Internal ports restricted to the dependents",
+			ingress: NetworkPolicyIngress{
+				InternalPorts:         []int32{9090},
+				RouterNamespaceLabels: routerNamespaceLabels,
+				Dependents:            []string{"frontend", "worker"},
+			},
+			want: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &internalPort}},
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							PodSelector: &v1.LabelSelector{
+								MatchLabels: map[string]string{"app.kubernetes.io/part-of": "application-a"},
+								MatchExpressions: []v1.LabelSelectorRequirement{
+									{Key: "app.kubernetes.io/instance", Operator: v1.LabelSelectorOpIn, Values: []string{"frontend", "worker"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			networkPolicy := GenerateNetworkPolicy("component-a", "application-a", nil, tt.ingress)
+			assert.Equal(t, "NetworkPolicy", networkPolicy.Kind)
+			assert.Equal(t, "component-a", networkPolicy.Name)
+			assert.Equal(t, map[string]string{"app.kubernetes.io/instance": "component-a"}, networkPolicy.Spec.PodSelector.MatchLabels)
+			assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, networkPolicy.Spec.PolicyTypes)
+			assert.Equal(t, tt.want, networkPolicy.Spec.Ingress)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"fmt"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ProbeType is the mechanism used by a probe to check the container
+type ProbeType string
+
+const (
+	// HTTPProbeType probes the container with an HTTP GET request
+	HTTPProbeType ProbeType = "http"
+
+	// TCPProbeType probes the container by opening a TCP socket
+	TCPProbeType ProbeType = "tcp"
+
+	// ExecProbeType probes the container by executing a command in it
+	ExecProbeType ProbeType = "exec"
+)
+
+// Probe describes a single container probe.
+// Unset fields are defaulted from the Component port and the kind of probe
+type Probe struct {
+	// Type is the probe mechanism, defaults to http if a path is set and tcp otherwise
+	Type ProbeType `json:"type,omitempty"`
+
+	// Path is the HTTP path to probe, defaults to / for http probes
+	Path string `json:"path,omitempty"`
+
+	// Port is the port to probe, defaults to the Component port
+	Port int `json:"port,omitempty"`
+
+	// Command is the command executed by exec probes
+	Command []string `json:"command,omitempty"`
+
+	// InitialDelaySeconds is the number of seconds after the container has started before the probe is initiated
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// PeriodSeconds is how often in seconds to perform the probe
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// TimeoutSeconds is the number of seconds after which the probe times out
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures for the probe to be considered failed
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// Probes describes the liveness, readiness and startup probes of a Component
+type Probes struct {
+	Liveness  *Probe `json:"liveness,omitempty"`
+	Readiness *Probe `json:"readiness,omitempty"`
+	Startup   *Probe `json:"startup,omitempty"`
+}
+
+// probeDefaults holds the timings used when a probe does not set them
+type probeDefaults struct {
+	initialDelaySeconds int32
+	periodSeconds       int32
+	failureThreshold    int32
+}
+
+var (
+	livenessProbeDefaults  = probeDefaults{initialDelaySeconds: 15, periodSeconds: 20, failureThreshold: 3}
+	readinessProbeDefaults = probeDefaults{initialDelaySeconds: 5, periodSeconds: 10, failureThreshold: 3}
+	// the startup probe holds off the other probes, so give slow starting containers up to 5 minutes
+	startupProbeDefaults = probeDefaults{initialDelaySeconds: 0, periodSeconds: 10, failureThreshold: 30}
+)
+
+// Validate checks that the probe is consistent with its type
+func (p Probe) Validate() error {
+	switch p.getType() {
+	case HTTPProbeType, TCPProbeType:
+		if len(p.Command) > 0 {
+			return fmt.Errorf("probe command can only be set on %s probes", ExecProbeType)
+		}
+		if p.Port < 0 || p.Port > 65535 {
+			return fmt.Errorf("probe port %d is not a valid port", p.Port)
+		}
+	case ExecProbeType:
+		if len(p.Command) == 0 {
+			return fmt.Errorf("probe command is required for %s probes", ExecProbeType)
+		}
+	default:
+		return fmt.Errorf("probe type %q is not supported, must be one of %s, %s or %s", p.Type, HTTPProbeType, TCPProbeType, ExecProbeType)
+	}
+	if p.getType() == TCPProbeType && p.Path != "" {
+		return fmt.Errorf("probe path can only be set on %s probes", HTTPProbeType)
+	}
+
+	timings := []struct {
+		name  string
+		value *int32
+	}{
+		{"initialDelaySeconds", p.InitialDelaySeconds},
+		{"periodSeconds", p.PeriodSeconds},
+		{"timeoutSeconds", p.TimeoutSeconds},
+		{"failureThreshold", p.FailureThreshold},
+	}
+	for _, timing := range timings {
+		if timing.value != nil && *timing.value < 0 {
+			return fmt.Errorf("probe %s cannot be negative", timing.name)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that every configured probe is valid
+func (p Probes) Validate() error {
+	if p.Liveness != nil {
+		if err := p.Liveness.Validate(); err != nil {
+			return fmt.Errorf("invalid liveness probe: %v", err)
+		}
+	}
+	if p.Readiness != nil {
+		if err := p.Readiness.Validate(); err != nil {
+			return fmt.Errorf("invalid readiness probe: %v", err)
+		}
+	}
+	if p.Startup != nil {
+		if err := p.Startup.Validate(); err != nil {
+			return fmt.Errorf("invalid startup probe: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetProbesFromAttributes returns the probes configuration stored in the devfile component attributes.
+// nil is returned if the component does not have a probes configuration
+func GetProbesFromAttributes(componentAttributes attributes.Attributes) (*Probes, error) {
+	var probes Probes
+	err := componentAttributes.GetInto(ProbesKey, &probes)
+	if err != nil {
+		if _, ok := err.(*attributes.KeyNotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := probes.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &probes, nil
+}
+
+// ApplyProbes sets the configured probes on the container. The probes that are not configured are left untouched.
+// port is the Component port, used for http and tcp probes that do not set a port
+func ApplyProbes(container *corev1.Container, probes Probes, port int) error {
+	var err error
+	if probes.Liveness != nil {
+		if container.LivenessProbe, err = probes.Liveness.toContainerProbe(port, livenessProbeDefaults); err != nil {
+			return fmt.Errorf("unable to generate the liveness probe: %v", err)
+		}
+	}
+	if probes.Readiness != nil {
+		if container.ReadinessProbe, err = probes.Readiness.toContainerProbe(port, readinessProbeDefaults); err != nil {
+			return fmt.Errorf("unable to generate the readiness probe: %v", err)
+		}
+	}
+	if probes.Startup != nil {
+		if container.StartupProbe, err = probes.Startup.toContainerProbe(port, startupProbeDefaults); err != nil {
+			return fmt.Errorf("unable to generate the startup probe: %v", err)
+		}
+	}
+	return nil
+}
+
+// getType returns the type of the probe, inferring it from the other fields if unset
+func (p Probe) getType() ProbeType {
+	if p.Type != "" {
+		return p.Type
+	}
+	if len(p.Command) > 0 {
+		return ExecProbeType
+	}
+	if p.Path != "" {
+		return HTTPProbeType
+	}
+	return TCPProbeType
+}
+
+func (p Probe) toContainerProbe(defaultPort int, defaults probeDefaults) (*corev1.Probe, error) {
+	probe := corev1.Probe{
+		InitialDelaySeconds: defaults.initialDelaySeconds,
+		PeriodSeconds:       defaults.periodSeconds,
+		FailureThreshold:    defaults.failureThreshold,
+	}
+	if p.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *p.InitialDelaySeconds
+	}
+	if p.PeriodSeconds != nil {
+		probe.PeriodSeconds = *p.PeriodSeconds
+	}
+	if p.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *p.TimeoutSeconds
+	}
+	if p.FailureThreshold != nil {
+		probe.FailureThreshold = *p.FailureThreshold
+	}
+
+	probeType := p.getType()
+	if probeType == ExecProbeType {
+		probe.ProbeHandler.Exec = &corev1.ExecAction{
+			Command: p.Command,
+		}
+		return &probe, nil
+	}
+
+	port := p.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	if port <= 0 {
+		return nil, fmt.Errorf("a port is required for %s probes when the component does not have a port", probeType)
+	}
+
+	if probeType == HTTPProbeType {
+		path := p.Path
+		if path == "" {
+			path = "/"
+		}
+		probe.ProbeHandler.HTTPGet = &corev1.HTTPGetAction{
+			Path: path,
+			Port: intstr.FromInt(port),
+		}
+	} else {
+		probe.ProbeHandler.TCPSocket = &corev1.TCPSocketAction{
+			Port: intstr.FromInt(port),
+		}
+	}
+
+	return &probe, nil
+}
@@ -0,0 +1,249 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	parser "github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGetProbesFromAttributes(t *testing.T) {
+	var err error
+	validAttributes := attributes.Attributes{}.FromMap(map[string]interface{}{
+		ProbesKey: Probes{
+			Readiness: &Probe{Path: "/ready"},
+		},
+	}, &err)
+	if err != nil {
+		t.Error(err)
+	}
+
+	tests := []struct {
+		name       string
+		attributes attributes.Attributes
+		want       *Probes
+		wantErr    bool
+	}{
+		{
+			name:       "No probes attribute",
+			attributes: attributes.Attributes{}.PutInteger(ContainerImagePortKey, 8080),
+		},
+		{
+			name:       "Valid probes attribute",
+			attributes: validAttributes,
+			want: &Probes{
+				Readiness: &Probe{Path: "/ready"},
+			},
+		},
+		{
+			name: "Exec probe without a command",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{
+				ProbesKey: Probes{Liveness: &Probe{Type: ExecProbeType}},
+			}, &err),
+			wantErr: true,
+		},
+		{
+			name: "TCP probe with a path",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{
+				ProbesKey: Probes{Startup: &Probe{Type: TCPProbeType, Path: "/healthz"}},
+			}, &err),
+			wantErr: true,
+		},
+		{
+			name:       "Malformed probes attribute",
+			attributes: attributes.Attributes{}.PutString(ProbesKey, "http"),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes, err := GetProbesFromAttributes(tt.attributes)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, probes, "probes configuration did not match")
+			}
+		})
+	}
+}
+
+func TestApplyProbes(t *testing.T) {
+	initialDelay := int32(30)
+	failureThreshold := int32(6)
+	existingProbe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(1111)},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		probes        Probes
+		port          int
+		wantLiveness  *corev1.Probe
+		wantReadiness *corev1.Probe
+		wantStartup   *corev1.Probe
+		wantErr       bool
+	}{
+		{
+			name: "HTTP readiness probe defaulted from the port, existing liveness probe left untouched",
+			probes: Probes{
+				Readiness: &Probe{Path: "/ready"},
+			},
+			port:         8080,
+			wantLiveness: existingProbe,
+			wantReadiness: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/ready", Port: intstr.FromInt(8080)},
+				},
+				InitialDelaySeconds: readinessProbeDefaults.initialDelaySeconds,
+				PeriodSeconds:       readinessProbeDefaults.periodSeconds,
+				FailureThreshold:    readinessProbeDefaults.failureThreshold,
+			},
+		},
+		{
+			name: "TCP liveness, exec startup and explicit timings",
+			probes: Probes{
+				Liveness: &Probe{Port: 9090, InitialDelaySeconds: &initialDelay},
+				Startup:  &Probe{Command: []string{"cat", "/tmp/started"}, FailureThreshold: &failureThreshold},
+			},
+			port: 8080,
+			wantLiveness: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(9090)},
+				},
+				InitialDelaySeconds: initialDelay,
+				PeriodSeconds:       livenessProbeDefaults.periodSeconds,
+				FailureThreshold:    livenessProbeDefaults.failureThreshold,
+			},
+			wantStartup: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					Exec: &corev1.ExecAction{Command: []string{"cat", "/tmp/started"}},
+				},
+				InitialDelaySeconds: startupProbeDefaults.initialDelaySeconds,
+				PeriodSeconds:       startupProbeDefaults.periodSeconds,
+				FailureThreshold:    failureThreshold,
+			},
+		},
+		{
+			name: "HTTP probe without a path probes the root",
+			probes: Probes{
+				Readiness: &Probe{Type: HTTPProbeType},
+			},
+			port:         3000,
+			wantLiveness: existingProbe,
+			wantReadiness: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/", Port: intstr.FromInt(3000)},
+				},
+				InitialDelaySeconds: readinessProbeDefaults.initialDelaySeconds,
+				PeriodSeconds:       readinessProbeDefaults.periodSeconds,
+				FailureThreshold:    readinessProbeDefaults.failureThreshold,
+			},
+		},
+		{
+			name: "TCP probe without any port",
+			probes: Probes{
+				Readiness: &Probe{},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container := corev1.Container{
+				Name:          "container",
+				LivenessProbe: existingProbe,
+			}
+			err := ApplyProbes(&container, tt.probes, tt.port)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.wantLiveness, container.LivenessProbe, "liveness probe did not match")
+				assert.Equal(t, tt.wantReadiness, container.ReadinessProbe, "readiness probe did not match")
+				assert.Equal(t, tt.wantStartup, container.StartupProbe, "startup probe did not match")
+			}
+		})
+	}
+}
+
+func TestGetResourceFromDevfileWithProbes(t *testing.T) {
+	probesDevfile := `
+components:
+- attributes:
+    deployment/container-port: 8081
+    deployment/probes:
+      readiness:
+        path: /health
+        periodSeconds: 5
+  kubernetes:
+    deployByDefault: false
+    inlined: |-
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: deploy-sample
+      spec:
+        template:
+          spec:
+            containers:
+            - image: quay.io/redhat-appstudio/user-workload:application-service-system-component-sample
+              name: container-image
+              readinessProbe:
+                tcpSocket:
+                  port: 1111
+  name: kubernetes-deploy
+metadata:
+  name: java-springboot
+schemaVersion: 2.2.0`
+
+	devfileData, err := ParseDevfile(DevfileSrc{Data: probesDevfile})
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	deployAssociatedComponents, err := parser.GetDeployComponents(devfileData)
+	if err != nil {
+		t.Fatalf("unexpected get deploy components error: %v", err)
+	}
+
+	logger := ctrl.Log.WithName("TestGetResourceFromDevfileWithProbes")
+	actualResources, err := GetResourceFromDevfile(logger, devfileData, deployAssociatedComponents, "component-sample", "application-sample", "image1", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantReadiness := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromInt(8081)},
+		},
+		InitialDelaySeconds: readinessProbeDefaults.initialDelaySeconds,
+		PeriodSeconds:       5,
+		FailureThreshold:    readinessProbeDefaults.failureThreshold,
+	}
+	assert.Equal(t, wantReadiness, actualResources.Deployments[0].Spec.Template.Spec.Containers[0].ReadinessProbe, "readiness probe did not match")
+}
@@ -0,0 +1,286 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"fmt"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	parser "github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	"golang.org/x/exp/maps"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// WorkloadKind is the kind of Kubernetes workload running a Component
+type WorkloadKind string
+
+const (
+	DeploymentWorkloadKind  WorkloadKind = "Deployment"
+	StatefulSetWorkloadKind WorkloadKind = "StatefulSet"
+	JobWorkloadKind         WorkloadKind = "Job"
+	CronJobWorkloadKind     WorkloadKind = "CronJob"
+
+	// KnativeServiceWorkloadKind runs the Component as a serving.knative.dev/v1 Service, which also routes the traffic to it
+	KnativeServiceWorkloadKind WorkloadKind = "KnativeService"
+)
+
+// Workload describes the kind of workload generated for a Component
+type Workload struct {
+	// Kind is the workload kind, defaults to Deployment
+	Kind WorkloadKind `json:"kind,omitempty"`
+
+	// Schedule is the cron schedule of CronJob workloads
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// Validate checks that the workload kind is supported and has the fields it requires
+func (w Workload) Validate() error {
+	switch w.Kind {
+	case "", DeploymentWorkloadKind, StatefulSetWorkloadKind, JobWorkloadKind, KnativeServiceWorkloadKind:
+		if w.Schedule != "" {
+			return fmt.Errorf("a schedule can only be set on %s workloads", CronJobWorkloadKind)
+		}
+	case CronJobWorkloadKind:
+		if w.Schedule == "" {
+			return fmt.Errorf("a schedule is required for %s workloads", CronJobWorkloadKind)
+		}
+	default:
+		return fmt.Errorf("workload kind %q is not supported, must be one of %s, %s, %s, %s or %s", w.Kind, DeploymentWorkloadKind, StatefulSetWorkloadKind, JobWorkloadKind, CronJobWorkloadKind, KnativeServiceWorkloadKind)
+	}
+	return nil
+}
+
+// GetWorkloadFromAttributes returns the workload configuration stored in the devfile component attributes.
+// A Deployment workload is returned if the component does not have a workload configuration
+func GetWorkloadFromAttributes(componentAttributes attributes.Attributes) (Workload, error) {
+	var workload Workload
+	err := componentAttributes.GetInto(WorkloadKey, &workload)
+	if err != nil {
+		if _, ok := err.(*attributes.KeyNotFoundError); !ok {
+			return Workload{}, err
+		}
+	}
+
+	if err := workload.Validate(); err != nil {
+		return Workload{}, err
+	}
+	if workload.Kind == "" {
+		workload.Kind = DeploymentWorkloadKind
+	}
+
+	return workload, nil
+}
+
+// GetWorkloadPodTemplate returns the pod template of a Deployment, StatefulSet, Job, CronJob or Knative Service, or nil for any other object
+func GetWorkloadPodTemplate(workload interface{}) *corev1.PodTemplateSpec {
+	switch w := workload.(type) {
+	case *KnativeService:
+		return &w.Spec.Template
+	case *appsv1.Deployment:
+		return &w.Spec.Template
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template
+	case *batchv1.Job:
+		return &w.Spec.Template
+	case *batchv1.CronJob:
+		return &w.Spec.JobTemplate.Spec.Template
+	}
+	return nil
+}
+
+// extractWorkload removes the first workload of the given kind from the resources and returns it.
+// If the resources do not have such a workload, it is converted from the first Deployment, or from the default Deployment
+func extractWorkload(resources *parser.KubernetesResources, workload Workload, defaultDeployment appsv1.Deployment) (interface{}, error) {
+	var typedWorkload interface{}
+	switch workload.Kind {
+	case StatefulSetWorkloadKind:
+		typedWorkload = &appsv1.StatefulSet{}
+	case JobWorkloadKind:
+		typedWorkload = &batchv1.Job{}
+	case CronJobWorkloadKind:
+		typedWorkload = &batchv1.CronJob{}
+	case KnativeServiceWorkloadKind:
+		typedWorkload = &KnativeService{}
+	default:
+		return nil, fmt.Errorf("workload kind %q cannot be extracted", workload.Kind)
+	}
+
+	for i, other := range resources.Others {
+		otherMap, ok := other.(map[string]interface{})
+		if !ok || !isWorkloadOfKind(otherMap, workload.Kind) {
+			continue
+		}
+		otherBytes, err := yaml.Marshal(otherMap)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(otherBytes, typedWorkload); err != nil {
+			return nil, err
+		}
+		resources.Others = append(resources.Others[:i], resources.Others[i+1:]...)
+		return typedWorkload, nil
+	}
+
+	deployment := defaultDeployment
+	if len(resources.Deployments) > 0 {
+		deployment = resources.Deployments[0]
+		resources.Deployments = resources.Deployments[1:]
+	}
+
+	switch w := typedWorkload.(type) {
+	case *appsv1.StatefulSet:
+		w.ObjectMeta = deployment.ObjectMeta
+		w.Spec.Selector = deployment.Spec.Selector
+		w.Spec.Template = deployment.Spec.Template
+	case *batchv1.Job:
+		w.ObjectMeta = deployment.ObjectMeta
+		w.Spec.Template = deployment.Spec.Template
+	case *batchv1.CronJob:
+		w.ObjectMeta = deployment.ObjectMeta
+		w.Spec.JobTemplate.Spec.Template = deployment.Spec.Template
+	case *KnativeService:
+		w.ObjectMeta = deployment.ObjectMeta
+		w.Spec.Template = deployment.Spec.Template
+	}
+	return typedWorkload, nil
+}
+
+// isWorkloadOfKind checks whether the unstructured object is a workload of the given kind, Knative Services are told apart from core Services by their API version
+func isWorkloadOfKind(object map[string]interface{}, workloadKind WorkloadKind) bool {
+	if workloadKind == KnativeServiceWorkloadKind {
+		return object["kind"] == "Service" && object["apiVersion"] == KnativeServiceAPIVersion
+	}
+	return object["kind"] == string(workloadKind)
+}
+
+// updateWorkload sets the name, labels and kind specific fields of a StatefulSet, Job or CronJob workload
+func updateWorkload(workload interface{}, config Workload, compName string, k8sLabels, matchLabels map[string]string, replicas int32) {
+	var objectMeta *v1.ObjectMeta
+	switch w := workload.(type) {
+	case *appsv1.StatefulSet:
+		w.TypeMeta = v1.TypeMeta{Kind: string(StatefulSetWorkloadKind), APIVersion: "apps/v1"}
+		objectMeta = &w.ObjectMeta
+		if w.Spec.Selector == nil {
+			w.Spec.Selector = &v1.LabelSelector{}
+		}
+		if w.Spec.Selector.MatchLabels == nil {
+			w.Spec.Selector.MatchLabels = make(map[string]string)
+		}
+		maps.Copy(w.Spec.Selector.MatchLabels, matchLabels)
+		if w.Spec.ServiceName == "" {
+			w.Spec.ServiceName = compName
+		}
+		if w.Spec.RevisionHistoryLimit == nil {
+			w.Spec.RevisionHistoryLimit = &util.RevisionHistoryLimit
+		}
+		if replicas > 0 {
+			w.Spec.Replicas = &replicas
+		}
+	case *batchv1.Job:
+		w.TypeMeta = v1.TypeMeta{Kind: string(JobWorkloadKind), APIVersion: "batch/v1"}
+		objectMeta = &w.ObjectMeta
+	case *batchv1.CronJob:
+		w.TypeMeta = v1.TypeMeta{Kind: string(CronJobWorkloadKind), APIVersion: "batch/v1"}
+		objectMeta = &w.ObjectMeta
+		w.Spec.Schedule = config.Schedule
+	case *KnativeService:
+		w.TypeMeta = v1.TypeMeta{Kind: "Service", APIVersion: KnativeServiceAPIVersion}
+		objectMeta = &w.ObjectMeta
+	default:
+		return
+	}
+
+	objectMeta.Name = compName
+	if objectMeta.Labels == nil {
+		objectMeta.Labels = make(map[string]string)
+	}
+	maps.Copy(objectMeta.Labels, k8sLabels)
+
+	podTemplate := GetWorkloadPodTemplate(workload)
+	if podTemplate.ObjectMeta.Labels == nil {
+		podTemplate.ObjectMeta.Labels = make(map[string]string)
+	}
+	maps.Copy(podTemplate.ObjectMeta.Labels, matchLabels)
+
+	// pods of Jobs and CronJobs run to completion and cannot be restarted always
+	if config.Kind == JobWorkloadKind || config.Kind == CronJobWorkloadKind {
+		if podTemplate.Spec.RestartPolicy == "" || podTemplate.Spec.RestartPolicy == corev1.RestartPolicyAlways {
+			podTemplate.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+		}
+	}
+}
+
+// dereferenceWorkload returns the workload struct a pointer points to, so that it is serialized like the other resources
+func dereferenceWorkload(workload interface{}) interface{} {
+	switch w := workload.(type) {
+	case *appsv1.StatefulSet:
+		return *w
+	case *batchv1.Job:
+		return *w
+	case *batchv1.CronJob:
+		return *w
+	case *KnativeService:
+		return *w
+	}
+	return workload
+}
+
+// GetWorkload returns a pointer to the first workload of the given kind in the resources, or nil if there is none.
+// Deployments are looked up in the Deployments of the resources, the other kinds in the typed workloads appended to Others
+func GetWorkload(resources parser.KubernetesResources, workloadKind WorkloadKind) interface{} {
+	if workloadKind == DeploymentWorkloadKind {
+		if len(resources.Deployments) > 0 {
+			return &resources.Deployments[0]
+		}
+		return nil
+	}
+	for _, other := range resources.Others {
+		switch w := other.(type) {
+		case appsv1.StatefulSet:
+			if workloadKind == StatefulSetWorkloadKind {
+				return &w
+			}
+		case batchv1.Job:
+			if workloadKind == JobWorkloadKind {
+				return &w
+			}
+		case batchv1.CronJob:
+			if workloadKind == CronJobWorkloadKind {
+				return &w
+			}
+		case KnativeService:
+			if workloadKind == KnativeServiceWorkloadKind {
+				return &w
+			}
+		}
+	}
+	return nil
+}
+
+// GetWorkloadMainContainerName returns the name of the first container of the workload of the given kind in the resources,
+// the main container is moved first when the resources are generated from the devfile
+func GetWorkloadMainContainerName(resources parser.KubernetesResources, workloadKind WorkloadKind) string {
+	podTemplate := GetWorkloadPodTemplate(GetWorkload(resources, workloadKind))
+	if podTemplate == nil || len(podTemplate.Spec.Containers) == 0 {
+		return ""
+	}
+	return podTemplate.Spec.Containers[0].Name
+}
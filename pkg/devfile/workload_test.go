@@ -0,0 +1,261 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	parser "github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGetWorkloadFromAttributes(t *testing.T) {
+	var err error
+
+	tests := []struct {
+		name       string
+		attributes attributes.Attributes
+		want       Workload
+		wantErr    bool
+	}{
+		{
+			name:       "No workload attribute",
+			attributes: attributes.Attributes{}.PutInteger(ReplicaKey, 1),
+			want:       Workload{Kind: DeploymentWorkloadKind},
+		},
+		{
+			name:       "StatefulSet workload",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{WorkloadKey: Workload{Kind: StatefulSetWorkloadKind}}, &err),
+			want:       Workload{Kind: StatefulSetWorkloadKind},
+		},
+		{
+			name:       "CronJob workload",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{WorkloadKey: Workload{Kind: CronJobWorkloadKind, Schedule: "*/5 * * * *"}}, &err),
+			want:       Workload{Kind: CronJobWorkloadKind, Schedule: "*/5 * * * *"},
+		},
+		{
+			name:       "CronJob workload without a schedule",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{WorkloadKey: Workload{Kind: CronJobWorkloadKind}}, &err),
+			wantErr:    true,
+		},
+		{
+			name:       "Job workload with a schedule",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{WorkloadKey: Workload{Kind: JobWorkloadKind, Schedule: "*/5 * * * *"}}, &err),
+			wantErr:    true,
+		},
+		{
+			name:       "Unsupported workload kind",
+			attributes: attributes.Attributes{}.FromMap(map[string]interface{}{WorkloadKey: Workload{Kind: "DaemonSet"}}, &err),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workload, err := GetWorkloadFromAttributes(tt.attributes)
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				assert.Equal(t, tt.want, workload, "workload configuration did not match")
+			}
+		})
+	}
+}
+
+func TestGetResourceFromDevfileWithWorkload(t *testing.T) {
+	deploymentDevfile := `
+components:
+- attributes:
+    deployment/container-port: 8080
+    deployment/replicas: 3
+    deployment/workload:
+      kind: %s
+  kubernetes:
+    deployByDefault: false
+    inlined: |-
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: deploy-sample
+      spec:
+        template:
+          spec:
+            containers:
+            - image: quay.io/redhat-appstudio/user-workload:application-service-system-component-sample
+              name: app
+  name: kubernetes-deploy
+metadata:
+  name: java-springboot
+schemaVersion: 2.2.0`
+
+	cronJobDevfile := `
+components:
+- attributes:
+    deployment/workload:
+      kind: CronJob
+      schedule: "0 * * * *"
+  kubernetes:
+    deployByDefault: false
+    inlined: |-
+      apiVersion: batch/v1
+      kind: CronJob
+      metadata:
+        name: cronjob-sample
+      spec:
+        schedule: "* * * * *"
+        jobTemplate:
+          spec:
+            template:
+              spec:
+                restartPolicy: Never
+                containers:
+                - image: quay.io/org/report:1.0
+                  name: report
+  name: kubernetes-deploy
+metadata:
+  name: java-springboot
+schemaVersion: 2.2.0`
+
+	replicas := int32(3)
+
+	tests := []struct {
+		name    string
+		devfile string
+		wantErr bool
+		check   func(t *testing.T, resources parser.KubernetesResources)
+	}{
+		{
+			name:    "Deployment converted to a StatefulSet",
+			devfile: fmt.Sprintf(deploymentDevfile, "StatefulSet"),
+			check: func(t *testing.T, resources parser.KubernetesResources) {
+				assert.Empty(t, resources.Deployments)
+				if assert.Len(t, resources.Others, 1) {
+					statefulSet, ok := resources.Others[0].(appsv1.StatefulSet)
+					if assert.True(t, ok, "expected a StatefulSet") {
+						assert.Equal(t, "component-sample", statefulSet.Name)
+						assert.Equal(t, "component-sample", statefulSet.Spec.ServiceName)
+						assert.Equal(t, &replicas, statefulSet.Spec.Replicas)
+						assert.Equal(t, getMatchLabel("component-sample"), statefulSet.Spec.Selector.MatchLabels)
+						assert.Equal(t, "image1", statefulSet.Spec.Template.Spec.Containers[0].Image)
+						assert.Equal(t, []corev1.ContainerPort{{ContainerPort: 8080}}, statefulSet.Spec.Template.Spec.Containers[0].Ports)
+					}
+				}
+				assert.Equal(t, "app", GetWorkloadMainContainerName(resources, StatefulSetWorkloadKind))
+			},
+		},
+		{
+			name:    "Deployment converted to a Job",
+			devfile: fmt.Sprintf(deploymentDevfile, "Job"),
+			check: func(t *testing.T, resources parser.KubernetesResources) {
+				assert.Empty(t, resources.Deployments)
+				if assert.Len(t, resources.Others, 1) {
+					job, ok := resources.Others[0].(batchv1.Job)
+					if assert.True(t, ok, "expected a Job") {
+						assert.Equal(t, "component-sample", job.Name)
+						assert.Equal(t, corev1.RestartPolicyOnFailure, job.Spec.Template.Spec.RestartPolicy)
+						assert.Equal(t, "image1", job.Spec.Template.Spec.Containers[0].Image)
+					}
+				}
+			},
+		},
+		{
+			name:    "CronJob declared in the devfile",
+			devfile: cronJobDevfile,
+			check: func(t *testing.T, resources parser.KubernetesResources) {
+				if assert.Len(t, resources.Others, 1) {
+					cronJob, ok := resources.Others[0].(batchv1.CronJob)
+					if assert.True(t, ok, "expected a CronJob") {
+						assert.Equal(t, "component-sample", cronJob.Name)
+						assert.Equal(t, "0 * * * *", cronJob.Spec.Schedule)
+						podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+						assert.Equal(t, corev1.RestartPolicyNever, podSpec.RestartPolicy)
+						assert.Equal(t, "image1", podSpec.Containers[0].Image)
+					}
+				}
+				assert.Equal(t, "report", GetWorkloadMainContainerName(resources, CronJobWorkloadKind))
+			},
+		},
+		{
+			name: "Deployment converted to an autoscaled Knative Service",
+			devfile: strings.Replace(fmt.Sprintf(deploymentDevfile, "KnativeService"), "    deployment/replicas: 3\n",
+				"    deployment/autoscaling:\n      maxReplicas: 5\n      targetCPUUtilizationPercentage: 70\n", 1),
+			check: func(t *testing.T, resources parser.KubernetesResources) {
+				assert.Empty(t, resources.Deployments)
+				assert.Empty(t, resources.Services)
+				assert.Empty(t, resources.Routes)
+				assert.Empty(t, resources.Ingresses)
+				if assert.Len(t, resources.Others, 1) {
+					knativeService, ok := resources.Others[0].(KnativeService)
+					if assert.True(t, ok, "expected a Knative Service") {
+						assert.Equal(t, KnativeServiceAPIVersion, knativeService.APIVersion)
+						assert.Equal(t, "component-sample", knativeService.Name)
+						assert.Equal(t, "image1", knativeService.Spec.Template.Spec.Containers[0].Image)
+						assert.Equal(t, []corev1.ContainerPort{{ContainerPort: 8080}}, knativeService.Spec.Template.Spec.Containers[0].Ports)
+						assert.Equal(t, map[string]string{
+							"autoscaling.knative.dev/max-scale": "5",
+							"autoscaling.knative.dev/class":     "hpa.autoscaling.knative.dev",
+							"autoscaling.knative.dev/metric":    "cpu",
+							"autoscaling.knative.dev/target":    "70",
+						}, knativeService.Spec.Template.Annotations)
+					}
+				}
+			},
+		},
+		{
+			name:    "Autoscaled Job",
+			devfile: strings.Replace(fmt.Sprintf(deploymentDevfile, "Job"), "    deployment/replicas: 3\n", "    deployment/autoscaling:\n      maxReplicas: 3\n", 1),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileData, err := ParseDevfile(DevfileSrc{Data: tt.devfile})
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			deployAssociatedComponents, err := parser.GetDeployComponents(devfileData)
+			if err != nil {
+				t.Fatalf("unexpected get deploy components error: %v", err)
+			}
+
+			logger := ctrl.Log.WithName("TestGetResourceFromDevfileWithWorkload")
+			actualResources, err := GetResourceFromDevfile(logger, devfileData, deployAssociatedComponents, "component-sample", "application-sample", "image1", "")
+			if tt.wantErr && (err == nil) {
+				t.Error("wanted error but got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if err == nil {
+				tt.check(t, actualResources)
+			}
+		})
+	}
+}
+
+func TestGenerateHorizontalPodAutoscalerForStatefulSet(t *testing.T) {
+	hpa := GenerateHorizontalPodAutoscaler("component-sample", nil, Autoscaling{MaxReplicas: 3}, StatefulSetWorkloadKind)
+	assert.Equal(t, autoscalingv2.CrossVersionObjectReference{Kind: "StatefulSet", Name: "component-sample", APIVersion: "apps/v1"}, hpa.Spec.ScaleTargetRef)
+}
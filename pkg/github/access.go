@@ -0,0 +1,187 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/google/go-github/v52/github"
+)
+
+// RepositoryVisibility is the visibility of a GitHub repository
+type RepositoryVisibility string
+
+const (
+	PrivateVisibility  RepositoryVisibility = "private"
+	InternalVisibility RepositoryVisibility = "internal"
+	PublicVisibility   RepositoryVisibility = "public"
+)
+
+// repositoryVisibilityExposure orders the visibilities from the least to the most exposed
+var repositoryVisibilityExposure = map[RepositoryVisibility]int{
+	PrivateVisibility:  0,
+	InternalVisibility: 1,
+	PublicVisibility:   2,
+}
+
+// repositoryPermissions maps the permissions that can be granted on a repository to the role name that GitHub reports for them
+var repositoryPermissions = map[string]string{
+	"pull":     "read",
+	"read":     "read",
+	"triage":   "triage",
+	"push":     "write",
+	"write":    "write",
+	"maintain": "maintain",
+	"admin":    "admin",
+}
+
+// ParseRepositoryVisibility returns the repository visibility of the given name, private if the name is empty
+func ParseRepositoryVisibility(visibility string) (RepositoryVisibility, error) {
+	if visibility == "" {
+		return PrivateVisibility, nil
+	}
+	if _, ok := repositoryVisibilityExposure[RepositoryVisibility(visibility)]; !ok {
+		return "", fmt.Errorf("invalid repository visibility %q, it must be one of private, internal or public", visibility)
+	}
+	return RepositoryVisibility(visibility), nil
+}
+
+// IsMoreExposedThan returns true if the visibility exposes the repository to more users than the other visibility
+func (v RepositoryVisibility) IsMoreExposedThan(other RepositoryVisibility) bool {
+	return repositoryVisibilityExposure[v] > repositoryVisibilityExposure[other]
+}
+
+// RepositoryAccess is the visibility of a repository and the permissions granted to users and teams on it.
+// Collaborators are keyed by user login and teams by team slug, the permissions are read, triage, write, maintain or admin
+type RepositoryAccess struct {
+	Visibility    RepositoryVisibility `json:"visibility,omitempty"`
+	Collaborators map[string]string    `json:"collaborators,omitempty"`
+	Teams         map[string]string    `json:"teams,omitempty"`
+}
+
+// Validate returns an error if a permission of the repository access is not a valid repository permission
+func (a RepositoryAccess) Validate() error {
+	if a.Visibility != "" {
+		if _, err := ParseRepositoryVisibility(string(a.Visibility)); err != nil {
+			return err
+		}
+	}
+	for _, grants := range []map[string]string{a.Collaborators, a.Teams} {
+		for grantee, permission := range grants {
+			if _, ok := repositoryPermissions[permission]; !ok {
+				return fmt.Errorf("invalid permission %q for %s, it must be one of read, triage, write, maintain or admin", permission, grantee)
+			}
+		}
+	}
+	return nil
+}
+
+// ReconcileRepositoryAccess sets the visibility of the repository and the permissions of its collaborators and teams to the ones of the access,
+// and revokes the access of the given collaborators and teams. Only the settings that differ are changed, which are returned as a sorted list
+func (g *GitHubClient) ReconcileRepositoryAccess(ctx context.Context, orgName string, repoName string, access RepositoryAccess, revokedCollaborators []string, revokedTeams []string) ([]string, error) {
+	var changes []string
+
+	repo, _, err := g.Client.Repositories.Get(ctx, orgName, repoName)
+	if err != nil || repo == nil {
+		return nil, fmt.Errorf("failed to get repo %s under %s, error: %v", repoName, orgName, err)
+	}
+	visibility := PublicVisibility
+	if repo.Visibility != nil {
+		visibility = RepositoryVisibility(repo.GetVisibility())
+	} else if repo.GetPrivate() {
+		visibility = PrivateVisibility
+	}
+	if access.Visibility != "" && visibility != access.Visibility {
+		desiredVisibility := string(access.Visibility)
+		isPrivate := access.Visibility != PublicVisibility
+		if _, _, err := g.Client.Repositories.Edit(ctx, orgName, repoName, &github.Repository{Visibility: &desiredVisibility, Private: &isPrivate}); err != nil {
+			return changes, fmt.Errorf("failed to set the visibility of repo %s under %s to %s, error: %v", repoName, orgName, desiredVisibility, err)
+		}
+		changes = append(changes, fmt.Sprintf("visibility %s", desiredVisibility))
+	}
+
+	for user, permission := range access.Collaborators {
+		permissionLevel, resp, err := g.Client.Repositories.GetPermissionLevel(ctx, orgName, repoName, user)
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return changes, fmt.Errorf("failed to get the permission of %s on repo %s under %s, error: %v", user, repoName, orgName, err)
+		}
+		if getCollaboratorRoleName(permissionLevel) == repositoryPermissions[permission] {
+			continue
+		}
+		if _, _, err := g.Client.Repositories.AddCollaborator(ctx, orgName, repoName, user, &github.RepositoryAddCollaboratorOptions{Permission: getPermissionOption(permission)}); err != nil {
+			return changes, fmt.Errorf("failed to grant %s the %s permission on repo %s under %s, error: %v", user, permission, repoName, orgName, err)
+		}
+		changes = append(changes, fmt.Sprintf("collaborator %s %s", user, permission))
+	}
+	for _, user := range revokedCollaborators {
+		if _, err := g.Client.Repositories.RemoveCollaborator(ctx, orgName, repoName, user); err != nil {
+			return changes, fmt.Errorf("failed to revoke the access of %s to repo %s under %s, error: %v", user, repoName, orgName, err)
+		}
+		changes = append(changes, fmt.Sprintf("collaborator %s revoked", user))
+	}
+
+	for team, permission := range access.Teams {
+		teamRepo, resp, err := g.Client.Teams.IsTeamRepoBySlug(ctx, orgName, team, orgName, repoName)
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return changes, fmt.Errorf("failed to get the permission of team %s on repo %s under %s, error: %v", team, repoName, orgName, err)
+		}
+		if err == nil && teamRepo.GetRoleName() == repositoryPermissions[permission] {
+			continue
+		}
+		if _, err := g.Client.Teams.AddTeamRepoBySlug(ctx, orgName, team, orgName, repoName, &github.TeamAddTeamRepoOptions{Permission: getPermissionOption(permission)}); err != nil {
+			return changes, fmt.Errorf("failed to grant team %s the %s permission on repo %s under %s, error: %v", team, permission, repoName, orgName, err)
+		}
+		changes = append(changes, fmt.Sprintf("team %s %s", team, permission))
+	}
+	for _, team := range revokedTeams {
+		if _, err := g.Client.Teams.RemoveTeamRepoBySlug(ctx, orgName, team, orgName, repoName); err != nil {
+			return changes, fmt.Errorf("failed to revoke the access of team %s to repo %s under %s, error: %v", team, repoName, orgName, err)
+		}
+		changes = append(changes, fmt.Sprintf("team %s revoked", team))
+	}
+
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// getCollaboratorRoleName returns the role name of a collaborator, or an empty string if the user is not a collaborator
+func getCollaboratorRoleName(permissionLevel *github.RepositoryPermissionLevel) string {
+	if permissionLevel == nil {
+		return ""
+	}
+	if roleName := permissionLevel.GetUser().GetRoleName(); roleName != "" {
+		return roleName
+	}
+	if permission := permissionLevel.GetPermission(); permission != "none" {
+		return permission
+	}
+	return ""
+}
+
+// getPermissionOption returns the value of the permission option of the GitHub API for the given permission
+func getPermissionOption(permission string) string {
+	switch repositoryPermissions[permission] {
+	case "read":
+		return "pull"
+	case "write":
+		return "push"
+	default:
+		return permission
+	}
+}
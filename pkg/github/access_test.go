@@ -0,0 +1,209 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v52/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRepositoryVisibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility string
+		want       RepositoryVisibility
+		wantErr    bool
+	}{
+		{
+			name: "No visibility defaults to private",
+			want: PrivateVisibility,
+		},
+		{
+			name:       "Internal visibility",
+			visibility: "internal",
+			want:       InternalVisibility,
+		},
+		{
+			name:       "Invalid visibility",
+			visibility: "secret",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visibility, err := ParseRepositoryVisibility(tt.visibility)
+			if tt.wantErr != (err != nil) {
+				t.Errorf("TestParseRepositoryVisibility() unexpected error value: %v", err)
+			}
+			assert.Equal(t, tt.want, visibility)
+		})
+	}
+
+	assert.True(t, PublicVisibility.IsMoreExposedThan(PrivateVisibility))
+	assert.True(t, InternalVisibility.IsMoreExposedThan(PrivateVisibility))
+	assert.False(t, PrivateVisibility.IsMoreExposedThan(InternalVisibility))
+}
+
+func TestRepositoryAccessValidate(t *testing.T) {
+	assert.NoError(t, RepositoryAccess{Visibility: InternalVisibility, Collaborators: map[string]string{"user": "push"}, Teams: map[string]string{"team": "maintain"}}.Validate())
+	assert.Error(t, RepositoryAccess{Visibility: "secret"}.Validate())
+	assert.Error(t, RepositoryAccess{Teams: map[string]string{"team": "owner"}}.Validate())
+}
+
+func TestReconcileRepositoryAccess(t *testing.T) {
+	tests := []struct {
+		name                 string
+		repo                 github.Repository
+		collaboratorRoles    map[string]string
+		teamRoles            map[string]string
+		access               RepositoryAccess
+		revokedCollaborators []string
+		revokedTeams         []string
+		wantChanges          []string
+		wantRequests         []string
+		wantErr              string
+	}{
+		{
+			name:              "No drift",
+			repo:              github.Repository{Private: github.Bool(true), Visibility: github.String("private")},
+			collaboratorRoles: map[string]string{"alice": "write"},
+			teamRoles:         map[string]string{"sre": "maintain"},
+			access: RepositoryAccess{
+				Visibility:    PrivateVisibility,
+				Collaborators: map[string]string{"alice": "push"},
+				Teams:         map[string]string{"sre": "maintain"},
+			},
+		},
+		{
+			name:              "Repository made public and permissions changed out-of-band",
+			repo:              github.Repository{Private: github.Bool(false)},
+			collaboratorRoles: map[string]string{"alice": "admin"},
+			access: RepositoryAccess{
+				Visibility:    PrivateVisibility,
+				Collaborators: map[string]string{"alice": "write", "bob": "read"},
+				Teams:         map[string]string{"sre": "maintain"},
+			},
+			wantChanges: []string{"collaborator alice write", "collaborator bob read", "team sre maintain", "visibility private"},
+			wantRequests: []string{
+				"PATCH /repos/test-org/test-repo {\"private\":true,\"visibility\":\"private\"}",
+				"PUT /orgs/test-org/teams/sre/repos/test-org/test-repo {\"permission\":\"maintain\"}",
+				"PUT /repos/test-org/test-repo/collaborators/alice {\"permission\":\"push\"}",
+				"PUT /repos/test-org/test-repo/collaborators/bob {\"permission\":\"pull\"}",
+			},
+		},
+		{
+			name:                 "Grants removed from the Application are revoked",
+			repo:                 github.Repository{Private: github.Bool(true), Visibility: github.String("private")},
+			revokedCollaborators: []string{"alice"},
+			revokedTeams:         []string{"sre"},
+			wantChanges:          []string{"collaborator alice revoked", "team sre revoked"},
+			wantRequests: []string{
+				"DELETE /orgs/test-org/teams/sre/repos/test-org/test-repo ",
+				"DELETE /repos/test-org/test-repo/collaborators/alice ",
+			},
+		},
+		{
+			name:    "Repository does not exist",
+			access:  RepositoryAccess{Visibility: PrivateVisibility},
+			wantErr: "failed to get repo test-repo under test-org",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			var mu sync.Mutex
+			record := func(req *http.Request) {
+				b, _ := ioutil.ReadAll(req.Body)
+				mu.Lock()
+				defer mu.Unlock()
+				requests = append(requests, req.Method+" "+req.URL.Path+" "+strings.TrimSpace(string(b)))
+			}
+			noContent := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				record(req)
+				w.WriteHeader(http.StatusNoContent)
+			})
+			mockedHTTPClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+						if tt.repo.Private == nil {
+							mock.WriteError(w, http.StatusNotFound, "Not Found")
+							return
+						}
+						w.Write(mock.MustMarshal(tt.repo))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PatchReposByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						record(req)
+						w.Write(mock.MustMarshal(tt.repo))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.GetReposCollaboratorsPermissionByOwnerByRepoByUsername,
+					http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						user := req.URL.Path[strings.LastIndex(strings.TrimSuffix(req.URL.Path, "/permission"), "/")+1 : len(req.URL.Path)-len("/permission")]
+						roleName, ok := tt.collaboratorRoles[user]
+						if !ok {
+							w.Write(mock.MustMarshal(github.RepositoryPermissionLevel{Permission: github.String("none")}))
+							return
+						}
+						w.Write(mock.MustMarshal(github.RepositoryPermissionLevel{Permission: github.String(roleName), User: &github.User{RoleName: github.String(roleName)}}))
+					}),
+				),
+				mock.WithRequestMatchHandler(mock.PutReposCollaboratorsByOwnerByRepoByUsername, noContent),
+				mock.WithRequestMatchHandler(mock.DeleteReposCollaboratorsByOwnerByRepoByUsername, noContent),
+				mock.WithRequestMatchHandler(
+					mock.GetOrgsTeamsReposByOrgByTeamSlugByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						team := strings.Split(req.URL.Path, "/")[4]
+						roleName, ok := tt.teamRoles[team]
+						if !ok {
+							mock.WriteError(w, http.StatusNotFound, "Not Found")
+							return
+						}
+						w.Write(mock.MustMarshal(github.Repository{RoleName: github.String(roleName)}))
+					}),
+				),
+				mock.WithRequestMatchHandler(mock.PutOrgsTeamsReposByOrgByTeamSlugByOwnerByRepo, noContent),
+				mock.WithRequestMatchHandler(mock.DeleteOrgsTeamsReposByOrgByTeamSlugByOwnerByRepo, noContent),
+			)
+			client := GitHubClient{Client: github.NewClient(mockedHTTPClient)}
+
+			changes, err := client.ReconcileRepositoryAccess(context.Background(), "test-org", "test-repo", tt.access, tt.revokedCollaborators, tt.revokedTeams)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantChanges, changes)
+			assert.ElementsMatch(t, tt.wantRequests, requests)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v52/github"
+)
+
+// ArchivedTopic is the topic set on the GitOps repositories that were archived when their Application was deleted
+const ArchivedTopic = "appstudio-archived"
+
+// deleteAfterTopicPrefix prefixes the topic recording the Unix time after which an archived repository can be deleted
+const deleteAfterTopicPrefix = "appstudio-delete-after-"
+
+// ArchiveRepository marks the repository with the archived topic and archives it, making it read-only. If deleteAfter is set,
+// the time after which the repository can be deleted is recorded in a topic too. Topics can't be changed once a repository
+// is archived, so they are set first
+func (g *GitHubClient) ArchiveRepository(ctx context.Context, orgName string, repoName string, deleteAfter time.Time) error {
+	topics, _, err := g.Client.Repositories.ListAllTopics(ctx, orgName, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get the topics of repo %s under %s, error: %v", repoName, orgName, err)
+	}
+	var archiveTopics []string
+	for _, topic := range topics {
+		if topic != ArchivedTopic && !strings.HasPrefix(topic, deleteAfterTopicPrefix) {
+			archiveTopics = append(archiveTopics, topic)
+		}
+	}
+	archiveTopics = append(archiveTopics, ArchivedTopic)
+	if !deleteAfter.IsZero() {
+		archiveTopics = append(archiveTopics, deleteAfterTopicPrefix+strconv.FormatInt(deleteAfter.Unix(), 10))
+	}
+	if _, _, err := g.Client.Repositories.ReplaceAllTopics(ctx, orgName, repoName, archiveTopics); err != nil {
+		return fmt.Errorf("failed to set the topics of repo %s under %s, error: %v", repoName, orgName, err)
+	}
+
+	if _, _, err := g.Client.Repositories.Edit(ctx, orgName, repoName, &github.Repository{Archived: github.Bool(true)}); err != nil {
+		return fmt.Errorf("failed to archive repo %s under %s, error: %v", repoName, orgName, err)
+	}
+	return nil
+}
+
+// ListExpiredArchivedRepositories returns the names of the archived repositories of the org whose retention window ended before now.
+// Archived repositories without a delete-after topic are retained indefinitely and are never returned
+func (g *GitHubClient) ListExpiredArchivedRepositories(ctx context.Context, orgName string, now time.Time) ([]string, error) {
+	var expired []string
+	opts := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		repos, resp, err := g.Client.Repositories.ListByOrg(ctx, orgName, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the repos under %s, error: %v", orgName, err)
+		}
+		for _, repo := range repos {
+			if !repo.GetArchived() {
+				continue
+			}
+			if deleteAfter, ok := getDeleteAfter(repo.Topics); ok && deleteAfter.Before(now) {
+				expired = append(expired, repo.GetName())
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return expired, nil
+}
+
+// getDeleteAfter returns the time recorded in the delete-after topic of an archived repository, false if the repository
+// is not marked as archived by the controller or has no delete-after topic
+func getDeleteAfter(topics []string) (time.Time, bool) {
+	var archived bool
+	var deleteAfter time.Time
+	for _, topic := range topics {
+		if topic == ArchivedTopic {
+			archived = true
+		} else if strings.HasPrefix(topic, deleteAfterTopicPrefix) {
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(topic, deleteAfterTopicPrefix), 10, 64)
+			if err != nil {
+				return time.Time{}, false
+			}
+			deleteAfter = time.Unix(seconds, 0)
+		}
+	}
+	return deleteAfter, archived && !deleteAfter.IsZero()
+}
@@ -0,0 +1,128 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v52/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveRepository(t *testing.T) {
+	tests := []struct {
+		name         string
+		repoName     string
+		deleteAfter  time.Time
+		wantRequests []string
+		wantErr      string
+	}{
+		{
+			name:     "Archive and retain indefinitely",
+			repoName: "test-repo",
+			wantRequests: []string{
+				"PUT /repos/test-org/test-repo/topics {\"names\":[\"gitops\",\"appstudio-archived\"]}",
+				"PATCH /repos/test-org/test-repo {\"archived\":true}",
+			},
+		},
+		{
+			name:        "Archive with a retention window",
+			repoName:    "test-repo",
+			deleteAfter: time.Unix(1700000000, 0),
+			wantRequests: []string{
+				"PUT /repos/test-org/test-repo/topics {\"names\":[\"gitops\",\"appstudio-archived\",\"appstudio-delete-after-1700000000\"]}",
+				"PATCH /repos/test-org/test-repo {\"archived\":true}",
+			},
+		},
+		{
+			name:     "Repository does not exist",
+			repoName: "missing-repo",
+			wantErr:  "failed to get the topics of repo missing-repo under test-org",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			record := func(w http.ResponseWriter, req *http.Request) {
+				b, _ := ioutil.ReadAll(req.Body)
+				requests = append(requests, req.Method+" "+req.URL.Path+" "+strings.TrimSpace(string(b)))
+				w.Write(b)
+			}
+			mockedHTTPClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposTopicsByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						if strings.Contains(req.URL.Path, "missing-repo") {
+							mock.WriteError(w, http.StatusNotFound, "Not Found")
+							return
+						}
+						// A previous delete-after topic is replaced
+						w.Write(mock.MustMarshal(map[string][]string{"names": {"gitops", "appstudio-delete-after-1"}}))
+					}),
+				),
+				mock.WithRequestMatchHandler(mock.PutReposTopicsByOwnerByRepo, http.HandlerFunc(record)),
+				mock.WithRequestMatchHandler(mock.PatchReposByOwnerByRepo, http.HandlerFunc(record)),
+			)
+			client := GitHubClient{Client: github.NewClient(mockedHTTPClient)}
+
+			err := client.ArchiveRepository(context.Background(), "test-org", tt.repoName, tt.deleteAfter)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestListExpiredArchivedRepositories(t *testing.T) {
+	client := GitHubClient{Client: GetMockedClient()}
+
+	expired, err := client.ListExpiredArchivedRepositories(context.Background(), "test-org", time.Now())
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	// Repositories that aren't archived, or are archived without a retention window, are never expired
+	assert.Equal(t, []string{"test-archived-repo-1"}, expired)
+
+	expired, err = client.ListExpiredArchivedRepositories(context.Background(), "test-org", time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+	assert.Empty(t, expired)
+}
+
+func TestGetDeleteAfter(t *testing.T) {
+	deleteAfter, ok := getDeleteAfter([]string{ArchivedTopic, "appstudio-delete-after-1700000000"})
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(1700000000, 0), deleteAfter)
+
+	_, ok = getDeleteAfter([]string{"appstudio-delete-after-1700000000"})
+	assert.False(t, ok, "repositories not archived by the controller are never deleted")
+
+	_, ok = getDeleteAfter([]string{ArchivedTopic, "appstudio-delete-after-soon"})
+	assert.False(t, ok)
+}
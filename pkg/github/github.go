@@ -61,17 +61,24 @@ func (e *ServerError) Error() string {
 
 // GenerateNewRepositoryName creates a new gitops repository name, based on the following format:
 // <display-name>-<partial-hash-of-clustername-and-namespace>-<random-word>-<random-word>
+//
+// Deprecated: the names are random, use a RepositoryNamingPolicy with the template "{application}-{hash}-{random}-{random}" instead
 func GenerateNewRepositoryName(displayName, uniqueHash string) string {
 	sanitizedName := util.SanitizeName(displayName)
 	repoName := sanitizedName + "-" + uniqueHash + "-" + util.SanitizeName(gofakeit.Verb()) + "-" + util.SanitizeName(gofakeit.Verb())
 	return repoName
 }
 
-func (g *GitHubClient) GenerateNewRepository(ctx context.Context, orgName string, repoName string, description string) (string, error) {
-	isPrivate := false
+// GenerateNewRepository creates a repository in the given org with the given visibility, a private repository if no visibility is given
+func (g *GitHubClient) GenerateNewRepository(ctx context.Context, orgName string, repoName string, description string, visibility RepositoryVisibility) (string, error) {
+	if visibility == "" {
+		visibility = PrivateVisibility
+	}
+	isPrivate := visibility != PublicVisibility
+	repoVisibility := string(visibility)
 	appStudioAppDataURL := "https://github.com/" + orgName + "/"
 	metrics.GitOpsRepoCreationTotalReqs.Inc()
-	r := &github.Repository{Name: &repoName, Private: &isPrivate, Description: &description}
+	r := &github.Repository{Name: &repoName, Private: &isPrivate, Visibility: &repoVisibility, Description: &description}
 	_, resp, err := g.Client.Repositories.Create(ctx, orgName, r)
 
 	if resp != nil && 500 <= resp.StatusCode && resp.StatusCode <= 599 {
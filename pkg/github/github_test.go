@@ -17,10 +17,15 @@ package github
 
 import (
 	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/go-github/v52/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/redhat-appstudio/application-service/pkg/metrics"
@@ -162,7 +167,7 @@ func TestGenerateNewRepository(t *testing.T) {
 		Clients["mock"].SecondaryRateLimit.mu.Lock()
 
 		t.Run(tt.name, func(t *testing.T) {
-			repoURL, err := mockedClient.GenerateNewRepository(tt.ctx, tt.orgName, tt.repoName, "")
+			repoURL, err := mockedClient.GenerateNewRepository(tt.ctx, tt.orgName, tt.repoName, "", PrivateVisibility)
 
 			if err != nil && tt.wantErr {
 				if _, ok := err.(*ServerError); ok {
@@ -208,6 +213,58 @@ func TestGenerateNewRepository(t *testing.T) {
 	assert.Equal(t, float64(numTests), testutil.ToFloat64(metrics.GitOpsRepoCreationTotalReqs))
 }
 
+// TestGenerateNewRepositoryVisibility runs after TestGenerateNewRepository, which counts the repositories created by the tests
+func TestGenerateNewRepositoryVisibility(t *testing.T) {
+	tests := []struct {
+		name        string
+		visibility  RepositoryVisibility
+		wantPrivate bool
+	}{
+		{
+			name:        "No visibility creates a private repository",
+			wantPrivate: true,
+		},
+		{
+			name:        "Internal repository",
+			visibility:  InternalVisibility,
+			wantPrivate: true,
+		},
+		{
+			name:       "Public repository",
+			visibility: PublicVisibility,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var createdRepo github.Repository
+			mockedHTTPClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostOrgsReposByOrg,
+					http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						b, _ := ioutil.ReadAll(req.Body)
+						assert.NoError(t, json.Unmarshal(b, &createdRepo))
+						/* #nosec G104 -- test code */
+						w.Write(b)
+					}),
+				),
+			)
+			client := GitHubClient{Client: github.NewClient(mockedHTTPClient)}
+
+			_, err := client.GenerateNewRepository(context.Background(), "test-org", "test-repo", "GitOps Repository", tt.visibility)
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantPrivate, createdRepo.GetPrivate())
+			wantVisibility := tt.visibility
+			if wantVisibility == "" {
+				wantVisibility = PrivateVisibility
+			}
+			assert.Equal(t, string(wantVisibility), createdRepo.GetVisibility())
+		})
+	}
+}
+
 func TestDeleteRepository(t *testing.T) {
 	tests := []struct {
 		name     string
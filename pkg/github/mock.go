@@ -16,8 +16,10 @@
 package github
 
 import (
+	"encoding/base64"
 	"io/ioutil"
 	"net/http"
+	"path"
 	"strings"
 
 	"github.com/google/go-github/v52/github"
@@ -122,6 +124,11 @@ func GetMockedClient() *github.Client {
 						http.StatusInternalServerError,
 						"github went belly up or something",
 					)
+				} else if strings.Contains(req.RequestURI, "missing-repo") {
+					mock.WriteError(w,
+						http.StatusNotFound,
+						"Not Found",
+					)
 				} else if strings.Contains(req.RequestURI, "multi-component-dockerfile-deep") {
 					w.Write(mock.MustMarshal(github.Repository{
 						Name:          github.String("multi-component-dockerfile-deep"),
@@ -138,10 +145,190 @@ func GetMockedClient() *github.Client {
 					w.Write(mock.MustMarshal(github.Repository{
 						Name:          github.String("test-repo-1"),
 						DefaultBranch: github.String("main"),
+						Private:       github.Bool(true),
+						Visibility:    github.String("private"),
+						Permissions:   map[string]bool{"admin": true, "push": true, "pull": true},
+					}))
+				}
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				b, _ := ioutil.ReadAll(req.Body)
+				/* #nosec G104 -- test code */
+				w.Write(b)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposTransferByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				/* #nosec G104 -- test code */
+				w.Write(mock.MustMarshal(github.Repository{
+					Name: github.String("test-repo-1"),
+				}))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposTopicsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if strings.Contains(req.RequestURI, "test-error-response") {
+					mock.WriteError(w,
+						http.StatusInternalServerError,
+						"github went belly up or something",
+					)
+				} else {
+					/* #nosec G104 -- test code */
+					w.Write(mock.MustMarshal(map[string][]string{"names": {"gitops"}}))
+				}
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PutReposTopicsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				b, _ := ioutil.ReadAll(req.Body)
+				/* #nosec G104 -- test code */
+				w.Write(b)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetOrgsReposByOrg,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				/* #nosec G104 -- test code */
+				w.Write(mock.MustMarshal([]github.Repository{
+					{
+						Name:   github.String("test-repo-1"),
+						Topics: []string{"gitops"},
+					},
+					{
+						Name:     github.String("test-archived-repo-1"),
+						Archived: github.Bool(true),
+						Topics:   []string{"gitops", ArchivedTopic, deleteAfterTopicPrefix + "1"},
+					},
+					{
+						Name:     github.String("test-archived-repo-2"),
+						Archived: github.Bool(true),
+						Topics:   []string{ArchivedTopic},
+					},
+				}))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposCollaboratorsPermissionByOwnerByRepoByUsername,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if strings.Contains(req.RequestURI, "test-error-response") {
+					mock.WriteError(w,
+						http.StatusInternalServerError,
+						"github went belly up or something",
+					)
+				} else {
+					/* #nosec G104 -- test code */
+					w.Write(mock.MustMarshal(github.RepositoryPermissionLevel{
+						Permission: github.String("write"),
+						User:       &github.User{RoleName: github.String("write")},
+					}))
+				}
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PutReposCollaboratorsByOwnerByRepoByUsername,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.DeleteReposCollaboratorsByOwnerByRepoByUsername,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetOrgsTeamsReposByOrgByTeamSlugByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if strings.Contains(req.RequestURI, "test-error-response") {
+					mock.WriteError(w,
+						http.StatusInternalServerError,
+						"github went belly up or something",
+					)
+				} else {
+					/* #nosec G104 -- test code */
+					w.Write(mock.MustMarshal(github.Repository{
+						Name:     github.String("test-repo-1"),
+						RoleName: github.String("maintain"),
 					}))
 				}
 			}),
 		),
+		mock.WithRequestMatchHandler(
+			mock.PutOrgsTeamsReposByOrgByTeamSlugByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.DeleteOrgsTeamsReposByOrgByTeamSlugByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposGitRefByOwnerByRepoByRef,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				/* #nosec G104 -- test code */
+				w.Write(mock.MustMarshal(github.Reference{
+					Ref:    github.String("refs/heads/main"),
+					Object: &github.GitObject{SHA: github.String("ca82a6dff817ec66f44342007202690a93763949")},
+				}))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposGitRefsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				b, _ := ioutil.ReadAll(req.Body)
+				w.WriteHeader(http.StatusCreated)
+				/* #nosec G104 -- test code */
+				w.Write(b)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if strings.Contains(req.RequestURI, "missing-context") || strings.Contains(req.RequestURI, "missing-file") {
+					mock.WriteError(w,
+						http.StatusNotFound,
+						"Not Found",
+					)
+				} else if strings.Contains(req.URL.Path, ".yaml") {
+					/* #nosec G104 -- test code */
+					w.Write(mock.MustMarshal(github.RepositoryContent{
+						Name:     github.String(path.Base(req.URL.Path)),
+						Type:     github.String("file"),
+						Encoding: github.String("base64"),
+						Content:  github.String(base64.StdEncoding.EncodeToString([]byte("schemaVersion: 2.2.0\n"))),
+						SHA:      github.String("3d21ec53a331a6f037a91c368710b99387d012c1"),
+					}))
+				} else {
+					/* #nosec G104 -- test code */
+					w.Write(mock.MustMarshal([]github.RepositoryContent{
+						{
+							Name: github.String("kustomization.yaml"),
+							Type: github.String("file"),
+						},
+					}))
+				}
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PutReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				/* #nosec G104 -- test code */
+				w.Write(mock.MustMarshal(github.RepositoryContentResponse{
+					Commit: github.Commit{SHA: github.String("ca82a6dff817ec66f44342007202690a93763949")},
+				}))
+			}),
+		),
 		mock.WithRequestMatchHandler(
 			mock.GetReposBranchesByOwnerByRepoByBranch,
 			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -150,7 +337,12 @@ func GetMockedClient() *github.Client {
 					w.Write(mock.MustMarshal(github.Branch{
 						Name: github.String("master"),
 					}))
-				} else if strings.Contains(req.RequestURI, "test-repo-1") && strings.Contains(req.RequestURI, "main") {
+				} else if strings.Contains(req.RequestURI, "missing-branch") {
+					mock.WriteError(w,
+						http.StatusNotFound,
+						"Branch not found",
+					)
+				} else if (strings.Contains(req.RequestURI, "test-repo-1") || strings.Contains(req.RequestURI, "petclinic-gitops")) && strings.Contains(req.RequestURI, "main") {
 					/* #nosec G104 -- test code */
 					w.Write(mock.MustMarshal(github.Branch{
 						Name: github.String("main"),
@@ -0,0 +1,100 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v52/github"
+)
+
+// MoveRepository renames the repository and transfers it to another org if newOrgName differs from orgName, and returns the URL of the
+// moved repository. Each step is skipped if it was already done, so that a move interrupted by an error can be resumed.
+// GitHub transfers repositories asynchronously, so renaming a transferred repository fails until the transfer completes.
+// An error is returned if another repository already has the new name in the new org
+func (g *GitHubClient) MoveRepository(ctx context.Context, orgName string, repoName string, newOrgName string, newRepoName string) (string, error) {
+	newRepoURL := "https://github.com/" + newOrgName + "/" + newRepoName
+
+	newRepo, err := g.getRepository(ctx, newOrgName, newRepoName)
+	if err != nil {
+		return "", err
+	}
+	if newRepo != nil {
+		// GitHub redirects the requests for a moved repository to its new location, so the repository was already moved
+		// if it resolves to the new repository, or if it no longer exists
+		repo, err := g.getRepository(ctx, orgName, repoName)
+		if err != nil {
+			return "", err
+		}
+		if repo == nil || repo.GetID() == newRepo.GetID() {
+			return newRepoURL, nil
+		}
+		return "", fmt.Errorf("failed to move repo %s under %s, the name %s is already taken under %s", repoName, orgName, newRepoName, newOrgName)
+	}
+
+	if orgName != newOrgName {
+		repo, err := g.getRepository(ctx, orgName, repoName)
+		if err != nil {
+			return "", err
+		}
+		// The repository was already transferred if it no longer exists in its org
+		if repo != nil {
+			_, _, err := g.Client.Repositories.Transfer(ctx, orgName, repoName, github.TransferRequest{NewOwner: newOrgName})
+			var acceptedError *github.AcceptedError
+			if err != nil && !errors.As(err, &acceptedError) {
+				return "", fmt.Errorf("failed to transfer repo %s under %s to %s, error: %v", repoName, orgName, newOrgName, err)
+			}
+		}
+	}
+
+	if repoName != newRepoName {
+		if _, _, err := g.Client.Repositories.Edit(ctx, newOrgName, repoName, &github.Repository{Name: github.String(newRepoName)}); err != nil {
+			return "", fmt.Errorf("failed to rename repo %s under %s to %s, error: %v", repoName, newOrgName, newRepoName, err)
+		}
+	}
+	return newRepoURL, nil
+}
+
+// getRepository returns the repository of the org, or nil if it doesn't exist
+func (g *GitHubClient) getRepository(ctx context.Context, orgName string, repoName string) (*github.Repository, error) {
+	repo, resp, err := g.Client.Repositories.Get(ctx, orgName, repoName)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get repo %s under %s, error: %v", repoName, orgName, err)
+	}
+	return repo, nil
+}
+
+// ParseMigrationOrgs returns the GitHub orgs listed, comma-separated, in orgs that the generated repositories can be moved to.
+// The repositories can only be moved within defaultOrg, the org of the controller, if none is listed
+func ParseMigrationOrgs(orgs string, defaultOrg string) []string {
+	var migrationOrgs []string
+	for _, org := range strings.Split(orgs, ",") {
+		if org = strings.TrimSpace(org); org != "" {
+			migrationOrgs = append(migrationOrgs, org)
+		}
+	}
+	if len(migrationOrgs) == 0 {
+		migrationOrgs = []string{defaultOrg}
+	}
+	return migrationOrgs
+}
@@ -0,0 +1,190 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v52/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveRepository(t *testing.T) {
+	tests := []struct {
+		name         string
+		repos        map[string]int64
+		newOrgName   string
+		newRepoName  string
+		wantRequests []string
+		wantErr      string
+	}{
+		{
+			name:         "Rename",
+			repos:        map[string]int64{"test-org/test-repo": 1},
+			newOrgName:   "test-org",
+			newRepoName:  "petclinic",
+			wantRequests: []string{"PATCH /repos/test-org/test-repo {\"name\":\"petclinic\"}"},
+		},
+		{
+			name:        "Transfer and rename",
+			repos:       map[string]int64{"test-org/test-repo": 1},
+			newOrgName:  "other-org",
+			newRepoName: "petclinic",
+			wantRequests: []string{
+				"POST /repos/test-org/test-repo/transfer {\"new_owner\":\"other-org\"}",
+				"PATCH /repos/other-org/test-repo {\"name\":\"petclinic\"}",
+			},
+		},
+		{
+			name:         "Transfer already done, the repository is renamed",
+			repos:        map[string]int64{"other-org/test-repo": 1},
+			newOrgName:   "other-org",
+			newRepoName:  "petclinic",
+			wantRequests: []string{"PATCH /repos/other-org/test-repo {\"name\":\"petclinic\"}"},
+		},
+		{
+			name:        "Move already done",
+			repos:       map[string]int64{"other-org/petclinic": 1},
+			newOrgName:  "other-org",
+			newRepoName: "petclinic",
+		},
+		{
+			name:        "Move already done, GitHub redirects to the moved repository",
+			repos:       map[string]int64{"test-org/test-repo": 1, "other-org/petclinic": 1},
+			newOrgName:  "other-org",
+			newRepoName: "petclinic",
+		},
+		{
+			name:        "Name taken by another repository",
+			repos:       map[string]int64{"test-org/test-repo": 1, "other-org/petclinic": 2},
+			newOrgName:  "other-org",
+			newRepoName: "petclinic",
+			wantErr:     "failed to move repo test-repo under test-org, the name petclinic is already taken under other-org",
+		},
+		{
+			name:        "Name taken by another repository in the same org",
+			repos:       map[string]int64{"test-org/test-repo": 1, "test-org/petclinic": 2},
+			newOrgName:  "test-org",
+			newRepoName: "petclinic",
+			wantErr:     "failed to move repo test-repo under test-org, the name petclinic is already taken under test-org",
+		},
+		{
+			name:        "Repository missing",
+			newOrgName:  "test-org",
+			newRepoName: "petclinic",
+			wantErr:     "failed to rename repo test-repo under test-org to petclinic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			repos := make(map[string]int64)
+			for repo, id := range tt.repos {
+				repos[repo] = id
+			}
+			notFound := func(w http.ResponseWriter, req *http.Request) bool {
+				parts := strings.Split(req.URL.Path, "/")
+				if _, ok := repos[parts[2]+"/"+parts[3]]; !ok {
+					mock.WriteError(w, http.StatusNotFound, "Not Found")
+					return true
+				}
+				return false
+			}
+			record := func(req *http.Request) {
+				b, _ := ioutil.ReadAll(req.Body)
+				requests = append(requests, req.Method+" "+req.URL.Path+" "+strings.TrimSpace(string(b)))
+			}
+			mockedHTTPClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						if !notFound(w, req) {
+							parts := strings.Split(req.URL.Path, "/")
+							w.Write(mock.MustMarshal(github.Repository{ID: github.Int64(repos[parts[2]+"/"+parts[3]]), Name: github.String(parts[3])}))
+						}
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposTransferByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						record(req)
+						// The transfer completes before the rename in this mock
+						repos["other-org/test-repo"] = repos["test-org/test-repo"]
+						delete(repos, "test-org/test-repo")
+						w.WriteHeader(http.StatusAccepted)
+						w.Write(mock.MustMarshal(github.Repository{Name: github.String("test-repo")}))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PatchReposByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						record(req)
+						if !notFound(w, req) {
+							w.Write(mock.MustMarshal(github.Repository{Name: github.String("petclinic")}))
+						}
+					}),
+				),
+			)
+			client := GitHubClient{Client: github.NewClient(mockedHTTPClient)}
+
+			repoURL, err := client.MoveRepository(context.Background(), "test-org", "test-repo", tt.newOrgName, tt.newRepoName)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, "https://github.com/"+tt.newOrgName+"/"+tt.newRepoName, repoURL)
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestParseMigrationOrgs(t *testing.T) {
+	tests := []struct {
+		name string
+		orgs string
+		want []string
+	}{
+		{
+			name: "No orgs defaults to the org of the controller",
+			want: []string{"controller-org"},
+		},
+		{
+			name: "Blank orgs default to the org of the controller",
+			orgs: " , ",
+			want: []string{"controller-org"},
+		},
+		{
+			name: "Listed orgs",
+			orgs: "controller-org, other-org,,third-org ",
+			want: []string{"controller-org", "other-org", "third-org"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseMigrationOrgs(tt.orgs, "controller-org"))
+		})
+	}
+}
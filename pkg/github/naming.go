@@ -0,0 +1,223 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/google/go-github/v52/github"
+)
+
+const (
+	// DefaultRepositoryNameTemplate is the template of the names of generated repositories if no template is configured
+	DefaultRepositoryNameTemplate = "{namespace}-{application}"
+
+	// MaxRepositoryNameLength is the maximum length of the name of a GitHub repository
+	MaxRepositoryNameLength = 100
+
+	// maxRepositoryNameAttempts is the number of names tried for a repository before giving up when they are all taken
+	maxRepositoryNameAttempts = 10
+
+	// maxRandomWordAttempts is the number of random words drawn for a placeholder before giving up when they are all blocked
+	maxRandomWordAttempts = 10
+)
+
+var (
+	// repositoryNamePlaceholder matches the placeholders of a repository naming template
+	repositoryNamePlaceholder = regexp.MustCompile(`{[^{}]*}`)
+
+	// invalidRepositoryNameChars matches the sequences of characters that are not allowed in a repository name
+	invalidRepositoryNameChars = regexp.MustCompile(`[^a-z0-9._]+`)
+)
+
+// RepositoryNameValues are the values substituted for the placeholders of a repository naming template
+type RepositoryNameValues struct {
+	// Namespace is substituted for {namespace}
+	Namespace string
+	// Application is substituted for {application}, the name of the Application
+	Application string
+	// DisplayName is substituted for {displayName}, the display name of the Application
+	DisplayName string
+	// Hash is substituted for {hash}, a hash of the namespace
+	Hash string
+}
+
+// RepositoryNamingPolicy names the generated repositories after a template, e.g. "{namespace}-{application}", with the
+// placeholders {namespace}, {application}, {displayName}, {hash} and {random}, a random word. Names are lowercased, their
+// invalid characters replaced with "-", the words of the blocked list removed, and they are truncated to MaxLength
+type RepositoryNamingPolicy struct {
+	// Template is the template of the names, DefaultRepositoryNameTemplate if empty
+	Template string
+	// MaxLength is the maximum length of the names, MaxRepositoryNameLength if 0
+	MaxLength int
+	// BlockedWords are the words that never appear in the names
+	BlockedWords []string
+	// RandomWord returns the words substituted for {random}, random verbs if nil
+	RandomWord func() string
+}
+
+// ParseRepositoryNamingPolicy returns the naming policy with the given template, maximum length and comma-separated blocked words,
+// the default policy if they are empty
+func ParseRepositoryNamingPolicy(template string, maxLength string, blockedWords string) (RepositoryNamingPolicy, error) {
+	policy := RepositoryNamingPolicy{Template: template}
+	if maxLength != "" {
+		length, err := strconv.Atoi(maxLength)
+		if err != nil {
+			return policy, fmt.Errorf("invalid repository name maximum length %q, it must be a number", maxLength)
+		}
+		policy.MaxLength = length
+	}
+	for _, word := range strings.Split(blockedWords, ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			policy.BlockedWords = append(policy.BlockedWords, word)
+		}
+	}
+	return policy, policy.Validate()
+}
+
+// Validate returns an error if the template uses an unknown placeholder, or if the maximum length exceeds the limit of GitHub
+func (p RepositoryNamingPolicy) Validate() error {
+	for _, placeholder := range repositoryNamePlaceholder.FindAllString(p.Template, -1) {
+		switch placeholder {
+		case "{namespace}", "{application}", "{displayName}", "{hash}", "{random}":
+		default:
+			return fmt.Errorf("invalid repository naming template %q, unknown placeholder %s", p.Template, placeholder)
+		}
+	}
+	if p.MaxLength < 0 || p.MaxLength > MaxRepositoryNameLength {
+		return fmt.Errorf("invalid repository name maximum length %d, it must be between 1 and %d", p.MaxLength, MaxRepositoryNameLength)
+	}
+	return nil
+}
+
+// Name returns the name of the repository for the given values. The name is the same for the same values unless
+// the template uses {random}
+func (p RepositoryNamingPolicy) Name(values RepositoryNameValues) string {
+	template := p.Template
+	if template == "" {
+		template = DefaultRepositoryNameTemplate
+	}
+	name := repositoryNamePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		switch placeholder {
+		case "{namespace}":
+			return values.Namespace
+		case "{application}":
+			return values.Application
+		case "{displayName}":
+			return values.DisplayName
+		case "{hash}":
+			return values.Hash
+		case "{random}":
+			return p.randomWord()
+		}
+		return placeholder
+	})
+
+	var words []string
+	for _, word := range strings.Split(invalidRepositoryNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-") {
+		if word != "" && !p.isBlocked(word) {
+			words = append(words, word)
+		}
+	}
+	return truncateRepositoryName(strings.Join(words, "-"), p.maxLength())
+}
+
+// IsDeterministic returns true if the policy always gives the same name for the same values, that is if its template doesn't use {random}
+func (p RepositoryNamingPolicy) IsDeterministic() bool {
+	return !strings.Contains(p.Template, "{random}")
+}
+
+// GenerateNewRepositoryWithPolicy creates a repository in the given org named by the policy for the given values, and returns its URL.
+// A name that is taken in the org is suffixed with "-2", "-3" and so on, and an error is returned if none of the first names are available
+func (g *GitHubClient) GenerateNewRepositoryWithPolicy(ctx context.Context, orgName string, policy RepositoryNamingPolicy, values RepositoryNameValues, description string, visibility RepositoryVisibility) (string, error) {
+	name := policy.Name(values)
+	if name == "" {
+		return "", fmt.Errorf("the repository naming template %q gives an empty name", policy.Template)
+	}
+	for attempt := 1; attempt <= maxRepositoryNameAttempts; attempt++ {
+		candidate := name
+		if attempt > 1 {
+			suffix := "-" + strconv.Itoa(attempt)
+			candidate = truncateRepositoryName(name, policy.maxLength()-len(suffix)) + suffix
+		}
+		repoURL, err := g.GenerateNewRepository(ctx, orgName, candidate, description, visibility)
+		if !isNameTakenError(err) {
+			return repoURL, err
+		}
+	}
+	return "", fmt.Errorf("unable to find an available repository name for %s under %s after %d attempts", name, orgName, maxRepositoryNameAttempts)
+}
+
+// isNameTakenError returns true if the error was returned by GitHub because a repository with the same name already exists
+func isNameTakenError(err error) bool {
+	var errorResponse *github.ErrorResponse
+	if !errors.As(err, &errorResponse) || errorResponse.Response == nil || errorResponse.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, e := range errorResponse.Errors {
+		if strings.Contains(e.Message, "already exists") {
+			return true
+		}
+	}
+	return strings.Contains(errorResponse.Message, "already exists")
+}
+
+// maxLength returns the maximum length of the names of the policy
+func (p RepositoryNamingPolicy) maxLength() int {
+	if p.MaxLength == 0 {
+		return MaxRepositoryNameLength
+	}
+	return p.MaxLength
+}
+
+// isBlocked returns true if the word is in the blocked list of the policy
+func (p RepositoryNamingPolicy) isBlocked(word string) bool {
+	for _, blockedWord := range p.BlockedWords {
+		if strings.EqualFold(word, blockedWord) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomWord returns a random word that is not blocked, or an empty string if no such word was drawn
+func (p RepositoryNamingPolicy) randomWord() string {
+	randomWord := p.RandomWord
+	if randomWord == nil {
+		randomWord = gofakeit.Verb
+	}
+	for attempt := 0; attempt < maxRandomWordAttempts; attempt++ {
+		if word := randomWord(); !p.isBlocked(word) {
+			return word
+		}
+	}
+	return ""
+}
+
+// truncateRepositoryName truncates the name to the length, without leaving a trailing "-"
+func truncateRepositoryName(name string, length int) string {
+	if len(name) > length {
+		name = name[:length]
+	}
+	return strings.TrimRight(name, "-")
+}
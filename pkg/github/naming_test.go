@@ -0,0 +1,225 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v52/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRepositoryNamingPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		template     string
+		maxLength    string
+		blockedWords string
+		want         RepositoryNamingPolicy
+		wantErr      string
+	}{
+		{
+			name: "Default policy",
+			want: RepositoryNamingPolicy{},
+		},
+		{
+			name:         "Template, maximum length and blocked words",
+			template:     "{application}-{hash}-{random}",
+			maxLength:    "40",
+			blockedWords: "kill, hate,,",
+			want: RepositoryNamingPolicy{
+				Template:     "{application}-{hash}-{random}",
+				MaxLength:    40,
+				BlockedWords: []string{"kill", "hate"},
+			},
+		},
+		{
+			name:     "Unknown placeholder",
+			template: "{cluster}-{application}",
+			wantErr:  "invalid repository naming template \"{cluster}-{application}\", unknown placeholder {cluster}",
+		},
+		{
+			name:      "Maximum length is not a number",
+			maxLength: "long",
+			wantErr:   "invalid repository name maximum length \"long\", it must be a number",
+		},
+		{
+			name:      "Maximum length exceeds the limit of GitHub",
+			maxLength: "101",
+			wantErr:   "invalid repository name maximum length 101, it must be between 1 and 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseRepositoryNamingPolicy(tt.template, tt.maxLength, tt.blockedWords)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.want, policy)
+		})
+	}
+}
+
+func TestRepositoryNamingPolicyName(t *testing.T) {
+	values := RepositoryNameValues{
+		Namespace:   "team-a",
+		Application: "petclinic",
+		DisplayName: "Pet Clinic's App",
+		Hash:        "abcde",
+	}
+
+	tests := []struct {
+		name        string
+		policy      RepositoryNamingPolicy
+		randomWords []string
+		want        string
+	}{
+		{
+			name:   "Default template",
+			policy: RepositoryNamingPolicy{},
+			want:   "team-a-petclinic",
+		},
+		{
+			name:   "Display name is sanitized",
+			policy: RepositoryNamingPolicy{Template: "{displayName}_{hash}"},
+			want:   "pet-clinic-s-app_abcde",
+		},
+		{
+			name:        "Random words",
+			policy:      RepositoryNamingPolicy{Template: "{application}-{hash}-{random}-{random}"},
+			randomWords: []string{"dance", "Cook"},
+			want:        "petclinic-abcde-dance-cook",
+		},
+		{
+			name:        "Blocked random words are drawn again",
+			policy:      RepositoryNamingPolicy{Template: "{application}-{random}", BlockedWords: []string{"kill"}},
+			randomWords: []string{"Kill", "jump"},
+			want:        "petclinic-jump",
+		},
+		{
+			name:   "Blocked words are removed",
+			policy: RepositoryNamingPolicy{Template: "{namespace}-{application}", BlockedWords: []string{"a"}},
+			want:   "team-petclinic",
+		},
+		{
+			name:   "Name is truncated to the maximum length",
+			policy: RepositoryNamingPolicy{MaxLength: 7},
+			want:   "team-a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			randomWords := tt.randomWords
+			tt.policy.RandomWord = func() string {
+				word := randomWords[0]
+				randomWords = randomWords[1:]
+				return word
+			}
+
+			assert.Equal(t, tt.want, tt.policy.Name(values))
+			assert.Empty(t, randomWords)
+		})
+	}
+}
+
+func TestGenerateNewRepositoryWithPolicy(t *testing.T) {
+	values := RepositoryNameValues{Namespace: "team-a", Application: "petclinic"}
+
+	tests := []struct {
+		name    string
+		policy  RepositoryNamingPolicy
+		repos   []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "Name available",
+			want: "team-a-petclinic",
+		},
+		{
+			name:  "Name taken",
+			repos: []string{"team-a-petclinic", "team-a-petclinic-2"},
+			want:  "team-a-petclinic-3",
+		},
+		{
+			name:   "Name taken at the maximum length",
+			policy: RepositoryNamingPolicy{MaxLength: 16},
+			repos:  []string{"team-a-petclinic"},
+			want:   "team-a-petclin-2",
+		},
+		{
+			name: "All names taken",
+			repos: []string{"team-a-petclinic", "team-a-petclinic-2", "team-a-petclinic-3", "team-a-petclinic-4", "team-a-petclinic-5",
+				"team-a-petclinic-6", "team-a-petclinic-7", "team-a-petclinic-8", "team-a-petclinic-9", "team-a-petclinic-10"},
+			wantErr: "unable to find an available repository name for team-a-petclinic under test-org after 10 attempts",
+		},
+		{
+			name:    "Empty name",
+			policy:  RepositoryNamingPolicy{Template: "{displayName}"},
+			wantErr: "the repository naming template \"{displayName}\" gives an empty name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := make(map[string]bool)
+			for _, repo := range tt.repos {
+				repos["test-org/"+repo] = true
+			}
+			var requestedNames []string
+			mockedHTTPClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostOrgsReposByOrg,
+					http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						var repo github.Repository
+						if err := json.NewDecoder(req.Body).Decode(&repo); err != nil {
+							t.Fatalf("got unexpected error %v", err)
+						}
+						requestedNames = append(requestedNames, repo.GetName())
+						if repos["test-org/"+repo.GetName()] {
+							WriteError(w, http.StatusUnprocessableEntity, "Repository creation failed.",
+								github.Error{Resource: "Repository", Field: "name", Code: "custom", Message: "name already exists on this account"})
+							return
+						}
+						w.Write(mock.MustMarshal(repo))
+					}),
+				),
+			)
+			client := GitHubClient{Client: github.NewClient(mockedHTTPClient)}
+
+			repoURL, err := client.GenerateNewRepositoryWithPolicy(context.Background(), "test-org", tt.policy, values, "GitOps Repository", PrivateVisibility)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, "https://github.com/test-org/"+tt.want, repoURL)
+			assert.Equal(t, tt.want, requestedNames[len(requestedNames)-1])
+		})
+	}
+}
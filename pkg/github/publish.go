@@ -0,0 +1,71 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v52/github"
+)
+
+// PublishFile commits the content to the file of the repository on the branch, the default branch if empty, unless the file
+// already has this content. The ID of the commit is returned, empty if the file was unchanged
+func (g *GitHubClient) PublishFile(ctx context.Context, repoURL string, branch string, filePath string, content []byte, message string) (string, error) {
+	repoName, orgName, err := GetRepoAndOrgFromURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+	if branch == "" {
+		if branch, err = g.GetDefaultBranchFromURL(repoURL, ctx); err != nil {
+			return "", err
+		}
+	}
+
+	options := &github.RepositoryContentFileOptions{
+		Message: github.String(message),
+		Content: content,
+		Branch:  github.String(branch),
+	}
+	fileContent, directoryContent, resp, err := g.Client.Repositories.GetContents(ctx, orgName, repoName, filePath, &github.RepositoryContentGetOptions{Ref: branch})
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return "", fmt.Errorf("failed to get the file %s from repo %s under %s, error: %v", filePath, repoName, orgName, err)
+	}
+	if directoryContent != nil {
+		return "", fmt.Errorf("the path %s of repo %s under %s is a directory", filePath, repoName, orgName)
+	}
+
+	var contentResponse *github.RepositoryContentResponse
+	if fileContent != nil {
+		currentContent, err := fileContent.GetContent()
+		if err != nil {
+			return "", fmt.Errorf("failed to decode the file %s from repo %s under %s, error: %v", filePath, repoName, orgName, err)
+		}
+		if bytes.Equal([]byte(currentContent), content) {
+			return "", nil
+		}
+		options.SHA = fileContent.SHA
+		contentResponse, _, err = g.Client.Repositories.UpdateFile(ctx, orgName, repoName, filePath, options)
+	} else {
+		contentResponse, _, err = g.Client.Repositories.CreateFile(ctx, orgName, repoName, filePath, options)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to commit the file %s to branch %s of repo %s under %s, error: %v", filePath, branch, repoName, orgName, err)
+	}
+	return contentResponse.Commit.GetSHA(), nil
+}
@@ -0,0 +1,87 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		repoURL      string
+		branch       string
+		filePath     string
+		content      string
+		wantCommitID string
+		wantErr      string
+	}{
+		{
+			name:         "File updated on the default branch",
+			repoURL:      "https://github.com/testorg/test-repo-1",
+			filePath:     "devfile.yaml",
+			content:      "schemaVersion: 2.2.0\nmetadata:\n  name: petclinic\n",
+			wantCommitID: "ca82a6dff817ec66f44342007202690a93763949",
+		},
+		{
+			name:     "File already has the content",
+			repoURL:  "https://github.com/testorg/test-repo-1",
+			branch:   "main",
+			filePath: "devfile.yaml",
+			content:  "schemaVersion: 2.2.0\n",
+		},
+		{
+			name:         "File created",
+			repoURL:      "https://github.com/testorg/test-repo-1",
+			branch:       "main",
+			filePath:     "app/missing-file.yaml",
+			content:      "schemaVersion: 2.2.0\n",
+			wantCommitID: "ca82a6dff817ec66f44342007202690a93763949",
+		},
+		{
+			name:     "Path is a directory",
+			repoURL:  "https://github.com/testorg/test-repo-1",
+			branch:   "main",
+			filePath: "components",
+			wantErr:  "the path components of repo test-repo-1 under testorg is a directory",
+		},
+		{
+			name:     "Unreachable repository",
+			repoURL:  "https://github.com/testorg/test-error-response",
+			filePath: "devfile.yaml",
+			wantErr:  "failed to get repo test-error-response under testorg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedClient := GitHubClient{Client: GetMockedClient()}
+
+			commitID, err := mockedClient.PublishFile(context.Background(), tt.repoURL, tt.branch, tt.filePath, []byte(tt.content), "Update the Application devfile")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantCommitID, commitID)
+		})
+	}
+}
@@ -297,7 +297,7 @@ func TestGetRandomClient(t *testing.T) {
 				Clients = tt.clientPool
 				// Deliberately lock the secondary rate limit object until we need to test the related fields
 				client.SecondaryRateLimit.mu.Lock()
-				_, err := client.GenerateNewRepository(ctx, "test-org", "test-repo", "test description")
+				_, err := client.GenerateNewRepository(ctx, "test-org", "test-repo", "test description", PrivateVisibility)
 				if err == nil {
 					t.Error("TestGetRandomClient() error: expected err not to be nil")
 				}
@@ -0,0 +1,122 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v52/github"
+)
+
+// contextPlaceholder is the file committed to create the context directory of a repository, as Git does not track empty directories
+const contextPlaceholder = ".gitkeep"
+
+// IsGitHubRepository returns true if the repository is hosted on github.com, and can be validated with ValidateRepository
+func IsGitHubRepository(repoURL string) bool {
+	parsedURL, err := url.Parse(repoURL)
+	return err == nil && parsedURL.Host == "github.com"
+}
+
+// ValidateRepository checks that the GitHub repository is reachable and that it can be pushed to, and creates its branch from the
+// default branch and its context directory if they do not exist. The default branch is used if branch is empty.
+// The branch and the changes made to the repository are returned. Only repositories hosted on github.com can be validated
+func (g *GitHubClient) ValidateRepository(ctx context.Context, repoURL string, branch string, repoContext string) (string, []string, error) {
+	var changes []string
+
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid repository URL %q: %v", repoURL, err)
+	}
+	if parsedURL.Host != "github.com" {
+		return "", nil, fmt.Errorf("the repository %s is not hosted on github.com", repoURL)
+	}
+	repoName, orgName, err := GetRepoAndOrgFromURL(repoURL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	repo, resp, err := g.Client.Repositories.Get(ctx, orgName, repoName)
+	if err != nil || repo == nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", nil, fmt.Errorf("the repository %s does not exist or is not reachable", repoURL)
+		}
+		return "", nil, fmt.Errorf("failed to get repo %s under %s, error: %v", repoName, orgName, err)
+	}
+	if repo.GetArchived() {
+		return "", nil, fmt.Errorf("the repository %s is archived and can't be pushed to", repoURL)
+	}
+	if permissions := repo.GetPermissions(); !permissions["push"] && !permissions["admin"] {
+		return "", nil, fmt.Errorf("no write access to the repository %s", repoURL)
+	}
+
+	if branch == "" {
+		branch = repo.GetDefaultBranch()
+	}
+	_, resp, err = g.Client.Repositories.GetBranch(ctx, orgName, repoName, branch, false)
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return branch, changes, fmt.Errorf("failed to get branch %s from repo %s under %s, error: %v", branch, repoName, orgName, err)
+		}
+		if err := g.createBranch(ctx, orgName, repoName, branch, repo.GetDefaultBranch()); err != nil {
+			return branch, changes, err
+		}
+		changes = append(changes, fmt.Sprintf("created branch %s", branch))
+	}
+
+	contextPath := strings.Trim(path.Clean("/"+repoContext), "/")
+	if contextPath == "" {
+		return branch, changes, nil
+	}
+	fileContent, _, resp, err := g.Client.Repositories.GetContents(ctx, orgName, repoName, contextPath, &github.RepositoryContentGetOptions{Ref: branch})
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return branch, changes, fmt.Errorf("failed to get the context %s of repo %s under %s, error: %v", contextPath, repoName, orgName, err)
+		}
+		_, _, err := g.Client.Repositories.CreateFile(ctx, orgName, repoName, path.Join(contextPath, contextPlaceholder), &github.RepositoryContentFileOptions{
+			Message: github.String(fmt.Sprintf("Create the %s context directory", contextPath)),
+			Content: []byte{},
+			Branch:  github.String(branch),
+		})
+		if err != nil {
+			return branch, changes, fmt.Errorf("failed to create the context %s of repo %s under %s, error: %v", contextPath, repoName, orgName, err)
+		}
+		changes = append(changes, fmt.Sprintf("created context directory %s", contextPath))
+	} else if fileContent != nil {
+		return branch, changes, fmt.Errorf("the context %s of the repository %s is a file, not a directory", contextPath, repoURL)
+	}
+	return branch, changes, nil
+}
+
+// createBranch creates the branch from the head of the base branch
+func (g *GitHubClient) createBranch(ctx context.Context, orgName string, repoName string, branch string, baseBranch string) error {
+	baseRef, _, err := g.Client.Git.GetRef(ctx, orgName, repoName, "heads/"+baseBranch)
+	if err != nil || baseRef.GetObject().GetSHA() == "" {
+		return fmt.Errorf("failed to get the head of branch %s from repo %s under %s, error: %v", baseBranch, repoName, orgName, err)
+	}
+	_, _, err = g.Client.Git.CreateRef(ctx, orgName, repoName, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: baseRef.GetObject().SHA},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s from %s in repo %s under %s, error: %v", branch, baseBranch, repoName, orgName, err)
+	}
+	return nil
+}
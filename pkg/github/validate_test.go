@@ -0,0 +1,131 @@
+//
+// Copyright 2023 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRepository(t *testing.T) {
+	tests := []struct {
+		name        string
+		repoURL     string
+		branch      string
+		repoContext string
+		wantBranch  string
+		wantChanges []string
+		wantErr     string
+	}{
+		{
+			name:       "Default branch and root context",
+			repoURL:    "https://github.com/testorg/test-repo-1",
+			wantBranch: "main",
+		},
+		{
+			name:        "Existing branch and context",
+			repoURL:     "https://github.com/testorg/test-repo-1",
+			branch:      "main",
+			repoContext: "./",
+			wantBranch:  "main",
+		},
+		{
+			name:        "Existing context directory",
+			repoURL:     "https://github.com/testorg/test-repo-1",
+			repoContext: "gitops/dev",
+			wantBranch:  "main",
+		},
+		{
+			name:        "Missing branch and context are created",
+			repoURL:     "https://github.com/testorg/test-repo-1",
+			branch:      "missing-branch",
+			repoContext: "folderA/missing-context/",
+			wantBranch:  "missing-branch",
+			wantChanges: []string{"created branch missing-branch", "created context directory folderA/missing-context"},
+		},
+		{
+			name:    "Repository not hosted on GitHub",
+			repoURL: "https://gitlab.com/testorg/test-repo-1",
+			wantErr: "the repository https://gitlab.com/testorg/test-repo-1 is not hosted on github.com",
+		},
+		{
+			name:    "Invalid URL",
+			repoURL: "http://github.com/?org\nrepo",
+			wantErr: "invalid repository URL",
+		},
+		{
+			name:    "Unreachable repository",
+			repoURL: "https://github.com/testorg/test-error-response",
+			wantErr: "failed to get repo test-error-response under testorg",
+		},
+		{
+			name:    "No write access",
+			repoURL: "https://github.com/testorg/test-repo-2",
+			wantErr: "no write access to the repository https://github.com/testorg/test-repo-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedClient := GitHubClient{Client: GetMockedClient()}
+
+			branch, changes, err := mockedClient.ValidateRepository(context.Background(), tt.repoURL, tt.branch, tt.repoContext)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tt.wantBranch, branch)
+			assert.Equal(t, tt.wantChanges, changes)
+		})
+	}
+}
+
+func TestIsGitHubRepository(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoURL string
+		want    bool
+	}{
+		{
+			name:    "GitHub repository",
+			repoURL: "https://github.com/testorg/test-repo-1",
+			want:    true,
+		},
+		{
+			name:    "GitLab repository",
+			repoURL: "https://gitlab.com/testorg/test-repo-1",
+		},
+		{
+			name:    "GitHub Enterprise repository",
+			repoURL: "https://github.example.com/testorg/test-repo-1",
+		},
+		{
+			name:    "Invalid URL",
+			repoURL: "http://github.com/?org\nrepo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsGitHubRepository(tt.repoURL))
+		})
+	}
+}
@@ -244,6 +244,17 @@ func CheckWithRegex(pattern, name string) bool {
 	return reg.MatchString(name)
 }
 
+// RemoveString returns the slice without any occurrence of s
+func RemoveString(slice []string, s string) []string {
+	var result []string
+	for _, item := range slice {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 const schemaBytes = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
 
 // GetRandomString returns a random string which is n characters long.
@@ -466,6 +466,41 @@ func TestCheckWithRegex(t *testing.T) {
 	}
 }
 
+func TestRemoveString(t *testing.T) {
+	tests := []struct {
+		name  string
+		slice []string
+		s     string
+		want  []string
+	}{
+		{
+			name:  "string removed",
+			slice: []string{"deployment.yaml", "service.yaml", "deployment.yaml"},
+			s:     "deployment.yaml",
+			want:  []string{"service.yaml"},
+		},
+		{
+			name:  "string not in the slice",
+			slice: []string{"service.yaml"},
+			s:     "deployment.yaml",
+			want:  []string{"service.yaml"},
+		},
+		{
+			name:  "empty slice",
+			slice: nil,
+			s:     "deployment.yaml",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RemoveString(tt.slice, tt.s)
+			assert.Equal(t, tt.want, got, "the values should match")
+		})
+	}
+}
+
 func TestGetRandomString(t *testing.T) {
 	tests := []struct {
 		name   string